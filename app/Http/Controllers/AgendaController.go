@@ -3,10 +3,13 @@ package controllers
 import (
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	requests "github.com/cvudumbarainformatika/backend/app/Http/Requests"
+	mail "github.com/cvudumbarainformatika/backend/app/Mail"
 	models "github.com/cvudumbarainformatika/backend/app/Models"
+	"github.com/cvudumbarainformatika/backend/config"
 	"github.com/cvudumbarainformatika/backend/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
@@ -14,18 +17,22 @@ import (
 
 // AgendaController handles agenda (event) operations
 type AgendaController struct {
-	db *sqlx.DB
+	db     *sqlx.DB
+	config *config.Config
+	mailer *mail.Mailer
 }
 
 // NewAgendaController creates a new AgendaController instance
-func NewAgendaController(db *sqlx.DB) *AgendaController {
+func NewAgendaController(db *sqlx.DB, cfg *config.Config, mailer *mail.Mailer) *AgendaController {
 	return &AgendaController{
-		db: db,
+		db:     db,
+		config: cfg,
+		mailer: mailer,
 	}
 }
 
 // GetList returns paginated list of agenda with optional filters
-// GET /api/v1/agenda?page=&limit=&type=&status=&upcoming=&sort=&order=
+// GET /api/v1/agenda?page=&limit=&type=&status=&upcoming=&series_id=&sort=&order=
 func (ac *AgendaController) GetList(c *gin.Context) {
 	// Get pagination parameters
 	page, limit := utils.GetPaginationParams(c)
@@ -45,6 +52,16 @@ func (ac *AgendaController) GetList(c *gin.Context) {
 		"upcoming": upcoming,
 	}
 
+	// Occurrences of one recurring series
+	if seriesID := c.Query("series_id"); seriesID != "" {
+		parsed, err := strconv.ParseInt(seriesID, 10, 64)
+		if err != nil {
+			utils.Error(c, http.StatusBadRequest, "invalid_series_id", "Invalid series_id", nil)
+			return
+		}
+		filters["series_id"] = parsed
+	}
+
 	// Calculate offset
 	offset := (page - 1) * limit
 
@@ -70,10 +87,15 @@ func (ac *AgendaController) GetList(c *gin.Context) {
 	})
 }
 
-// GetBySlug returns a single agenda by slug
+// GetBySlug returns a single agenda by slug, or an iCalendar file when the slug ends with .ics
 // GET /api/v1/agenda/:slug
+// GET /api/v1/agenda/:slug.ics
 func (ac *AgendaController) GetBySlug(c *gin.Context) {
 	slug := c.Param("slug")
+	if strings.HasSuffix(slug, ".ics") {
+		ac.exportICS(c, strings.TrimSuffix(slug, ".ics"))
+		return
+	}
 
 	agenda, err := models.FindAgendaBySlug(ac.db, slug)
 	if err != nil {
@@ -82,11 +104,72 @@ func (ac *AgendaController) GetBySlug(c *gin.Context) {
 	}
 
 	if agenda == nil {
+		// Redirect old slugs to the canonical slug
+		currentSlug, err := models.FindCurrentSlug(ac.db, models.SlugEntityAgenda, slug)
+		if err == nil && currentSlug != "" {
+			utils.Redirect(c, http.StatusMovedPermanently, "/api/v1/agenda/"+currentSlug, "Agenda has moved", gin.H{
+				"redirect": true,
+				"slug":     currentSlug,
+			})
+			return
+		}
+
 		utils.Error(c, http.StatusNotFound, "agenda_not_found", "Agenda not found", nil)
 		return
 	}
 
-	utils.Success(c, http.StatusOK, "Agenda retrieved successfully", formatAgendaResponse(*agenda))
+	speakers, err := models.GetAgendaSpeakers(ac.db, agenda.ID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch speakers", nil)
+		return
+	}
+	sessions, err := models.GetAgendaSessions(ac.db, agenda.ID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch rundown", nil)
+		return
+	}
+
+	// Each session carries its own SKP, the total is what attending the whole rundown earns
+	rundown := make([]gin.H, 0, len(sessions))
+	var sessionsSKP float64
+	for _, session := range sessions {
+		rundown = append(rundown, formatSessionResponse(session))
+		sessionsSKP += session.SKP
+	}
+
+	response := formatAgendaResponse(*agenda)
+	response["speakers"] = speakers
+	response["sessions"] = rundown
+	response["sessions_skp_total"] = sessionsSKP
+
+	utils.Success(c, http.StatusOK, "Agenda retrieved successfully", response)
+}
+
+// exportICS returns a published agenda as an iCalendar file
+func (ac *AgendaController) exportICS(c *gin.Context, slug string) {
+	agenda, err := models.FindAgendaBySlug(ac.db, slug)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch agenda", nil)
+		return
+	}
+
+	if agenda == nil {
+		currentSlug, err := models.FindCurrentSlug(ac.db, models.SlugEntityAgenda, slug)
+		if err == nil && currentSlug != "" {
+			utils.Redirect(c, http.StatusMovedPermanently, "/api/v1/agenda/"+currentSlug+".ics", "Agenda has moved", gin.H{
+				"redirect": true,
+				"slug":     currentSlug,
+			})
+			return
+		}
+	}
+
+	if agenda == nil || agenda.Status != "published" {
+		utils.Error(c, http.StatusNotFound, "agenda_not_found", "Agenda not found", nil)
+		return
+	}
+
+	writeICalendar(c, ac.config, agenda.Slug+".ics", agenda.Title, []utils.ICalEvent{agendaICalEvent(ac.config, *agenda)})
 }
 
 // Create creates a new agenda
@@ -98,8 +181,11 @@ func (ac *AgendaController) Create(c *gin.Context) {
 		return
 	}
 
-	// Generate slug from title
+	// Generate slug from title, all-digit slugs are reserved for agenda IDs
 	slug := utils.GenerateSlug(req.Title)
+	if utils.IsNumericSlug(slug) {
+		slug = "agenda-" + slug
+	}
 
 	// Check if slug already exists
 	existing, _ := models.FindAgendaBySlug(ac.db, slug)
@@ -122,22 +208,38 @@ func (ac *AgendaController) Create(c *gin.Context) {
 		}
 	}
 
+	// Items belong to the organization unit of their creator
+	scope, ok := currentOrgScope(c, ac.db)
+	if !ok {
+		return
+	}
+
+	province, ok := normalizeProvince(c, ac.db, req.Province)
+	if !ok {
+		return
+	}
+
 	// Create agenda model
 	agenda := &models.Agenda{
-		Slug:            slug,
-		Title:           req.Title,
-		Description:     req.Description,
-		Type:            req.Type,
-		Date:            eventDate,
-		EndDate:         endDate,
-		IsOnline:        req.IsOnline,
-		Location:        req.Location,
-		SKP:             req.Skp, // Note: Model field is SKP (capitalized in previous thought, check model file)
-		Quota:           req.Quota,
-		RegistrationURL: req.RegistrationURL,
-		ImageURL:        req.ImageURL,
-		Fee:             req.Fee,
-		Status:          req.Status,
+		Slug:             slug,
+		Title:            req.Title,
+		Description:      utils.SanitizeHTML(req.Description),
+		Type:             req.Type,
+		Date:             eventDate,
+		EndDate:          endDate,
+		IsOnline:         req.IsOnline,
+		Location:         req.Location,
+		Province:         province,
+		SKP:              req.Skp, // Note: Model field is SKP (capitalized in previous thought, check model file)
+		Quota:            req.Quota,
+		RegistrationURL:  req.RegistrationURL,
+		RegistrationForm: req.RegistrationForm,
+		ImageURL:         req.ImageURL,
+		Fee:              req.Fee,
+		Price:            req.Price,
+		ReminderOffsets:  req.ReminderOffsets,
+		Status:           req.Status,
+		Cabang:           scope.OwnerCabang(),
 	}
 
 	// Set published_at if status is published
@@ -187,6 +289,11 @@ func (ac *AgendaController) Update(c *gin.Context) {
 		return
 	}
 
+	province, ok := normalizeProvince(c, ac.db, req.Province)
+	if !ok {
+		return
+	}
+
 	// Parse dates
 	eventDate, err := time.Parse(time.RFC3339, req.Date)
 	if err != nil {
@@ -204,21 +311,50 @@ func (ac *AgendaController) Update(c *gin.Context) {
 		endDate = nil
 	}
 
+	// Change slug if provided, keeping the old one for redirects
+	oldSlug := agenda.Slug
+	oldQuota := agenda.Quota
+	if req.Slug != "" {
+		newSlug := utils.NormalizeSlug(req.Slug)
+		if !utils.ValidateSlug(newSlug) {
+			utils.Error(c, http.StatusBadRequest, "invalid_slug", "Invalid slug", nil)
+			return
+		}
+
+		existing, _ := models.FindAgendaBySlug(ac.db, newSlug)
+		if existing != nil && existing.ID != agenda.ID {
+			utils.Error(c, http.StatusConflict, "slug_exists", "Slug is already used by another agenda", nil)
+			return
+		}
+		agenda.Slug = newSlug
+	}
+
 	// Update fields
 	agenda.Title = req.Title
-	agenda.Description = req.Description
+	agenda.Description = utils.SanitizeHTML(req.Description)
 	agenda.Type = req.Type
 	agenda.Date = eventDate
 	agenda.EndDate = endDate
 	agenda.IsOnline = req.IsOnline
 	agenda.Location = req.Location
+	agenda.Province = province
 	agenda.SKP = req.Skp
 	agenda.Quota = req.Quota
 	agenda.RegistrationURL = req.RegistrationURL
+	if req.RegistrationForm != nil {
+		agenda.RegistrationForm = req.RegistrationForm
+	}
 	agenda.ImageURL = req.ImageURL
 	agenda.Fee = req.Fee
+	agenda.Price = req.Price
+	if req.ReminderOffsets != nil {
+		agenda.ReminderOffsets = req.ReminderOffsets
+	}
 	agenda.Status = req.Status
 
+	// An occurrence edited on its own is no longer changed by series edits
+	agenda.IsException = agenda.SeriesID != nil
+
 	// Update published_at logic
 	if req.Status == "published" {
 		if req.PublishedAt != nil && *req.PublishedAt != "" {
@@ -239,6 +375,26 @@ func (ac *AgendaController) Update(c *gin.Context) {
 		return
 	}
 
+	if err := models.RecordSlugChange(ac.db, models.SlugEntityAgenda, agenda.ID, oldSlug, agenda.Slug); err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to record slug history: "+err.Error(), nil)
+		return
+	}
+
+	// A raised (or removed) quota frees seats for the waitlist
+	if agenda.Quota <= 0 || agenda.Quota > oldQuota {
+		promoted, err := models.PromoteWaitlist(ac.db, agenda.ID)
+		if err != nil {
+			utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to promote waitlist: "+err.Error(), nil)
+			return
+		}
+		notifyWaitlistPromotions(ac.db, ac.mailer, ac.config.SEO.SiteURL, *agenda, promoted)
+	}
+
+	// Reload to include the current seat usage
+	if reloaded, err := models.FindAgendaByID(ac.db, agenda.ID); err == nil && reloaded != nil {
+		agenda = reloaded
+	}
+
 	utils.Success(c, http.StatusOK, "Agenda updated successfully", formatAgendaResponse(*agenda))
 }
 
@@ -341,6 +497,34 @@ func (ac *AgendaController) Delete(c *gin.Context) {
 	utils.Success(c, http.StatusOK, "Agenda deleted successfully", nil)
 }
 
+// Bulk applies one action to many agenda in a single transaction
+// POST /api/v1/agenda/bulk
+func (ac *AgendaController) Bulk(c *gin.Context) {
+	var req requests.BulkAgendaRequest
+	if err := req.Validate(c); err != nil {
+		return
+	}
+
+	scope, ok := requireAdminScope(c, ac.db)
+	if !ok {
+		return
+	}
+
+	action := models.BulkAction{Action: req.Action}
+	if req.Action == "set_type" {
+		action.Action = models.BulkActionSetCategory
+		action.Category = req.Type
+	}
+
+	results, err := models.ApplyBulkAction(ac.db, "agenda", req.IDs, action, *scope)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to apply bulk action: "+err.Error(), nil)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Bulk action applied", formatBulkResponse(req.Action, results))
+}
+
 // GetTypes returns all unique agenda types
 // GET /api/v1/agenda/types
 func (ac *AgendaController) GetTypes(c *gin.Context) {
@@ -355,26 +539,60 @@ func (ac *AgendaController) GetTypes(c *gin.Context) {
 	})
 }
 
+// findAgendaBySlugParam loads an agenda by the :slug route parameter, writing the error response
+// Admin GET routes share the :slug wildcard with the public GET /agenda/:slug route, so an all-digit
+// value is read as the agenda ID and anything else as its slug
+func findAgendaBySlugParam(c *gin.Context, db *sqlx.DB) (*models.Agenda, bool) {
+	agenda, err := models.FindAgendaBySlugOrID(db, c.Param("slug"))
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch agenda", nil)
+		return nil, false
+	}
+	if agenda == nil {
+		utils.Error(c, http.StatusNotFound, "agenda_not_found", "Agenda not found", nil)
+		return nil, false
+	}
+
+	return agenda, true
+}
+
+// Helper function to format a registration form, agenda without a form get an empty list
+func formatRegistrationForm(form models.RegistrationForm) []utils.FormField {
+	if form == nil {
+		return []utils.FormField{}
+	}
+	return form
+}
+
 // Helper function to format agenda response
 func formatAgendaResponse(agenda models.Agenda) gin.H {
 	response := gin.H{
-		"id":               agenda.ID,
-		"slug":             agenda.Slug,
-		"title":            agenda.Title,
-		"description":      agenda.Description,
-		"type":             agenda.Type,
-		"date":             agenda.Date,
-		"end_date":         agenda.EndDate,
-		"is_online":        agenda.IsOnline,
-		"location":         agenda.Location,
-		"skp":              agenda.SKP,
-		"quota":            agenda.Quota,
-		"registration_url": agenda.RegistrationURL,
-		"image_url":        agenda.ImageURL,
-		"fee":              agenda.Fee,
-		"status":           agenda.Status,
-		"created_at":       agenda.CreatedAt,
-		"updated_at":       agenda.UpdatedAt,
+		"id":                agenda.ID,
+		"slug":              agenda.Slug,
+		"title":             agenda.Title,
+		"description":       agenda.Description,
+		"type":              agenda.Type,
+		"date":              agenda.Date,
+		"end_date":          agenda.EndDate,
+		"is_online":         agenda.IsOnline,
+		"location":          agenda.Location,
+		"province":          agenda.Province,
+		"skp":               agenda.SKP,
+		"quota":             agenda.Quota,
+		"seats_taken":       agenda.SeatsTaken,
+		"remaining_seats":   agenda.RemainingSeats(), // null when the quota is unlimited
+		"waitlist_count":    agenda.WaitlistCount,
+		"registration_url":  agenda.RegistrationURL,
+		"registration_form": formatRegistrationForm(agenda.RegistrationForm),
+		"image_url":         agenda.ImageURL,
+		"fee":               agenda.Fee,
+		"price":             agenda.Price,
+		"price_formatted":   utils.FormatRupiah(agenda.Price),
+		"reminder_offsets":  agenda.ReminderSchedule(),
+		"status":            agenda.Status,
+		"cabang":            agenda.Cabang,
+		"created_at":        agenda.CreatedAt,
+		"updated_at":        agenda.UpdatedAt,
 	}
 
 	if agenda.PublishedAt != nil {
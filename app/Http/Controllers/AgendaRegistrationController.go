@@ -0,0 +1,629 @@
+package controllers
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/csv"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	requests "github.com/cvudumbarainformatika/backend/app/Http/Requests"
+	mail "github.com/cvudumbarainformatika/backend/app/Mail"
+	models "github.com/cvudumbarainformatika/backend/app/Models"
+	payment "github.com/cvudumbarainformatika/backend/app/Payment"
+	"github.com/cvudumbarainformatika/backend/config"
+	"github.com/cvudumbarainformatika/backend/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// AgendaRegistrationController handles member registrations for agenda
+type AgendaRegistrationController struct {
+	db      *sqlx.DB
+	config  *config.Config
+	mailer  *mail.Mailer
+	gateway *payment.Gateway
+}
+
+// NewAgendaRegistrationController creates a new AgendaRegistrationController instance
+func NewAgendaRegistrationController(db *sqlx.DB, cfg *config.Config, mailer *mail.Mailer, gateway *payment.Gateway) *AgendaRegistrationController {
+	return &AgendaRegistrationController{
+		db:      db,
+		config:  cfg,
+		mailer:  mailer,
+		gateway: gateway,
+	}
+}
+
+// Register registers the authenticated member for an agenda, with answers to its registration form
+// POST /api/v1/agenda/:id/registrations
+func (rc *AgendaRegistrationController) Register(c *gin.Context) {
+	var req requests.RegisterAgendaRequest
+	if err := req.Validate(c); err != nil {
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		utils.Error(c, http.StatusUnauthorized, "unauthorized", "User not authenticated", nil)
+		return
+	}
+
+	agenda, ok := rc.findAgenda(c, "id")
+	if !ok {
+		return
+	}
+
+	if agenda.Status != "published" {
+		utils.Error(c, http.StatusBadRequest, "registration_closed", "Agenda is not open for registration", nil)
+		return
+	}
+	if agenda.CancelledAt != nil {
+		utils.Error(c, http.StatusBadRequest, "registration_closed", "Agenda has been cancelled", nil)
+		return
+	}
+	if agendaEndTime(*agenda).Before(time.Now()) {
+		utils.Error(c, http.StatusBadRequest, "registration_closed", "Agenda has already ended", nil)
+		return
+	}
+	if agenda.Price > 0 && !rc.gateway.Enabled() {
+		utils.Error(c, http.StatusServiceUnavailable, "payments_unavailable", "Paid registration is not available yet", nil)
+		return
+	}
+
+	// Answers to questions that are not on the form are dropped
+	answers, answerErrors := utils.ValidateFormAnswers(agenda.RegistrationForm, req.Answers)
+	if answerErrors != nil {
+		utils.ValidationError(c, gin.H{"answers": answerErrors})
+		return
+	}
+
+	registration, err := models.RegisterForAgenda(rc.db, agenda.ID, userID, answers)
+	if err != nil {
+		switch err {
+		case models.ErrAlreadyRegistered:
+			utils.Error(c, http.StatusConflict, "already_registered", "You are already registered for this agenda", nil)
+		case models.ErrAgendaNotFound:
+			utils.Error(c, http.StatusNotFound, "agenda_not_found", "Agenda not found", nil)
+		default:
+			utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to register: "+err.Error(), nil)
+		}
+		return
+	}
+
+	message := "Registered successfully"
+	if registration.Status == models.RegistrationStatusWaitlisted {
+		message = "Agenda is full, you have been added to the waitlist"
+	}
+	response := rc.formatRegistrationWithPosition(*registration)
+
+	// Paid seats are confirmed once the invoice is settled, waitlisted members are invoiced when promoted
+	if agenda.Price > 0 && registration.Status == models.RegistrationStatusPending {
+		message = "Registered successfully, pay the invoice to confirm your seat"
+		response["invoice"] = nil
+		if invoice := rc.issueInvoice(c.Request.Context(), *agenda, *registration); invoice != nil {
+			response["invoice"] = formatInvoiceResponse(*invoice)
+		}
+	}
+
+	utils.Success(c, http.StatusCreated, message, response)
+}
+
+// Cancel cancels the authenticated member's own registration for an agenda
+// DELETE /api/v1/agenda/:id/registrations
+func (rc *AgendaRegistrationController) Cancel(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		utils.Error(c, http.StatusUnauthorized, "unauthorized", "User not authenticated", nil)
+		return
+	}
+
+	agenda, ok := rc.findAgenda(c, "id")
+	if !ok {
+		return
+	}
+
+	registration, err := models.FindAgendaRegistrationByUser(rc.db, agenda.ID, userID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch registration", nil)
+		return
+	}
+	if registration == nil || registration.Status == models.RegistrationStatusCancelled {
+		utils.Error(c, http.StatusNotFound, "registration_not_found", "You are not registered for this agenda", nil)
+		return
+	}
+
+	registration, promoted, err := models.SetAgendaRegistrationStatus(rc.db, registration.ID, models.RegistrationStatusCancelled)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to cancel registration: "+err.Error(), nil)
+		return
+	}
+
+	notifyWaitlistPromotions(rc.db, rc.mailer, rc.config.SEO.SiteURL, *agenda, promoted)
+
+	utils.Success(c, http.StatusOK, "Registration cancelled successfully", formatRegistrationResponse(*registration))
+}
+
+// GetList returns paginated registrations of an agenda with their form answers, as JSON or CSV (admin only)
+// The agenda is addressed by slug or numeric ID
+// GET /api/v1/agenda/:slug/registrations?page=&limit=&status=&search=&registered_from=&registered_to=&format=csv
+func (rc *AgendaRegistrationController) GetList(c *gin.Context) {
+	scope, ok := requireAdminScope(c, rc.db)
+	if !ok {
+		return
+	}
+
+	agenda, ok := findAgendaBySlugParam(c, rc.db)
+	if !ok {
+		return
+	}
+	if !scope.CanManage(agenda.Cabang) {
+		utils.Error(c, http.StatusForbidden, "forbidden", "Agenda is outside your organization scope", nil)
+		return
+	}
+
+	page, limit := utils.GetPaginationParams(c)
+
+	status := c.Query("status")
+	if status != "" && !isRegistrationStatus(status) {
+		utils.Error(c, http.StatusBadRequest, "invalid_status", "Invalid registration status: "+status, nil)
+		return
+	}
+
+	filters := map[string]interface{}{
+		"status": status,
+		"search": c.Query("search"),
+	}
+
+	// Date filters (YYYY-MM-DD, registered_to is inclusive)
+	if from := c.Query("registered_from"); from != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", from, time.Local)
+		if err != nil {
+			utils.Error(c, http.StatusBadRequest, "invalid_date", "Invalid registered_from format (YYYY-MM-DD required)", nil)
+			return
+		}
+		filters["registered_from"] = parsed
+	}
+	if to := c.Query("registered_to"); to != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", to, time.Local)
+		if err != nil {
+			utils.Error(c, http.StatusBadRequest, "invalid_date", "Invalid registered_to format (YYYY-MM-DD required)", nil)
+			return
+		}
+		filters["registered_to"] = parsed.AddDate(0, 0, 1)
+	}
+
+	if c.Query("format") == "csv" {
+		registrations, _, err := models.GetAgendaRegistrationList(rc.db, agenda.ID, filters, 0, 0)
+		if err != nil {
+			utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch registrations: "+err.Error(), nil)
+			return
+		}
+		rc.writeRegistrationsCSV(c, *agenda, registrations)
+		return
+	}
+
+	offset := (page - 1) * limit
+
+	registrations, total, err := models.GetAgendaRegistrationList(rc.db, agenda.ID, filters, offset, limit)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch registrations: "+err.Error(), nil)
+		return
+	}
+
+	registrationResponses := make([]gin.H, len(registrations))
+	for i, registration := range registrations {
+		registrationResponses[i] = formatRegistrationDetailResponse(registration)
+	}
+
+	pagination := utils.OffsetPaginate(registrationResponses, page, limit, total)
+
+	utils.Success(c, http.StatusOK, "Registrations fetched successfully", gin.H{
+		"registration_form": formatRegistrationForm(agenda.RegistrationForm),
+		"items":             pagination.Data,
+		"pagination":        pagination.Meta,
+	})
+}
+
+// GetReminders returns the reminder schedule of an agenda and its paginated delivery log per registrant (admin only)
+// The agenda is addressed by slug or numeric ID
+// GET /api/v1/agenda/:slug/reminders?page=&limit=&status=&user_id=
+func (rc *AgendaRegistrationController) GetReminders(c *gin.Context) {
+	scope, ok := requireAdminScope(c, rc.db)
+	if !ok {
+		return
+	}
+
+	agenda, ok := findAgendaBySlugParam(c, rc.db)
+	if !ok {
+		return
+	}
+	if !scope.CanManage(agenda.Cabang) {
+		utils.Error(c, http.StatusForbidden, "forbidden", "Agenda is outside your organization scope", nil)
+		return
+	}
+
+	page, limit := utils.GetPaginationParams(c)
+
+	status := c.Query("status")
+	switch status {
+	case "", models.ReminderStatusSending, models.ReminderStatusSent, models.ReminderStatusFailed:
+	default:
+		utils.Error(c, http.StatusBadRequest, "invalid_status", "Invalid reminder status: "+status, nil)
+		return
+	}
+
+	filters := map[string]interface{}{
+		"status": status,
+	}
+	if userID, err := strconv.ParseInt(c.Query("user_id"), 10, 64); err == nil {
+		filters["user_id"] = userID
+	}
+
+	offset := (page - 1) * limit
+
+	deliveries, total, err := models.GetAgendaReminderDeliveries(rc.db, agenda.ID, filters, offset, limit)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch reminders: "+err.Error(), nil)
+		return
+	}
+
+	schedule := []gin.H{}
+	for _, minutes := range agenda.ReminderSchedule() {
+		schedule = append(schedule, gin.H{
+			"offset_minutes": minutes,
+			"scheduled_for":  agenda.Date.Add(-time.Duration(minutes) * time.Minute),
+		})
+	}
+
+	pagination := utils.OffsetPaginate(deliveries, page, limit, total)
+
+	utils.Success(c, http.StatusOK, "Reminders fetched successfully", gin.H{
+		"schedule":   schedule,
+		"items":      pagination.Data,
+		"pagination": pagination.Meta,
+	})
+}
+
+// UpdateStatus confirms or cancels a registration (admin only)
+// PATCH /api/v1/agenda/:id/registrations/:regId
+func (rc *AgendaRegistrationController) UpdateStatus(c *gin.Context) {
+	var req requests.UpdateAgendaRegistrationRequest
+	if err := req.Validate(c); err != nil {
+		return
+	}
+
+	scope, ok := requireAdminScope(c, rc.db)
+	if !ok {
+		return
+	}
+
+	agenda, ok := rc.findAgenda(c, "id")
+	if !ok {
+		return
+	}
+	if !scope.CanManage(agenda.Cabang) {
+		utils.Error(c, http.StatusForbidden, "forbidden", "Agenda is outside your organization scope", nil)
+		return
+	}
+
+	regID, err := strconv.ParseInt(c.Param("regId"), 10, 64)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_id", "Invalid registration ID", nil)
+		return
+	}
+
+	registration, err := models.FindAgendaRegistrationByID(rc.db, regID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch registration", nil)
+		return
+	}
+	if registration == nil || registration.AgendaID != agenda.ID {
+		utils.Error(c, http.StatusNotFound, "registration_not_found", "Registration not found", nil)
+		return
+	}
+
+	previousStatus := registration.Status
+	registration, promoted, err := models.SetAgendaRegistrationStatus(rc.db, registration.ID, req.Status)
+	if err != nil {
+		switch err {
+		case models.ErrAgendaFull:
+			utils.Error(c, http.StatusConflict, "agenda_full", "Agenda quota is full, cancel another registration or raise the quota first", nil)
+		case models.ErrRegistrationNotFound:
+			utils.Error(c, http.StatusNotFound, "registration_not_found", "Registration not found", nil)
+		default:
+			utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to update registration: "+err.Error(), nil)
+		}
+		return
+	}
+
+	notifyWaitlistPromotions(rc.db, rc.mailer, rc.config.SEO.SiteURL, *agenda, promoted)
+
+	// Confirmed members receive their QR ticket
+	if registration.Status == models.RegistrationStatusConfirmed && previousStatus != models.RegistrationStatusConfirmed {
+		sendRegistrationTicket(rc.db, rc.config, rc.mailer, *agenda, *registration)
+	}
+
+	utils.Success(c, http.StatusOK, "Registration updated successfully", gin.H{
+		"registration": formatRegistrationResponse(*registration),
+		"promoted":     formatPromotedResponse(promoted),
+	})
+}
+
+// GetMine returns paginated registrations of the authenticated member
+// GET /api/v1/me/registrations?page=&limit=&status=&upcoming=
+func (rc *AgendaRegistrationController) GetMine(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		utils.Error(c, http.StatusUnauthorized, "unauthorized", "User not authenticated", nil)
+		return
+	}
+
+	page, limit := utils.GetPaginationParams(c)
+
+	status := c.Query("status")
+	if status != "" && !isRegistrationStatus(status) {
+		utils.Error(c, http.StatusBadRequest, "invalid_status", "Invalid registration status: "+status, nil)
+		return
+	}
+
+	filters := map[string]interface{}{
+		"status":   status,
+		"upcoming": c.Query("upcoming") == "true",
+	}
+
+	offset := (page - 1) * limit
+
+	registrations, total, err := models.GetUserRegistrations(rc.db, userID, filters, offset, limit)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch registrations: "+err.Error(), nil)
+		return
+	}
+
+	registrationResponses := make([]gin.H, len(registrations))
+	for i, registration := range registrations {
+		registrationResponses[i] = formatRegistrationDetailResponse(registration)
+	}
+
+	pagination := utils.OffsetPaginate(registrationResponses, page, limit, total)
+
+	utils.Success(c, http.StatusOK, "Registrations fetched successfully", gin.H{
+		"items":      pagination.Data,
+		"pagination": pagination.Meta,
+	})
+}
+
+// GetTicket returns the signed QR ticket of the authenticated member's confirmed registration
+// GET /api/v1/me/registrations/:id/ticket
+func (rc *AgendaRegistrationController) GetTicket(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		utils.Error(c, http.StatusUnauthorized, "unauthorized", "User not authenticated", nil)
+		return
+	}
+
+	regID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_id", "Invalid registration ID", nil)
+		return
+	}
+
+	registration, err := models.FindAgendaRegistrationByID(rc.db, regID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch registration", nil)
+		return
+	}
+	if registration == nil || registration.UserID != userID {
+		utils.Error(c, http.StatusNotFound, "registration_not_found", "Registration not found", nil)
+		return
+	}
+	if registration.Status != models.RegistrationStatusConfirmed {
+		utils.Error(c, http.StatusBadRequest, "registration_not_confirmed", "Tickets are issued once the registration is confirmed", nil)
+		return
+	}
+
+	ticket, err := registrationTicket(rc.config, *registration)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "ticket_error", "Failed to issue ticket", nil)
+		return
+	}
+
+	png, err := utils.QRCodePNG(ticket, ticketQRSize)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "ticket_error", "Failed to render ticket QR code", nil)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Ticket retrieved successfully", gin.H{
+		"registration": formatRegistrationResponse(*registration),
+		"ticket":       ticket,
+		"qr_code":      "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	})
+}
+
+// writeRegistrationsCSV writes registrations as a CSV download with one column per form field
+// Answers to fields that were removed from the form after registering are not exported
+func (rc *AgendaRegistrationController) writeRegistrationsCSV(c *gin.Context, agenda models.Agenda, registrations []models.AgendaRegistrationDetail) {
+	loc := utils.LoadTimezone(rc.config.App.Timezone)
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="registrations-`+agenda.Slug+`.csv"`)
+	c.Status(http.StatusOK)
+
+	header := []string{"registration_id", "name", "email", "status", "registered_at", "checked_in_at"}
+	for _, field := range agenda.RegistrationForm {
+		header = append(header, field.Label)
+	}
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write(utils.EscapeCSVRow(header))
+	for _, registration := range registrations {
+		checkedInAt := ""
+		if registration.CheckedInAt != nil {
+			checkedInAt = registration.CheckedInAt.In(loc).Format("2006-01-02 15:04:05")
+		}
+		row := []string{
+			strconv.FormatInt(registration.ID, 10),
+			registration.UserName,
+			registration.UserEmail,
+			registration.Status,
+			registration.RegisteredAt.In(loc).Format("2006-01-02 15:04:05"),
+			checkedInAt,
+		}
+		for _, field := range agenda.RegistrationForm {
+			row = append(row, utils.FormatFormAnswer(registration.Answers[field.Name]))
+		}
+		_ = w.Write(utils.EscapeCSVRow(row))
+	}
+	w.Flush()
+}
+
+// sendRegistrationTicket emails the QR ticket of a confirmed registration
+func sendRegistrationTicket(db *sqlx.DB, cfg *config.Config, mailer *mail.Mailer, agenda models.Agenda, registration models.AgendaRegistration) {
+	user, err := models.FindByID(db, registration.UserID)
+	if err != nil || user == nil {
+		log.Printf("[AgendaRegistration] Cannot send ticket to user #%d: %v", registration.UserID, err)
+		return
+	}
+
+	ticket, err := registrationTicket(cfg, registration)
+	if err != nil {
+		log.Printf("[AgendaRegistration] Failed to sign ticket of registration #%d: %v", registration.ID, err)
+		return
+	}
+	png, err := utils.QRCodePNG(ticket, ticketQRSize)
+	if err != nil {
+		log.Printf("[AgendaRegistration] Failed to render ticket of registration #%d: %v", registration.ID, err)
+	}
+
+	mailer.SendAsync(mail.RegistrationConfirmedMessage(*user, agenda, cfg.SEO.SiteURL, ticket, png))
+}
+
+// issueInvoice issues the invoice of a paid registration, the gateway emails the payment link
+// Failures are only logged: the registration stands and the member can request the invoice again
+func (rc *AgendaRegistrationController) issueInvoice(ctx context.Context, agenda models.Agenda, registration models.AgendaRegistration) *models.Invoice {
+	user, err := models.FindByID(rc.db, registration.UserID)
+	if err != nil || user == nil {
+		log.Printf("[AgendaRegistration] Cannot invoice user #%d: %v", registration.UserID, err)
+		return nil
+	}
+
+	invoice, _, err := rc.gateway.IssueInvoice(ctx, agenda, registration, *user)
+	if err != nil {
+		log.Printf("[AgendaRegistration] Failed to issue invoice for registration #%d: %v", registration.ID, err)
+	}
+	return invoice
+}
+
+// findAgenda loads the agenda identified by a numeric route parameter, writing the error response
+func (rc *AgendaRegistrationController) findAgenda(c *gin.Context, param string) (*models.Agenda, bool) {
+	agendaID, err := strconv.ParseInt(c.Param(param), 10, 64)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_id", "Invalid agenda ID", nil)
+		return nil, false
+	}
+
+	agenda, err := models.FindAgendaByID(rc.db, agendaID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch agenda", nil)
+		return nil, false
+	}
+	if agenda == nil {
+		utils.Error(c, http.StatusNotFound, "agenda_not_found", "Agenda not found", nil)
+		return nil, false
+	}
+
+	return agenda, true
+}
+
+// formatRegistrationWithPosition formats a registration with its waitlist position
+func (rc *AgendaRegistrationController) formatRegistrationWithPosition(registration models.AgendaRegistration) gin.H {
+	response := formatRegistrationResponse(registration)
+	if registration.Status == models.RegistrationStatusWaitlisted {
+		if position, err := models.GetWaitlistPosition(rc.db, registration); err == nil {
+			response["waitlist_position"] = position
+		}
+	}
+	return response
+}
+
+// notifyWaitlistPromotions emails members who were moved off the waitlist
+func notifyWaitlistPromotions(db *sqlx.DB, mailer *mail.Mailer, siteURL string, agenda models.Agenda, promoted []models.AgendaRegistration) {
+	for _, registration := range promoted {
+		user, err := models.FindByID(db, registration.UserID)
+		if err != nil || user == nil {
+			log.Printf("[AgendaRegistration] Cannot notify user #%d about promotion: %v", registration.UserID, err)
+			continue
+		}
+		mailer.SendAsync(mail.WaitlistPromotedMessage(*user, agenda, siteURL))
+	}
+}
+
+// agendaEndTime returns when an agenda ends (its start date when no end date is set)
+func agendaEndTime(agenda models.Agenda) time.Time {
+	if agenda.EndDate != nil {
+		return *agenda.EndDate
+	}
+	return agenda.Date
+}
+
+// isRegistrationStatus reports whether a status is a known registration status
+func isRegistrationStatus(status string) bool {
+	switch status {
+	case models.RegistrationStatusPending, models.RegistrationStatusConfirmed, models.RegistrationStatusCancelled, models.RegistrationStatusWaitlisted:
+		return true
+	}
+	return false
+}
+
+// Helper function to format registration response
+func formatRegistrationResponse(registration models.AgendaRegistration) gin.H {
+	return gin.H{
+		"id":            registration.ID,
+		"agenda_id":     registration.AgendaID,
+		"user_id":       registration.UserID,
+		"status":        registration.Status,
+		"registered_at": registration.RegisteredAt,
+		"updated_at":    registration.UpdatedAt,
+		"checked_in_at": registration.CheckedInAt,
+		"checked_in_by": registration.CheckedInBy,
+		"answers":       formatRegistrationAnswers(registration.Answers),
+	}
+}
+
+// Helper function to format registration form answers, registrations without answers get an empty object
+func formatRegistrationAnswers(answers models.RegistrationAnswers) gin.H {
+	response := gin.H{}
+	for name, answer := range answers {
+		response[name] = answer
+	}
+	return response
+}
+
+// Helper function to format registrations promoted from the waitlist
+func formatPromotedResponse(promoted []models.AgendaRegistration) []gin.H {
+	responses := make([]gin.H, len(promoted))
+	for i, registration := range promoted {
+		responses[i] = formatRegistrationResponse(registration)
+	}
+	return responses
+}
+
+// Helper function to format registration response with user and agenda details
+func formatRegistrationDetailResponse(registration models.AgendaRegistrationDetail) gin.H {
+	response := formatRegistrationResponse(registration.AgendaRegistration)
+	response["user"] = gin.H{
+		"id":    registration.UserID,
+		"name":  registration.UserName,
+		"email": registration.UserEmail,
+	}
+	response["agenda"] = gin.H{
+		"id":    registration.AgendaID,
+		"slug":  registration.AgendaSlug,
+		"title": registration.AgendaTitle,
+		"date":  registration.AgendaDate,
+	}
+	return response
+}
@@ -0,0 +1,468 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	requests "github.com/cvudumbarainformatika/backend/app/Http/Requests"
+	models "github.com/cvudumbarainformatika/backend/app/Models"
+	"github.com/cvudumbarainformatika/backend/config"
+	"github.com/cvudumbarainformatika/backend/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// AgendaRundownController handles the speakers and the session rundown of agenda
+type AgendaRundownController struct {
+	db     *sqlx.DB
+	config *config.Config
+}
+
+// NewAgendaRundownController creates a new AgendaRundownController instance
+func NewAgendaRundownController(db *sqlx.DB, cfg *config.Config) *AgendaRundownController {
+	return &AgendaRundownController{
+		db:     db,
+		config: cfg,
+	}
+}
+
+// CreateSpeaker adds a speaker to an agenda
+// POST /api/v1/agenda/:id/speakers
+func (rc *AgendaRundownController) CreateSpeaker(c *gin.Context) {
+	var req requests.AgendaSpeakerRequest
+	if err := req.Validate(c); err != nil {
+		return
+	}
+
+	agenda, ok := rc.findManagedAgenda(c)
+	if !ok {
+		return
+	}
+
+	speaker := &models.AgendaSpeaker{AgendaID: agenda.ID}
+	if !rc.fillSpeaker(c, speaker, req) {
+		return
+	}
+
+	if err := speaker.Create(rc.db); err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to create speaker", nil)
+		return
+	}
+
+	utils.Success(c, http.StatusCreated, "Speaker created successfully", speaker)
+}
+
+// UpdateSpeaker updates a speaker of an agenda
+// PUT /api/v1/agenda/:id/speakers/:speakerId
+func (rc *AgendaRundownController) UpdateSpeaker(c *gin.Context) {
+	var req requests.AgendaSpeakerRequest
+	if err := req.Validate(c); err != nil {
+		return
+	}
+
+	agenda, ok := rc.findManagedAgenda(c)
+	if !ok {
+		return
+	}
+
+	speakerID, err := strconv.ParseInt(c.Param("speakerId"), 10, 64)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_id", "Invalid speaker ID", nil)
+		return
+	}
+
+	speaker, err := models.FindAgendaSpeaker(rc.db, agenda.ID, speakerID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch speaker", nil)
+		return
+	}
+	if speaker == nil {
+		utils.Error(c, http.StatusNotFound, "speaker_not_found", "Speaker not found", nil)
+		return
+	}
+
+	if !rc.fillSpeaker(c, speaker, req) {
+		return
+	}
+
+	if err := speaker.Update(rc.db); err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to update speaker", nil)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Speaker updated successfully", speaker)
+}
+
+// DeleteSpeaker removes a speaker from an agenda and its sessions
+// DELETE /api/v1/agenda/:id/speakers/:speakerId
+func (rc *AgendaRundownController) DeleteSpeaker(c *gin.Context) {
+	agenda, ok := rc.findManagedAgenda(c)
+	if !ok {
+		return
+	}
+
+	speakerID, err := strconv.ParseInt(c.Param("speakerId"), 10, 64)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_id", "Invalid speaker ID", nil)
+		return
+	}
+
+	if err := models.DeleteAgendaSpeaker(rc.db, agenda.ID, speakerID); err != nil {
+		if errors.Is(err, models.ErrSpeakerNotFound) {
+			utils.Error(c, http.StatusNotFound, "speaker_not_found", "Speaker not found", nil)
+			return
+		}
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to delete speaker", nil)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Speaker deleted successfully", nil)
+}
+
+// CreateSession adds a session to the rundown of an agenda
+// POST /api/v1/agenda/:id/sessions
+func (rc *AgendaRundownController) CreateSession(c *gin.Context) {
+	var req requests.AgendaSessionRequest
+	if err := req.Validate(c); err != nil {
+		return
+	}
+
+	agenda, ok := rc.findManagedAgenda(c)
+	if !ok {
+		return
+	}
+
+	session := &models.AgendaSession{AgendaID: agenda.ID}
+	if !rc.fillSession(c, *agenda, session, req) {
+		return
+	}
+
+	if err := models.CreateAgendaSession(rc.db, session, req.SpeakerIDs, req.ModeratorIDs); err != nil {
+		rc.sessionError(c, err, "Failed to create session")
+		return
+	}
+
+	rc.respondSession(c, http.StatusCreated, "Session created successfully", agenda.ID, session.ID)
+}
+
+// UpdateSession updates a session of the rundown, replacing its speakers and moderators
+// PUT /api/v1/agenda/:id/sessions/:sessionId
+func (rc *AgendaRundownController) UpdateSession(c *gin.Context) {
+	var req requests.AgendaSessionRequest
+	if err := req.Validate(c); err != nil {
+		return
+	}
+
+	agenda, ok := rc.findManagedAgenda(c)
+	if !ok {
+		return
+	}
+
+	sessionID, err := strconv.ParseInt(c.Param("sessionId"), 10, 64)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_id", "Invalid session ID", nil)
+		return
+	}
+
+	session, err := models.FindAgendaSession(rc.db, agenda.ID, sessionID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch session", nil)
+		return
+	}
+	if session == nil {
+		utils.Error(c, http.StatusNotFound, "session_not_found", "Session not found", nil)
+		return
+	}
+
+	if !rc.fillSession(c, *agenda, session, req) {
+		return
+	}
+
+	if err := models.UpdateAgendaSession(rc.db, session, req.SpeakerIDs, req.ModeratorIDs); err != nil {
+		rc.sessionError(c, err, "Failed to update session")
+		return
+	}
+
+	rc.respondSession(c, http.StatusOK, "Session updated successfully", agenda.ID, session.ID)
+}
+
+// DeleteSession removes a session from the rundown of an agenda
+// DELETE /api/v1/agenda/:id/sessions/:sessionId
+func (rc *AgendaRundownController) DeleteSession(c *gin.Context) {
+	agenda, ok := rc.findManagedAgenda(c)
+	if !ok {
+		return
+	}
+
+	sessionID, err := strconv.ParseInt(c.Param("sessionId"), 10, 64)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_id", "Invalid session ID", nil)
+		return
+	}
+
+	if err := models.DeleteAgendaSession(rc.db, agenda.ID, sessionID); err != nil {
+		rc.sessionError(c, err, "Failed to delete session")
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Session deleted successfully", nil)
+}
+
+// ExportRundownICS returns the sessions of a published agenda as an iCalendar file, one event per session
+// GET /api/v1/agenda/:slug/rundown.ics
+func (rc *AgendaRundownController) ExportRundownICS(c *gin.Context) {
+	agenda, err := models.FindAgendaBySlug(rc.db, c.Param("slug"))
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch agenda", nil)
+		return
+	}
+	if agenda == nil || agenda.Status != "published" {
+		utils.Error(c, http.StatusNotFound, "agenda_not_found", "Agenda not found", nil)
+		return
+	}
+
+	sessions, err := models.GetAgendaSessions(rc.db, agenda.ID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch rundown", nil)
+		return
+	}
+
+	events := make([]utils.ICalEvent, 0, len(sessions))
+	for _, session := range sessions {
+		events = append(events, sessionICalEvent(rc.config, *agenda, session))
+	}
+
+	writeICalendar(c, rc.config, agenda.Slug+"-rundown.ics", agenda.Title+" - Rundown", events)
+}
+
+// findManagedAgenda loads an agenda by the :id route parameter and checks the organization scope of the admin
+func (rc *AgendaRundownController) findManagedAgenda(c *gin.Context) (*models.Agenda, bool) {
+	scope, ok := requireAdminScope(c, rc.db)
+	if !ok {
+		return nil, false
+	}
+
+	agendaID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_id", "Invalid agenda ID", nil)
+		return nil, false
+	}
+
+	agenda, err := models.FindAgendaByID(rc.db, agendaID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch agenda", nil)
+		return nil, false
+	}
+	if agenda == nil {
+		utils.Error(c, http.StatusNotFound, "agenda_not_found", "Agenda not found", nil)
+		return nil, false
+	}
+	if !scope.CanManage(agenda.Cabang) {
+		utils.Error(c, http.StatusForbidden, "forbidden", "Agenda is outside your organization scope", nil)
+		return nil, false
+	}
+
+	return agenda, true
+}
+
+// fillSpeaker copies the request into a speaker, checking the linked user and pengurus
+// An empty name is taken from the linked pengurus or user, an empty photo from the linked pengurus
+func (rc *AgendaRundownController) fillSpeaker(c *gin.Context, speaker *models.AgendaSpeaker, req requests.AgendaSpeakerRequest) bool {
+	name := strings.TrimSpace(req.Name)
+	photoURL := req.PhotoURL
+
+	if req.PengurusID != nil {
+		pengurus, err := models.FindPengurusByID(rc.db, *req.PengurusID)
+		if err != nil {
+			utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch pengurus", nil)
+			return false
+		}
+		if pengurus == nil {
+			utils.ValidationError(c, gin.H{"pengurus_id": "pengurus not found"})
+			return false
+		}
+		if name == "" {
+			name = pengurus.Name
+		}
+		if photoURL == "" && pengurus.PhotoURL != nil {
+			photoURL = *pengurus.PhotoURL
+		}
+	}
+
+	if req.UserID != nil {
+		user, err := models.FindByID(rc.db, *req.UserID)
+		if err != nil {
+			utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch user", nil)
+			return false
+		}
+		if user == nil {
+			utils.ValidationError(c, gin.H{"user_id": "user not found"})
+			return false
+		}
+		if name == "" {
+			name = user.Name
+		}
+	}
+
+	speaker.Name = name
+	speaker.Title = req.Title
+	speaker.Institution = req.Institution
+	speaker.Bio = req.Bio
+	speaker.PhotoURL = photoURL
+	speaker.UserID = req.UserID
+	speaker.PengurusID = req.PengurusID
+	speaker.SortOrder = req.SortOrder
+	return true
+}
+
+// fillSession copies the request into a session, checking that the time slot falls on the days of the agenda
+func (rc *AgendaRundownController) fillSession(c *gin.Context, agenda models.Agenda, session *models.AgendaSession, req requests.AgendaSessionRequest) bool {
+	startsAt, err := time.Parse(time.RFC3339, req.StartsAt)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_date", "Invalid starts_at format (RFC3339 required)", nil)
+		return false
+	}
+	endsAt, err := time.Parse(time.RFC3339, req.EndsAt)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_date", "Invalid ends_at format (RFC3339 required)", nil)
+		return false
+	}
+	if !endsAt.After(startsAt) {
+		utils.ValidationError(c, gin.H{"ends_at": "ends_at must be after starts_at"})
+		return false
+	}
+
+	// Sessions may start and end anywhere on the local days the agenda runs
+	loc := utils.LoadTimezone(rc.config.App.Timezone)
+	first := agenda.Date.In(loc)
+	last := first
+	if agenda.EndDate != nil && agenda.EndDate.After(agenda.Date) {
+		last = agenda.EndDate.In(loc)
+	}
+	dayStart := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, loc)
+	dayEnd := time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, loc).AddDate(0, 0, 1)
+	if startsAt.Before(dayStart) || endsAt.After(dayEnd) {
+		utils.ValidationError(c, gin.H{"starts_at": "session must take place on the dates of the agenda"})
+		return false
+	}
+
+	session.Title = req.Title
+	session.Description = req.Description
+	session.StartsAt = startsAt.UTC()
+	session.EndsAt = endsAt.UTC()
+	session.Room = req.Room
+	session.SKP = req.SKP
+	session.SortOrder = req.SortOrder
+	return true
+}
+
+// sessionError writes the error response of a failed session write
+func (rc *AgendaRundownController) sessionError(c *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, models.ErrSessionNotFound):
+		utils.Error(c, http.StatusNotFound, "session_not_found", "Session not found", nil)
+	case errors.Is(err, models.ErrSpeakerNotFound):
+		utils.ValidationError(c, gin.H{"speaker_ids": "speakers and moderators must be speakers of this agenda"})
+	default:
+		utils.Error(c, http.StatusInternalServerError, "database_error", message, nil)
+	}
+}
+
+// respondSession reloads a session with its speakers and writes it as response
+func (rc *AgendaRundownController) respondSession(c *gin.Context, status int, message string, agendaID int64, sessionID int64) {
+	session, err := models.FindAgendaSession(rc.db, agendaID, sessionID)
+	if err != nil || session == nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch session", nil)
+		return
+	}
+
+	utils.Success(c, status, message, formatSessionResponse(*session))
+}
+
+// sessionICalEvent converts a rundown session into an iCalendar event
+func sessionICalEvent(cfg *config.Config, agenda models.Agenda, session models.AgendaSession) utils.ICalEvent {
+	pageURL := cfg.SEO.SiteURL + "/agenda/" + agenda.Slug
+
+	location := agenda.Location
+	if agenda.IsOnline && location == "" {
+		location = "Online"
+	}
+	if session.Room != "" {
+		location = session.Room + ", " + location
+	}
+
+	var lines []string
+	if session.Description != "" {
+		lines = append(lines, session.Description)
+	}
+	for _, speaker := range session.Speakers {
+		label := "Pembicara"
+		if speaker.Role == models.SessionRoleModerator {
+			label = "Moderator"
+		}
+		lines = append(lines, label+": "+speakerDisplayName(speaker.AgendaSpeaker))
+	}
+	lines = append(lines, agenda.Title, pageURL)
+
+	status := "CONFIRMED"
+	if agenda.CancelledAt != nil {
+		status = "CANCELLED"
+	}
+
+	return utils.ICalEvent{
+		UID:          fmt.Sprintf("agenda-session-%d@%s", session.ID, calendarUIDHost(cfg)),
+		Sequence:     session.Sequence + agenda.Sequence,
+		Summary:      session.Title,
+		Description:  strings.Join(lines, "\n\n"),
+		Location:     location,
+		URL:          pageURL,
+		Start:        session.StartsAt,
+		End:          session.EndsAt,
+		Status:       status,
+		Created:      session.CreatedAt,
+		LastModified: session.UpdatedAt,
+	}
+}
+
+// speakerDisplayName returns the name of a speaker with their title and institution
+func speakerDisplayName(speaker models.AgendaSpeaker) string {
+	name := speaker.Name
+	if speaker.Title != "" {
+		name += ", " + speaker.Title
+	}
+	if speaker.Institution != "" {
+		name += " (" + speaker.Institution + ")"
+	}
+	return name
+}
+
+// Helper function to format session response with speakers and moderators split by role
+func formatSessionResponse(session models.AgendaSession) gin.H {
+	speakers := []models.AgendaSpeaker{}
+	moderators := []models.AgendaSpeaker{}
+	for _, speaker := range session.Speakers {
+		if speaker.Role == models.SessionRoleModerator {
+			moderators = append(moderators, speaker.AgendaSpeaker)
+		} else {
+			speakers = append(speakers, speaker.AgendaSpeaker)
+		}
+	}
+
+	return gin.H{
+		"id":          session.ID,
+		"agenda_id":   session.AgendaID,
+		"title":       session.Title,
+		"description": session.Description,
+		"starts_at":   session.StartsAt,
+		"ends_at":     session.EndsAt,
+		"room":        session.Room,
+		"skp":         session.SKP,
+		"sort_order":  session.SortOrder,
+		"speakers":    speakers,
+		"moderators":  moderators,
+	}
+}
@@ -0,0 +1,362 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	requests "github.com/cvudumbarainformatika/backend/app/Http/Requests"
+	mail "github.com/cvudumbarainformatika/backend/app/Mail"
+	models "github.com/cvudumbarainformatika/backend/app/Models"
+	"github.com/cvudumbarainformatika/backend/config"
+	"github.com/cvudumbarainformatika/backend/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// AgendaSeriesController handles recurring agenda series and their occurrences
+type AgendaSeriesController struct {
+	db     *sqlx.DB
+	config *config.Config
+	mailer *mail.Mailer
+}
+
+// NewAgendaSeriesController creates a new AgendaSeriesController instance
+func NewAgendaSeriesController(db *sqlx.DB, cfg *config.Config, mailer *mail.Mailer) *AgendaSeriesController {
+	return &AgendaSeriesController{
+		db:     db,
+		config: cfg,
+		mailer: mailer,
+	}
+}
+
+// Create creates a recurring series with one agenda per occurrence
+// POST /api/v1/agenda/series
+func (sc *AgendaSeriesController) Create(c *gin.Context) {
+	var req requests.AgendaSeriesRequest
+	if err := req.Validate(c); err != nil {
+		return
+	}
+
+	// Items belong to the organization unit of their creator
+	scope, ok := requireAdminScope(c, sc.db)
+	if !ok {
+		return
+	}
+
+	loc := utils.LoadTimezone(sc.config.App.Timezone)
+	series, starts, ok := sc.expandRequest(c, &models.AgendaSeries{}, req, loc)
+	if !ok {
+		return
+	}
+
+	// Occurrence slugs get the date appended, they must not collide with existing agenda
+	series.Slug = utils.GenerateSlug(req.Title)
+	if existing, _ := models.FindAgendaBySlug(sc.db, models.OccurrenceSlug(series.Slug, starts[0], loc)); existing != nil {
+		series.Slug = series.Slug + "-" + strconv.FormatInt(time.Now().Unix(), 10)
+	}
+
+	template, ok := sc.templateFromRequest(c, req)
+	if !ok {
+		return
+	}
+	template.Cabang = scope.OwnerCabang()
+
+	occurrences, err := models.CreateAgendaSeries(sc.db, series, template, starts, loc)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to create agenda series: "+err.Error(), nil)
+		return
+	}
+
+	utils.Success(c, http.StatusCreated, "Agenda series created successfully", formatSeriesResponse(*series, occurrences))
+}
+
+// GetByID returns a series with all of its occurrences
+// GET /api/v1/agenda/series/:id
+func (sc *AgendaSeriesController) GetByID(c *gin.Context) {
+	scope, ok := requireAdminScope(c, sc.db)
+	if !ok {
+		return
+	}
+
+	series, ok := sc.findSeries(c, scope)
+	if !ok {
+		return
+	}
+
+	occurrences, err := models.GetAgendaSeriesOccurrences(sc.db, series.ID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch occurrences: "+err.Error(), nil)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Agenda series retrieved successfully", formatSeriesResponse(*series, occurrences))
+}
+
+// Update edits the whole series: upcoming occurrences follow the new rule and details
+// Occurrences edited on their own (PUT /agenda/:id) and past occurrences are left untouched
+// PUT /api/v1/agenda/series/:id
+func (sc *AgendaSeriesController) Update(c *gin.Context) {
+	var req requests.AgendaSeriesRequest
+	if err := req.Validate(c); err != nil {
+		return
+	}
+
+	scope, ok := requireAdminScope(c, sc.db)
+	if !ok {
+		return
+	}
+
+	series, ok := sc.findSeries(c, scope)
+	if !ok {
+		return
+	}
+	if series.CancelledAt != nil {
+		utils.Error(c, http.StatusBadRequest, "series_cancelled", "A cancelled series cannot be edited", nil)
+		return
+	}
+
+	loc := utils.LoadTimezone(sc.config.App.Timezone)
+	series, starts, ok := sc.expandRequest(c, series, req, loc)
+	if !ok {
+		return
+	}
+
+	template, ok := sc.templateFromRequest(c, req)
+	if !ok {
+		return
+	}
+	template.Cabang = series.Cabang
+
+	result, err := models.SyncAgendaSeries(sc.db, series, template, starts, loc)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to update agenda series: "+err.Error(), nil)
+		return
+	}
+
+	// The quota may have been raised, free seats go to the waitlists
+	for _, agendaID := range result.Updated {
+		promoted, err := models.PromoteWaitlist(sc.db, agendaID)
+		if err != nil || len(promoted) == 0 {
+			continue
+		}
+		if agenda, err := models.FindAgendaByID(sc.db, agendaID); err == nil && agenda != nil {
+			notifyWaitlistPromotions(sc.db, sc.mailer, sc.config.SEO.SiteURL, *agenda, promoted)
+		}
+	}
+
+	occurrences, err := models.GetAgendaSeriesOccurrences(sc.db, series.ID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch occurrences: "+err.Error(), nil)
+		return
+	}
+
+	response := formatSeriesResponse(*series, occurrences)
+	response["changes"] = result
+	utils.Success(c, http.StatusOK, "Agenda series updated successfully", response)
+}
+
+// Cancel cancels the whole series, its upcoming occurrences are marked cancelled
+// DELETE /api/v1/agenda/series/:id
+func (sc *AgendaSeriesController) Cancel(c *gin.Context) {
+	scope, ok := requireAdminScope(c, sc.db)
+	if !ok {
+		return
+	}
+
+	series, ok := sc.findSeries(c, scope)
+	if !ok {
+		return
+	}
+
+	cancelled, err := models.CancelAgendaSeries(sc.db, series.ID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to cancel agenda series: "+err.Error(), nil)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Agenda series cancelled successfully", gin.H{
+		"series_id": series.ID,
+		"cancelled": cancelled,
+	})
+}
+
+// CancelOccurrence cancels a single agenda, such as one occurrence of a series
+// POST /api/v1/agenda/:id/cancel
+func (sc *AgendaSeriesController) CancelOccurrence(c *gin.Context) {
+	sc.setOccurrenceCancelled(c, false)
+}
+
+// RestoreOccurrence takes back the cancellation of a single agenda
+// DELETE /api/v1/agenda/:id/cancel
+func (sc *AgendaSeriesController) RestoreOccurrence(c *gin.Context) {
+	sc.setOccurrenceCancelled(c, true)
+}
+
+// setOccurrenceCancelled cancels or restores the agenda of the :id route parameter
+func (sc *AgendaSeriesController) setOccurrenceCancelled(c *gin.Context, restore bool) {
+	scope, ok := requireAdminScope(c, sc.db)
+	if !ok {
+		return
+	}
+
+	agendaID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_id", "Invalid agenda ID", nil)
+		return
+	}
+
+	agenda, err := models.FindAgendaByID(sc.db, agendaID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch agenda", nil)
+		return
+	}
+	if agenda == nil {
+		utils.Error(c, http.StatusNotFound, "agenda_not_found", "Agenda not found", nil)
+		return
+	}
+	if !scope.CanManage(agenda.Cabang) {
+		utils.Error(c, http.StatusForbidden, "forbidden", "Agenda is outside your organization scope", nil)
+		return
+	}
+
+	if err := models.CancelAgendaOccurrence(sc.db, agenda.ID, restore); err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to update agenda: "+err.Error(), nil)
+		return
+	}
+
+	if reloaded, err := models.FindAgendaByID(sc.db, agenda.ID); err == nil && reloaded != nil {
+		agenda = reloaded
+	}
+
+	message := "Agenda cancelled successfully"
+	if restore {
+		message = "Agenda cancellation taken back successfully"
+	}
+	utils.Success(c, http.StatusOK, message, formatAgendaResponse(*agenda))
+}
+
+// findSeries loads the series of the :id route parameter and checks the organization scope
+func (sc *AgendaSeriesController) findSeries(c *gin.Context, scope *models.OrgScope) (*models.AgendaSeries, bool) {
+	seriesID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_id", "Invalid series ID", nil)
+		return nil, false
+	}
+
+	series, err := models.FindAgendaSeriesByID(sc.db, seriesID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch agenda series", nil)
+		return nil, false
+	}
+	if series == nil {
+		utils.Error(c, http.StatusNotFound, "series_not_found", "Agenda series not found", nil)
+		return nil, false
+	}
+	if !scope.CanManage(series.Cabang) {
+		utils.Error(c, http.StatusForbidden, "forbidden", "Agenda series is outside your organization scope", nil)
+		return nil, false
+	}
+
+	return series, true
+}
+
+// expandRequest applies the rule and first occurrence times of a request to a series and expands its occurrence starts
+func (sc *AgendaSeriesController) expandRequest(c *gin.Context, series *models.AgendaSeries, req requests.AgendaSeriesRequest, loc *time.Location) (*models.AgendaSeries, []time.Time, bool) {
+	start, err := time.Parse(time.RFC3339, req.Date)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_date", "Invalid date format (RFC3339 required)", nil)
+		return nil, nil, false
+	}
+
+	series.DurationSeconds = nil
+	if req.EndDate != nil && *req.EndDate != "" {
+		end, err := time.Parse(time.RFC3339, *req.EndDate)
+		if err != nil || !end.After(start) {
+			utils.Error(c, http.StatusBadRequest, "invalid_date", "end_date must be an RFC3339 date after date", nil)
+			return nil, nil, false
+		}
+		duration := int(end.Sub(start).Seconds())
+		series.DurationSeconds = &duration
+	}
+
+	rule, err := utils.ParseRRule(req.RRule)
+	if err != nil {
+		utils.ValidationError(c, gin.H{"rrule": err.Error()})
+		return nil, nil, false
+	}
+
+	starts, err := rule.Occurrences(start, loc)
+	if err != nil {
+		utils.ValidationError(c, gin.H{"rrule": err.Error()})
+		return nil, nil, false
+	}
+	if len(starts) == 0 {
+		utils.ValidationError(c, gin.H{"rrule": "the rule does not produce any occurrence after date"})
+		return nil, nil, false
+	}
+
+	series.RRule = rule.String()
+	return series, starts, true
+}
+
+// templateFromRequest builds the agenda all occurrences are copied from, writing the error response of an unknown province
+func (sc *AgendaSeriesController) templateFromRequest(c *gin.Context, req requests.AgendaSeriesRequest) (models.Agenda, bool) {
+	province, ok := normalizeProvince(c, sc.db, req.Province)
+	if !ok {
+		return models.Agenda{}, false
+	}
+
+	template := models.Agenda{
+		Title:            req.Title,
+		Description:      utils.SanitizeHTML(req.Description),
+		Type:             req.Type,
+		IsOnline:         req.IsOnline,
+		Location:         req.Location,
+		Province:         province,
+		SKP:              req.Skp,
+		Quota:            req.Quota,
+		RegistrationURL:  req.RegistrationURL,
+		RegistrationForm: req.RegistrationForm,
+		ImageURL:         req.ImageURL,
+		Fee:              req.Fee,
+		Price:            req.Price,
+		ReminderOffsets:  req.ReminderOffsets,
+		Status:           req.Status,
+	}
+
+	if req.Status == "published" {
+		if req.PublishedAt != nil && *req.PublishedAt != "" {
+			if publishedAt, err := time.Parse(time.RFC3339, *req.PublishedAt); err == nil {
+				template.PublishedAt = &publishedAt
+			}
+		}
+		if template.PublishedAt == nil {
+			now := time.Now()
+			template.PublishedAt = &now
+		}
+	}
+
+	return template, true
+}
+
+// Helper function to format series response with its occurrences
+func formatSeriesResponse(series models.AgendaSeries, occurrences []models.Agenda) gin.H {
+	occurrenceResponses := make([]gin.H, len(occurrences))
+	for i, occurrence := range occurrences {
+		occurrenceResponses[i] = formatAgendaResponse(occurrence)
+	}
+
+	return gin.H{
+		"id":               series.ID,
+		"slug":             series.Slug,
+		"rrule":            series.RRule,
+		"starts_at":        series.StartsAt,
+		"duration_seconds": series.DurationSeconds,
+		"cabang":           series.Cabang,
+		"cancelled_at":     series.CancelledAt,
+		"created_at":       series.CreatedAt,
+		"updated_at":       series.UpdatedAt,
+		"occurrences":      occurrenceResponses,
+	}
+}
@@ -0,0 +1,253 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"time"
+
+	requests "github.com/cvudumbarainformatika/backend/app/Http/Requests"
+	models "github.com/cvudumbarainformatika/backend/app/Models"
+	"github.com/cvudumbarainformatika/backend/config"
+	"github.com/cvudumbarainformatika/backend/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// ticketQRSize is the width and height in pixels of ticket QR codes
+const ticketQRSize = 512
+
+// AttendanceController handles ticket check-in and attendance reports of agenda
+type AttendanceController struct {
+	db     *sqlx.DB
+	config *config.Config
+}
+
+// NewAttendanceController creates a new AttendanceController instance
+func NewAttendanceController(db *sqlx.DB, cfg *config.Config) *AttendanceController {
+	return &AttendanceController{
+		db:     db,
+		config: cfg,
+	}
+}
+
+// CheckIn validates a scanned QR ticket and records the attendance
+// POST /api/v1/agenda/:id/check-in
+func (ac *AttendanceController) CheckIn(c *gin.Context) {
+	var req requests.CheckInRequest
+	if err := req.Validate(c); err != nil {
+		return
+	}
+
+	scope, ok := requireAdminScope(c, ac.db)
+	if !ok {
+		return
+	}
+
+	agenda, ok := ac.findManagedAgenda(c, "id", scope)
+	if !ok {
+		return
+	}
+
+	claims, err := utils.VerifyTicket(ac.config.Ticket.Secret, req.Ticket)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_ticket", "Ticket is invalid or has been tampered with", nil)
+		return
+	}
+	if claims.AgendaID != agenda.ID {
+		utils.Error(c, http.StatusBadRequest, "wrong_agenda", "Ticket belongs to another agenda", nil)
+		return
+	}
+
+	registration, err := models.FindAgendaRegistrationByID(ac.db, claims.RegistrationID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch registration", nil)
+		return
+	}
+	if registration == nil || registration.AgendaID != agenda.ID || registration.UserID != claims.UserID {
+		utils.Error(c, http.StatusNotFound, "registration_not_found", "Registration of this ticket no longer exists", nil)
+		return
+	}
+
+	operatorID, _ := currentUserID(c)
+	registration, err = models.CheckInRegistration(ac.db, registration.ID, operatorID)
+	if err != nil {
+		switch err {
+		case models.ErrAlreadyCheckedIn:
+			utils.Error(c, http.StatusConflict, "already_checked_in", "Ticket has already been used to check in", gin.H{
+				"checked_in_at": registration.CheckedInAt,
+				"checked_in_by": registration.CheckedInBy,
+			})
+		case models.ErrNotConfirmed:
+			utils.Error(c, http.StatusBadRequest, "registration_not_confirmed", "Registration is "+registration.Status+", only confirmed registrations can check in", nil)
+		case models.ErrRegistrationNotFound:
+			utils.Error(c, http.StatusNotFound, "registration_not_found", "Registration of this ticket no longer exists", nil)
+		default:
+			utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to check in: "+err.Error(), nil)
+		}
+		return
+	}
+
+	response := formatRegistrationResponse(*registration)
+	if user, err := models.FindByID(ac.db, registration.UserID); err == nil && user != nil {
+		response["user"] = gin.H{
+			"id":    user.ID,
+			"name":  user.Name,
+			"email": user.Email,
+		}
+	}
+
+	utils.Success(c, http.StatusOK, "Checked in successfully", response)
+}
+
+// GetReport returns who checked in and who did not show up, as JSON or CSV
+// The agenda is addressed by slug or numeric ID
+// GET /api/v1/agenda/:slug/attendance?page=&limit=&attendance=checked_in|no_show&search=&format=csv
+func (ac *AttendanceController) GetReport(c *gin.Context) {
+	scope, ok := requireAdminScope(c, ac.db)
+	if !ok {
+		return
+	}
+
+	agenda, ok := findAgendaBySlugParam(c, ac.db)
+	if !ok {
+		return
+	}
+	if !scope.CanManage(agenda.Cabang) {
+		utils.Error(c, http.StatusForbidden, "forbidden", "Agenda is outside your organization scope", nil)
+		return
+	}
+
+	attendance := c.Query("attendance")
+	if attendance != "" && attendance != "checked_in" && attendance != "no_show" {
+		utils.Error(c, http.StatusBadRequest, "invalid_attendance", "attendance must be checked_in or no_show", nil)
+		return
+	}
+
+	filters := map[string]interface{}{
+		"attendance": attendance,
+		"search":     c.Query("search"),
+	}
+
+	if c.Query("format") == "csv" {
+		entries, _, err := models.GetAttendanceReport(ac.db, agenda.ID, filters, 0, 0)
+		if err != nil {
+			utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch attendance: "+err.Error(), nil)
+			return
+		}
+		ac.writeReportCSV(c, *agenda, entries)
+		return
+	}
+
+	summary, err := models.GetAttendanceSummary(ac.db, agenda.ID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch attendance: "+err.Error(), nil)
+		return
+	}
+
+	page, limit := utils.GetPaginationParams(c)
+	offset := (page - 1) * limit
+
+	entries, total, err := models.GetAttendanceReport(ac.db, agenda.ID, filters, offset, limit)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch attendance: "+err.Error(), nil)
+		return
+	}
+
+	entryResponses := make([]gin.H, len(entries))
+	for i, entry := range entries {
+		entryResponses[i] = formatAttendanceResponse(entry)
+	}
+
+	pagination := utils.OffsetPaginate(entryResponses, page, limit, total)
+
+	utils.Success(c, http.StatusOK, "Attendance fetched successfully", gin.H{
+		"agenda": gin.H{
+			"id":    agenda.ID,
+			"slug":  agenda.Slug,
+			"title": agenda.Title,
+			"date":  agenda.Date,
+		},
+		// No-shows are only final once the agenda has ended
+		"ended":      agendaEndTime(*agenda).Before(time.Now()),
+		"summary":    summary,
+		"items":      pagination.Data,
+		"pagination": pagination.Meta,
+	})
+}
+
+// findManagedAgenda loads an agenda by numeric route parameter and checks the organization scope
+func (ac *AttendanceController) findManagedAgenda(c *gin.Context, param string, scope *models.OrgScope) (*models.Agenda, bool) {
+	agendaID, err := strconv.ParseInt(c.Param(param), 10, 64)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_id", "Invalid agenda ID", nil)
+		return nil, false
+	}
+
+	agenda, err := models.FindAgendaByID(ac.db, agendaID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch agenda", nil)
+		return nil, false
+	}
+	if agenda == nil {
+		utils.Error(c, http.StatusNotFound, "agenda_not_found", "Agenda not found", nil)
+		return nil, false
+	}
+	if !scope.CanManage(agenda.Cabang) {
+		utils.Error(c, http.StatusForbidden, "forbidden", "Agenda is outside your organization scope", nil)
+		return nil, false
+	}
+
+	return agenda, true
+}
+
+// writeReportCSV writes the attendance report as a CSV download
+func (ac *AttendanceController) writeReportCSV(c *gin.Context, agenda models.Agenda, entries []models.AttendanceEntry) {
+	loc := utils.LoadTimezone(ac.config.App.Timezone)
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="attendance-`+agenda.Slug+`.csv"`)
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write([]string{"registration_id", "name", "email", "attendance", "checked_in_at", "checked_in_by"})
+	for _, entry := range entries {
+		attendance, checkedInAt, checkedInBy := "no_show", "", ""
+		if entry.CheckedInAt != nil {
+			attendance = "checked_in"
+			checkedInAt = entry.CheckedInAt.In(loc).Format("2006-01-02 15:04:05")
+		}
+		if entry.CheckedInByName != nil {
+			checkedInBy = *entry.CheckedInByName
+		}
+		_ = w.Write(utils.EscapeCSVRow([]string{
+			strconv.FormatInt(entry.ID, 10),
+			entry.UserName,
+			entry.UserEmail,
+			attendance,
+			checkedInAt,
+			checkedInBy,
+		}))
+	}
+	w.Flush()
+}
+
+// registrationTicket signs the QR ticket of a registration
+func registrationTicket(cfg *config.Config, registration models.AgendaRegistration) (string, error) {
+	return utils.SignTicket(cfg.Ticket.Secret, utils.TicketClaims{
+		RegistrationID: registration.ID,
+		AgendaID:       registration.AgendaID,
+		UserID:         registration.UserID,
+	})
+}
+
+// Helper function to format attendance report entry
+func formatAttendanceResponse(entry models.AttendanceEntry) gin.H {
+	response := formatRegistrationDetailResponse(entry.AgendaRegistrationDetail)
+	response["attendance"] = "no_show"
+	if entry.CheckedInAt != nil {
+		response["attendance"] = "checked_in"
+	}
+	response["checked_in_by_name"] = entry.CheckedInByName
+	return response
+}
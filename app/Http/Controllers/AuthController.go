@@ -103,6 +103,8 @@ func (ac *AuthController) Register(c *gin.Context) {
 			"email":      user.Email,
 			"phone":      getStringValue(user.Phone),
 			"address":    getStringValue(user.Address),
+			"province":   getStringValue(user.Province),
+			"city":       getStringValue(user.City),
 			"bio":        getStringValue(user.Bio),
 			"avatar":     getStringValue(user.Avatar),
 			"cabang":     getStringValue(user.Cabang),
@@ -185,6 +187,8 @@ func (ac *AuthController) Login(c *gin.Context) {
 			"email":      user.Email,
 			"phone":      getStringValue(user.Phone),
 			"address":    getStringValue(user.Address),
+			"province":   getStringValue(user.Province),
+			"city":       getStringValue(user.City),
 			"bio":        getStringValue(user.Bio),
 			"avatar":     getStringValue(user.Avatar),
 			"cabang":     getStringValue(user.Cabang),
@@ -242,6 +246,8 @@ func (ac *AuthController) Me(c *gin.Context) {
 		"email":      user.Email,
 		"phone":      getStringValue(user.Phone),
 		"address":    getStringValue(user.Address),
+		"province":   getStringValue(user.Province),
+		"city":       getStringValue(user.City),
 		"bio":        getStringValue(user.Bio),
 		"avatar":     getStringValue(user.Avatar),
 		"cabang":     getStringValue(user.Cabang),
@@ -413,12 +419,21 @@ func (ac *AuthController) UpdateProfile(c *gin.Context) {
 	}
 	// If no file upload, avatar field from JSON stays as is (can be empty string or existing URL)
 
+	province, city, ok := normalizeRegion(c, ac.db, req.Province, req.City)
+	if !ok {
+		return
+	}
+
 	// Update user profile fields
 	user.Name = req.Name
 	user.Phone.String = req.Phone
 	user.Phone.Valid = req.Phone != ""
 	user.Address.String = req.Address
 	user.Address.Valid = req.Address != ""
+	user.Province.String = province
+	user.Province.Valid = province != ""
+	user.City.String = city
+	user.City.Valid = city != ""
 	user.Bio.String = req.Bio
 	user.Bio.Valid = req.Bio != ""
 	user.Cabang.String = req.Cabang
@@ -444,6 +459,8 @@ func (ac *AuthController) UpdateProfile(c *gin.Context) {
 		"email":      user.Email,
 		"phone":      getStringValue(user.Phone),
 		"address":    getStringValue(user.Address),
+		"province":   getStringValue(user.Province),
+		"city":       getStringValue(user.City),
 		"bio":        getStringValue(user.Bio),
 		"avatar":     getStringValue(user.Avatar), // Already contains API endpoint URL
 		"cabang":     getStringValue(user.Cabang),
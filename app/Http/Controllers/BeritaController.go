@@ -64,7 +64,7 @@ func (bc *BeritaController) GetList(c *gin.Context) {
 	}
 
 	// Build query
-	query := `SELECT id, slug, title, excerpt, content, image_url, category, author, status, views, published_at, created_at, updated_at, deleted_at 
+	query := `SELECT id, slug, title, excerpt, content, image_url, category, author, cabang, status, views, published_at, created_at, updated_at, deleted_at 
 	          FROM berita WHERE deleted_at IS NULL`
 	args := []interface{}{}
 
@@ -159,6 +159,16 @@ func (bc *BeritaController) GetBySlug(c *gin.Context) {
 	}
 
 	if berita == nil {
+		// Redirect old slugs to the canonical slug
+		currentSlug, err := models.FindCurrentSlug(bc.db, models.SlugEntityBerita, slug)
+		if err == nil && currentSlug != "" {
+			utils.Redirect(c, http.StatusMovedPermanently, "/api/v1/berita/"+currentSlug, "Berita has moved", gin.H{
+				"redirect": true,
+				"slug":     currentSlug,
+			})
+			return
+		}
+
 		utils.Error(c, http.StatusNotFound, "berita_not_found", "Berita not found", nil)
 		return
 	}
@@ -166,6 +176,8 @@ func (bc *BeritaController) GetBySlug(c *gin.Context) {
 	// Increment views
 	_, _ = bc.db.Exec(`UPDATE berita SET views = views + 1 WHERE id = ?`, berita.ID)
 
+	berita.Tags, _ = models.GetBeritaTags(bc.db, berita.ID)
+
 	utils.Success(c, http.StatusOK, "Berita retrieved successfully", formatBeritaResponse(*berita, true))
 }
 
@@ -188,15 +200,22 @@ func (bc *BeritaController) Create(c *gin.Context) {
 		slug = slug + "-" + strconv.FormatInt(time.Now().Unix(), 10)
 	}
 
+	// Items belong to the organization unit of their creator
+	scope, ok := currentOrgScope(c, bc.db)
+	if !ok {
+		return
+	}
+
 	// Create berita model
 	berita := &models.Berita{
 		Slug:     slug,
 		Title:    req.Title,
 		Excerpt:  req.Excerpt,
-		Content:  req.Content,
+		Content:  utils.SanitizeHTML(req.Content),
 		ImageURL: req.ImageURL,
 		Category: req.Category,
 		Author:   req.Author,
+		Cabang:   scope.OwnerCabang(),
 		Status:   req.Status,
 		Views:    0,
 		Tags:     req.Tags,
@@ -223,6 +242,11 @@ func (bc *BeritaController) Create(c *gin.Context) {
 		return
 	}
 
+	if err := models.SyncBeritaTags(bc.db, berita.ID, req.Tags); err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to save tags: "+err.Error(), nil)
+		return
+	}
+
 	utils.Success(c, http.StatusCreated, "Berita created successfully", formatBeritaResponse(*berita, true))
 }
 
@@ -249,10 +273,27 @@ func (bc *BeritaController) Update(c *gin.Context) {
 		return
 	}
 
+	// Change slug if provided, keeping the old one for redirects
+	oldSlug := berita.Slug
+	if req.Slug != "" {
+		newSlug := utils.NormalizeSlug(req.Slug)
+		if !utils.ValidateSlug(newSlug) {
+			utils.Error(c, http.StatusBadRequest, "invalid_slug", "Invalid slug", nil)
+			return
+		}
+
+		existing, _ := models.FindBeritaBySlug(bc.db, newSlug)
+		if existing != nil && existing.ID != berita.ID {
+			utils.Error(c, http.StatusConflict, "slug_exists", "Slug is already used by another berita", nil)
+			return
+		}
+		berita.Slug = newSlug
+	}
+
 	// Update fields
 	berita.Title = req.Title
 	berita.Excerpt = req.Excerpt
-	berita.Content = req.Content
+	berita.Content = utils.SanitizeHTML(req.Content)
 	berita.ImageURL = req.ImageURL
 	berita.Category = req.Category
 	berita.Author = req.Author
@@ -279,6 +320,16 @@ func (bc *BeritaController) Update(c *gin.Context) {
 		return
 	}
 
+	if err := models.RecordSlugChange(bc.db, models.SlugEntityBerita, berita.ID, oldSlug, berita.Slug); err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to record slug history: "+err.Error(), nil)
+		return
+	}
+
+	if err := models.SyncBeritaTags(bc.db, berita.ID, req.Tags); err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to save tags: "+err.Error(), nil)
+		return
+	}
+
 	utils.Success(c, http.StatusOK, "Berita updated successfully", formatBeritaResponse(*berita, true))
 }
 
@@ -381,6 +432,34 @@ func (bc *BeritaController) Delete(c *gin.Context) {
 	utils.Success(c, http.StatusOK, "Berita deleted successfully", nil)
 }
 
+// Bulk applies one action to many berita in a single transaction
+// POST /api/v1/berita/bulk
+func (bc *BeritaController) Bulk(c *gin.Context) {
+	var req requests.BulkBeritaRequest
+	if err := req.Validate(c); err != nil {
+		return
+	}
+
+	scope, ok := requireAdminScope(c, bc.db)
+	if !ok {
+		return
+	}
+
+	action := models.BulkAction{
+		Action:   req.Action,
+		Category: req.Category,
+		Tags:     req.Tags,
+	}
+
+	results, err := models.ApplyBulkAction(bc.db, "berita", req.IDs, action, *scope)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to apply bulk action: "+err.Error(), nil)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Bulk action applied", formatBulkResponse(req.Action, results))
+}
+
 // GetCategories returns all unique categories
 // GET /api/v1/berita/categories
 func (bc *BeritaController) GetCategories(c *gin.Context) {
@@ -395,6 +474,26 @@ func (bc *BeritaController) GetCategories(c *gin.Context) {
 	})
 }
 
+// Helper function to format bulk action response
+func formatBulkResponse(action string, results []models.BulkItemResult) gin.H {
+	succeeded := 0
+	for _, result := range results {
+		if result.Success {
+			succeeded++
+		}
+	}
+
+	return gin.H{
+		"action":  action,
+		"results": results,
+		"summary": gin.H{
+			"total":     len(results),
+			"succeeded": succeeded,
+			"failed":    len(results) - succeeded,
+		},
+	}
+}
+
 // Helper function to format berita response
 func formatBeritaResponse(berita models.Berita, includeContent bool) gin.H {
 	response := gin.H{
@@ -405,6 +504,7 @@ func formatBeritaResponse(berita models.Berita, includeContent bool) gin.H {
 		"image_url":  berita.ImageURL,
 		"category":   berita.Category,
 		"author":     berita.Author,
+		"cabang":     berita.Cabang,
 		"status":     berita.Status,
 		"views":      berita.Views,
 		"created_at": berita.CreatedAt,
@@ -0,0 +1,280 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	models "github.com/cvudumbarainformatika/backend/app/Models"
+	"github.com/cvudumbarainformatika/backend/config"
+	"github.com/cvudumbarainformatika/backend/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// calendarFeedHistory is how far back past events stay in calendar feeds
+const calendarFeedHistory = 90 * 24 * time.Hour
+
+// maxCalendarRange is the longest span the calendar range endpoint returns at once
+const maxCalendarRange = 366 * 24 * time.Hour
+
+// calendarDateLayout is the date format of calendar query parameters and day buckets
+const calendarDateLayout = "2006-01-02"
+
+// CalendarController handles calendar views and iCalendar (ICS) feeds of agenda
+type CalendarController struct {
+	db     *sqlx.DB
+	config *config.Config
+}
+
+// NewCalendarController creates a new CalendarController instance
+func NewCalendarController(db *sqlx.DB, cfg *config.Config) *CalendarController {
+	return &CalendarController{
+		db:     db,
+		config: cfg,
+	}
+}
+
+// Feed returns a subscribable calendar of published agenda
+// GET /api/v1/calendar.ics?type=&cabang=&is_online=&province=&location=
+func (cc *CalendarController) Feed(c *gin.Context) {
+	agendas, err := models.GetCalendarAgenda(cc.db, calendarFilters(c, cc.db), time.Now().Add(-calendarFeedHistory))
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch agenda: "+err.Error(), nil)
+		return
+	}
+
+	events := make([]utils.ICalEvent, len(agendas))
+	for i, agenda := range agendas {
+		events[i] = agendaICalEvent(cc.config, agenda)
+	}
+
+	writeICalendar(c, cc.config, "agenda.ics", cc.config.App.Name+" Agenda", events)
+}
+
+// Range returns published agenda overlapping a date range, multi-day agenda are included on every day they span
+// Dates are local dates in the application time zone and both ends are inclusive
+// GET /api/v1/agenda/calendar?from=2025-01-01&to=2025-01-31&type=&cabang=&is_online=&province=&location=
+func (cc *CalendarController) Range(c *gin.Context) {
+	loc := utils.LoadTimezone(cc.config.App.Timezone)
+
+	from, err := parseCalendarDate(c.Query("from"), loc)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_range", "from must be a date (YYYY-MM-DD)", nil)
+		return
+	}
+	to, err := parseCalendarDate(c.Query("to"), loc)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_range", "to must be a date (YYYY-MM-DD)", nil)
+		return
+	}
+	// to is inclusive, the query bound is the start of the following day
+	to = to.AddDate(0, 0, 1)
+	if !to.After(from) || to.Sub(from) > maxCalendarRange {
+		utils.Error(c, http.StatusBadRequest, "invalid_range", "to must not be before from and the range must not exceed 366 days", nil)
+		return
+	}
+
+	agendas, err := models.GetAgendaInRange(cc.db, calendarFilters(c, cc.db), from, to)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch agenda: "+err.Error(), nil)
+		return
+	}
+
+	items := make([]gin.H, len(agendas))
+	for i, agenda := range agendas {
+		items[i] = formatAgendaResponse(agenda)
+	}
+
+	utils.Success(c, http.StatusOK, "Agenda retrieved successfully", gin.H{
+		"from":     from.Format(calendarDateLayout),
+		"to":       to.AddDate(0, 0, -1).Format(calendarDateLayout),
+		"timezone": loc.String(),
+		"items":    items,
+	})
+}
+
+// Month returns the number of published agenda on each day of a month, for month views
+// GET /api/v1/agenda/calendar/month?month=2025-01&type=&cabang=&is_online=&province=&location=
+func (cc *CalendarController) Month(c *gin.Context) {
+	loc := utils.LoadTimezone(cc.config.App.Timezone)
+
+	now := time.Now().In(loc)
+	from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+	if month := c.Query("month"); month != "" {
+		parsed, err := time.ParseInLocation("2006-01", month, loc)
+		if err != nil {
+			utils.Error(c, http.StatusBadRequest, "invalid_range", "month must be formatted as YYYY-MM", nil)
+			return
+		}
+		from = parsed
+	}
+	to := from.AddDate(0, 1, 0)
+
+	agendas, err := models.GetAgendaInRange(cc.db, calendarFilters(c, cc.db), from, to)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch agenda: "+err.Error(), nil)
+		return
+	}
+
+	days := []gin.H{}
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		next := day.AddDate(0, 0, 1)
+		ids := []int64{}
+		for _, agenda := range agendas {
+			end := agenda.Date
+			if agenda.EndDate != nil && agenda.EndDate.After(end) {
+				end = *agenda.EndDate
+			}
+			if agenda.Date.Before(next) && !end.Before(day) {
+				ids = append(ids, agenda.ID)
+			}
+		}
+		days = append(days, gin.H{
+			"date":       day.Format(calendarDateLayout),
+			"count":      len(ids),
+			"agenda_ids": ids,
+		})
+	}
+
+	utils.Success(c, http.StatusOK, "Agenda month retrieved successfully", gin.H{
+		"month":    from.Format("2006-01"),
+		"timezone": loc.String(),
+		"days":     days,
+		"total":    len(agendas),
+	})
+}
+
+// PrivateFeed returns the calendar of agenda a member registered for, authenticated by a secret token
+// GET /api/v1/calendar/:token.ics
+func (cc *CalendarController) PrivateFeed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+	if token == "" {
+		utils.Error(c, http.StatusNotFound, "calendar_not_found", "Calendar not found", nil)
+		return
+	}
+
+	user, err := models.FindByCalendarToken(cc.db, token)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch calendar", nil)
+		return
+	}
+	if user == nil {
+		utils.Error(c, http.StatusNotFound, "calendar_not_found", "Calendar not found", nil)
+		return
+	}
+
+	agendas, err := models.GetUserCalendarAgenda(cc.db, user.ID, time.Now().Add(-calendarFeedHistory))
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch agenda: "+err.Error(), nil)
+		return
+	}
+
+	events := make([]utils.ICalEvent, len(agendas))
+	for i, agenda := range agendas {
+		events[i] = agendaICalEvent(cc.config, agenda.Agenda)
+		// Only confirmed seats are certain
+		if agenda.RegistrationStatus != models.RegistrationStatusConfirmed && agenda.CancelledAt == nil {
+			events[i].Status = "TENTATIVE"
+		}
+	}
+
+	// Private feeds must not be cached by shared caches
+	c.Header("Cache-Control", "private, max-age=300")
+	writeICalendar(c, cc.config, "my-agenda.ics", cc.config.App.Name+" - My Agenda", events)
+}
+
+// GetMyFeed returns the private calendar feed URL of the authenticated member
+// GET /api/v1/me/calendar
+func (cc *CalendarController) GetMyFeed(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		utils.Error(c, http.StatusUnauthorized, "unauthorized", "User not authenticated", nil)
+		return
+	}
+
+	token, err := models.GetCalendarToken(cc.db, userID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch calendar token: "+err.Error(), nil)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Calendar feed retrieved successfully", cc.formatFeedResponse(token))
+}
+
+// ResetMyFeed replaces the private calendar feed URL of the authenticated member
+// POST /api/v1/me/calendar/reset
+func (cc *CalendarController) ResetMyFeed(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		utils.Error(c, http.StatusUnauthorized, "unauthorized", "User not authenticated", nil)
+		return
+	}
+
+	token, err := models.ResetCalendarToken(cc.db, userID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to reset calendar token: "+err.Error(), nil)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Calendar feed reset successfully, the old URL no longer works", cc.formatFeedResponse(token))
+}
+
+// Helper function to format private feed response
+func (cc *CalendarController) formatFeedResponse(token string) gin.H {
+	feedURL := cc.config.App.URL + "/api/v1/calendar/" + token + ".ics"
+	return gin.H{
+		"url":        feedURL,
+		"webcal_url": "webcal://" + strings.TrimPrefix(strings.TrimPrefix(feedURL, "https://"), "http://"),
+	}
+}
+
+// calendarFilters reads the agenda filters shared by calendar views and feeds from the query string
+func calendarFilters(c *gin.Context, db *sqlx.DB) map[string]interface{} {
+	province, _ := canonicalRegion(db, c.Query("province"), "")
+	filters := map[string]interface{}{
+		"type":     c.Query("type"),
+		"cabang":   c.Query("cabang"), // "pusat" selects agenda of the central organization
+		"province": province,
+		"location": strings.TrimSpace(c.Query("location")),
+	}
+	if isOnline := c.Query("is_online"); isOnline != "" {
+		filters["is_online"] = isOnline == "true" || isOnline == "1"
+	}
+	return filters
+}
+
+// parseCalendarDate parses a YYYY-MM-DD date as the start of that day in loc, RFC 3339 timestamps are accepted as well
+func parseCalendarDate(value string, loc *time.Location) (time.Time, error) {
+	if parsed, err := time.ParseInLocation(calendarDateLayout, value, loc); err == nil {
+		return parsed, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	local := parsed.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc), nil
+}
+
+// agendaICalEvent converts an agenda to an iCalendar event
+func agendaICalEvent(cfg *config.Config, agenda models.Agenda) utils.ICalEvent {
+	return agenda.ICalEvent(cfg.SEO.SiteURL, calendarUIDHost(cfg))
+}
+
+// calendarUIDHost returns the host part of event UIDs
+func calendarUIDHost(cfg *config.Config) string {
+	return utils.ICalUIDHost(cfg.App.URL)
+}
+
+// writeICalendar writes events as a text/calendar response
+func writeICalendar(c *gin.Context, cfg *config.Config, filename string, name string, events []utils.ICalEvent) {
+	calendar := utils.ICalendar{
+		Name:     name,
+		Timezone: utils.LoadTimezone(cfg.App.Timezone),
+		Events:   events,
+	}
+
+	c.Header("Content-Disposition", `inline; filename="`+filename+`"`)
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", []byte(calendar.String()))
+}
@@ -30,6 +30,7 @@ type ContentBadge struct {
 }
 
 type ContentInput struct {
+	ID          int64                 `json:"id"` // Optional: set to rename an existing page's slug
 	Slug        string                `json:"slug" binding:"required"`
 	Title       string                `json:"title"`
 	Description string                `json:"description"`
@@ -50,6 +51,8 @@ func (c *ContentController) InitTable() {
 		description TEXT,
 		body LONGTEXT,
 		html LONGTEXT,
+		toc JSON,
+		reading_time INT NOT NULL DEFAULT 0,
 		date DATETIME,
 		image_src VARCHAR(255),
 		badge_label VARCHAR(100),
@@ -78,6 +81,16 @@ func (c *ContentController) GetContentBySlug(ctx *gin.Context) {
 	// fmt.Printf logs removed
 
 	if err == sql.ErrNoRows {
+		// Redirect old slugs to the canonical slug
+		currentSlug, err := models.FindCurrentSlug(c.DB, models.SlugEntityContent, slug)
+		if err == nil && currentSlug != "" {
+			utils.Redirect(ctx, http.StatusMovedPermanently, "/api/v1/dynamic-content/"+currentSlug, "Content has moved", gin.H{
+				"redirect": true,
+				"slug":     currentSlug,
+			})
+			return
+		}
+
 		// Return 404
 		utils.Error(ctx, http.StatusNotFound, "content_not_found", "Content not found", nil)
 		return
@@ -120,19 +133,45 @@ func (c *ContentController) SaveContent(ctx *gin.Context) {
 	// Clean slug
 	input.Slug = strings.Trim(input.Slug, "/")
 
-	// Check if exists
+	// Render markdown (or sanitize raw HTML) on the server, never trusting client HTML
+	rendered, err := utils.RenderContent(input.Body, input.HTML)
+	if err != nil {
+		utils.Error(ctx, http.StatusBadRequest, "invalid_content", err.Error(), nil)
+		return
+	}
+	input.HTML = rendered.HTML
+	toc := models.ContentTOC(rendered.TOC)
+
+	// Check if exists (by ID when renaming, otherwise by slug)
 	var existsID int64
-	checkQuery := `SELECT id FROM content_pages WHERE slug = ?`
-	err = c.DB.Get(&existsID, checkQuery, input.Slug)
+	oldSlug := input.Slug
+	if input.ID > 0 {
+		err = c.DB.Get(&oldSlug, `SELECT slug FROM content_pages WHERE id = ?`, input.ID)
+		if err == sql.ErrNoRows {
+			utils.Error(ctx, http.StatusNotFound, "content_not_found", "Content not found", nil)
+			return
+		}
+		existsID = input.ID
+
+		// Make sure the new slug is not used by another page
+		var otherID int64
+		if oldSlug != input.Slug && c.DB.Get(&otherID, `SELECT id FROM content_pages WHERE slug = ? AND id <> ?`, input.Slug, input.ID) == nil {
+			utils.Error(ctx, http.StatusConflict, "slug_exists", "Slug is already used by another page", nil)
+			return
+		}
+	} else {
+		checkQuery := `SELECT id FROM content_pages WHERE slug = ?`
+		err = c.DB.Get(&existsID, checkQuery, input.Slug)
+	}
 
 	if err == sql.ErrNoRows {
 		// Insert
 		insertQuery := `
-			INSERT INTO content_pages (slug, title, description, body, html, date, image_src, badge_label, authors, created_at, updated_at)
-			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
+			INSERT INTO content_pages (slug, title, description, body, html, toc, reading_time, date, image_src, badge_label, authors, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, NOW(), NOW())
 		`
 		res, err := c.DB.Exec(insertQuery,
-			input.Slug, input.Title, input.Description, input.Body, input.HTML,
+			input.Slug, input.Title, input.Description, input.Body, input.HTML, toc, rendered.ReadingTime,
 			parsedDate, input.Image.Src, input.Badge.Label, input.Authors,
 		)
 		if err != nil {
@@ -141,7 +180,12 @@ func (c *ContentController) SaveContent(ctx *gin.Context) {
 		}
 
 		id, _ := res.LastInsertId()
-		utils.Success(ctx, http.StatusCreated, "Content created successfully", gin.H{"id": id})
+		utils.Success(ctx, http.StatusCreated, "Content created successfully", gin.H{
+			"id":           id,
+			"html":         input.HTML,
+			"toc":          toc,
+			"reading_time": rendered.ReadingTime,
+		})
 
 	} else if err != nil {
 		utils.Error(ctx, http.StatusInternalServerError, "database_error", err.Error(), nil)
@@ -150,11 +194,11 @@ func (c *ContentController) SaveContent(ctx *gin.Context) {
 		// Update
 		updateQuery := `
 			UPDATE content_pages 
-			SET title=?, description=?, body=?, html=?, date=?, image_src=?, badge_label=?, authors=?, updated_at=NOW()
+			SET slug=?, title=?, description=?, body=?, html=?, toc=?, reading_time=?, date=?, image_src=?, badge_label=?, authors=?, updated_at=NOW()
 			WHERE id=?
 		`
 		_, err := c.DB.Exec(updateQuery,
-			input.Title, input.Description, input.Body, input.HTML,
+			input.Slug, input.Title, input.Description, input.Body, input.HTML, toc, rendered.ReadingTime,
 			parsedDate, input.Image.Src, input.Badge.Label, input.Authors,
 			existsID,
 		)
@@ -163,6 +207,17 @@ func (c *ContentController) SaveContent(ctx *gin.Context) {
 			return
 		}
 
-		utils.Success(ctx, http.StatusOK, "Content updated successfully", gin.H{"id": existsID})
+		// Keep the old slug so shared links redirect to the new one
+		if err := models.RecordSlugChange(c.DB, models.SlugEntityContent, existsID, oldSlug, input.Slug); err != nil {
+			utils.Error(ctx, http.StatusInternalServerError, "database_error", err.Error(), nil)
+			return
+		}
+
+		utils.Success(ctx, http.StatusOK, "Content updated successfully", gin.H{
+			"id":           existsID,
+			"html":         input.HTML,
+			"toc":          toc,
+			"reading_time": rendered.ReadingTime,
+		})
 	}
 }
@@ -0,0 +1,395 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	requests "github.com/cvudumbarainformatika/backend/app/Http/Requests"
+	models "github.com/cvudumbarainformatika/backend/app/Models"
+	"github.com/cvudumbarainformatika/backend/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// defaultNearbyRadiusKm is the search radius of nearby direktori when none is given
+const defaultNearbyRadiusKm = 25.0
+
+// maxNearbyRadiusKm is the largest accepted search radius
+const maxNearbyRadiusKm = 500.0
+
+// maxGeoJSONFeatures caps the number of features in the map layer
+const maxGeoJSONFeatures = 5000
+
+// DirektoriController handles the directory of hospitals, clinics and institutions
+type DirektoriController struct {
+	db *sqlx.DB
+}
+
+// NewDirektoriController creates a new DirektoriController instance
+func NewDirektoriController(db *sqlx.DB) *DirektoriController {
+	return &DirektoriController{
+		db: db,
+	}
+}
+
+// GetList returns paginated direktori with optional filters
+// GET /api/v1/direktori?page=&limit=&province=&city=&type=&search=&has_respirologist=&facility=
+func (dc *DirektoriController) GetList(c *gin.Context) {
+	page, limit := utils.GetPaginationParams(c)
+
+	filters, ok := direktoriFilters(c, dc.db)
+	if !ok {
+		return
+	}
+
+	offset := (page - 1) * limit
+
+	direktori, total, err := models.GetAllDirektori(dc.db, filters, offset, limit)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch direktori: "+err.Error(), nil)
+		return
+	}
+
+	direktoriResponses := make([]gin.H, len(direktori))
+	for i, entry := range direktori {
+		direktoriResponses[i] = formatDirektoriResponse(entry)
+	}
+
+	pagination := utils.OffsetPaginate(direktoriResponses, page, limit, total)
+
+	utils.Success(c, http.StatusOK, "Direktori fetched successfully", gin.H{
+		"items":      pagination.Data,
+		"pagination": pagination.Meta,
+	})
+}
+
+// Nearby returns direktori within radius_km of a point, nearest first, with the same filters as GetList
+// GET /api/v1/direktori/nearby?lat=&lng=&radius_km=&page=&limit=&type=&has_respirologist=&facility=
+func (dc *DirektoriController) Nearby(c *gin.Context) {
+	page, limit := utils.GetPaginationParams(c)
+
+	lat, errLat := strconv.ParseFloat(c.Query("lat"), 64)
+	lng, errLng := strconv.ParseFloat(c.Query("lng"), 64)
+	if errLat != nil || errLng != nil || lat < -90 || lat > 90 || lng < -180 || lng > 180 {
+		utils.Error(c, http.StatusBadRequest, "invalid_coordinates", "lat and lng are required decimal degrees", nil)
+		return
+	}
+
+	radiusKm := defaultNearbyRadiusKm
+	if value := c.Query("radius_km"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed <= 0 || parsed > maxNearbyRadiusKm {
+			utils.Error(c, http.StatusBadRequest, "invalid_radius", fmt.Sprintf("radius_km must be between 0 and %g", maxNearbyRadiusKm), nil)
+			return
+		}
+		radiusKm = parsed
+	}
+
+	filters, ok := direktoriFilters(c, dc.db)
+	if !ok {
+		return
+	}
+
+	offset := (page - 1) * limit
+
+	direktori, total, err := models.GetNearbyDirektori(dc.db, lat, lng, radiusKm, filters, offset, limit)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch direktori: "+err.Error(), nil)
+		return
+	}
+
+	direktoriResponses := make([]gin.H, len(direktori))
+	for i, entry := range direktori {
+		response := formatDirektoriResponse(entry.Direktori)
+		response["distance_km"] = math.Round(entry.DistanceKm*100) / 100
+		direktoriResponses[i] = response
+	}
+
+	pagination := utils.OffsetPaginate(direktoriResponses, page, limit, total)
+
+	utils.Success(c, http.StatusOK, "Direktori fetched successfully", gin.H{
+		"origin":     gin.H{"lat": lat, "lng": lng, "radius_km": radiusKm},
+		"items":      pagination.Data,
+		"pagination": pagination.Meta,
+	})
+}
+
+// GeoJSON returns direktori with coordinates as a GeoJSON FeatureCollection for map layers, with the same filters as GetList
+// GET /api/v1/direktori.geojson?province=&type=&has_respirologist=&facility=
+func (dc *DirektoriController) GeoJSON(c *gin.Context) {
+	filters, ok := direktoriFilters(c, dc.db)
+	if !ok {
+		return
+	}
+
+	direktori, err := models.GetMappedDirektori(dc.db, filters, maxGeoJSONFeatures)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch direktori", nil)
+		return
+	}
+
+	features := make([]gin.H, 0, len(direktori))
+	for _, entry := range direktori {
+		if entry.Latitude == nil || entry.Longitude == nil {
+			continue
+		}
+		// GeoJSON positions are longitude first
+		features = append(features, gin.H{
+			"type": "Feature",
+			"id":   entry.ID,
+			"geometry": gin.H{
+				"type":        "Point",
+				"coordinates": []float64{*entry.Longitude, *entry.Latitude},
+			},
+			"properties": gin.H{
+				"id":                entry.ID,
+				"name":              entry.Name,
+				"type":              entry.Type,
+				"address":           entry.Address,
+				"phone":             entry.Phone,
+				"city":              entry.City,
+				"province":          entry.Province,
+				"has_respirologist": entry.HasRespirologist,
+				"facilities":        entry.Facilities,
+				"verified":          entry.VerifiedAt != nil,
+			},
+		})
+	}
+
+	body, err := json.Marshal(gin.H{
+		"type":     "FeatureCollection",
+		"features": features,
+	})
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "encode_error", "Failed to encode map layer", nil)
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=300")
+	c.Data(http.StatusOK, "application/geo+json; charset=utf-8", body)
+}
+
+// GetByID returns a single direktori entry
+// GET /api/v1/direktori/:id
+func (dc *DirektoriController) GetByID(c *gin.Context) {
+	direktori, ok := dc.findDirektori(c)
+	if !ok {
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Direktori retrieved successfully", formatDirektoriResponse(*direktori))
+}
+
+// Create creates a new direktori entry (admin only)
+// POST /api/v1/direktori
+func (dc *DirektoriController) Create(c *gin.Context) {
+	var req requests.DirektoriRequest
+	if err := req.Validate(c); err != nil {
+		return
+	}
+
+	if _, ok := requireAdminScope(c, dc.db); !ok {
+		return
+	}
+
+	province, city, ok := normalizeRegion(c, dc.db, req.Province, req.City)
+	if !ok {
+		return
+	}
+	req.Province, req.City = province, city
+
+	direktori := &models.Direktori{}
+	fillDirektori(direktori, req)
+
+	if err := direktori.Create(dc.db); err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to create direktori: "+err.Error(), nil)
+		return
+	}
+
+	utils.Success(c, http.StatusCreated, "Direktori created successfully", formatDirektoriResponse(*direktori))
+}
+
+// Update updates a direktori entry (admin only)
+// PUT /api/v1/direktori/:id
+func (dc *DirektoriController) Update(c *gin.Context) {
+	var req requests.DirektoriRequest
+	if err := req.Validate(c); err != nil {
+		return
+	}
+
+	if _, ok := requireAdminScope(c, dc.db); !ok {
+		return
+	}
+
+	direktori, ok := dc.findDirektori(c)
+	if !ok {
+		return
+	}
+
+	province, city, ok := normalizeRegion(c, dc.db, req.Province, req.City)
+	if !ok {
+		return
+	}
+	req.Province, req.City = province, city
+
+	fillDirektori(direktori, req)
+
+	if err := direktori.Update(dc.db); err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to update direktori: "+err.Error(), nil)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Direktori updated successfully", formatDirektoriResponse(*direktori))
+}
+
+// Delete moves a direktori entry to the trash (admin only)
+// DELETE /api/v1/direktori/:id
+func (dc *DirektoriController) Delete(c *gin.Context) {
+	if _, ok := requireAdminScope(c, dc.db); !ok {
+		return
+	}
+
+	direktori, ok := dc.findDirektori(c)
+	if !ok {
+		return
+	}
+
+	if err := direktori.Delete(dc.db); err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to delete direktori: "+err.Error(), nil)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Direktori deleted successfully", nil)
+}
+
+// Verify marks a direktori entry as checked by the admin (admin only)
+// POST /api/v1/direktori/:id/verify
+func (dc *DirektoriController) Verify(c *gin.Context) {
+	scope, ok := requireAdminScope(c, dc.db)
+	if !ok {
+		return
+	}
+
+	direktori, ok := dc.findDirektori(c)
+	if !ok {
+		return
+	}
+
+	if err := direktori.MarkVerified(dc.db, scope.UserID); err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to verify direktori: "+err.Error(), nil)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Direktori verified successfully", formatDirektoriResponse(*direktori))
+}
+
+// direktoriFilters reads the list filters shared by the direktori endpoints, writing the error response
+// facility may be repeated or comma separated, entries must have all given facilities
+func direktoriFilters(c *gin.Context, db *sqlx.DB) (map[string]interface{}, bool) {
+	dirType := c.Query("type")
+	if dirType != "" && !isDirektoriType(dirType) {
+		utils.Error(c, http.StatusBadRequest, "invalid_type", "Invalid direktori type: "+dirType, nil)
+		return nil, false
+	}
+
+	province, city := canonicalRegion(db, c.Query("province"), c.Query("city"))
+	filters := map[string]interface{}{
+		"province": province,
+		"city":     city,
+		"type":     dirType,
+		"search":   c.Query("search"),
+	}
+
+	if value := c.Query("has_respirologist"); value != "" {
+		hasRespirologist, err := strconv.ParseBool(value)
+		if err != nil {
+			utils.Error(c, http.StatusBadRequest, "invalid_filter", "has_respirologist must be true or false", nil)
+			return nil, false
+		}
+		filters["has_respirologist"] = hasRespirologist
+	}
+
+	var facilityNames []string
+	for _, value := range c.QueryArray("facility") {
+		facilityNames = append(facilityNames, strings.Split(value, ",")...)
+	}
+	if facilities := models.NormalizeFacilities(facilityNames); len(facilities) > 0 {
+		filters["facilities"] = facilities
+	}
+
+	return filters, true
+}
+
+// findDirektori loads the direktori entry identified by the :id route parameter, writing the error response
+func (dc *DirektoriController) findDirektori(c *gin.Context) (*models.Direktori, bool) {
+	direktoriID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_id", "Invalid direktori ID", nil)
+		return nil, false
+	}
+
+	direktori, err := models.FindDirektoriByID(dc.db, direktoriID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch direktori", nil)
+		return nil, false
+	}
+	if direktori == nil {
+		utils.Error(c, http.StatusNotFound, "direktori_not_found", "Direktori not found", nil)
+		return nil, false
+	}
+
+	return direktori, true
+}
+
+// fillDirektori copies the request into a direktori entry
+func fillDirektori(direktori *models.Direktori, req requests.DirektoriRequest) {
+	direktori.Name = strings.TrimSpace(req.Name)
+	direktori.Type = req.Type
+	direktori.Address = req.Address
+	direktori.Phone = req.Phone
+	direktori.Email = req.Email
+	direktori.Website = req.Website
+	direktori.City = strings.TrimSpace(req.City)
+	direktori.Province = strings.TrimSpace(req.Province)
+	direktori.Latitude = req.Latitude
+	direktori.Longitude = req.Longitude
+	direktori.HasRespirologist = req.HasRespirologist
+	direktori.Facilities = models.NormalizeFacilities(req.Facilities)
+}
+
+// isDirektoriType reports whether a type is a known direktori type
+func isDirektoriType(dirType string) bool {
+	switch dirType {
+	case models.DirektoriTypeRumahSakit, models.DirektoriTypeKlinik, models.DirektoriTypeInstitusi:
+		return true
+	}
+	return false
+}
+
+// Helper function to format direktori response
+func formatDirektoriResponse(direktori models.Direktori) gin.H {
+	return gin.H{
+		"id":                direktori.ID,
+		"name":              direktori.Name,
+		"type":              direktori.Type,
+		"address":           direktori.Address,
+		"phone":             direktori.Phone,
+		"email":             direktori.Email,
+		"website":           direktori.Website,
+		"city":              direktori.City,
+		"province":          direktori.Province,
+		"latitude":          direktori.Latitude,
+		"longitude":         direktori.Longitude,
+		"has_respirologist": direktori.HasRespirologist,
+		"facilities":        direktori.Facilities,
+		"verified":          direktori.VerifiedAt != nil,
+		"verified_at":       direktori.VerifiedAt,
+		"verified_by":       direktori.VerifiedBy,
+		"created_at":        direktori.CreatedAt,
+		"updated_at":        direktori.UpdatedAt,
+	}
+}
@@ -0,0 +1,325 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	jobs "github.com/cvudumbarainformatika/backend/app/Jobs"
+	models "github.com/cvudumbarainformatika/backend/app/Models"
+	"github.com/cvudumbarainformatika/backend/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// maxDirektoriImportSize is the largest accepted spreadsheet upload (10MB)
+const maxDirektoriImportSize = 10 << 20
+
+// maxDirektoriImportRows is the largest number of data rows in one import
+const maxDirektoriImportRows = 10000
+
+// direktoriPreviewRows is how many data rows the import preview returns
+const direktoriPreviewRows = 5
+
+// DirektoriImportController handles spreadsheet import and export of the direktori (admin only)
+type DirektoriImportController struct {
+	db *sqlx.DB
+}
+
+// NewDirektoriImportController creates a new DirektoriImportController instance
+func NewDirektoriImportController(db *sqlx.DB) *DirektoriImportController {
+	return &DirektoriImportController{
+		db: db,
+	}
+}
+
+// Preview reads an uploaded CSV or XLSX file and suggests the column mapping of an import, nothing is stored
+// POST /api/v1/direktori/import/preview (multipart: file)
+func (ic *DirektoriImportController) Preview(c *gin.Context) {
+	if _, ok := requireAdminScope(c, ic.db); !ok {
+		return
+	}
+
+	filename, format, rows, ok := readDirektoriSpreadsheet(c)
+	if !ok {
+		return
+	}
+
+	headers := rows[0]
+	samples := rows[1:]
+	if len(samples) > direktoriPreviewRows {
+		samples = samples[:direktoriPreviewRows]
+	}
+
+	utils.Success(c, http.StatusOK, "Spreadsheet read successfully", gin.H{
+		"filename":          filename,
+		"format":            format,
+		"headers":           headers,
+		"fields":            jobs.DirektoriImportFields,
+		"required_fields":   jobs.DirektoriImportRequiredFields,
+		"suggested_mapping": jobs.SuggestDirektoriMapping(headers),
+		"sample_rows":       samples,
+		"total_rows":        len(rows) - 1,
+	})
+}
+
+// Import starts a background import of an uploaded CSV or XLSX file, rows are upserted on name + city
+// mapping is a JSON object of direktori field to column header and defaults to the suggested mapping
+// Poll GetImport for progress and row errors
+// POST /api/v1/direktori/import (multipart: file, mapping, dry_run)
+func (ic *DirektoriImportController) Import(c *gin.Context) {
+	if _, ok := requireAdminScope(c, ic.db); !ok {
+		return
+	}
+
+	dryRun := false
+	if value := c.PostForm("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			utils.ValidationError(c, gin.H{"dry_run": "dry_run must be true or false"})
+			return
+		}
+		dryRun = parsed
+	}
+
+	filename, format, rows, ok := readDirektoriSpreadsheet(c)
+	if !ok {
+		return
+	}
+	headers := rows[0]
+
+	mapping := jobs.SuggestDirektoriMapping(headers)
+	if value := c.PostForm("mapping"); value != "" {
+		mapping = models.ImportMapping{}
+		if err := json.Unmarshal([]byte(value), &mapping); err != nil {
+			utils.ValidationError(c, gin.H{"mapping": "mapping must be a JSON object of field to column header"})
+			return
+		}
+	}
+
+	columns, err := jobs.ResolveDirektoriMapping(mapping, headers)
+	if err != nil {
+		utils.ValidationError(c, gin.H{"mapping": err.Error()})
+		return
+	}
+
+	directoryImport := &models.DirektoriImport{
+		Filename:  filename,
+		Format:    format,
+		Mapping:   mapping,
+		DryRun:    dryRun,
+		TotalRows: len(rows) - 1,
+		Errors:    models.ImportRowErrors{},
+	}
+	if userID, ok := currentUserID(c); ok {
+		directoryImport.UserID = &userID
+	}
+
+	if err := directoryImport.Create(ic.db); err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to create import: "+err.Error(), nil)
+		return
+	}
+
+	// The job keeps its own copy of the record, the response shows it queued
+	response := formatDirektoriImportResponse(*directoryImport)
+	go jobs.RunDirektoriImport(ic.db, directoryImport, columns, rows[1:])
+
+	utils.Success(c, http.StatusAccepted, "Import started", response)
+}
+
+// GetImport returns the progress and row errors of an import
+// GET /api/v1/direktori/import/:id
+func (ic *DirektoriImportController) GetImport(c *gin.Context) {
+	if _, ok := requireAdminScope(c, ic.db); !ok {
+		return
+	}
+
+	importID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_id", "Invalid import ID", nil)
+		return
+	}
+
+	directoryImport, err := models.FindDirektoriImportByID(ic.db, importID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch import", nil)
+		return
+	}
+	if directoryImport == nil {
+		utils.Error(c, http.StatusNotFound, "import_not_found", "Import not found", nil)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Import retrieved successfully", formatDirektoriImportResponse(*directoryImport))
+}
+
+// Export downloads the direktori matching the list filters as CSV or XLSX
+// The columns match the import fields so an export can be edited and imported again
+// GET /api/v1/direktori/export?format=csv|xlsx&province=&city=&type=&search=&has_respirologist=&facility=
+func (ic *DirektoriImportController) Export(c *gin.Context) {
+	if _, ok := requireAdminScope(c, ic.db); !ok {
+		return
+	}
+
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "xlsx" {
+		utils.Error(c, http.StatusBadRequest, "invalid_format", "format must be csv or xlsx", nil)
+		return
+	}
+
+	filters, ok := direktoriFilters(c, ic.db)
+	if !ok {
+		return
+	}
+
+	direktori, _, err := models.GetAllDirektori(ic.db, filters, 0, 0)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch direktori: "+err.Error(), nil)
+		return
+	}
+
+	rows := [][]string{jobs.DirektoriImportFields}
+	for _, entry := range direktori {
+		rows = append(rows, direktoriExportRow(entry))
+	}
+
+	filename := "direktori-" + time.Now().Format("20060102") + "." + format
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+
+	if format == "xlsx" {
+		c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		c.Status(http.StatusOK)
+		_ = utils.WriteXLSX(c.Writer, "Direktori", rows)
+		return
+	}
+
+	// XLSX cells are written as strings, only CSV cells can turn into formulas
+	for _, row := range rows[1:] {
+		utils.EscapeCSVRow(row)
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+	w := csv.NewWriter(c.Writer)
+	_ = w.WriteAll(rows)
+}
+
+// readDirektoriSpreadsheet reads the uploaded file of an import, writing the error response
+// The first row is the header and at least one data row is required
+func readDirektoriSpreadsheet(c *gin.Context) (string, string, [][]string, bool) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "no_file", "No file uploaded", nil)
+		return "", "", nil, false
+	}
+	if file.Size > maxDirektoriImportSize {
+		utils.Error(c, http.StatusRequestEntityTooLarge, "file_too_large", fmt.Sprintf("File must be at most %dMB", maxDirektoriImportSize>>20), nil)
+		return "", "", nil, false
+	}
+
+	format := utils.SpreadsheetFormat(file.Filename)
+	if format == "" {
+		utils.Error(c, http.StatusBadRequest, "invalid_format", utils.ErrUnsupportedSpreadsheet.Error(), nil)
+		return "", "", nil, false
+	}
+
+	src, err := file.Open()
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "upload_error", "Failed to read uploaded file", nil)
+		return "", "", nil, false
+	}
+	defer src.Close()
+
+	data, err := io.ReadAll(io.LimitReader(src, maxDirektoriImportSize+1))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "upload_error", "Failed to read uploaded file", nil)
+		return "", "", nil, false
+	}
+
+	rows, err := utils.ReadSpreadsheet(file.Filename, data)
+	if err != nil {
+		if errors.Is(err, utils.ErrUnsupportedSpreadsheet) {
+			utils.Error(c, http.StatusBadRequest, "invalid_format", err.Error(), nil)
+		} else {
+			utils.Error(c, http.StatusBadRequest, "invalid_spreadsheet", "Failed to read spreadsheet: "+err.Error(), nil)
+		}
+		return "", "", nil, false
+	}
+
+	if len(rows) < 2 {
+		utils.Error(c, http.StatusBadRequest, "empty_spreadsheet", "The spreadsheet needs a header row and at least one data row", nil)
+		return "", "", nil, false
+	}
+	if len(rows)-1 > maxDirektoriImportRows {
+		utils.Error(c, http.StatusBadRequest, "too_many_rows", fmt.Sprintf("The spreadsheet has more than %d data rows, split it into smaller files", maxDirektoriImportRows), nil)
+		return "", "", nil, false
+	}
+
+	return file.Filename, format, rows, true
+}
+
+// direktoriExportRow returns the cells of a direktori entry in DirektoriImportFields order
+func direktoriExportRow(entry models.Direktori) []string {
+	coordinate := func(value *float64) string {
+		if value == nil {
+			return ""
+		}
+		return strconv.FormatFloat(*value, 'f', -1, 64)
+	}
+
+	hasRespirologist := "no"
+	if entry.HasRespirologist {
+		hasRespirologist = "yes"
+	}
+
+	return []string{
+		entry.Name,
+		entry.Type,
+		entry.Address,
+		entry.Phone,
+		entry.Email,
+		entry.Website,
+		entry.City,
+		entry.Province,
+		coordinate(entry.Latitude),
+		coordinate(entry.Longitude),
+		hasRespirologist,
+		strings.Join(entry.Facilities, "; "),
+	}
+}
+
+// Helper function to format direktori import response
+func formatDirektoriImportResponse(directoryImport models.DirektoriImport) gin.H {
+	progress := 0 // percentage of processed rows
+	if directoryImport.TotalRows > 0 {
+		progress = directoryImport.ProcessedRows * 100 / directoryImport.TotalRows
+	}
+
+	return gin.H{
+		"id":             directoryImport.ID,
+		"user_id":        directoryImport.UserID,
+		"filename":       directoryImport.Filename,
+		"format":         directoryImport.Format,
+		"mapping":        directoryImport.Mapping,
+		"dry_run":        directoryImport.DryRun,
+		"status":         directoryImport.Status,
+		"total_rows":     directoryImport.TotalRows,
+		"processed_rows": directoryImport.ProcessedRows,
+		"progress":       progress,
+		"created_count":  directoryImport.CreatedCount,
+		"updated_count":  directoryImport.UpdatedCount,
+		"error_count":    directoryImport.ErrorCount,
+		"errors":         directoryImport.Errors,
+		"message":        directoryImport.Message,
+		"started_at":     directoryImport.StartedAt,
+		"finished_at":    directoryImport.FinishedAt,
+		"created_at":     directoryImport.CreatedAt,
+		"updated_at":     directoryImport.UpdatedAt,
+	}
+}
@@ -0,0 +1,462 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	requests "github.com/cvudumbarainformatika/backend/app/Http/Requests"
+	models "github.com/cvudumbarainformatika/backend/app/Models"
+	"github.com/cvudumbarainformatika/backend/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// maxPendingNewDirektori is how many proposed new entries a member can have waiting for moderation
+const maxPendingNewDirektori = 10
+
+// DirektoriSubmissionController handles member proposals for the direktori and their moderation
+type DirektoriSubmissionController struct {
+	db *sqlx.DB
+}
+
+// NewDirektoriSubmissionController creates a new DirektoriSubmissionController instance
+func NewDirektoriSubmissionController(db *sqlx.DB) *DirektoriSubmissionController {
+	return &DirektoriSubmissionController{
+		db: db,
+	}
+}
+
+// Propose submits a new direktori entry for moderation (members)
+// POST /api/v1/direktori/submissions
+func (sc *DirektoriSubmissionController) Propose(c *gin.Context) {
+	var req requests.DirektoriSubmissionRequest
+	if err := req.Validate(c); err != nil {
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		utils.Error(c, http.StatusUnauthorized, "unauthorized", "User not authenticated", nil)
+		return
+	}
+
+	pending, err := models.CountPendingDirektoriSubmissions(sc.db, userID, nil)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to check submissions", nil)
+		return
+	}
+	if pending >= maxPendingNewDirektori {
+		utils.Error(c, http.StatusTooManyRequests, "too_many_pending", "Please wait until your earlier proposals have been reviewed", nil)
+		return
+	}
+
+	province, city, ok := normalizeRegion(c, sc.db, req.Province, req.City)
+	if !ok {
+		return
+	}
+	req.Province, req.City = province, city
+
+	proposed := &models.Direktori{}
+	fillDirektori(proposed, req.DirektoriRequest)
+
+	existing, err := models.FindDirektoriByNameCity(sc.db, proposed.Name, proposed.City)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to check direktori", nil)
+		return
+	}
+	if existing != nil {
+		utils.Error(c, http.StatusConflict, "direktori_exists", "This entry is already in the direktori, propose a correction instead", gin.H{
+			"direktori_id": existing.ID,
+		})
+		return
+	}
+
+	submission := &models.DirektoriSubmission{
+		UserID:   &userID,
+		Proposal: proposalFromDirektori(*proposed),
+		Note:     optionalNote(req.Note),
+	}
+	if err := submission.Create(sc.db); err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to submit proposal: "+err.Error(), nil)
+		return
+	}
+
+	utils.Success(c, http.StatusCreated, "Proposal submitted for review", formatDirektoriSubmissionResponse(models.DirektoriSubmissionDetail{DirektoriSubmission: *submission}, nil))
+}
+
+// Correct submits a correction of a direktori entry for moderation, only the given fields are proposed (members)
+// POST /api/v1/direktori/:id/submissions
+func (sc *DirektoriSubmissionController) Correct(c *gin.Context) {
+	var req requests.DirektoriCorrectionRequest
+	if err := req.Validate(c); err != nil {
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		utils.Error(c, http.StatusUnauthorized, "unauthorized", "User not authenticated", nil)
+		return
+	}
+
+	direktoriID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_id", "Invalid direktori ID", nil)
+		return
+	}
+
+	direktori, err := models.FindDirektoriByID(sc.db, direktoriID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch direktori", nil)
+		return
+	}
+	if direktori == nil {
+		utils.Error(c, http.StatusNotFound, "direktori_not_found", "Direktori not found", nil)
+		return
+	}
+
+	pending, err := models.CountPendingDirektoriSubmissions(sc.db, userID, &direktori.ID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to check submissions", nil)
+		return
+	}
+	if pending > 0 {
+		utils.Error(c, http.StatusConflict, "submission_pending", "Your earlier correction of this entry is still waiting for review", nil)
+		return
+	}
+
+	proposal := proposalFromCorrection(req)
+
+	// A corrected city is checked against the province it ends up in, so both are proposed
+	if proposal.Province != nil || proposal.City != nil {
+		province, city := direktori.Province, direktori.City
+		if proposal.Province != nil {
+			province = *proposal.Province
+		}
+		if proposal.City != nil {
+			city = *proposal.City
+		}
+		province, city, ok = normalizeRegion(c, sc.db, province, city)
+		if !ok {
+			return
+		}
+		proposal.Province, proposal.City = &province, &city
+	}
+
+	if len(proposal.Diff(direktori)) == 0 {
+		utils.ValidationError(c, gin.H{"proposal": "The correction does not change anything"})
+		return
+	}
+
+	submission := &models.DirektoriSubmission{
+		DirektoriID: &direktori.ID,
+		UserID:      &userID,
+		Proposal:    proposal,
+		Note:        optionalNote(req.Note),
+	}
+	if err := submission.Create(sc.db); err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to submit correction: "+err.Error(), nil)
+		return
+	}
+
+	utils.Success(c, http.StatusCreated, "Correction submitted for review", formatDirektoriSubmissionResponse(models.DirektoriSubmissionDetail{DirektoriSubmission: *submission}, direktori))
+}
+
+// GetMine returns the paginated submissions of the authenticated member with their review outcome
+// GET /api/v1/direktori/submissions/mine?page=&limit=&status=
+func (sc *DirektoriSubmissionController) GetMine(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		utils.Error(c, http.StatusUnauthorized, "unauthorized", "User not authenticated", nil)
+		return
+	}
+
+	sc.respondList(c, map[string]interface{}{
+		"status":  c.Query("status"),
+		"user_id": userID,
+	})
+}
+
+// GetQueue returns the moderation queue, pending submissions oldest first by default (admin only)
+// GET /api/v1/direktori/submissions?page=&limit=&status=&kind=&direktori_id=&user_id=
+func (sc *DirektoriSubmissionController) GetQueue(c *gin.Context) {
+	if _, ok := requireAdminScope(c, sc.db); !ok {
+		return
+	}
+
+	filters := map[string]interface{}{
+		"status": c.DefaultQuery("status", models.SubmissionStatusPending),
+		"kind":   c.Query("kind"),
+	}
+	if direktoriID, err := strconv.ParseInt(c.Query("direktori_id"), 10, 64); err == nil {
+		filters["direktori_id"] = direktoriID
+	}
+	if userID, err := strconv.ParseInt(c.Query("user_id"), 10, 64); err == nil {
+		filters["user_id"] = userID
+	}
+
+	sc.respondList(c, filters)
+}
+
+// GetByID returns a submission with its diff against the current entry (admin or the submitting member)
+// GET /api/v1/direktori/submissions/:id
+func (sc *DirektoriSubmissionController) GetByID(c *gin.Context) {
+	submission, ok := sc.findSubmission(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := currentUserID(c)
+	if submission.UserID == nil || *submission.UserID != userID {
+		if _, ok := requireAdminScope(c, sc.db); !ok {
+			return
+		}
+	}
+
+	var current *models.Direktori
+	if submission.DirektoriID != nil {
+		direktori, err := models.FindDirektoriByID(sc.db, *submission.DirektoriID)
+		if err != nil {
+			utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch direktori", nil)
+			return
+		}
+		current = direktori
+	}
+
+	utils.Success(c, http.StatusOK, "Submission retrieved successfully", formatDirektoriSubmissionResponse(*submission, current))
+}
+
+// Approve applies a pending submission and marks the entry as verified (admin only)
+// POST /api/v1/direktori/submissions/:id/approve
+func (sc *DirektoriSubmissionController) Approve(c *gin.Context) {
+	scope, ok := requireAdminScope(c, sc.db)
+	if !ok {
+		return
+	}
+
+	submission, ok := sc.findSubmission(c)
+	if !ok {
+		return
+	}
+
+	direktori, err := models.ApproveDirektoriSubmission(sc.db, submission.ID, scope.UserID)
+	if err != nil {
+		sc.reviewError(c, err)
+		return
+	}
+
+	submission, ok = sc.findSubmission(c)
+	if !ok {
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Submission approved", gin.H{
+		"submission": formatDirektoriSubmissionResponse(*submission, direktori),
+		"direktori":  formatDirektoriResponse(*direktori),
+	})
+}
+
+// Reject rejects a pending submission with a reason shown to the member (admin only)
+// POST /api/v1/direktori/submissions/:id/reject
+func (sc *DirektoriSubmissionController) Reject(c *gin.Context) {
+	var req requests.DirektoriRejectRequest
+	if err := req.Validate(c); err != nil {
+		return
+	}
+
+	scope, ok := requireAdminScope(c, sc.db)
+	if !ok {
+		return
+	}
+
+	submission, ok := sc.findSubmission(c)
+	if !ok {
+		return
+	}
+
+	if err := models.RejectDirektoriSubmission(sc.db, submission.ID, scope.UserID, strings.TrimSpace(req.Reason)); err != nil {
+		sc.reviewError(c, err)
+		return
+	}
+
+	submission, ok = sc.findSubmission(c)
+	if !ok {
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Submission rejected", formatDirektoriSubmissionResponse(*submission, nil))
+}
+
+// respondList writes a paginated list of submissions with their diffs against the current entries
+func (sc *DirektoriSubmissionController) respondList(c *gin.Context, filters map[string]interface{}) {
+	page, limit := utils.GetPaginationParams(c)
+	offset := (page - 1) * limit
+
+	submissions, total, err := models.GetDirektoriSubmissions(sc.db, filters, offset, limit)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch submissions: "+err.Error(), nil)
+		return
+	}
+
+	direktoriIDs := []int64{}
+	for _, submission := range submissions {
+		if submission.DirektoriID != nil {
+			direktoriIDs = append(direktoriIDs, *submission.DirektoriID)
+		}
+	}
+	direktori, err := models.GetDirektoriByIDs(sc.db, direktoriIDs)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch direktori: "+err.Error(), nil)
+		return
+	}
+
+	submissionResponses := make([]gin.H, len(submissions))
+	for i, submission := range submissions {
+		var current *models.Direktori
+		if submission.DirektoriID != nil {
+			if entry, ok := direktori[*submission.DirektoriID]; ok {
+				current = &entry
+			}
+		}
+		submissionResponses[i] = formatDirektoriSubmissionResponse(submission, current)
+	}
+
+	pagination := utils.OffsetPaginate(submissionResponses, page, limit, total)
+
+	utils.Success(c, http.StatusOK, "Submissions fetched successfully", gin.H{
+		"items":      pagination.Data,
+		"pagination": pagination.Meta,
+	})
+}
+
+// findSubmission loads the submission identified by the :id route parameter, writing the error response
+func (sc *DirektoriSubmissionController) findSubmission(c *gin.Context) (*models.DirektoriSubmissionDetail, bool) {
+	submissionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_id", "Invalid submission ID", nil)
+		return nil, false
+	}
+
+	submission, err := models.FindDirektoriSubmissionByID(sc.db, submissionID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch submission", nil)
+		return nil, false
+	}
+	if submission == nil {
+		utils.Error(c, http.StatusNotFound, "submission_not_found", "Submission not found", nil)
+		return nil, false
+	}
+
+	return submission, true
+}
+
+// reviewError writes the response of a failed approval or rejection
+func (sc *DirektoriSubmissionController) reviewError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, models.ErrSubmissionNotFound):
+		utils.Error(c, http.StatusNotFound, "submission_not_found", "Submission not found", nil)
+	case errors.Is(err, models.ErrSubmissionNotPending):
+		utils.Error(c, http.StatusConflict, "submission_reviewed", "Submission has already been reviewed", nil)
+	case errors.Is(err, models.ErrDirektoriNotFound):
+		utils.Error(c, http.StatusConflict, "direktori_not_found", "The corrected entry has been deleted, reject the submission instead", nil)
+	case errors.Is(err, models.ErrDirektoriExists):
+		utils.Error(c, http.StatusConflict, "direktori_exists", "An entry with this name already exists in this city, reject the submission instead", nil)
+	default:
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to review submission: "+err.Error(), nil)
+	}
+}
+
+// proposalFromDirektori proposes every field of a new entry
+func proposalFromDirektori(direktori models.Direktori) models.DirektoriProposal {
+	facilities := direktori.Facilities
+	return models.DirektoriProposal{
+		Name:             &direktori.Name,
+		Type:             &direktori.Type,
+		Address:          &direktori.Address,
+		Phone:            &direktori.Phone,
+		Email:            &direktori.Email,
+		Website:          &direktori.Website,
+		City:             &direktori.City,
+		Province:         &direktori.Province,
+		Latitude:         direktori.Latitude,
+		Longitude:        direktori.Longitude,
+		HasRespirologist: &direktori.HasRespirologist,
+		Facilities:       &facilities,
+	}
+}
+
+// proposalFromCorrection proposes the fields given in a correction, trimmed like fillDirektori does
+func proposalFromCorrection(req requests.DirektoriCorrectionRequest) models.DirektoriProposal {
+	trimmed := func(value *string) *string {
+		if value == nil {
+			return nil
+		}
+		result := strings.TrimSpace(*value)
+		return &result
+	}
+
+	proposal := models.DirektoriProposal{
+		Name:             trimmed(req.Name),
+		Type:             req.Type,
+		Address:          req.Address,
+		Phone:            req.Phone,
+		Email:            req.Email,
+		Website:          req.Website,
+		City:             trimmed(req.City),
+		Province:         trimmed(req.Province),
+		Latitude:         req.Latitude,
+		Longitude:        req.Longitude,
+		HasRespirologist: req.HasRespirologist,
+	}
+	if req.Facilities != nil {
+		facilities := models.NormalizeFacilities(*req.Facilities)
+		proposal.Facilities = &facilities
+	}
+	return proposal
+}
+
+// optionalNote stores a blank note as NULL
+func optionalNote(note string) *string {
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return nil
+	}
+	return &note
+}
+
+// Helper function to format direktori submission response
+// changes compares the proposal with the current entry, stale is set when the entry changed after the submission
+func formatDirektoriSubmissionResponse(submission models.DirektoriSubmissionDetail, current *models.Direktori) gin.H {
+	response := gin.H{
+		"id":            submission.ID,
+		"kind":          submission.Kind,
+		"direktori_id":  submission.DirektoriID,
+		"user_id":       submission.UserID,
+		"user_name":     submission.UserName,
+		"proposal":      submission.Proposal,
+		"note":          submission.Note,
+		"status":        submission.Status,
+		"reject_reason": submission.RejectReason,
+		"reviewed_by":   submission.ReviewedBy,
+		"reviewer_name": submission.ReviewerName,
+		"reviewed_at":   submission.ReviewedAt,
+		"created_at":    submission.CreatedAt,
+		"updated_at":    submission.UpdatedAt,
+	}
+
+	if submission.Status == models.SubmissionStatusPending {
+		if submission.Kind == models.SubmissionKindCorrection && current == nil {
+			response["changes"] = []models.DirektoriChange{}
+			response["stale"] = true
+		} else {
+			response["changes"] = submission.Proposal.Diff(current)
+			response["stale"] = current != nil && current.UpdatedAt.After(submission.CreatedAt)
+		}
+	}
+	if current != nil {
+		response["current"] = formatDirektoriResponse(*current)
+	}
+
+	return response
+}
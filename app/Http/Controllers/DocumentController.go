@@ -0,0 +1,609 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	requests "github.com/cvudumbarainformatika/backend/app/Http/Requests"
+	models "github.com/cvudumbarainformatika/backend/app/Models"
+	"github.com/cvudumbarainformatika/backend/config"
+	"github.com/cvudumbarainformatika/backend/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// DocumentController handles member documents (STR, SIP, Serkom, ...) and their moderation
+// Files live in the private dokumen storage and are only served to the owner and admins in scope
+type DocumentController struct {
+	db     *sqlx.DB
+	config *config.Config
+}
+
+// NewDocumentController creates a new DocumentController instance
+func NewDocumentController(db *sqlx.DB, cfg *config.Config) *DocumentController {
+	return &DocumentController{
+		db:     db,
+		config: cfg,
+	}
+}
+
+// GetMine returns the paginated documents of the authenticated member
+// GET /api/v1/me/documents?page=&limit=&type=&status=
+func (dc *DocumentController) GetMine(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		utils.Error(c, http.StatusUnauthorized, "unauthorized", "User not authenticated", nil)
+		return
+	}
+
+	page, limit := utils.GetPaginationParams(c)
+	offset := (page - 1) * limit
+
+	filters := map[string]interface{}{
+		"type":   c.Query("type"),
+		"status": c.Query("status"),
+	}
+
+	documents, total, err := models.GetUserDocuments(dc.db, userID, filters, offset, limit)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch documents: "+err.Error(), nil)
+		return
+	}
+
+	documentResponses := make([]gin.H, len(documents))
+	for i, document := range documents {
+		documentResponses[i] = formatDocumentResponse(document, memberDocumentFileURL(document.ID))
+	}
+
+	pagination := utils.OffsetPaginate(documentResponses, page, limit, total)
+
+	utils.Success(c, http.StatusOK, "Documents fetched successfully", gin.H{
+		"items":      pagination.Data,
+		"pagination": pagination.Meta,
+	})
+}
+
+// GetMineByID returns a document of the authenticated member
+// GET /api/v1/me/documents/:id
+func (dc *DocumentController) GetMineByID(c *gin.Context) {
+	document, ok := dc.findOwnDocument(c)
+	if !ok {
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Document retrieved successfully", formatDocumentResponse(*document, memberDocumentFileURL(document.ID)))
+}
+
+// Create uploads a document of the authenticated member, pending review
+// POST /api/v1/me/documents (multipart: file, name, type, valid_until)
+func (dc *DocumentController) Create(c *gin.Context) {
+	var req requests.DocumentRequest
+	if err := req.Validate(c, true); err != nil {
+		return
+	}
+
+	userID, ok := currentUserID(c)
+	if !ok {
+		utils.Error(c, http.StatusUnauthorized, "unauthorized", "User not authenticated", nil)
+		return
+	}
+
+	document := &models.Document{UserID: userID}
+	if !fillDocument(c, document, req) {
+		return
+	}
+	if !dc.storeFile(c, document, req) {
+		return
+	}
+
+	if err := document.Create(dc.db); err != nil {
+		utils.DeleteFileByURL(document.FileURL)
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to create document: "+err.Error(), nil)
+		return
+	}
+
+	utils.Success(c, http.StatusCreated, "Document submitted for review", formatDocumentResponse(*document, memberDocumentFileURL(document.ID)))
+}
+
+// Update changes a document of the authenticated member and sends it back for review
+// The file is replaced only when a new one is uploaded
+// PUT /api/v1/me/documents/:id (multipart: name, type, valid_until, file)
+func (dc *DocumentController) Update(c *gin.Context) {
+	var req requests.DocumentRequest
+	if err := req.Validate(c, false); err != nil {
+		return
+	}
+
+	document, ok := dc.findOwnDocument(c)
+	if !ok {
+		return
+	}
+
+	if !fillDocument(c, document, req) {
+		return
+	}
+
+	previousFileURL := document.FileURL
+	if req.File != nil && !dc.storeFile(c, document, req) {
+		return
+	}
+
+	if err := document.Update(dc.db); err != nil {
+		if document.FileURL != previousFileURL {
+			utils.DeleteFileByURL(document.FileURL)
+		}
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to update document: "+err.Error(), nil)
+		return
+	}
+	if document.FileURL != previousFileURL {
+		utils.DeleteFileByURL(previousFileURL)
+	}
+
+	utils.Success(c, http.StatusOK, "Document submitted for review", formatDocumentResponse(*document, memberDocumentFileURL(document.ID)))
+}
+
+// Delete moves a document of the authenticated member to the trash, its file is kept until purged
+// DELETE /api/v1/me/documents/:id
+func (dc *DocumentController) Delete(c *gin.Context) {
+	document, ok := dc.findOwnDocument(c)
+	if !ok {
+		return
+	}
+
+	if err := document.Delete(dc.db); err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to delete document: "+err.Error(), nil)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Document deleted successfully", nil)
+}
+
+// DownloadMine serves the file of a document of the authenticated member
+// GET /api/v1/me/documents/:id/file
+func (dc *DocumentController) DownloadMine(c *gin.Context) {
+	document, ok := dc.findOwnDocument(c)
+	if !ok {
+		return
+	}
+
+	serveDocumentFile(c, *document)
+}
+
+// GetQueue returns documents for moderation, pending documents oldest first by default (admin only)
+// Admins of a cabang only see documents of members in their cabang
+// GET /api/v1/documents?page=&limit=&status=&type=&user_id=&search=
+func (dc *DocumentController) GetQueue(c *gin.Context) {
+	scope, ok := requireAdminScope(c, dc.db)
+	if !ok {
+		return
+	}
+
+	page, limit := utils.GetPaginationParams(c)
+	offset := (page - 1) * limit
+
+	filters := map[string]interface{}{
+		"status": c.DefaultQuery("status", models.DocumentStatusPending),
+		"type":   c.Query("type"),
+		"search": strings.TrimSpace(c.Query("search")),
+	}
+	if userID, err := strconv.ParseInt(c.Query("user_id"), 10, 64); err == nil {
+		filters["user_id"] = userID
+	}
+	if !scope.IsGlobal() {
+		if scope.Cabang == "" {
+			utils.Error(c, http.StatusForbidden, "forbidden", "Your account is not assigned to a cabang", nil)
+			return
+		}
+		filters["cabang"] = scope.Cabang
+	}
+
+	documents, total, err := models.GetDocumentQueue(dc.db, filters, offset, limit)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch documents: "+err.Error(), nil)
+		return
+	}
+
+	documentResponses := make([]gin.H, len(documents))
+	for i, document := range documents {
+		documentResponses[i] = formatDocumentDetailResponse(document)
+	}
+
+	pagination := utils.OffsetPaginate(documentResponses, page, limit, total)
+
+	utils.Success(c, http.StatusOK, "Documents fetched successfully", gin.H{
+		"items":      pagination.Data,
+		"pagination": pagination.Meta,
+	})
+}
+
+// GetByID returns a document with its owner (admin only)
+// GET /api/v1/documents/:id
+func (dc *DocumentController) GetByID(c *gin.Context) {
+	document, _, ok := dc.findDocumentInScope(c)
+	if !ok {
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Document retrieved successfully", formatDocumentDetailResponse(*document))
+}
+
+// Download serves the file of a document for review (admin only)
+// GET /api/v1/documents/:id/file
+func (dc *DocumentController) Download(c *gin.Context) {
+	document, _, ok := dc.findDocumentInScope(c)
+	if !ok {
+		return
+	}
+
+	serveDocumentFile(c, document.Document)
+}
+
+// Approve marks a pending document as valid (admin only)
+// POST /api/v1/documents/:id/approve
+func (dc *DocumentController) Approve(c *gin.Context) {
+	document, scope, ok := dc.findDocumentInScope(c)
+	if !ok {
+		return
+	}
+
+	if err := models.ReviewDocument(dc.db, document.ID, scope.UserID, models.DocumentStatusValid, nil); err != nil {
+		dc.reviewError(c, err)
+		return
+	}
+
+	dc.respondReviewed(c, document.ID, "Document marked as valid")
+}
+
+// Reject rejects a pending document with a reason shown to the member (admin only)
+// POST /api/v1/documents/:id/reject
+func (dc *DocumentController) Reject(c *gin.Context) {
+	var req requests.DocumentRejectRequest
+	if err := req.Validate(c); err != nil {
+		return
+	}
+
+	document, scope, ok := dc.findDocumentInScope(c)
+	if !ok {
+		return
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if err := models.ReviewDocument(dc.db, document.ID, scope.UserID, models.DocumentStatusRejected, &reason); err != nil {
+		dc.reviewError(c, err)
+		return
+	}
+
+	dc.respondReviewed(c, document.ID, "Document rejected")
+}
+
+// ExpiryReport returns expired documents and documents expiring within days, grouped per cabang (admin only)
+// days defaults to the longest reminder lead time, admins of a cabang only get their own cabang
+// GET /api/v1/documents/expiry-report?days=&state=expiring|expired&type=&cabang=&format=json|csv
+func (dc *DocumentController) ExpiryReport(c *gin.Context) {
+	scope, ok := requireAdminScope(c, dc.db)
+	if !ok {
+		return
+	}
+
+	days := 0
+	for _, lead := range dc.config.Document.ReminderDays {
+		if lead > days {
+			days = lead
+		}
+	}
+	if days == 0 {
+		days = 90
+	}
+	if value := c.Query("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 || parsed > 365 {
+			utils.Error(c, http.StatusBadRequest, "invalid_days", "days must be between 0 and 365", nil)
+			return
+		}
+		days = parsed
+	}
+
+	state := c.Query("state")
+	if state != "" && state != "expiring" && state != "expired" {
+		utils.Error(c, http.StatusBadRequest, "invalid_state", "state must be expiring or expired", nil)
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		utils.Error(c, http.StatusBadRequest, "invalid_format", "format must be json or csv", nil)
+		return
+	}
+
+	filters := map[string]interface{}{
+		"state":  state,
+		"type":   c.Query("type"),
+		"cabang": strings.TrimSpace(c.Query("cabang")),
+	}
+	if !scope.IsGlobal() {
+		if scope.Cabang == "" {
+			utils.Error(c, http.StatusForbidden, "forbidden", "Your account is not assigned to a cabang", nil)
+			return
+		}
+		filters["cabang"] = scope.Cabang
+	}
+
+	today := models.DocumentToday(utils.LoadTimezone(dc.config.App.Timezone))
+	until := today.AddDate(0, 0, days)
+
+	documents, err := models.GetDocumentExpiryReport(dc.db, today, until, filters)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch documents: "+err.Error(), nil)
+		return
+	}
+
+	if format == "csv" {
+		writeDocumentExpiryCSV(c, today, documents)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Expiry report generated successfully", gin.H{
+		"today": today.Format("2006-01-02"),
+		"until": until.Format("2006-01-02"),
+		"units": buildDocumentExpiryUnits(documents),
+	})
+}
+
+// findOwnDocument loads the document identified by the :id route parameter, writing the error response
+// Documents of other members are reported as not found
+func (dc *DocumentController) findOwnDocument(c *gin.Context) (*models.Document, bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		utils.Error(c, http.StatusUnauthorized, "unauthorized", "User not authenticated", nil)
+		return nil, false
+	}
+
+	documentID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_id", "Invalid document ID", nil)
+		return nil, false
+	}
+
+	document, err := models.FindDocumentByID(dc.db, documentID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch document", nil)
+		return nil, false
+	}
+	if document == nil || document.UserID != userID {
+		utils.Error(c, http.StatusNotFound, "document_not_found", "Document not found", nil)
+		return nil, false
+	}
+
+	return document, true
+}
+
+// findDocumentInScope loads the document identified by the :id route parameter for an admin, writing the error response
+// The owner must belong to a cabang the admin manages
+func (dc *DocumentController) findDocumentInScope(c *gin.Context) (*models.DocumentDetail, *models.OrgScope, bool) {
+	scope, ok := requireAdminScope(c, dc.db)
+	if !ok {
+		return nil, nil, false
+	}
+
+	documentID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_id", "Invalid document ID", nil)
+		return nil, nil, false
+	}
+
+	document, err := models.FindDocumentDetailByID(dc.db, documentID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch document", nil)
+		return nil, nil, false
+	}
+	if document == nil {
+		utils.Error(c, http.StatusNotFound, "document_not_found", "Document not found", nil)
+		return nil, nil, false
+	}
+	if !scope.CanManage(document.UserCabang) {
+		utils.Error(c, http.StatusForbidden, "forbidden", "Document is outside your organization scope", nil)
+		return nil, nil, false
+	}
+
+	return document, scope, true
+}
+
+// storeFile uploads the file of a request into the private dokumen storage, writing the error response
+func (dc *DocumentController) storeFile(c *gin.Context, document *models.Document, req requests.DocumentRequest) bool {
+	token, err := utils.GenerateToken(8)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "upload_error", "Failed to store document", nil)
+		return false
+	}
+
+	uploadInfo, err := utils.UploadFileOfType(req.File, utils.FileTypeDokumen, fmt.Sprintf("%d_%s", document.UserID, token))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "upload_error", err.Error(), nil)
+		return false
+	}
+
+	document.FileURL = uploadInfo.FileURL
+	document.FileName = &uploadInfo.OriginalFilename
+	return true
+}
+
+// respondReviewed writes the reviewed document
+func (dc *DocumentController) respondReviewed(c *gin.Context, documentID int64, message string) {
+	document, err := models.FindDocumentDetailByID(dc.db, documentID)
+	if err != nil || document == nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch document", nil)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, message, formatDocumentDetailResponse(*document))
+}
+
+// reviewError writes the response of a failed approval or rejection
+func (dc *DocumentController) reviewError(c *gin.Context, err error) {
+	if errors.Is(err, models.ErrDocumentNotPending) {
+		utils.Error(c, http.StatusConflict, "document_reviewed", "Document has already been reviewed", nil)
+		return
+	}
+	utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to review document: "+err.Error(), nil)
+}
+
+// fillDocument copies the request fields into a document, writing the error response
+func fillDocument(c *gin.Context, document *models.Document, req requests.DocumentRequest) bool {
+	document.Name = strings.TrimSpace(req.Name)
+	document.Type = req.Type
+	document.ValidUntil = nil
+
+	if req.ValidUntil != "" {
+		validUntil, err := time.Parse("2006-01-02", req.ValidUntil)
+		if err != nil {
+			utils.ValidationError(c, gin.H{"valid_until": "Invalid date format. Use YYYY-MM-DD"})
+			return false
+		}
+		document.ValidUntil = &validUntil
+	}
+
+	return true
+}
+
+// serveDocumentFile sends a document file as a download that is never cached by shared caches
+func serveDocumentFile(c *gin.Context, document models.Document) {
+	filePath, err := utils.FilePathByURL(document.FileURL)
+	if err != nil {
+		utils.Error(c, http.StatusNotFound, "not_found", "File not found", nil)
+		return
+	}
+	if _, err := os.Stat(filePath); err != nil {
+		utils.Error(c, http.StatusNotFound, "not_found", "File not found", nil)
+		return
+	}
+
+	filename := document.Name
+	if document.FileName != nil {
+		filename = *document.FileName
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.FileAttachment(filePath, filename)
+}
+
+// memberDocumentFileURL returns the URL the owner downloads a document file from
+func memberDocumentFileURL(documentID int64) string {
+	return fmt.Sprintf("/api/v1/me/documents/%d/file", documentID)
+}
+
+// Helper function to format document response
+// fileURL is the access-checked download URL, the storage location is never exposed
+func formatDocumentResponse(document models.Document, fileURL string) gin.H {
+	return gin.H{
+		"id":            document.ID,
+		"user_id":       document.UserID,
+		"name":          document.Name,
+		"type":          document.Type,
+		"valid_until":   formatOptionalDate(document.ValidUntil),
+		"status":        document.Status,
+		"reject_reason": document.RejectReason,
+		"reviewed_by":   document.ReviewedBy,
+		"reviewed_at":   document.ReviewedAt,
+		"file_url":      fileURL,
+		"file_name":     document.FileName,
+		"created_at":    document.CreatedAt,
+		"updated_at":    document.UpdatedAt,
+	}
+}
+
+// Helper function to format document response with its owner for admins
+func formatDocumentDetailResponse(document models.DocumentDetail) gin.H {
+	response := formatDocumentResponse(document.Document, fmt.Sprintf("/api/v1/documents/%d/file", document.ID))
+	response["user"] = gin.H{
+		"id":     document.UserID,
+		"name":   document.UserName,
+		"email":  document.UserEmail,
+		"cabang": document.UserCabang,
+	}
+	return response
+}
+
+// documentExpiryState tells whether a report entry has expired or is expiring
+func documentExpiryState(document models.ExpiringDocument) string {
+	if document.DaysLeft < 0 {
+		return "expired"
+	}
+	return "expiring"
+}
+
+// buildDocumentExpiryUnits groups report entries, already ordered by cabang, into one unit per cabang
+// Members without a cabang are grouped under a null cabang
+func buildDocumentExpiryUnits(documents []models.ExpiringDocument) []gin.H {
+	units := []gin.H{}
+	var current gin.H
+	var currentCabang *string
+	for _, document := range documents {
+		if current == nil || !sameCabang(currentCabang, document.UserCabang) {
+			currentCabang = document.UserCabang
+			current = gin.H{"cabang": currentCabang, "expired": 0, "expiring": 0, "documents": []gin.H{}}
+			units = append(units, current)
+		}
+
+		state := documentExpiryState(document)
+		current[state] = current[state].(int) + 1
+		current["documents"] = append(current["documents"].([]gin.H), gin.H{
+			"id":          document.ID,
+			"name":        document.Name,
+			"type":        document.Type,
+			"status":      document.Status,
+			"state":       state,
+			"valid_until": document.ValidUntil.Format("2006-01-02"),
+			"days_left":   document.DaysLeft,
+			"user": gin.H{
+				"id":    document.UserID,
+				"name":  document.UserName,
+				"email": document.UserEmail,
+			},
+		})
+	}
+	return units
+}
+
+// sameCabang compares two optional cabang names
+func sameCabang(a *string, b *string) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// writeDocumentExpiryCSV writes the expiry report as a CSV download
+func writeDocumentExpiryCSV(c *gin.Context, today time.Time, documents []models.ExpiringDocument) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="document-expiry-`+today.Format("20060102")+`.csv"`)
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write([]string{"cabang", "member", "email", "document_id", "document", "type", "status", "state", "valid_until", "days_left"})
+	for _, document := range documents {
+		cabang := ""
+		if document.UserCabang != nil {
+			cabang = *document.UserCabang
+		}
+		_ = w.Write(utils.EscapeCSVRow([]string{
+			cabang,
+			document.UserName,
+			document.UserEmail,
+			strconv.FormatInt(document.ID, 10),
+			document.Name,
+			document.Type,
+			document.Status,
+			documentExpiryState(document),
+			document.ValidUntil.Format("2006-01-02"),
+			strconv.Itoa(document.DaysLeft),
+		}))
+	}
+	w.Flush()
+}
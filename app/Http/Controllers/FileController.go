@@ -38,6 +38,12 @@ func (fc *FileController) ServeFile(c *gin.Context) {
 		return
 	}
 
+	// Private files are only served through endpoints that check access
+	if config.Private {
+		utils.Error(c, http.StatusNotFound, "not_found", "File not found", nil)
+		return
+	}
+
 	// Validate filename format (prevent directory traversal)
 	if !isValidFilename(filename) {
 		fmt.Printf("[FileController] Invalid filename format: %s\n", filename)
@@ -97,6 +103,10 @@ func (fc *FileController) ListFiles(c *gin.Context) {
 		utils.Error(c, http.StatusBadRequest, "invalid_file_type", "Invalid file type", nil)
 		return
 	}
+	if config.Private {
+		utils.Error(c, http.StatusForbidden, "forbidden", "Files of this type cannot be listed", nil)
+		return
+	}
 
 	// Get storage path (lazy loaded from environment)
 	storagePath := utils.GetStoragePathForConfig(config)
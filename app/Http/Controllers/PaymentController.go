@@ -0,0 +1,391 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	mail "github.com/cvudumbarainformatika/backend/app/Mail"
+	models "github.com/cvudumbarainformatika/backend/app/Models"
+	payment "github.com/cvudumbarainformatika/backend/app/Payment"
+	"github.com/cvudumbarainformatika/backend/config"
+	"github.com/cvudumbarainformatika/backend/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// maxNotificationSize limits the body of payment notifications
+const maxNotificationSize = 64 << 10
+
+// PaymentController handles invoices of paid registrations and payment provider notifications
+type PaymentController struct {
+	db      *sqlx.DB
+	config  *config.Config
+	mailer  *mail.Mailer
+	gateway *payment.Gateway
+}
+
+// NewPaymentController creates a new PaymentController instance
+func NewPaymentController(db *sqlx.DB, cfg *config.Config, mailer *mail.Mailer, gateway *payment.Gateway) *PaymentController {
+	return &PaymentController{
+		db:      db,
+		config:  cfg,
+		mailer:  mailer,
+		gateway: gateway,
+	}
+}
+
+// Webhook receives payment status notifications, verified by the provider signature
+// Settled invoices confirm their registration, expired ones release the seat. Notifications are idempotent
+// POST /api/v1/payments/notifications
+func (pc *PaymentController) Webhook(c *gin.Context) {
+	if !pc.gateway.Enabled() {
+		utils.Error(c, http.StatusNotFound, "not_found", "Payment notifications are not available", nil)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxNotificationSize))
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_notification", "Failed to read notification", nil)
+		return
+	}
+
+	notification, err := pc.gateway.Provider().ParseNotification(body, c.Request.Header)
+	if err != nil {
+		if errors.Is(err, payment.ErrInvalidSignature) {
+			log.Printf("[Payment] Rejected notification with an invalid signature from %s", c.ClientIP())
+			utils.Error(c, http.StatusUnauthorized, "invalid_signature", "Invalid notification signature", nil)
+			return
+		}
+		utils.Error(c, http.StatusBadRequest, "invalid_notification", "Invalid notification payload", nil)
+		return
+	}
+
+	pc.handleNotification(c, *notification)
+}
+
+// Simulate settles, expires or fails an invoice of the fake provider (local testing only)
+// The route is only registered when PAYMENT_PROVIDER=fake
+// POST /api/v1/payments/fake/:number?status=settled|expired|failed
+func (pc *PaymentController) Simulate(c *gin.Context) {
+	fake, ok := pc.gateway.Provider().(*payment.FakeProvider)
+	if !ok || pc.config.App.Env == "production" {
+		utils.Error(c, http.StatusNotFound, "not_found", "Payment simulation is not available", nil)
+		return
+	}
+
+	invoice, err := models.FindInvoiceByNumber(pc.db, c.Param("number"))
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch invoice", nil)
+		return
+	}
+	if invoice == nil {
+		utils.Error(c, http.StatusNotFound, "invoice_not_found", "Invoice not found", nil)
+		return
+	}
+
+	status := c.DefaultQuery("status", payment.StatusSettled)
+	switch status {
+	case payment.StatusSettled, payment.StatusExpired, payment.StatusFailed:
+	default:
+		utils.Error(c, http.StatusBadRequest, "invalid_status", "status must be settled, expired or failed", nil)
+		return
+	}
+
+	// The notification goes through the same signature check as a real webhook call
+	body, _ := json.Marshal(payment.FakeNotification{
+		OrderID:   invoice.Number,
+		Status:    status,
+		Amount:    invoice.Amount,
+		Reference: "fake-" + invoice.Number,
+		Method:    "fake",
+	})
+	header := http.Header{}
+	header.Set(payment.FakeSignatureHeader, fake.Sign(body))
+
+	notification, err := fake.ParseNotification(body, header)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "invalid_notification", err.Error(), nil)
+		return
+	}
+
+	pc.handleNotification(c, *notification)
+}
+
+// GetMine returns paginated invoices of the authenticated member
+// GET /api/v1/me/invoices?page=&limit=&status=
+func (pc *PaymentController) GetMine(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		utils.Error(c, http.StatusUnauthorized, "unauthorized", "User not authenticated", nil)
+		return
+	}
+
+	page, limit := utils.GetPaginationParams(c)
+
+	status := c.Query("status")
+	if status != "" && !isInvoiceStatus(status) {
+		utils.Error(c, http.StatusBadRequest, "invalid_status", "Invalid invoice status: "+status, nil)
+		return
+	}
+
+	offset := (page - 1) * limit
+
+	invoices, total, err := models.GetUserInvoices(pc.db, userID, map[string]interface{}{"status": status}, offset, limit)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch invoices: "+err.Error(), nil)
+		return
+	}
+
+	invoiceResponses := make([]gin.H, len(invoices))
+	for i, invoice := range invoices {
+		invoiceResponses[i] = formatInvoiceDetailResponse(invoice)
+	}
+
+	pagination := utils.OffsetPaginate(invoiceResponses, page, limit, total)
+
+	utils.Success(c, http.StatusOK, "Invoices fetched successfully", gin.H{
+		"items":      pagination.Data,
+		"pagination": pagination.Meta,
+	})
+}
+
+// Pay returns the open invoice of the authenticated member's pending paid registration, issuing one if needed
+// POST /api/v1/me/registrations/:id/invoice
+func (pc *PaymentController) Pay(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		utils.Error(c, http.StatusUnauthorized, "unauthorized", "User not authenticated", nil)
+		return
+	}
+
+	regID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_id", "Invalid registration ID", nil)
+		return
+	}
+
+	registration, err := models.FindAgendaRegistrationByID(pc.db, regID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch registration", nil)
+		return
+	}
+	if registration == nil || registration.UserID != userID {
+		utils.Error(c, http.StatusNotFound, "registration_not_found", "Registration not found", nil)
+		return
+	}
+
+	agenda, err := models.FindAgendaByID(pc.db, registration.AgendaID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch agenda", nil)
+		return
+	}
+	if agenda == nil {
+		utils.Error(c, http.StatusNotFound, "agenda_not_found", "Agenda not found", nil)
+		return
+	}
+
+	user, err := models.FindByID(pc.db, userID)
+	if err != nil || user == nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch user", nil)
+		return
+	}
+
+	invoice, _, err := pc.gateway.IssueInvoice(c.Request.Context(), *agenda, *registration, *user)
+	if err != nil {
+		switch {
+		case errors.Is(err, payment.ErrNoProvider):
+			utils.Error(c, http.StatusServiceUnavailable, "payments_unavailable", "Payments are not available yet", nil)
+		case errors.Is(err, models.ErrRegistrationNotPayable):
+			utils.Error(c, http.StatusBadRequest, "not_payable", "Registration does not require payment", nil)
+		case errors.Is(err, models.ErrRegistrationNotFound):
+			utils.Error(c, http.StatusNotFound, "registration_not_found", "Registration not found", nil)
+		case invoice != nil:
+			log.Printf("[Payment] Failed to create charge for invoice %s: %v", invoice.Number, err)
+			utils.Error(c, http.StatusBadGateway, "payment_provider_error", "Payment provider is unavailable, please try again", nil)
+		default:
+			utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to issue invoice: "+err.Error(), nil)
+		}
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Invoice retrieved successfully", formatInvoiceResponse(*invoice))
+}
+
+// GetAgendaInvoices returns paginated invoices of an agenda with payment totals (admin only)
+// The agenda is addressed by slug or numeric ID
+// GET /api/v1/agenda/:slug/invoices?page=&limit=&status=&search=
+func (pc *PaymentController) GetAgendaInvoices(c *gin.Context) {
+	scope, ok := requireAdminScope(c, pc.db)
+	if !ok {
+		return
+	}
+
+	agenda, ok := findAgendaBySlugParam(c, pc.db)
+	if !ok {
+		return
+	}
+	if !scope.CanManage(agenda.Cabang) {
+		utils.Error(c, http.StatusForbidden, "forbidden", "Agenda is outside your organization scope", nil)
+		return
+	}
+
+	page, limit := utils.GetPaginationParams(c)
+
+	status := c.Query("status")
+	if status != "" && !isInvoiceStatus(status) {
+		utils.Error(c, http.StatusBadRequest, "invalid_status", "Invalid invoice status: "+status, nil)
+		return
+	}
+
+	filters := map[string]interface{}{
+		"status": status,
+		"search": c.Query("search"),
+	}
+
+	offset := (page - 1) * limit
+
+	invoices, total, err := models.GetAgendaInvoices(pc.db, agenda.ID, filters, offset, limit)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch invoices: "+err.Error(), nil)
+		return
+	}
+
+	summary, err := models.GetAgendaInvoiceSummary(pc.db, agenda.ID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to summarize invoices: "+err.Error(), nil)
+		return
+	}
+
+	invoiceResponses := make([]gin.H, len(invoices))
+	for i, invoice := range invoices {
+		invoiceResponses[i] = formatInvoiceDetailResponse(invoice)
+	}
+
+	pagination := utils.OffsetPaginate(invoiceResponses, page, limit, total)
+
+	utils.Success(c, http.StatusOK, "Invoices fetched successfully", gin.H{
+		"summary": gin.H{
+			"paid":                  summary.Paid,
+			"pending":               summary.Pending,
+			"paid_amount":           summary.PaidAmount,
+			"paid_amount_formatted": utils.FormatRupiah(summary.PaidAmount),
+		},
+		"items":      pagination.Data,
+		"pagination": pagination.Meta,
+	})
+}
+
+// handleNotification applies a verified notification to its invoice and writes the response
+// Unknown invoices and amount mismatches are rejected, errors return 500 so the provider retries
+func (pc *PaymentController) handleNotification(c *gin.Context, notification payment.Notification) {
+	switch notification.Status {
+	case payment.StatusSettled:
+		invoice, registration, err := models.SettleInvoice(pc.db, notification.OrderID, notification.Amount, notification.Reference, notification.Method)
+		if err != nil {
+			pc.notificationError(c, notification, err)
+			return
+		}
+		if registration != nil {
+			if agenda, err := models.FindAgendaByID(pc.db, registration.AgendaID); err == nil && agenda != nil {
+				sendRegistrationTicket(pc.db, pc.config, pc.mailer, *agenda, *registration)
+			}
+		} else if current, err := models.FindAgendaRegistrationByID(pc.db, invoice.RegistrationID); err == nil && current != nil && current.Status != models.RegistrationStatusConfirmed {
+			log.Printf("[Payment] Invoice %s was paid but registration #%d has no seat, the payment needs a refund", invoice.Number, current.ID)
+		}
+		utils.Success(c, http.StatusOK, "Notification processed", formatInvoiceResponse(*invoice))
+
+	case payment.StatusExpired:
+		invoice, err := models.FindInvoiceByNumber(pc.db, notification.OrderID)
+		if err != nil || invoice == nil {
+			pc.notificationError(c, notification, err)
+			return
+		}
+		if err := pc.gateway.ExpireInvoice(*invoice); err != nil {
+			pc.notificationError(c, notification, err)
+			return
+		}
+		invoice, err = models.FindInvoiceByNumber(pc.db, notification.OrderID)
+		if err != nil || invoice == nil {
+			pc.notificationError(c, notification, err)
+			return
+		}
+		utils.Success(c, http.StatusOK, "Notification processed", formatInvoiceResponse(*invoice))
+
+	case payment.StatusFailed:
+		invoice, err := models.FailInvoice(pc.db, notification.OrderID)
+		if err != nil {
+			pc.notificationError(c, notification, err)
+			return
+		}
+		utils.Success(c, http.StatusOK, "Notification processed", formatInvoiceResponse(*invoice))
+
+	default:
+		utils.Success(c, http.StatusOK, "Notification acknowledged", nil)
+	}
+}
+
+// notificationError writes the error response of a notification that could not be applied
+func (pc *PaymentController) notificationError(c *gin.Context, notification payment.Notification, err error) {
+	switch {
+	case err == nil, errors.Is(err, models.ErrInvoiceNotFound):
+		utils.Error(c, http.StatusNotFound, "invoice_not_found", "Invoice not found", nil)
+	case errors.Is(err, models.ErrInvoiceAmountMismatch):
+		log.Printf("[Payment] Invoice %s was paid with a different amount (%d)", notification.OrderID, notification.Amount)
+		utils.Error(c, http.StatusBadRequest, "amount_mismatch", "Paid amount does not match the invoice", nil)
+	default:
+		log.Printf("[Payment] Failed to process notification of invoice %s: %v", notification.OrderID, err)
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to process notification", nil)
+	}
+}
+
+// isInvoiceStatus reports whether a status is a known invoice status
+func isInvoiceStatus(status string) bool {
+	switch status {
+	case models.InvoiceStatusPending, models.InvoiceStatusPaid, models.InvoiceStatusExpired, models.InvoiceStatusFailed, models.InvoiceStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// Helper function to format invoice response
+func formatInvoiceResponse(invoice models.Invoice) gin.H {
+	return gin.H{
+		"id":               invoice.ID,
+		"number":           invoice.Number,
+		"registration_id":  invoice.RegistrationID,
+		"agenda_id":        invoice.AgendaID,
+		"user_id":          invoice.UserID,
+		"amount":           invoice.Amount,
+		"amount_formatted": utils.FormatRupiah(invoice.Amount),
+		"currency":         invoice.Currency,
+		"status":           invoice.Status,
+		"provider":         invoice.Provider,
+		"payment_url":      invoice.PaymentURL,
+		"payment_method":   invoice.PaymentMethod,
+		"expires_at":       invoice.ExpiresAt,
+		"paid_at":          invoice.PaidAt,
+		"created_at":       invoice.CreatedAt,
+		"updated_at":       invoice.UpdatedAt,
+	}
+}
+
+// Helper function to format invoice response with agenda and user details
+func formatInvoiceDetailResponse(invoice models.InvoiceDetail) gin.H {
+	response := formatInvoiceResponse(invoice.Invoice)
+	response["agenda"] = gin.H{
+		"id":    invoice.AgendaID,
+		"slug":  invoice.AgendaSlug,
+		"title": invoice.AgendaTitle,
+		"date":  invoice.AgendaDate,
+	}
+	response["user"] = gin.H{
+		"id":    invoice.UserID,
+		"name":  invoice.UserName,
+		"email": invoice.UserEmail,
+	}
+	return response
+}
@@ -0,0 +1,445 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	requests "github.com/cvudumbarainformatika/backend/app/Http/Requests"
+	models "github.com/cvudumbarainformatika/backend/app/Models"
+	"github.com/cvudumbarainformatika/backend/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// PengurusController handles the board members of the organization and their terms
+type PengurusController struct {
+	db *sqlx.DB
+}
+
+// NewPengurusController creates a new PengurusController instance
+func NewPengurusController(db *sqlx.DB) *PengurusController {
+	return &PengurusController{
+		db: db,
+	}
+}
+
+// GetList returns paginated pengurus with their terms
+// level, periode and bidang select people holding a matching term, only those terms are returned
+// GET /api/v1/pengurus?page=&limit=&level=&periode=&bidang=&search=
+func (pc *PengurusController) GetList(c *gin.Context) {
+	page, limit := utils.GetPaginationParams(c)
+
+	level := c.Query("level")
+	if level != "" && !isPengurusLevel(level) {
+		utils.Error(c, http.StatusBadRequest, "invalid_level", "Invalid level: "+level, nil)
+		return
+	}
+
+	filters := map[string]interface{}{
+		"level":   level,
+		"periode": strings.TrimSpace(c.Query("periode")),
+		"bidang":  strings.TrimSpace(c.Query("bidang")),
+		"search":  strings.TrimSpace(c.Query("search")),
+	}
+
+	offset := (page - 1) * limit
+
+	pengurus, total, err := models.GetAllPengurus(pc.db, filters, offset, limit)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch pengurus: "+err.Error(), nil)
+		return
+	}
+
+	pengurusResponses := make([]gin.H, len(pengurus))
+	for i, p := range pengurus {
+		pengurusResponses[i] = formatPengurusResponse(p)
+	}
+
+	pagination := utils.OffsetPaginate(pengurusResponses, page, limit, total)
+
+	utils.Success(c, http.StatusOK, "Pengurus fetched successfully", gin.H{
+		"items":      pagination.Data,
+		"pagination": pagination.Meta,
+	})
+}
+
+// OrgChart returns the board of a period as a tree of levels, bidang and positions
+// The executive board (terms without bidang) comes first in each level, bidang follow in the order of their
+// first position. Without periode the latest period is shown
+// GET /api/v1/pengurus/org-chart?periode=&level=
+func (pc *PengurusController) OrgChart(c *gin.Context) {
+	level := c.Query("level")
+	if level != "" && !isPengurusLevel(level) {
+		utils.Error(c, http.StatusBadRequest, "invalid_level", "Invalid level: "+level, nil)
+		return
+	}
+
+	periodes, err := models.GetPengurusPeriodes(pc.db)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch periods", nil)
+		return
+	}
+
+	periode := strings.TrimSpace(c.Query("periode"))
+	if periode == "" && len(periodes) > 0 {
+		periode = periodes[0]
+	}
+
+	entries := []models.PengurusChartEntry{}
+	if periode != "" {
+		entries, err = models.GetPengurusChartEntries(pc.db, periode, level)
+		if err != nil {
+			utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch org chart", nil)
+			return
+		}
+	}
+
+	utils.Success(c, http.StatusOK, "Org chart retrieved successfully", gin.H{
+		"periode":  periode,
+		"periodes": periodes,
+		"levels":   buildPengurusOrgChart(entries),
+	})
+}
+
+// GetByID returns a pengurus with all terms, latest period first
+// GET /api/v1/pengurus/:id
+func (pc *PengurusController) GetByID(c *gin.Context) {
+	pengurus, ok := pc.findPengurus(c)
+	if !ok {
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Pengurus retrieved successfully", formatPengurusResponse(*pengurus))
+}
+
+// Create creates a pengurus with their initial terms (admin only)
+// POST /api/v1/pengurus
+func (pc *PengurusController) Create(c *gin.Context) {
+	var req requests.PengurusRequest
+	if err := req.Validate(c); err != nil {
+		return
+	}
+
+	if _, ok := requireAdminScope(c, pc.db); !ok {
+		return
+	}
+
+	pengurus := &models.Pengurus{}
+	if !pc.fillPengurus(c, pengurus, req) {
+		return
+	}
+	pengurus.Terms = make([]models.PengurusTerm, len(req.Terms))
+	for i, termReq := range req.Terms {
+		fillPengurusTerm(&pengurus.Terms[i], termReq)
+	}
+
+	if err := pengurus.Create(pc.db); err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to create pengurus: "+err.Error(), nil)
+		return
+	}
+
+	utils.Success(c, http.StatusCreated, "Pengurus created successfully", formatPengurusResponse(*pengurus))
+}
+
+// Update updates the details of a pengurus, terms are changed through the term endpoints (admin only)
+// PUT /api/v1/pengurus/:id
+func (pc *PengurusController) Update(c *gin.Context) {
+	var req requests.PengurusRequest
+	if err := req.Validate(c); err != nil {
+		return
+	}
+
+	if _, ok := requireAdminScope(c, pc.db); !ok {
+		return
+	}
+
+	pengurus, ok := pc.findPengurus(c)
+	if !ok {
+		return
+	}
+
+	if !pc.fillPengurus(c, pengurus, req) {
+		return
+	}
+
+	if err := pengurus.Update(pc.db); err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to update pengurus: "+err.Error(), nil)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Pengurus updated successfully", formatPengurusResponse(*pengurus))
+}
+
+// Delete moves a pengurus to the trash (admin only)
+// DELETE /api/v1/pengurus/:id
+func (pc *PengurusController) Delete(c *gin.Context) {
+	if _, ok := requireAdminScope(c, pc.db); !ok {
+		return
+	}
+
+	pengurus, ok := pc.findPengurus(c)
+	if !ok {
+		return
+	}
+
+	if err := pengurus.Delete(pc.db); err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to delete pengurus: "+err.Error(), nil)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Pengurus deleted successfully", nil)
+}
+
+// CreateTerm adds a term to a pengurus (admin only)
+// POST /api/v1/pengurus/:id/terms
+func (pc *PengurusController) CreateTerm(c *gin.Context) {
+	var req requests.PengurusTermRequest
+	if err := req.Validate(c); err != nil {
+		return
+	}
+
+	if _, ok := requireAdminScope(c, pc.db); !ok {
+		return
+	}
+
+	pengurus, ok := pc.findPengurus(c)
+	if !ok {
+		return
+	}
+
+	term := &models.PengurusTerm{PengurusID: pengurus.ID}
+	fillPengurusTerm(term, req)
+
+	if err := term.Create(pc.db); err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to create term", nil)
+		return
+	}
+
+	utils.Success(c, http.StatusCreated, "Term created successfully", term)
+}
+
+// UpdateTerm updates a term of a pengurus (admin only)
+// PUT /api/v1/pengurus/:id/terms/:termId
+func (pc *PengurusController) UpdateTerm(c *gin.Context) {
+	var req requests.PengurusTermRequest
+	if err := req.Validate(c); err != nil {
+		return
+	}
+
+	if _, ok := requireAdminScope(c, pc.db); !ok {
+		return
+	}
+
+	pengurus, ok := pc.findPengurus(c)
+	if !ok {
+		return
+	}
+
+	termID, err := strconv.ParseInt(c.Param("termId"), 10, 64)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_id", "Invalid term ID", nil)
+		return
+	}
+
+	term, err := models.FindPengurusTerm(pc.db, pengurus.ID, termID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch term", nil)
+		return
+	}
+	if term == nil {
+		utils.Error(c, http.StatusNotFound, "term_not_found", "Term not found", nil)
+		return
+	}
+
+	fillPengurusTerm(term, req)
+
+	if err := term.Update(pc.db); err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to update term", nil)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Term updated successfully", term)
+}
+
+// DeleteTerm removes a term of a pengurus (admin only)
+// DELETE /api/v1/pengurus/:id/terms/:termId
+func (pc *PengurusController) DeleteTerm(c *gin.Context) {
+	if _, ok := requireAdminScope(c, pc.db); !ok {
+		return
+	}
+
+	pengurus, ok := pc.findPengurus(c)
+	if !ok {
+		return
+	}
+
+	termID, err := strconv.ParseInt(c.Param("termId"), 10, 64)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_id", "Invalid term ID", nil)
+		return
+	}
+
+	if err := models.DeletePengurusTerm(pc.db, pengurus.ID, termID); err != nil {
+		if errors.Is(err, models.ErrPengurusTermNotFound) {
+			utils.Error(c, http.StatusNotFound, "term_not_found", "Term not found", nil)
+			return
+		}
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to delete term", nil)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Term deleted successfully", nil)
+}
+
+// findPengurus loads the pengurus identified by the :id route parameter, writing the error response
+func (pc *PengurusController) findPengurus(c *gin.Context) (*models.Pengurus, bool) {
+	pengurusID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_id", "Invalid pengurus ID", nil)
+		return nil, false
+	}
+
+	pengurus, err := models.FindPengurusByID(pc.db, pengurusID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch pengurus", nil)
+		return nil, false
+	}
+	if pengurus == nil {
+		utils.Error(c, http.StatusNotFound, "pengurus_not_found", "Pengurus not found", nil)
+		return nil, false
+	}
+
+	return pengurus, true
+}
+
+// fillPengurus copies the request into a pengurus, checking the linked user
+// A user can be linked to one pengurus only, an empty name is taken from the user
+func (pc *PengurusController) fillPengurus(c *gin.Context, pengurus *models.Pengurus, req requests.PengurusRequest) bool {
+	name := strings.TrimSpace(req.Name)
+
+	if req.UserID != nil {
+		user, err := models.FindByID(pc.db, *req.UserID)
+		if err != nil {
+			utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch user", nil)
+			return false
+		}
+		if user == nil {
+			utils.ValidationError(c, gin.H{"user_id": "user not found"})
+			return false
+		}
+
+		linked, err := models.FindPengurusByUserID(pc.db, *req.UserID)
+		if err != nil {
+			utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to check linked pengurus", nil)
+			return false
+		}
+		if linked != nil && linked.ID != pengurus.ID {
+			utils.Error(c, http.StatusConflict, "user_linked", models.ErrPengurusUserLinked.Error(), gin.H{
+				"pengurus_id": linked.ID,
+			})
+			return false
+		}
+
+		if name == "" {
+			name = user.Name
+		}
+	}
+
+	pengurus.Name = name
+	pengurus.Email = optionalString(req.Email)
+	pengurus.UserID = req.UserID
+	pengurus.PhotoURL = optionalString(req.PhotoURL)
+	return true
+}
+
+// fillPengurusTerm copies the request into a term
+func fillPengurusTerm(term *models.PengurusTerm, req requests.PengurusTermRequest) {
+	term.Level = req.Level
+	term.Periode = strings.TrimSpace(req.Periode)
+	term.Bidang = strings.TrimSpace(req.Bidang)
+	term.Position = strings.TrimSpace(req.Position)
+	term.SortOrder = req.SortOrder
+}
+
+// isPengurusLevel reports whether a level is known
+func isPengurusLevel(level string) bool {
+	for _, known := range models.PengurusLevels {
+		if level == known {
+			return true
+		}
+	}
+	return false
+}
+
+// optionalString trims a value, an empty one is stored as NULL
+func optionalString(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// buildPengurusOrgChart groups org chart entries into levels and bidang
+// Entries come sorted by level and position order, a bidang is placed where its first position is
+func buildPengurusOrgChart(entries []models.PengurusChartEntry) []gin.H {
+	levels := []gin.H{}
+
+	for start := 0; start < len(entries); {
+		level := entries[start].Level
+		end := start
+		for end < len(entries) && entries[end].Level == level {
+			end++
+		}
+
+		order := []string{}
+		members := map[string][]gin.H{}
+		for _, entry := range entries[start:end] {
+			if _, seen := members[entry.Bidang]; !seen {
+				order = append(order, entry.Bidang)
+			}
+			members[entry.Bidang] = append(members[entry.Bidang], gin.H{
+				"term_id":    entry.ID,
+				"position":   entry.Position,
+				"sort_order": entry.SortOrder,
+				"pengurus": gin.H{
+					"id":        entry.PengurusID,
+					"name":      entry.Name,
+					"photo_url": entry.PhotoURL,
+					"user_id":   entry.UserID,
+				},
+			})
+		}
+
+		bidang := make([]gin.H, len(order))
+		for i, name := range order {
+			bidang[i] = gin.H{"name": name, "members": members[name]}
+		}
+		levels = append(levels, gin.H{"level": level, "bidang": bidang})
+
+		start = end
+	}
+
+	return levels
+}
+
+// Helper function to format pengurus response
+func formatPengurusResponse(pengurus models.Pengurus) gin.H {
+	terms := pengurus.Terms
+	if terms == nil {
+		terms = []models.PengurusTerm{}
+	}
+
+	return gin.H{
+		"id":         pengurus.ID,
+		"name":       pengurus.Name,
+		"email":      pengurus.Email,
+		"user_id":    pengurus.UserID,
+		"photo_url":  pengurus.PhotoURL,
+		"terms":      terms,
+		"created_at": pengurus.CreatedAt,
+		"updated_at": pengurus.UpdatedAt,
+	}
+}
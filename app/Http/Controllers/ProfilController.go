@@ -0,0 +1,402 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	requests "github.com/cvudumbarainformatika/backend/app/Http/Requests"
+	models "github.com/cvudumbarainformatika/backend/app/Models"
+	"github.com/cvudumbarainformatika/backend/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// ProfilController handles the organization profile: visi & misi, sejarah and AD/ART
+type ProfilController struct {
+	db *sqlx.DB
+}
+
+// NewProfilController creates a new ProfilController instance
+func NewProfilController(db *sqlx.DB) *ProfilController {
+	return &ProfilController{
+		db: db,
+	}
+}
+
+// Get returns the current version of every profile section
+// Sections never saved are null, AD/ART includes the documents attached to its current version
+// GET /api/v1/profil
+func (pc *ProfilController) Get(c *gin.Context) {
+	current, err := models.GetCurrentProfilSections(pc.db)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch profil: "+err.Error(), nil)
+		return
+	}
+
+	attachments, ok := pc.loadAttachments(c, current)
+	if !ok {
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Profil retrieved successfully", formatProfilResponse(current, attachments))
+}
+
+// Update saves the sections sent as new versions, sections left out are kept (global admin only)
+// A section sent with the same markdown as its current version is not versioned again
+// POST /api/v1/profil
+func (pc *ProfilController) Update(c *gin.Context) {
+	var req requests.ProfilRequest
+	if err := req.Validate(c); err != nil {
+		return
+	}
+
+	scope, ok := requireAdminScope(c, pc.db)
+	if !ok {
+		return
+	}
+	if !scope.CanManage(nil) {
+		utils.Error(c, http.StatusForbidden, "forbidden", "Only admin pusat can change the organization profile", nil)
+		return
+	}
+
+	var effectiveDate *time.Time
+	if req.EffectiveDate != "" {
+		parsed, err := time.Parse("2006-01-02", req.EffectiveDate)
+		if err != nil {
+			utils.ValidationError(c, gin.H{"effective_date": "Invalid date format. Use YYYY-MM-DD"})
+			return
+		}
+		effectiveDate = &parsed
+	}
+
+	current, err := models.GetCurrentProfilSections(pc.db)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch profil: "+err.Error(), nil)
+		return
+	}
+
+	bodies := map[string]*string{
+		models.ProfilSectionVisiMisi: req.VisiMisi,
+		models.ProfilSectionSejarah:  req.Sejarah,
+		models.ProfilSectionADART:    req.AdArt,
+	}
+
+	versions := []*models.ProfilSectionVersion{}
+	for _, section := range models.ProfilSections {
+		body := bodies[section]
+		if body == nil {
+			continue
+		}
+		markdown := strings.TrimSpace(*body)
+		if latest, exists := current[section]; exists && latest.Body == markdown {
+			continue
+		}
+
+		rendered, err := utils.RenderMarkdown(markdown)
+		if err != nil {
+			utils.Error(c, http.StatusInternalServerError, "render_error", "Failed to render "+section+": "+err.Error(), nil)
+			return
+		}
+
+		version := &models.ProfilSectionVersion{
+			Section:       section,
+			Body:          markdown,
+			HTML:          &rendered.HTML,
+			TOC:           models.ContentTOC(rendered.TOC),
+			ReadingTime:   rendered.ReadingTime,
+			Note:          optionalString(req.Note),
+			EffectiveDate: effectiveDate,
+			CreatedBy:     &scope.UserID,
+		}
+		versions = append(versions, version)
+	}
+
+	if len(versions) > 0 {
+		if err := models.SaveProfilSections(pc.db, versions); err != nil {
+			utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to save profil: "+err.Error(), nil)
+			return
+		}
+		for _, version := range versions {
+			current[version.Section] = *version
+		}
+	}
+
+	attachments, ok := pc.loadAttachments(c, current)
+	if !ok {
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Profil updated successfully", formatProfilResponse(current, attachments))
+}
+
+// GetVersions returns the version history of a section without the content, latest first
+// GET /api/v1/profil/:section/versions
+func (pc *ProfilController) GetVersions(c *gin.Context) {
+	section, ok := profilSectionParam(c)
+	if !ok {
+		return
+	}
+
+	versions, err := models.GetProfilSectionVersions(pc.db, section)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch versions: "+err.Error(), nil)
+		return
+	}
+
+	versionIDs := make([]int64, len(versions))
+	for i, version := range versions {
+		versionIDs[i] = version.ID
+	}
+	attachments, err := models.GetProfilAttachments(pc.db, versionIDs)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch attachments: "+err.Error(), nil)
+		return
+	}
+
+	items := make([]gin.H, len(versions))
+	for i, version := range versions {
+		items[i] = gin.H{
+			"version":        version.Version,
+			"note":           version.Note,
+			"effective_date": formatOptionalDate(version.EffectiveDate),
+			"created_by":     version.CreatedBy,
+			"created_at":     version.CreatedAt,
+			"attachments":    profilAttachmentList(attachments[version.ID]),
+		}
+	}
+
+	utils.Success(c, http.StatusOK, "Versions retrieved successfully", gin.H{
+		"section": section,
+		"items":   items,
+	})
+}
+
+// GetVersion returns a single version of a section with its content
+// GET /api/v1/profil/:section/versions/:version
+func (pc *ProfilController) GetVersion(c *gin.Context) {
+	version, ok := pc.findVersion(c)
+	if !ok {
+		return
+	}
+
+	attachments, err := models.GetProfilAttachments(pc.db, []int64{version.ID})
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch attachments: "+err.Error(), nil)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Version retrieved successfully", formatProfilVersionResponse(*version, attachments[version.ID]))
+}
+
+// CreateAttachment attaches an official PDF document to an AD/ART version (global admin only)
+// Multipart form with file and an optional title, the original filename is used without one
+// POST /api/v1/profil/:section/versions/:version/attachments
+func (pc *ProfilController) CreateAttachment(c *gin.Context) {
+	scope, ok := requireAdminScope(c, pc.db)
+	if !ok {
+		return
+	}
+	if !scope.CanManage(nil) {
+		utils.Error(c, http.StatusForbidden, "forbidden", "Only admin pusat can change the organization profile", nil)
+		return
+	}
+
+	version, ok := pc.findVersion(c)
+	if !ok {
+		return
+	}
+	if version.Section != models.ProfilSectionADART {
+		utils.Error(c, http.StatusBadRequest, "invalid_section", "Documents can only be attached to AD/ART versions", nil)
+		return
+	}
+
+	file, err := c.FormFile("file")
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "no_file", "No file uploaded", nil)
+		return
+	}
+	if strings.ToLower(filepath.Ext(file.Filename)) != ".pdf" {
+		utils.Error(c, http.StatusBadRequest, "invalid_type", "Only PDF documents can be attached", nil)
+		return
+	}
+
+	title := strings.TrimSpace(c.PostForm("title"))
+	if title == "" {
+		title = strings.TrimSuffix(file.Filename, filepath.Ext(file.Filename))
+	}
+	if len(title) > 255 {
+		utils.ValidationError(c, gin.H{"title": "title must be at most 255 characters"})
+		return
+	}
+
+	identifier := fmt.Sprintf("adart_v%d", version.Version)
+	uploadInfo, err := utils.UploadFileOfType(file, utils.FileTypeAttachment, identifier)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "upload_error", err.Error(), nil)
+		return
+	}
+
+	attachment := &models.ProfilAttachment{
+		VersionID: version.ID,
+		Title:     title,
+		FileURL:   uploadInfo.FileURL,
+		FileName:  uploadInfo.OriginalFilename,
+		FileSize:  uploadInfo.FileSize,
+		CreatedBy: &scope.UserID,
+	}
+	if err := attachment.Create(pc.db); err != nil {
+		utils.DeleteFileByURL(uploadInfo.FileURL)
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to save attachment: "+err.Error(), nil)
+		return
+	}
+
+	utils.Success(c, http.StatusCreated, "Attachment added successfully", attachment)
+}
+
+// DeleteAttachment removes a document from a version and deletes its file (global admin only)
+// DELETE /api/v1/profil/:section/versions/:version/attachments/:attachmentId
+func (pc *ProfilController) DeleteAttachment(c *gin.Context) {
+	scope, ok := requireAdminScope(c, pc.db)
+	if !ok {
+		return
+	}
+	if !scope.CanManage(nil) {
+		utils.Error(c, http.StatusForbidden, "forbidden", "Only admin pusat can change the organization profile", nil)
+		return
+	}
+
+	version, ok := pc.findVersion(c)
+	if !ok {
+		return
+	}
+
+	attachmentID, err := strconv.ParseInt(c.Param("attachmentId"), 10, 64)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_id", "Invalid attachment ID", nil)
+		return
+	}
+
+	if err := models.DeleteProfilAttachment(pc.db, version.ID, attachmentID); err != nil {
+		if errors.Is(err, models.ErrProfilAttachmentNotFound) {
+			utils.Error(c, http.StatusNotFound, "attachment_not_found", "Attachment not found", nil)
+			return
+		}
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to delete attachment: "+err.Error(), nil)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Attachment deleted successfully", nil)
+}
+
+// findVersion loads the version named by the :section and :version params, writing the error response
+func (pc *ProfilController) findVersion(c *gin.Context) (*models.ProfilSectionVersion, bool) {
+	section, ok := profilSectionParam(c)
+	if !ok {
+		return nil, false
+	}
+
+	number, err := strconv.Atoi(c.Param("version"))
+	if err != nil || number < 1 {
+		utils.Error(c, http.StatusBadRequest, "invalid_version", "Invalid version number", nil)
+		return nil, false
+	}
+
+	version, err := models.FindProfilSectionVersion(pc.db, section, number)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch version: "+err.Error(), nil)
+		return nil, false
+	}
+	if version == nil {
+		utils.Error(c, http.StatusNotFound, "version_not_found", "Version not found", nil)
+		return nil, false
+	}
+	return version, true
+}
+
+// loadAttachments loads the documents of the current AD/ART version, writing the error response
+func (pc *ProfilController) loadAttachments(c *gin.Context, current map[string]models.ProfilSectionVersion) (map[int64][]models.ProfilAttachment, bool) {
+	versionIDs := []int64{}
+	if adArt, exists := current[models.ProfilSectionADART]; exists {
+		versionIDs = append(versionIDs, adArt.ID)
+	}
+
+	attachments, err := models.GetProfilAttachments(pc.db, versionIDs)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch attachments: "+err.Error(), nil)
+		return nil, false
+	}
+	return attachments, true
+}
+
+// profilSectionParam reads the :section param, writing the error response for an unknown section
+func profilSectionParam(c *gin.Context) (string, bool) {
+	section := c.Param("section")
+	for _, known := range models.ProfilSections {
+		if section == known {
+			return section, true
+		}
+	}
+	utils.Error(c, http.StatusNotFound, "section_not_found", "Unknown profil section: "+section, nil)
+	return "", false
+}
+
+// formatOptionalDate formats an optional date column as YYYY-MM-DD
+func formatOptionalDate(date *time.Time) *string {
+	if date == nil {
+		return nil
+	}
+	formatted := date.Format("2006-01-02")
+	return &formatted
+}
+
+// profilAttachmentList returns the attachments as a list that is never null
+func profilAttachmentList(attachments []models.ProfilAttachment) []models.ProfilAttachment {
+	if attachments == nil {
+		return []models.ProfilAttachment{}
+	}
+	return attachments
+}
+
+// Helper function to format profil response
+func formatProfilResponse(current map[string]models.ProfilSectionVersion, attachments map[int64][]models.ProfilAttachment) gin.H {
+	response := gin.H{}
+	for _, section := range models.ProfilSections {
+		version, exists := current[section]
+		if !exists {
+			response[section] = nil
+			continue
+		}
+		response[section] = formatProfilVersionResponse(version, attachments[version.ID])
+	}
+	return response
+}
+
+// Helper function to format profil section version response
+func formatProfilVersionResponse(version models.ProfilSectionVersion, attachments []models.ProfilAttachment) gin.H {
+	toc := version.TOC
+	if toc == nil {
+		toc = models.ContentTOC{}
+	}
+
+	response := gin.H{
+		"section":        version.Section,
+		"version":        version.Version,
+		"body":           version.Body,
+		"html":           version.HTML,
+		"toc":            toc,
+		"reading_time":   version.ReadingTime,
+		"note":           version.Note,
+		"effective_date": formatOptionalDate(version.EffectiveDate),
+		"created_by":     version.CreatedBy,
+		"updated_at":     version.CreatedAt,
+	}
+	if version.Section == models.ProfilSectionADART {
+		response["attachments"] = profilAttachmentList(attachments)
+	}
+	return response
+}
@@ -0,0 +1,314 @@
+package controllers
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	requests "github.com/cvudumbarainformatika/backend/app/Http/Requests"
+	models "github.com/cvudumbarainformatika/backend/app/Models"
+	"github.com/cvudumbarainformatika/backend/config"
+	"github.com/cvudumbarainformatika/backend/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// maxRedirectHops bounds how far a redirect chain is followed when checking for loops
+const maxRedirectHops = 10
+
+// RedirectController handles admin-managed path-to-path redirects
+type RedirectController struct {
+	db     *sqlx.DB
+	config *config.Config
+}
+
+// NewRedirectController creates a new RedirectController instance
+func NewRedirectController(db *sqlx.DB, cfg *config.Config) *RedirectController {
+	return &RedirectController{
+		db:     db,
+		config: cfg,
+	}
+}
+
+// Resolve looks up an active redirect for a path and counts the hit
+// GET /api/v1/redirects/resolve?path=
+func (rc *RedirectController) Resolve(c *gin.Context) {
+	path := normalizeRedirectPath(c.Query("path"))
+	if path == "" {
+		utils.Error(c, http.StatusBadRequest, "invalid_path", "Query parameter 'path' is required", nil)
+		return
+	}
+
+	redirect, err := models.FindActiveRedirectByPath(rc.db, path)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to resolve redirect", nil)
+		return
+	}
+
+	// Targets saved before absolute URLs were rejected are never served
+	if redirect == nil || !isLocalRedirectPath(redirect.ToPath) {
+		utils.Error(c, http.StatusNotFound, "redirect_not_found", "No redirect for this path", nil)
+		return
+	}
+
+	_ = redirect.RecordHit(rc.db)
+
+	utils.Success(c, http.StatusOK, "Redirect resolved successfully", gin.H{
+		"from_path":   redirect.FromPath,
+		"to_path":     redirect.ToPath,
+		"status_code": redirect.StatusCode,
+	})
+}
+
+// GetList returns paginated list of redirects
+// GET /api/v1/redirects?page=&limit=&search=&is_active=
+func (rc *RedirectController) GetList(c *gin.Context) {
+	if _, ok := requireAdminScope(c, rc.db); !ok {
+		return
+	}
+
+	page, limit := utils.GetPaginationParams(c)
+
+	filters := map[string]interface{}{
+		"search": c.Query("search"),
+	}
+	if isActive := c.Query("is_active"); isActive != "" {
+		filters["is_active"] = isActive == "true" || isActive == "1"
+	}
+
+	offset := (page - 1) * limit
+
+	redirects, total, err := models.GetAllRedirects(rc.db, filters, offset, limit)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch redirects: "+err.Error(), nil)
+		return
+	}
+
+	pagination := utils.OffsetPaginate(redirects, page, limit, total)
+
+	utils.Success(c, http.StatusOK, "Redirects fetched successfully", gin.H{
+		"items":      pagination.Data,
+		"pagination": pagination.Meta,
+	})
+}
+
+// GetByID returns a single redirect
+// GET /api/v1/redirects/:id
+func (rc *RedirectController) GetByID(c *gin.Context) {
+	if _, ok := requireAdminScope(c, rc.db); !ok {
+		return
+	}
+
+	redirectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_id", "Invalid redirect ID", nil)
+		return
+	}
+
+	redirect, err := models.FindRedirectByID(rc.db, redirectID)
+	if err != nil || redirect == nil {
+		utils.Error(c, http.StatusNotFound, "redirect_not_found", "Redirect not found", nil)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Redirect retrieved successfully", redirect)
+}
+
+// Create creates a new redirect
+// POST /api/v1/redirects
+func (rc *RedirectController) Create(c *gin.Context) {
+	if _, ok := requireAdminScope(c, rc.db); !ok {
+		return
+	}
+
+	var req requests.CreateRedirectRequest
+	if err := req.Validate(c); err != nil {
+		return
+	}
+
+	redirect := &models.Redirect{
+		FromPath:   normalizeRedirectPath(req.FromPath),
+		ToPath:     rc.normalizeRedirectTarget(req.ToPath),
+		StatusCode: req.StatusCode,
+		IsActive:   req.IsActive == nil || *req.IsActive,
+	}
+
+	if !rc.validateRedirect(c, redirect) {
+		return
+	}
+
+	if err := redirect.Create(rc.db); err != nil {
+		if strings.Contains(err.Error(), "Duplicate") {
+			utils.Error(c, http.StatusConflict, "redirect_exists", "A redirect for this path already exists", nil)
+			return
+		}
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to create redirect: "+err.Error(), nil)
+		return
+	}
+
+	utils.Success(c, http.StatusCreated, "Redirect created successfully", redirect)
+}
+
+// Update updates a redirect
+// PUT /api/v1/redirects/:id
+func (rc *RedirectController) Update(c *gin.Context) {
+	if _, ok := requireAdminScope(c, rc.db); !ok {
+		return
+	}
+
+	redirectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_id", "Invalid redirect ID", nil)
+		return
+	}
+
+	var req requests.UpdateRedirectRequest
+	if err := req.Validate(c); err != nil {
+		return
+	}
+
+	redirect, err := models.FindRedirectByID(rc.db, redirectID)
+	if err != nil || redirect == nil {
+		utils.Error(c, http.StatusNotFound, "redirect_not_found", "Redirect not found", nil)
+		return
+	}
+
+	redirect.FromPath = normalizeRedirectPath(req.FromPath)
+	redirect.ToPath = rc.normalizeRedirectTarget(req.ToPath)
+	redirect.StatusCode = req.StatusCode
+	if req.IsActive != nil {
+		redirect.IsActive = *req.IsActive
+	}
+
+	if !rc.validateRedirect(c, redirect) {
+		return
+	}
+
+	if err := redirect.Update(rc.db); err != nil {
+		if strings.Contains(err.Error(), "Duplicate") {
+			utils.Error(c, http.StatusConflict, "redirect_exists", "A redirect for this path already exists", nil)
+			return
+		}
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to update redirect: "+err.Error(), nil)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Redirect updated successfully", redirect)
+}
+
+// Delete permanently deletes a redirect
+// DELETE /api/v1/redirects/:id
+func (rc *RedirectController) Delete(c *gin.Context) {
+	if _, ok := requireAdminScope(c, rc.db); !ok {
+		return
+	}
+
+	redirectID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_id", "Invalid redirect ID", nil)
+		return
+	}
+
+	redirect, err := models.FindRedirectByID(rc.db, redirectID)
+	if err != nil || redirect == nil {
+		utils.Error(c, http.StatusNotFound, "redirect_not_found", "Redirect not found", nil)
+		return
+	}
+
+	if err := redirect.Delete(rc.db); err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to delete redirect: "+err.Error(), nil)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Redirect deleted successfully", nil)
+}
+
+// validateRedirect rejects non-local paths, self-redirects and loops, writing the error response
+// The chain starting at to_path is followed up to maxRedirectHops, so A -> B -> C -> A is caught as well
+func (rc *RedirectController) validateRedirect(c *gin.Context, redirect *models.Redirect) bool {
+	if !isLocalRedirectPath(redirect.FromPath) {
+		utils.Error(c, http.StatusBadRequest, "invalid_path", "from_path must be a relative path starting with '/'", nil)
+		return false
+	}
+	if !isLocalRedirectPath(redirect.ToPath) {
+		utils.Error(c, http.StatusBadRequest, "invalid_path", "to_path must be a path on this site", nil)
+		return false
+	}
+
+	if redirect.FromPath == redirect.ToPath {
+		utils.Error(c, http.StatusBadRequest, "redirect_loop", "from_path and to_path must be different", nil)
+		return false
+	}
+
+	path := redirect.ToPath
+	for hop := 0; hop < maxRedirectHops; hop++ {
+		next, err := models.FindActiveRedirectByPath(rc.db, path)
+		if err != nil {
+			utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to check redirect chain", nil)
+			return false
+		}
+		// The saved version of this redirect is replaced, so the chain ends there
+		if next == nil || next.ID == redirect.ID {
+			return true
+		}
+		if next.ToPath == redirect.FromPath {
+			utils.Error(c, http.StatusConflict, "redirect_loop", "The target path already redirects back to this path", nil)
+			return false
+		}
+		path = next.ToPath
+	}
+
+	utils.Error(c, http.StatusConflict, "redirect_chain_too_long", "The target path starts a redirect chain that is too long", nil)
+	return false
+}
+
+// normalizeRedirectTarget normalizes a to_path, reducing absolute URLs on the site host to their path
+// Absolute URLs on other hosts are returned as-is and rejected by validateRedirect
+func (rc *RedirectController) normalizeRedirectTarget(target string) string {
+	target = normalizeRedirectPath(target)
+	if !strings.HasPrefix(target, "http://") && !strings.HasPrefix(target, "https://") {
+		return target
+	}
+
+	parsed, err := url.Parse(target)
+	site, siteErr := url.Parse(rc.config.SEO.SiteURL)
+	if err != nil || siteErr != nil || site.Host == "" || !strings.EqualFold(parsed.Host, site.Host) {
+		return target
+	}
+
+	path := normalizeRedirectPath(parsed.EscapedPath())
+	if path == "" {
+		path = "/"
+	}
+	if parsed.RawQuery != "" {
+		path += "?" + parsed.RawQuery
+	}
+	return path
+}
+
+// isLocalRedirectPath reports whether a path stays on the site
+// Protocol-relative paths (//host) and backslashes, which browsers treat as slashes, are rejected
+func isLocalRedirectPath(path string) bool {
+	return strings.HasPrefix(path, "/") && !strings.HasPrefix(path, "//") && !strings.Contains(path, "\\")
+}
+
+// normalizeRedirectPath trims whitespace and trailing slashes and ensures a leading slash
+// Absolute URLs (http/https) are returned as-is and rejected by validateRedirect
+func normalizeRedirectPath(path string) string {
+	path = strings.TrimSpace(path)
+	if path == "" || strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+		return path
+	}
+
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if len(path) > 1 {
+		path = strings.TrimRight(path, "/")
+		if path == "" {
+			path = "/"
+		}
+	}
+	return path
+}
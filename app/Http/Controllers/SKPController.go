@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	models "github.com/cvudumbarainformatika/backend/app/Models"
+	"github.com/cvudumbarainformatika/backend/config"
+	"github.com/cvudumbarainformatika/backend/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// SKPController handles the SKP ledger of members and their attendance certificates
+type SKPController struct {
+	db     *sqlx.DB
+	config *config.Config
+}
+
+// NewSKPController creates a new SKPController instance
+func NewSKPController(db *sqlx.DB, cfg *config.Config) *SKPController {
+	return &SKPController{
+		db:     db,
+		config: cfg,
+	}
+}
+
+// GetMine returns the SKP ledger of the authenticated member with yearly totals
+// GET /api/v1/me/skp?page=1&limit=10&year=2025
+func (sc *SKPController) GetMine(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		utils.Error(c, http.StatusUnauthorized, "unauthorized", "User not authenticated", nil)
+		return
+	}
+
+	filters := map[string]interface{}{}
+	if yearParam := c.Query("year"); yearParam != "" {
+		year, err := strconv.Atoi(yearParam)
+		if err != nil || year < 1900 {
+			utils.Error(c, http.StatusBadRequest, "invalid_year", "Invalid year", nil)
+			return
+		}
+		filters["year"] = year
+	}
+
+	loc := utils.LoadTimezone(sc.config.App.Timezone)
+
+	totals, err := models.GetUserSKPYearTotals(sc.db, userID, loc)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch SKP totals: "+err.Error(), nil)
+		return
+	}
+
+	var totalPoints float64
+	for _, total := range totals {
+		totalPoints += total.Points
+	}
+
+	page, limit := utils.GetPaginationParams(c)
+	offset := (page - 1) * limit
+
+	entries, total, err := models.GetUserSKPEntries(sc.db, userID, filters, loc, offset, limit)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch SKP ledger: "+err.Error(), nil)
+		return
+	}
+
+	entryResponses := make([]gin.H, len(entries))
+	for i, entry := range entries {
+		entryResponses[i] = sc.formatEntryResponse(entry)
+	}
+
+	pagination := utils.OffsetPaginate(entryResponses, page, limit, total)
+
+	utils.Success(c, http.StatusOK, "SKP ledger fetched successfully", gin.H{
+		"total_points": totalPoints,
+		"years":        totals,
+		"items":        pagination.Data,
+		"pagination":   pagination.Meta,
+	})
+}
+
+// DownloadCertificate returns the PDF certificate of a ledger entry of the authenticated member
+// GET /api/v1/me/skp/:id/certificate
+func (sc *SKPController) DownloadCertificate(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		utils.Error(c, http.StatusUnauthorized, "unauthorized", "User not authenticated", nil)
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_id", "Invalid SKP entry ID", nil)
+		return
+	}
+
+	entry, err := models.FindSKPEntryByID(sc.db, id)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch SKP entry", nil)
+		return
+	}
+	// Other members' entries are reported as missing
+	if entry == nil || entry.UserID != userID {
+		utils.Error(c, http.StatusNotFound, "skp_entry_not_found", "SKP entry not found", nil)
+		return
+	}
+
+	pdf, err := utils.RenderCertificatePDF(utils.CertificateData{
+		Code:       entry.CertificateCode,
+		Issuer:     sc.config.App.Name,
+		MemberName: entry.MemberName,
+		EventTitle: entry.AgendaTitle,
+		EventDate:  entry.AgendaDate,
+		Points:     entry.Points,
+		VerifyURL:  sc.verifyURL(entry.CertificateCode),
+		Timezone:   utils.LoadTimezone(sc.config.App.Timezone),
+	})
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "certificate_error", "Failed to generate certificate: "+err.Error(), nil)
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="certificate-`+entry.CertificateCode+`.pdf"`)
+	c.Data(http.StatusOK, "application/pdf", pdf)
+}
+
+// Verify checks a certificate code and returns what the certificate was issued for
+// GET /api/v1/certificates/verify/:code
+func (sc *SKPController) Verify(c *gin.Context) {
+	entry, err := models.FindSKPEntryByCertificateCode(sc.db, c.Param("code"))
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to verify certificate", nil)
+		return
+	}
+	if entry == nil {
+		utils.Error(c, http.StatusNotFound, "certificate_not_found", "Certificate not found, it may be forged", gin.H{"valid": false})
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Certificate is valid", gin.H{
+		"valid":        true,
+		"code":         entry.CertificateCode,
+		"member_name":  entry.MemberName,
+		"agenda_title": entry.AgendaTitle,
+		"agenda_date":  entry.AgendaDate,
+		"points":       entry.Points,
+		"earned_at":    entry.EarnedAt,
+		"issuer":       sc.config.App.Name,
+	})
+}
+
+// verifyURL returns the public verification URL of a certificate code
+func (sc *SKPController) verifyURL(code string) string {
+	return sc.config.App.URL + "/api/v1/certificates/verify/" + code
+}
+
+// Helper function to format SKP ledger entry response
+func (sc *SKPController) formatEntryResponse(entry models.SKPEntry) gin.H {
+	return gin.H{
+		"id":               entry.ID,
+		"agenda_id":        entry.AgendaID,
+		"registration_id":  entry.RegistrationID,
+		"points":           entry.Points,
+		"description":      entry.Description,
+		"agenda_title":     entry.AgendaTitle,
+		"agenda_date":      entry.AgendaDate,
+		"earned_at":        entry.EarnedAt,
+		"certificate_code": entry.CertificateCode,
+		"certificate_url":  sc.config.App.URL + "/api/v1/me/skp/" + strconv.FormatInt(entry.ID, 10) + "/certificate",
+		"verify_url":       sc.verifyURL(entry.CertificateCode),
+	}
+}
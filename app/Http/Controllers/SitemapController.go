@@ -0,0 +1,284 @@
+package controllers
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	models "github.com/cvudumbarainformatika/backend/app/Models"
+	"github.com/cvudumbarainformatika/backend/config"
+	"github.com/cvudumbarainformatika/backend/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// sitemapMaxURLs is the maximum number of URLs allowed in a single sitemap file
+	sitemapMaxURLs = 50000
+
+	// SitemapCachePrefix is the Redis key prefix for cached sitemap documents
+	SitemapCachePrefix = "sitemap:"
+)
+
+// SitemapController handles sitemap.xml and robots.txt generation
+type SitemapController struct {
+	db     *sqlx.DB
+	redis  *redis.Client
+	config *config.Config
+}
+
+// NewSitemapController creates a new SitemapController instance
+func NewSitemapController(db *sqlx.DB, redisClient *redis.Client, cfg *config.Config) *SitemapController {
+	return &SitemapController{
+		db:     db,
+		redis:  redisClient,
+		config: cfg,
+	}
+}
+
+// XML structures for the sitemap protocol (with image extension)
+type sitemapURLSet struct {
+	XMLName    xml.Name     `xml:"urlset"`
+	Xmlns      string       `xml:"xmlns,attr"`
+	XmlnsImage string       `xml:"xmlns:image,attr"`
+	URLs       []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string         `xml:"loc"`
+	LastMod string         `xml:"lastmod,omitempty"`
+	Images  []sitemapImage `xml:"image:image,omitempty"`
+}
+
+type sitemapImage struct {
+	Loc string `xml:"image:loc"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name          `xml:"sitemapindex"`
+	Xmlns    string            `xml:"xmlns,attr"`
+	Sitemaps []sitemapIndexRef `xml:"sitemap"`
+}
+
+type sitemapIndexRef struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// Sitemap serves the root sitemap (a urlset, or a sitemap index when split)
+// GET /sitemap.xml
+func (sc *SitemapController) Sitemap(c *gin.Context) {
+	sc.serveDocument(c, "index")
+}
+
+// SitemapPage serves a single split sitemap file
+// GET /sitemaps/:page (e.g. /sitemaps/1.xml)
+func (sc *SitemapController) SitemapPage(c *gin.Context) {
+	page, err := strconv.Atoi(strings.TrimSuffix(c.Param("page"), ".xml"))
+	if err != nil || page < 1 {
+		utils.Error(c, http.StatusNotFound, "not_found", "Sitemap not found", nil)
+		return
+	}
+
+	sc.serveDocument(c, "page:"+strconv.Itoa(page))
+}
+
+// Robots serves a configurable robots.txt that references the sitemap
+// GET /robots.txt
+func (sc *SitemapController) Robots(c *gin.Context) {
+	seo := sc.config.SEO
+
+	var b strings.Builder
+	b.WriteString("User-agent: *\n")
+	if seo.RobotsBlockAll {
+		b.WriteString("Disallow: /\n")
+	} else {
+		for _, path := range seo.RobotsAllow {
+			if path = strings.TrimSpace(path); path != "" {
+				b.WriteString("Allow: " + path + "\n")
+			}
+		}
+		for _, path := range seo.RobotsDisallow {
+			if path = strings.TrimSpace(path); path != "" {
+				b.WriteString("Disallow: " + path + "\n")
+			}
+		}
+	}
+	b.WriteString("\nSitemap: " + seo.SiteURL + "/sitemap.xml\n")
+
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(b.String()))
+}
+
+// serveDocument returns a cached sitemap document, building all documents on a cache miss
+func (sc *SitemapController) serveDocument(c *gin.Context, name string) {
+	ctx := c.Request.Context()
+
+	// Try cache first
+	if sc.redis != nil {
+		if cached, err := sc.redis.Get(ctx, SitemapCachePrefix+name).Bytes(); err == nil {
+			sc.writeXML(c, cached)
+			return
+		}
+	}
+
+	documents, err := sc.buildDocuments()
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to build sitemap: "+err.Error(), nil)
+		return
+	}
+
+	sc.cacheDocuments(ctx, documents)
+
+	document, exists := documents[name]
+	if !exists {
+		utils.Error(c, http.StatusNotFound, "not_found", "Sitemap not found", nil)
+		return
+	}
+
+	sc.writeXML(c, document)
+}
+
+// buildDocuments renders the root sitemap and, when needed, all split sitemap files
+func (sc *SitemapController) buildDocuments() (map[string][]byte, error) {
+	entries, err := models.GetSitemapEntries(sc.db)
+	if err != nil {
+		return nil, err
+	}
+
+	// Always include the homepage
+	entries = append([]models.SitemapEntry{{Path: "/", LastMod: latestLastMod(entries)}}, entries...)
+
+	documents := make(map[string][]byte)
+
+	// Single sitemap file
+	if len(entries) <= sitemapMaxURLs {
+		doc, err := sc.renderURLSet(entries)
+		if err != nil {
+			return nil, err
+		}
+		documents["index"] = doc
+		return documents, nil
+	}
+
+	// Split into multiple files and render a sitemap index
+	index := sitemapIndex{Xmlns: "http://www.sitemaps.org/schemas/sitemap/0.9"}
+	for page, start := 1, 0; start < len(entries); page, start = page+1, start+sitemapMaxURLs {
+		end := start + sitemapMaxURLs
+		if end > len(entries) {
+			end = len(entries)
+		}
+
+		doc, err := sc.renderURLSet(entries[start:end])
+		if err != nil {
+			return nil, err
+		}
+		documents["page:"+strconv.Itoa(page)] = doc
+
+		index.Sitemaps = append(index.Sitemaps, sitemapIndexRef{
+			Loc:     fmt.Sprintf("%s/sitemaps/%d.xml", sc.config.SEO.SiteURL, page),
+			LastMod: formatLastMod(latestLastMod(entries[start:end])),
+		})
+	}
+
+	doc, err := marshalSitemapXML(index)
+	if err != nil {
+		return nil, err
+	}
+	documents["index"] = doc
+
+	return documents, nil
+}
+
+// renderURLSet renders a single urlset document
+func (sc *SitemapController) renderURLSet(entries []models.SitemapEntry) ([]byte, error) {
+	urlSet := sitemapURLSet{
+		Xmlns:      "http://www.sitemaps.org/schemas/sitemap/0.9",
+		XmlnsImage: "http://www.google.com/schemas/sitemap-image/1.1",
+		URLs:       make([]sitemapURL, 0, len(entries)),
+	}
+
+	for _, entry := range entries {
+		url := sitemapURL{
+			Loc:     sc.config.SEO.SiteURL + entry.Path,
+			LastMod: formatLastMod(entry.LastMod),
+		}
+		if entry.ImageURL != "" {
+			url.Images = []sitemapImage{{Loc: sc.absoluteAssetURL(entry.ImageURL)}}
+		}
+		urlSet.URLs = append(urlSet.URLs, url)
+	}
+
+	return marshalSitemapXML(urlSet)
+}
+
+// cacheDocuments stores all rendered documents in Redis
+func (sc *SitemapController) cacheDocuments(ctx context.Context, documents map[string][]byte) {
+	if sc.redis == nil {
+		return
+	}
+
+	ttl := time.Duration(sc.config.SEO.SitemapCacheTTL) * time.Second
+
+	// Clear stale split files first (the number of pages may have shrunk)
+	if err := utils.DeleteRedisCacheByPrefix(sc.redis, ctx, SitemapCachePrefix); err != nil {
+		log.Printf("[Sitemap] Failed to clear cache: %v", err)
+	}
+
+	for name, doc := range documents {
+		if err := sc.redis.Set(ctx, SitemapCachePrefix+name, doc, ttl).Err(); err != nil {
+			log.Printf("[Sitemap] Failed to cache %s: %v", name, err)
+		}
+	}
+}
+
+// absoluteAssetURL converts a relative file URL (e.g. /api/v1/files/...) into an absolute URL
+func (sc *SitemapController) absoluteAssetURL(url string) string {
+	if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
+		return url
+	}
+	if !strings.HasPrefix(url, "/") {
+		url = "/" + url
+	}
+	return sc.config.App.URL + url
+}
+
+// writeXML writes an XML document response
+func (sc *SitemapController) writeXML(c *gin.Context, doc []byte) {
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", doc)
+}
+
+// marshalSitemapXML marshals a sitemap structure with the XML header
+func marshalSitemapXML(v interface{}) ([]byte, error) {
+	body, err := xml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+// latestLastMod returns the most recent lastmod among entries
+func latestLastMod(entries []models.SitemapEntry) time.Time {
+	var latest time.Time
+	for _, entry := range entries {
+		if entry.LastMod.After(latest) {
+			latest = entry.LastMod
+		}
+	}
+	return latest
+}
+
+// formatLastMod formats a time in W3C datetime format (empty for zero time)
+func formatLastMod(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
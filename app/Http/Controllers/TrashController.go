@@ -0,0 +1,231 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	models "github.com/cvudumbarainformatika/backend/app/Models"
+	"github.com/cvudumbarainformatika/backend/config"
+	"github.com/cvudumbarainformatika/backend/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// TrashController handles the trash bin for soft-deleted content
+type TrashController struct {
+	db     *sqlx.DB
+	config *config.Config
+}
+
+// NewTrashController creates a new TrashController instance
+func NewTrashController(db *sqlx.DB, cfg *config.Config) *TrashController {
+	return &TrashController{
+		db:     db,
+		config: cfg,
+	}
+}
+
+// GetList returns paginated list of soft-deleted items across all types
+// Admins of a cabang only see the items owned by their cabang
+// GET /api/v1/trash?page=&limit=&type=&search=&deleted_from=&deleted_to=
+func (tc *TrashController) GetList(c *gin.Context) {
+	scope, ok := requireAdminScope(c, tc.db)
+	if !ok {
+		return
+	}
+
+	page, limit := utils.GetPaginationParams(c)
+
+	itemType := c.Query("type")
+	if itemType != "" {
+		if _, ok := models.TrashTypes[itemType]; !ok {
+			utils.Error(c, http.StatusBadRequest, "invalid_type", "Invalid trash type: "+itemType, nil)
+			return
+		}
+	}
+
+	filters := map[string]interface{}{
+		"type":   itemType,
+		"search": c.Query("search"),
+	}
+	if !scope.IsGlobal() {
+		if scope.Cabang == "" {
+			utils.Error(c, http.StatusForbidden, "forbidden", "Your account is not assigned to a cabang", nil)
+			return
+		}
+		filters["cabang"] = scope.Cabang
+	}
+
+	// Date filters (YYYY-MM-DD, deleted_to is inclusive)
+	if from := c.Query("deleted_from"); from != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", from, time.Local)
+		if err != nil {
+			utils.Error(c, http.StatusBadRequest, "invalid_date", "Invalid deleted_from format (YYYY-MM-DD required)", nil)
+			return
+		}
+		filters["deleted_from"] = parsed
+	}
+	if to := c.Query("deleted_to"); to != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", to, time.Local)
+		if err != nil {
+			utils.Error(c, http.StatusBadRequest, "invalid_date", "Invalid deleted_to format (YYYY-MM-DD required)", nil)
+			return
+		}
+		filters["deleted_to"] = parsed.AddDate(0, 0, 1)
+	}
+
+	offset := (page - 1) * limit
+
+	items, total, err := models.GetTrashItems(tc.db, filters, offset, limit)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch trash: "+err.Error(), nil)
+		return
+	}
+
+	trashResponses := make([]gin.H, len(items))
+	for i, item := range items {
+		trashResponses[i] = tc.formatTrashResponse(item)
+	}
+
+	pagination := utils.OffsetPaginate(trashResponses, page, limit, total)
+
+	utils.Success(c, http.StatusOK, "Trash fetched successfully", gin.H{
+		"items":      pagination.Data,
+		"pagination": pagination.Meta,
+	})
+}
+
+// Restore restores a soft-deleted item
+// POST /api/v1/trash/:type/:id/restore
+func (tc *TrashController) Restore(c *gin.Context) {
+	scope, ok := requireAdminScope(c, tc.db)
+	if !ok {
+		return
+	}
+
+	itemType, itemID, ok := tc.parseItemParams(c)
+	if !ok {
+		return
+	}
+
+	original, ok := tc.findManagedItem(c, scope, itemType, itemID)
+	if !ok {
+		return
+	}
+
+	restored, err := models.RestoreTrashItem(tc.db, itemType, itemID)
+	if err != nil {
+		if err == models.ErrTrashItemNotFound {
+			utils.Error(c, http.StatusNotFound, "trash_item_not_found", "Item not found in trash", nil)
+			return
+		}
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to restore item: "+err.Error(), nil)
+		return
+	}
+
+	response := gin.H{
+		"type":         restored.Type,
+		"id":           restored.ID,
+		"title":        restored.Title,
+		"slug":         restored.Slug,
+		"slug_changed": original.Slug != nil && restored.Slug != nil && *original.Slug != *restored.Slug,
+	}
+	if original.Slug != nil {
+		response["original_slug"] = *original.Slug
+	}
+
+	utils.Success(c, http.StatusOK, "Item restored successfully", response)
+}
+
+// Delete permanently deletes a soft-deleted item and its uploaded files
+// Member documents may only be purged by admin pusat
+// DELETE /api/v1/trash/:type/:id
+func (tc *TrashController) Delete(c *gin.Context) {
+	scope, ok := requireAdminScope(c, tc.db)
+	if !ok {
+		return
+	}
+
+	itemType, itemID, ok := tc.parseItemParams(c)
+	if !ok {
+		return
+	}
+
+	if itemType == "documents" && !scope.IsGlobal() {
+		utils.Error(c, http.StatusForbidden, "forbidden", "Only admin pusat may permanently delete member documents", nil)
+		return
+	}
+	if _, ok := tc.findManagedItem(c, scope, itemType, itemID); !ok {
+		return
+	}
+
+	if err := models.PurgeTrashItem(tc.db, itemType, itemID); err != nil {
+		if err == models.ErrTrashItemNotFound {
+			utils.Error(c, http.StatusNotFound, "trash_item_not_found", "Item not found in trash", nil)
+			return
+		}
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to delete item: "+err.Error(), nil)
+		return
+	}
+
+	utils.Success(c, http.StatusOK, "Item permanently deleted", nil)
+}
+
+// parseItemParams validates the :type and :id route parameters, writing the error response
+func (tc *TrashController) parseItemParams(c *gin.Context) (string, int64, bool) {
+	itemType := c.Param("type")
+	if _, ok := models.TrashTypes[itemType]; !ok {
+		utils.Error(c, http.StatusBadRequest, "invalid_type", "Invalid trash type: "+itemType, nil)
+		return "", 0, false
+	}
+
+	itemID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.Error(c, http.StatusBadRequest, "invalid_id", "Invalid item ID", nil)
+		return "", 0, false
+	}
+
+	return itemType, itemID, true
+}
+
+// findManagedItem loads a trashed item and checks it is within the admin's organization scope,
+// writing the error response
+func (tc *TrashController) findManagedItem(c *gin.Context, scope *models.OrgScope, itemType string, itemID int64) (*models.TrashItem, bool) {
+	item, err := models.FindTrashItem(tc.db, itemType, itemID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to fetch trash item", nil)
+		return nil, false
+	}
+	if item == nil {
+		utils.Error(c, http.StatusNotFound, "trash_item_not_found", "Item not found in trash", nil)
+		return nil, false
+	}
+	if !scope.CanManage(item.Cabang) {
+		utils.Error(c, http.StatusForbidden, "forbidden", "Item is outside your organization scope", nil)
+		return nil, false
+	}
+	return item, true
+}
+
+// Helper function to format trash item response
+func (tc *TrashController) formatTrashResponse(item models.TrashItem) gin.H {
+	response := gin.H{
+		"type":       item.Type,
+		"id":         item.ID,
+		"title":      item.Title,
+		"cabang":     item.Cabang,
+		"deleted_at": item.DeletedAt,
+	}
+
+	if item.Slug != nil {
+		response["slug"] = *item.Slug
+	}
+
+	// When the item will be purged automatically
+	if tc.config.Trash.RetentionDays > 0 {
+		response["purge_at"] = item.DeletedAt.AddDate(0, 0, tc.config.Trash.RetentionDays)
+	}
+
+	return response
+}
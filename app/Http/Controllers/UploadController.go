@@ -38,6 +38,10 @@ func (uc *UploadController) UploadFile(c *gin.Context) {
 		utils.Error(c, http.StatusBadRequest, "invalid_type", "Invalid file type", nil)
 		return
 	}
+	if config.Private {
+		utils.Error(c, http.StatusBadRequest, "invalid_type", "Files of this type are uploaded through their own endpoint", nil)
+		return
+	}
 
 	// Set storage path (lazy load from environment)
 	config.StoragePath = utils.GetStoragePathForConfig(config)
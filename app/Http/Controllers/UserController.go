@@ -28,7 +28,7 @@ func NewUserController(db *sqlx.DB) *UserController {
 }
 
 // GetList returns paginated list of users with optional filters
-// GET /api/v1/users/get-lists?page=&per_page=&q=&role=&status=&cabang=&sort=&order=
+// GET /api/v1/users/get-lists?page=&per_page=&q=&role=&status=&cabang=&province=&city=&sort=&order=
 func (uc *UserController) GetList(c *gin.Context) {
 	// Get pagination parameters
 	page, limit := utils.GetPaginationParams(c)
@@ -38,6 +38,7 @@ func (uc *UserController) GetList(c *gin.Context) {
 	role := c.Query("role")
 	status := c.Query("status")
 	cabang := c.Query("cabang")
+	province, city := canonicalRegion(uc.db, c.Query("province"), c.Query("city"))
 	
 	// Get sort parameters (frontend sends sort=column, order=direction)
 	orderBy := c.DefaultQuery("sort", "created_at")
@@ -53,6 +54,8 @@ func (uc *UserController) GetList(c *gin.Context) {
 		"cabang":     true,
 		"phone":      true,
 		"address":    true,
+		"province":   true,
+		"city":       true,
 		"bio":        true,
 		"avatar":     true,
 		"created_at": true,
@@ -69,7 +72,7 @@ func (uc *UserController) GetList(c *gin.Context) {
 	}
 
 	// Build query
-	query := `SELECT id, name, email, role, status, cabang, phone, address, bio, avatar, created_at, updated_at FROM users WHERE 1=1`
+	query := `SELECT id, name, email, role, status, cabang, phone, address, province, city, bio, avatar, created_at, updated_at FROM users WHERE 1=1`
 	args := []interface{}{}
 
 	// Add filters
@@ -88,6 +91,16 @@ func (uc *UserController) GetList(c *gin.Context) {
 		args = append(args, cabang)
 	}
 
+	if province != "" {
+		query += ` AND province = ?`
+		args = append(args, province)
+	}
+
+	if city != "" {
+		query += ` AND city = ?`
+		args = append(args, city)
+	}
+
 	if search != "" {
 		query += ` AND (name LIKE ? OR email LIKE ?)`
 		searchPattern := "%" + search + "%"
@@ -110,6 +123,14 @@ func (uc *UserController) GetList(c *gin.Context) {
 		countQuery += ` AND cabang = ?`
 		countArgs = append(countArgs, cabang)
 	}
+	if province != "" {
+		countQuery += ` AND province = ?`
+		countArgs = append(countArgs, province)
+	}
+	if city != "" {
+		countQuery += ` AND city = ?`
+		countArgs = append(countArgs, city)
+	}
 	if search != "" {
 		countQuery += ` AND (name LIKE ? OR email LIKE ?)`
 		searchPattern := "%" + search + "%"
@@ -149,6 +170,8 @@ func (uc *UserController) GetList(c *gin.Context) {
 			"email":      user.Email,
 			"phone":      getStringValue(user.Phone),
 			"address":    getStringValue(user.Address),
+			"province":   getStringValue(user.Province),
+			"city":       getStringValue(user.City),
 			"bio":        getStringValue(user.Bio),
 			"avatar":     getStringValue(user.Avatar),
 			"cabang":     getStringValue(user.Cabang),
@@ -191,6 +214,8 @@ func (uc *UserController) GetByID(c *gin.Context) {
 		"email":      user.Email,
 		"phone":      getStringValue(user.Phone),
 		"address":    getStringValue(user.Address),
+		"province":   getStringValue(user.Province),
+		"city":       getStringValue(user.City),
 		"bio":        getStringValue(user.Bio),
 		"avatar":     getStringValue(user.Avatar),
 		"cabang":     getStringValue(user.Cabang),
@@ -224,6 +249,11 @@ func (uc *UserController) Create(c *gin.Context) {
 		return
 	}
 
+	province, city, ok := normalizeRegion(c, uc.db, req.Province, req.City)
+	if !ok {
+		return
+	}
+
 	// Create new user
 	user := &models.User{
 		Name:   req.Name,
@@ -242,6 +272,8 @@ func (uc *UserController) Create(c *gin.Context) {
 		user.Address.String = req.Address
 		user.Address.Valid = true
 	}
+	user.Province = sql.NullString{String: province, Valid: province != ""}
+	user.City = sql.NullString{String: city, Valid: city != ""}
 	if req.Bio != "" {
 		user.Bio.String = req.Bio
 		user.Bio.Valid = true
@@ -264,6 +296,8 @@ func (uc *UserController) Create(c *gin.Context) {
 		"email":      user.Email,
 		"phone":      getStringValue(user.Phone),
 		"address":    getStringValue(user.Address),
+		"province":   getStringValue(user.Province),
+		"city":       getStringValue(user.City),
 		"bio":        getStringValue(user.Bio),
 		"avatar":     getStringValue(user.Avatar),
 		"cabang":     getStringValue(user.Cabang),
@@ -306,6 +340,11 @@ func (uc *UserController) Update(c *gin.Context) {
 		}
 	}
 
+	province, city, ok := normalizeRegion(c, uc.db, req.Province, req.City)
+	if !ok {
+		return
+	}
+
 	// Update fields
 	user.Name = req.Name
 	user.Email = req.Email
@@ -327,6 +366,9 @@ func (uc *UserController) Update(c *gin.Context) {
 		user.Address.Valid = false
 	}
 
+	user.Province = sql.NullString{String: province, Valid: province != ""}
+	user.City = sql.NullString{String: city, Valid: city != ""}
+
 	if req.Bio != "" {
 		user.Bio.String = req.Bio
 		user.Bio.Valid = true
@@ -364,6 +406,8 @@ func (uc *UserController) Update(c *gin.Context) {
 		"email":      user.Email,
 		"phone":      getStringValue(user.Phone),
 		"address":    getStringValue(user.Address),
+		"province":   getStringValue(user.Province),
+		"city":       getStringValue(user.City),
 		"bio":        getStringValue(user.Bio),
 		"avatar":     getStringValue(user.Avatar),
 		"cabang":     getStringValue(user.Cabang),
@@ -419,6 +463,8 @@ func (uc *UserController) Patch(c *gin.Context) {
 		"email":      user.Email,
 		"phone":      getStringValue(user.Phone),
 		"address":    getStringValue(user.Address),
+		"province":   getStringValue(user.Province),
+		"city":       getStringValue(user.City),
 		"bio":        getStringValue(user.Bio),
 		"avatar":     getStringValue(user.Avatar),
 		"cabang":     getStringValue(user.Cabang),
@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	models "github.com/cvudumbarainformatika/backend/app/Models"
+	"github.com/cvudumbarainformatika/backend/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// WilayahController serves the province and regency reference data
+type WilayahController struct {
+	db *sqlx.DB
+}
+
+// NewWilayahController creates a new WilayahController instance
+func NewWilayahController(db *sqlx.DB) *WilayahController {
+	return &WilayahController{
+		db: db,
+	}
+}
+
+// GetProvinces returns the provinces ordered by code, optionally filtered by name or alias
+// GET /api/v1/wilayah/provinces?search=
+func (wc *WilayahController) GetProvinces(c *gin.Context) {
+	index, ok := loadWilayah(c, wc.db)
+	if !ok {
+		return
+	}
+
+	provinces := index.SearchProvinces(c.Query("search"))
+	items := make([]gin.H, len(provinces))
+	for i, province := range provinces {
+		items[i] = formatProvinceResponse(province)
+	}
+
+	utils.Success(c, http.StatusOK, "Provinces retrieved successfully", gin.H{
+		"items": items,
+		"total": len(items),
+	})
+}
+
+// GetProvinceRegencies returns the regencies and cities of a province, which may be given by code, name or alias
+// GET /api/v1/wilayah/provinces/:code/regencies?type=&search=
+func (wc *WilayahController) GetProvinceRegencies(c *gin.Context) {
+	kind, ok := regencyTypeQuery(c)
+	if !ok {
+		return
+	}
+
+	index, ok := loadWilayah(c, wc.db)
+	if !ok {
+		return
+	}
+
+	province, err := index.ResolveProvince(c.Param("code"))
+	if err != nil {
+		utils.Error(c, http.StatusNotFound, "province_not_found", "Province not found", nil)
+		return
+	}
+
+	regencies := index.SearchRegencies(province.Code, kind, c.Query("search"))
+	items := make([]gin.H, len(regencies))
+	for i, regency := range regencies {
+		items[i] = formatRegencyResponse(regency)
+	}
+
+	utils.Success(c, http.StatusOK, "Regencies retrieved successfully", gin.H{
+		"province": formatProvinceResponse(province),
+		"items":    items,
+		"total":    len(items),
+	})
+}
+
+// GetRegencies searches regencies and cities across provinces
+// GET /api/v1/wilayah/regencies?province=&type=&search=
+func (wc *WilayahController) GetRegencies(c *gin.Context) {
+	kind, ok := regencyTypeQuery(c)
+	if !ok {
+		return
+	}
+
+	index, ok := loadWilayah(c, wc.db)
+	if !ok {
+		return
+	}
+
+	var provinceCode string
+	if value := c.Query("province"); value != "" {
+		province, err := index.ResolveProvince(value)
+		if err != nil {
+			utils.Error(c, http.StatusBadRequest, "invalid_province", err.Error(), nil)
+			return
+		}
+		provinceCode = province.Code
+	}
+
+	regencies := index.SearchRegencies(provinceCode, kind, c.Query("search"))
+	items := make([]gin.H, len(regencies))
+	for i, regency := range regencies {
+		items[i] = formatRegencyResponse(regency)
+	}
+
+	utils.Success(c, http.StatusOK, "Regencies retrieved successfully", gin.H{
+		"items": items,
+		"total": len(items),
+	})
+}
+
+// Resolve shows how a free text province and city would be normalized on save
+// GET /api/v1/wilayah/resolve?province=&city=
+func (wc *WilayahController) Resolve(c *gin.Context) {
+	if c.Query("province") == "" {
+		utils.ValidationError(c, gin.H{"province": "province is required"})
+		return
+	}
+
+	index, ok := loadWilayah(c, wc.db)
+	if !ok {
+		return
+	}
+
+	province, regency, err := index.Resolve(c.Query("province"), c.Query("city"))
+	if err != nil {
+		var regionErr *models.RegionError
+		if errors.As(err, &regionErr) {
+			utils.Error(c, http.StatusUnprocessableEntity, "unresolved_region", regionErr.Error(), gin.H{
+				"field":      regionErr.Field,
+				"candidates": regionErr.Candidates,
+			})
+			return
+		}
+		utils.Error(c, http.StatusUnprocessableEntity, "unresolved_region", err.Error(), nil)
+		return
+	}
+
+	response := gin.H{
+		"province": formatProvinceResponse(province),
+		"city":     nil,
+	}
+	if regency != nil {
+		response["city"] = formatRegencyResponse(*regency)
+	}
+
+	utils.Success(c, http.StatusOK, "Region resolved successfully", response)
+}
+
+// regencyTypeQuery reads the optional type filter, writing the error response
+func regencyTypeQuery(c *gin.Context) (string, bool) {
+	kind := c.Query("type")
+	if kind != "" && kind != models.RegencyTypeKabupaten && kind != models.RegencyTypeKota {
+		utils.Error(c, http.StatusBadRequest, "invalid_type", "type must be kabupaten or kota", nil)
+		return "", false
+	}
+	return kind, true
+}
+
+// Helper function to format province response
+func formatProvinceResponse(province models.Province) gin.H {
+	return gin.H{
+		"code":    province.Code,
+		"name":    province.Name,
+		"aliases": province.Aliases,
+	}
+}
+
+// Helper function to format regency response
+func formatRegencyResponse(regency models.Regency) gin.H {
+	return gin.H{
+		"code":          regency.Code,
+		"province_code": regency.ProvinceCode,
+		"name":          regency.Name,
+		"type":          regency.Type,
+		"aliases":       regency.Aliases,
+	}
+}
@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"net/http"
+
+	models "github.com/cvudumbarainformatika/backend/app/Models"
+	"github.com/cvudumbarainformatika/backend/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// currentUserID returns the authenticated user ID set by JWTAuthMiddleware
+func currentUserID(c *gin.Context) (int64, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		return 0, false
+	}
+	id, ok := userID.(int64)
+	return id, ok
+}
+
+// currentOrgScope loads the organization scope of the authenticated user, writing the error response
+func currentOrgScope(c *gin.Context, db *sqlx.DB) (*models.OrgScope, bool) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		utils.Error(c, http.StatusUnauthorized, "unauthorized", "User not authenticated", nil)
+		return nil, false
+	}
+
+	scope, err := models.LoadOrgScope(db, userID)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "database_error", "Failed to retrieve user", nil)
+		return nil, false
+	}
+	if scope == nil {
+		utils.Error(c, http.StatusUnauthorized, "unauthorized", "User not found", nil)
+		return nil, false
+	}
+
+	return scope, true
+}
+
+// requireAdminScope loads the organization scope and rejects non-admin users
+func requireAdminScope(c *gin.Context, db *sqlx.DB) (*models.OrgScope, bool) {
+	scope, ok := currentOrgScope(c, db)
+	if !ok {
+		return nil, false
+	}
+	if !scope.IsAdmin() {
+		utils.Error(c, http.StatusForbidden, "forbidden", "Admin access required", nil)
+		return nil, false
+	}
+	return scope, true
+}
@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	models "github.com/cvudumbarainformatika/backend/app/Models"
+	"github.com/cvudumbarainformatika/backend/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+)
+
+// loadWilayah loads the province and regency reference data, writing the error response
+func loadWilayah(c *gin.Context, db *sqlx.DB) (*models.WilayahIndex, bool) {
+	index, err := models.LoadWilayahIndex(db)
+	if err != nil {
+		utils.Error(c, http.StatusInternalServerError, "reference_data_error", "Failed to load province and city reference data", nil)
+		return nil, false
+	}
+	return index, true
+}
+
+// normalizeRegion replaces a province and a city of it with their reference names, writing the error response
+// Both may be empty, a city without a province is rejected
+func normalizeRegion(c *gin.Context, db *sqlx.DB, province string, city string) (string, string, bool) {
+	province = strings.TrimSpace(province)
+	city = strings.TrimSpace(city)
+	if province == "" {
+		if city != "" {
+			utils.ValidationError(c, gin.H{"province": "province is required with a city"})
+			return "", "", false
+		}
+		return "", "", true
+	}
+
+	index, ok := loadWilayah(c, db)
+	if !ok {
+		return "", "", false
+	}
+
+	resolvedProvince, regency, err := index.Resolve(province, city)
+	if err != nil {
+		var regionErr *models.RegionError
+		if errors.As(err, &regionErr) {
+			utils.ValidationError(c, gin.H{regionErr.Field: regionErr.Error()})
+		} else {
+			utils.ValidationError(c, gin.H{"province": err.Error()})
+		}
+		return "", "", false
+	}
+
+	if regency == nil {
+		return resolvedProvince.Name, "", true
+	}
+	return resolvedProvince.Name, regency.Name, true
+}
+
+// normalizeProvince replaces a province with its reference name, an empty one is stored as NULL
+// It writes the error response of an unknown province
+func normalizeProvince(c *gin.Context, db *sqlx.DB, province string) (*string, bool) {
+	name, _, ok := normalizeRegion(c, db, province, "")
+	if !ok || name == "" {
+		return nil, ok
+	}
+	return &name, true
+}
+
+// canonicalRegion returns the reference names of a province and city filter
+// Values that are not known, or a city name shared by several regencies, are returned as is
+func canonicalRegion(db *sqlx.DB, province string, city string) (string, string) {
+	province = strings.TrimSpace(province)
+	city = strings.TrimSpace(city)
+	if province == "" && city == "" {
+		return province, city
+	}
+	index, err := models.LoadWilayahIndex(db)
+	if err != nil {
+		return province, city
+	}
+
+	var provinceCode string
+	if province != "" {
+		if resolved, err := index.ResolveProvince(province); err == nil {
+			province = resolved.Name
+			provinceCode = resolved.Code
+		}
+	}
+	if city != "" {
+		if provinceCode != "" {
+			if regency, err := index.ResolveRegency(provinceCode, city); err == nil {
+				city = regency.Name
+			}
+		} else if matches := index.RegenciesNamed(city); len(matches) == 1 {
+			city = matches[0].Name
+		}
+	}
+	return province, city
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/cvudumbarainformatika/backend/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+)
+
+// InvalidateCacheMiddleware clears cached keys with the given prefixes after a successful write request
+func InvalidateCacheMiddleware(redisClient *redis.Client, prefixes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if redisClient == nil || c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			return
+		}
+		if c.Writer.Status() >= http.StatusBadRequest {
+			return
+		}
+
+		for _, prefix := range prefixes {
+			if err := utils.DeleteRedisCacheByPrefix(redisClient, c.Request.Context(), prefix); err != nil {
+				log.Printf("[Cache] Failed to invalidate %s*: %v", prefix, err)
+			}
+		}
+	}
+}
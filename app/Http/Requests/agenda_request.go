@@ -1,26 +1,32 @@
 package requests
 
 import (
+	"errors"
+
 	"github.com/cvudumbarainformatika/backend/utils"
 	"github.com/gin-gonic/gin"
 )
 
 // CreateAgendaRequest represents the request payload for creating a new agenda
 type CreateAgendaRequest struct {
-	Title           string  `json:"title" binding:"required,min=1,max=255"`
-	Description     string  `json:"description" binding:"required,min=1"`
-	Type            string  `json:"type" binding:"required,oneof=webinar workshop seminar kongres pelatihan"`
-	Date            string  `json:"date" binding:"required"` // ISO8601 string
-	EndDate         *string `json:"end_date" binding:"omitempty"`
-	IsOnline        bool    `json:"is_online" binding:"omitempty"`
-	Location        string  `json:"location" binding:"required"`
-	Skp             float64 `json:"skp" binding:"omitempty"`
-	Quota           int     `json:"quota" binding:"omitempty"`
-	RegistrationURL string  `json:"registration_url" binding:"omitempty"`
-	ImageURL        string  `json:"image_url" binding:"omitempty"`
-	Fee             string  `json:"fee" binding:"omitempty"`
-	Status          string  `json:"status" binding:"omitempty,oneof=draft published"`
-	PublishedAt     *string `json:"published_at" binding:"omitempty"`
+	Title            string            `json:"title" binding:"required,min=1,max=255"`
+	Description      string            `json:"description" binding:"required,min=1"`
+	Type             string            `json:"type" binding:"required,oneof=webinar workshop seminar kongres pelatihan"`
+	Date             string            `json:"date" binding:"required"` // ISO8601 string
+	EndDate          *string           `json:"end_date" binding:"omitempty"`
+	IsOnline         bool              `json:"is_online" binding:"omitempty"`
+	Location         string            `json:"location" binding:"required"`
+	Province         string            `json:"province" binding:"omitempty,max=100"`
+	Skp              float64           `json:"skp" binding:"omitempty"`
+	Quota            int               `json:"quota" binding:"omitempty"`
+	RegistrationURL  string            `json:"registration_url" binding:"omitempty"`
+	RegistrationForm []utils.FormField `json:"registration_form" binding:"omitempty"` // Custom questions asked on registration
+	ImageURL         string            `json:"image_url" binding:"omitempty"`
+	Fee              string            `json:"fee" binding:"omitempty"`
+	Price            int64             `json:"price" binding:"omitempty,min=0"`                                 // Registration price in IDR, 0 = free
+	ReminderOffsets  []int             `json:"reminder_offsets" binding:"omitempty,max=5,dive,min=5,max=43200"` // Minutes before start to remind registrants, omit for defaults and send [] to disable
+	Status           string            `json:"status" binding:"omitempty,oneof=draft published"`
+	PublishedAt      *string           `json:"published_at" binding:"omitempty"`
 }
 
 // Validate validates the CreateAgendaRequest
@@ -30,6 +36,11 @@ func (r *CreateAgendaRequest) Validate(c *gin.Context) error {
 		return err
 	}
 
+	if errs := utils.ValidateFormSchema(r.RegistrationForm); errs != nil {
+		utils.ValidationError(c, gin.H{"registration_form": errs})
+		return errors.New("invalid registration form")
+	}
+
 	// Set default status if not provided
 	if r.Status == "" {
 		r.Status = "draft"
@@ -40,20 +51,25 @@ func (r *CreateAgendaRequest) Validate(c *gin.Context) error {
 
 // UpdateAgendaRequest represents the request payload for updating an agenda
 type UpdateAgendaRequest struct {
-	Title           string  `json:"title" binding:"required,min=1,max=255"`
-	Description     string  `json:"description" binding:"required,min=1"`
-	Type            string  `json:"type" binding:"required,oneof=webinar workshop seminar kongres pelatihan"`
-	Date            string  `json:"date" binding:"required"`
-	EndDate         *string `json:"end_date" binding:"omitempty"`
-	IsOnline        bool    `json:"is_online" binding:"omitempty"`
-	Location        string  `json:"location" binding:"required"`
-	Skp             float64 `json:"skp" binding:"omitempty"`
-	Quota           int     `json:"quota" binding:"omitempty"`
-	RegistrationURL string  `json:"registration_url" binding:"omitempty"`
-	ImageURL        string  `json:"image_url" binding:"omitempty"`
-	Fee             string  `json:"fee" binding:"omitempty"`
-	Status          string  `json:"status" binding:"required,oneof=draft published"`
-	PublishedAt     *string `json:"published_at" binding:"omitempty"`
+	Title            string            `json:"title" binding:"required,min=1,max=255"`
+	Slug             string            `json:"slug" binding:"omitempty,max=255"` // Optional: renaming keeps the old slug as a redirect
+	Description      string            `json:"description" binding:"required,min=1"`
+	Type             string            `json:"type" binding:"required,oneof=webinar workshop seminar kongres pelatihan"`
+	Date             string            `json:"date" binding:"required"`
+	EndDate          *string           `json:"end_date" binding:"omitempty"`
+	IsOnline         bool              `json:"is_online" binding:"omitempty"`
+	Location         string            `json:"location" binding:"required"`
+	Province         string            `json:"province" binding:"omitempty,max=100"`
+	Skp              float64           `json:"skp" binding:"omitempty"`
+	Quota            int               `json:"quota" binding:"omitempty"`
+	RegistrationURL  string            `json:"registration_url" binding:"omitempty"`
+	RegistrationForm []utils.FormField `json:"registration_form" binding:"omitempty"` // Custom questions, omit to keep the current form and send [] to remove it
+	ImageURL         string            `json:"image_url" binding:"omitempty"`
+	Fee              string            `json:"fee" binding:"omitempty"`
+	Price            int64             `json:"price" binding:"omitempty,min=0"`                                 // Registration price in IDR, 0 = free
+	ReminderOffsets  []int             `json:"reminder_offsets" binding:"omitempty,max=5,dive,min=5,max=43200"` // Minutes before start to remind registrants, omit to keep the current offsets
+	Status           string            `json:"status" binding:"required,oneof=draft published"`
+	PublishedAt      *string           `json:"published_at" binding:"omitempty"`
 }
 
 // Validate validates the UpdateAgendaRequest
@@ -63,6 +79,43 @@ func (r *UpdateAgendaRequest) Validate(c *gin.Context) error {
 		return err
 	}
 
+	if errs := utils.ValidateFormSchema(r.RegistrationForm); errs != nil {
+		utils.ValidationError(c, gin.H{"registration_form": errs})
+		return errors.New("invalid registration form")
+	}
+
+	return nil
+}
+
+// AgendaSeriesRequest represents the request payload for creating or editing a recurring agenda series
+// Date and end_date are the start and end of the first occurrence
+type AgendaSeriesRequest struct {
+	CreateAgendaRequest
+	RRule string `json:"rrule" binding:"required,max=255"` // RRULE subset, e.g. FREQ=MONTHLY;BYDAY=2TH;COUNT=12
+}
+
+// Validate validates the AgendaSeriesRequest
+func (r *AgendaSeriesRequest) Validate(c *gin.Context) error {
+	if err := c.ShouldBindJSON(r); err != nil {
+		utils.ValidationError(c, err.Error())
+		return err
+	}
+
+	if errs := utils.ValidateFormSchema(r.RegistrationForm); errs != nil {
+		utils.ValidationError(c, gin.H{"registration_form": errs})
+		return errors.New("invalid registration form")
+	}
+
+	if _, err := utils.ParseRRule(r.RRule); err != nil {
+		utils.ValidationError(c, gin.H{"rrule": err.Error()})
+		return err
+	}
+
+	// Set default status if not provided
+	if r.Status == "" {
+		r.Status = "draft"
+	}
+
 	return nil
 }
 
@@ -82,3 +135,102 @@ func (r *PatchAgendaRequest) Validate(c *gin.Context) error {
 
 	return nil
 }
+
+// RegisterAgendaRequest represents the optional request payload of a registration
+type RegisterAgendaRequest struct {
+	Answers map[string]interface{} `json:"answers" binding:"omitempty"` // Keyed by registration form field name
+}
+
+// Validate validates the RegisterAgendaRequest, an empty body is allowed for agenda without a form
+func (r *RegisterAgendaRequest) Validate(c *gin.Context) error {
+	if c.Request.ContentLength == 0 {
+		return nil
+	}
+	if err := c.ShouldBindJSON(r); err != nil {
+		utils.ValidationError(c, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// UpdateAgendaRegistrationRequest represents the request payload for confirming or cancelling a registration
+type UpdateAgendaRegistrationRequest struct {
+	Status string `json:"status" binding:"required,oneof=confirmed cancelled"`
+}
+
+// Validate validates the UpdateAgendaRegistrationRequest
+func (r *UpdateAgendaRegistrationRequest) Validate(c *gin.Context) error {
+	if err := c.ShouldBindJSON(r); err != nil {
+		utils.ValidationError(c, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// CheckInRequest represents the request payload of a committee ticket scan
+type CheckInRequest struct {
+	Ticket string `json:"ticket" binding:"required,max=512"`
+}
+
+// Validate validates the CheckInRequest
+func (r *CheckInRequest) Validate(c *gin.Context) error {
+	if err := c.ShouldBindJSON(r); err != nil {
+		utils.ValidationError(c, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// AgendaSpeakerRequest represents the request payload for creating or updating a speaker of an agenda
+// Name may be empty when the speaker is linked to a user or pengurus, their name is used instead
+type AgendaSpeakerRequest struct {
+	Name        string `json:"name" binding:"omitempty,max=255"`
+	Title       string `json:"title" binding:"omitempty,max=255"` // Academic title or position
+	Institution string `json:"institution" binding:"omitempty,max=255"`
+	Bio         string `json:"bio" binding:"omitempty"`
+	PhotoURL    string `json:"photo_url" binding:"omitempty,max=500"`
+	UserID      *int64 `json:"user_id" binding:"omitempty,min=1"`
+	PengurusID  *int64 `json:"pengurus_id" binding:"omitempty,min=1"`
+	SortOrder   int    `json:"sort_order" binding:"omitempty"`
+}
+
+// Validate validates the AgendaSpeakerRequest
+func (r *AgendaSpeakerRequest) Validate(c *gin.Context) error {
+	if err := c.ShouldBindJSON(r); err != nil {
+		utils.ValidationError(c, err.Error())
+		return err
+	}
+
+	if r.Name == "" && r.UserID == nil && r.PengurusID == nil {
+		utils.ValidationError(c, gin.H{"name": "name is required when no user or pengurus is linked"})
+		return errors.New("missing speaker name")
+	}
+
+	return nil
+}
+
+// AgendaSessionRequest represents the request payload for creating or updating a rundown session
+type AgendaSessionRequest struct {
+	Title        string  `json:"title" binding:"required,min=1,max=255"`
+	Description  string  `json:"description" binding:"omitempty"`
+	StartsAt     string  `json:"starts_at" binding:"required"` // ISO8601 string
+	EndsAt       string  `json:"ends_at" binding:"required"`   // ISO8601 string
+	Room         string  `json:"room" binding:"omitempty,max=255"`
+	SKP          float64 `json:"skp" binding:"omitempty,min=0"` // SKP credits of this session
+	SortOrder    int     `json:"sort_order" binding:"omitempty"`
+	SpeakerIDs   []int64 `json:"speaker_ids" binding:"omitempty,dive,min=1"`   // Speaker IDs of the same agenda
+	ModeratorIDs []int64 `json:"moderator_ids" binding:"omitempty,dive,min=1"` // Speaker IDs of the same agenda
+}
+
+// Validate validates the AgendaSessionRequest
+func (r *AgendaSessionRequest) Validate(c *gin.Context) error {
+	if err := c.ShouldBindJSON(r); err != nil {
+		utils.ValidationError(c, err.Error())
+		return err
+	}
+
+	return nil
+}
@@ -36,6 +36,7 @@ func (r *CreateBeritaRequest) Validate(c *gin.Context) error {
 // UpdateBeritaRequest represents the request payload for updating a berita
 type UpdateBeritaRequest struct {
 	Title       string   `json:"title" binding:"required,min=1,max=255"`
+	Slug        string   `json:"slug" binding:"omitempty,max=255"` // Optional: renaming keeps the old slug as a redirect
 	Excerpt     string   `json:"excerpt" binding:"required,min=1"`
 	Content     string   `json:"content" binding:"required,min=1"`
 	ImageURL    string   `json:"image_url" binding:"omitempty,max=255"`
@@ -0,0 +1,41 @@
+package requests
+
+import (
+	"github.com/cvudumbarainformatika/backend/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// BulkBeritaRequest represents the request payload for a bulk berita action
+type BulkBeritaRequest struct {
+	IDs      []int64  `json:"ids" binding:"required,min=1,max=200,dive,gt=0"`
+	Action   string   `json:"action" binding:"required,oneof=publish unpublish set_category add_tags remove_tags delete restore"`
+	Category string   `json:"category" binding:"required_if=Action set_category,omitempty,oneof=umum ilmiah kegiatan pengumuman prestasi"`
+	Tags     []string `json:"tags" binding:"required_if=Action add_tags,required_if=Action remove_tags,omitempty,dive,min=1,max=255"`
+}
+
+// Validate validates the BulkBeritaRequest
+func (r *BulkBeritaRequest) Validate(c *gin.Context) error {
+	if err := c.ShouldBindJSON(r); err != nil {
+		utils.ValidationError(c, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// BulkAgendaRequest represents the request payload for a bulk agenda action
+type BulkAgendaRequest struct {
+	IDs    []int64 `json:"ids" binding:"required,min=1,max=200,dive,gt=0"`
+	Action string  `json:"action" binding:"required,oneof=publish unpublish set_type delete restore"`
+	Type   string  `json:"type" binding:"required_if=Action set_type,omitempty,oneof=webinar workshop seminar kongres pelatihan"`
+}
+
+// Validate validates the BulkAgendaRequest
+func (r *BulkAgendaRequest) Validate(c *gin.Context) error {
+	if err := c.ShouldBindJSON(r); err != nil {
+		utils.ValidationError(c, err.Error())
+		return err
+	}
+
+	return nil
+}
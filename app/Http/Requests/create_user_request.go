@@ -14,6 +14,8 @@ type CreateUserRequest struct {
 	Status   string `json:"status" binding:"required,oneof=active pending inactive"`
 	Phone    string `json:"phone" binding:"max=20"`
 	Address  string `json:"address" binding:"max=500"`
+	Province string `json:"province" binding:"max=100"`
+	City     string `json:"city" binding:"max=100"`
 	Bio      string `json:"bio" binding:"max=1000"`
 	Cabang   string `json:"cabang" binding:"max=255"`
 }
@@ -0,0 +1,130 @@
+package requests
+
+import (
+	"errors"
+	"net/mail"
+	"net/url"
+
+	"github.com/cvudumbarainformatika/backend/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// DirektoriRequest represents the request payload for creating or updating a direktori entry
+type DirektoriRequest struct {
+	Name             string   `json:"name" binding:"required,min=1,max=255"`
+	Type             string   `json:"type" binding:"required,oneof=rumah_sakit klinik institusi"`
+	Address          string   `json:"address" binding:"omitempty"`
+	Phone            string   `json:"phone" binding:"omitempty,max=20"`
+	Email            string   `json:"email" binding:"omitempty,email,max=255"`
+	Website          string   `json:"website" binding:"omitempty,url,max=255"`
+	City             string   `json:"city" binding:"required,min=1,max=100"`
+	Province         string   `json:"province" binding:"required,min=1,max=100"`
+	Latitude         *float64 `json:"latitude" binding:"omitempty,min=-90,max=90"`    // WGS84 degrees, set together with longitude
+	Longitude        *float64 `json:"longitude" binding:"omitempty,min=-180,max=180"` // WGS84 degrees, set together with latitude
+	HasRespirologist bool     `json:"has_respirologist" binding:"omitempty"`
+	Facilities       []string `json:"facilities" binding:"omitempty,max=50,dive,max=100"` // e.g. ["spirometri", "bronkoskopi"]
+}
+
+// Validate validates the DirektoriRequest
+func (r *DirektoriRequest) Validate(c *gin.Context) error {
+	if err := c.ShouldBindJSON(r); err != nil {
+		utils.ValidationError(c, err.Error())
+		return err
+	}
+
+	if (r.Latitude == nil) != (r.Longitude == nil) {
+		utils.ValidationError(c, gin.H{"latitude": "latitude and longitude must be set together"})
+		return errors.New("incomplete coordinates")
+	}
+
+	return nil
+}
+
+// DirektoriSubmissionRequest represents a member proposal for a new direktori entry
+type DirektoriSubmissionRequest struct {
+	DirektoriRequest
+	Note string `json:"note" binding:"omitempty,max=2000"` // Source or explanation for the moderators
+}
+
+// Validate validates the DirektoriSubmissionRequest
+func (r *DirektoriSubmissionRequest) Validate(c *gin.Context) error {
+	if err := c.ShouldBindJSON(r); err != nil {
+		utils.ValidationError(c, err.Error())
+		return err
+	}
+
+	if (r.Latitude == nil) != (r.Longitude == nil) {
+		utils.ValidationError(c, gin.H{"latitude": "latitude and longitude must be set together"})
+		return errors.New("incomplete coordinates")
+	}
+
+	return nil
+}
+
+// DirektoriCorrectionRequest represents a member correction of a direktori entry, only the given fields are proposed
+type DirektoriCorrectionRequest struct {
+	Name             *string   `json:"name" binding:"omitempty,min=1,max=255"`
+	Type             *string   `json:"type" binding:"omitempty,oneof=rumah_sakit klinik institusi"`
+	Address          *string   `json:"address" binding:"omitempty"`
+	Phone            *string   `json:"phone" binding:"omitempty,max=20"`
+	Email            *string   `json:"email" binding:"omitempty,max=255"`
+	Website          *string   `json:"website" binding:"omitempty,max=255"`
+	City             *string   `json:"city" binding:"omitempty,min=1,max=100"`
+	Province         *string   `json:"province" binding:"omitempty,min=1,max=100"`
+	Latitude         *float64  `json:"latitude" binding:"omitempty,min=-90,max=90"`
+	Longitude        *float64  `json:"longitude" binding:"omitempty,min=-180,max=180"`
+	HasRespirologist *bool     `json:"has_respirologist" binding:"omitempty"`
+	Facilities       *[]string `json:"facilities" binding:"omitempty,max=50,dive,max=100"`
+	Note             string    `json:"note" binding:"omitempty,max=2000"`
+}
+
+// Validate validates the DirektoriCorrectionRequest
+func (r *DirektoriCorrectionRequest) Validate(c *gin.Context) error {
+	if err := c.ShouldBindJSON(r); err != nil {
+		utils.ValidationError(c, err.Error())
+		return err
+	}
+
+	if (r.Latitude == nil) != (r.Longitude == nil) {
+		utils.ValidationError(c, gin.H{"latitude": "latitude and longitude must be set together"})
+		return errors.New("incomplete coordinates")
+	}
+
+	// Email and website may be cleared with an empty string, otherwise they must be valid
+	if r.Email != nil && *r.Email != "" && !isEmailAddress(*r.Email) {
+		utils.ValidationError(c, gin.H{"email": "email must be a valid email address"})
+		return errors.New("invalid email")
+	}
+	if r.Website != nil && *r.Website != "" && !isAbsoluteURL(*r.Website) {
+		utils.ValidationError(c, gin.H{"website": "website must be a valid URL"})
+		return errors.New("invalid website")
+	}
+
+	return nil
+}
+
+// isEmailAddress reports whether value is a bare email address
+func isEmailAddress(value string) bool {
+	address, err := mail.ParseAddress(value)
+	return err == nil && address.Address == value
+}
+
+// isAbsoluteURL reports whether value is an absolute URL with a host
+func isAbsoluteURL(value string) bool {
+	parsed, err := url.ParseRequestURI(value)
+	return err == nil && parsed.Scheme != "" && parsed.Host != ""
+}
+
+// DirektoriRejectRequest represents the reason an admin rejects a submission with
+type DirektoriRejectRequest struct {
+	Reason string `json:"reason" binding:"required,min=3,max=1000"`
+}
+
+// Validate validates the DirektoriRejectRequest
+func (r *DirektoriRejectRequest) Validate(c *gin.Context) error {
+	if err := c.ShouldBindJSON(r); err != nil {
+		utils.ValidationError(c, err.Error())
+		return err
+	}
+	return nil
+}
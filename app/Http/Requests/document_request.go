@@ -0,0 +1,47 @@
+package requests
+
+import (
+	"errors"
+	"mime/multipart"
+
+	"github.com/cvudumbarainformatika/backend/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// DocumentRequest represents the multipart payload for creating or updating a member document
+// The file is required on create, on update it replaces the current file when given
+type DocumentRequest struct {
+	Name       string                `form:"name" binding:"required,min=1,max=255"`
+	Type       string                `form:"type" binding:"required,oneof=STR SIP Serkom Identitas Lainnya"`
+	ValidUntil string                `form:"valid_until" binding:"omitempty,datetime=2006-01-02"` // Empty for documents valid for life
+	File       *multipart.FileHeader `form:"file"`
+}
+
+// Validate validates the DocumentRequest, requireFile is set on create
+func (r *DocumentRequest) Validate(c *gin.Context, requireFile bool) error {
+	if err := c.ShouldBind(r); err != nil {
+		utils.ValidationError(c, err.Error())
+		return err
+	}
+
+	if requireFile && r.File == nil {
+		utils.ValidationError(c, gin.H{"file": "file is required"})
+		return errors.New("missing document file")
+	}
+
+	return nil
+}
+
+// DocumentRejectRequest represents the reason an admin rejects a document with
+type DocumentRejectRequest struct {
+	Reason string `json:"reason" binding:"required,min=3,max=1000"`
+}
+
+// Validate validates the DocumentRejectRequest
+func (r *DocumentRejectRequest) Validate(c *gin.Context) error {
+	if err := c.ShouldBindJSON(r); err != nil {
+		utils.ValidationError(c, err.Error())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package requests
+
+import (
+	"errors"
+
+	"github.com/cvudumbarainformatika/backend/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// PengurusRequest represents the request payload for creating or updating a pengurus
+// Name may be empty when the pengurus is linked to a user, their name is used instead.
+// Terms are only read on create, later changes go through the term endpoints
+type PengurusRequest struct {
+	Name     string                `json:"name" binding:"omitempty,max=255"`
+	Email    string                `json:"email" binding:"omitempty,email,max=255"`
+	UserID   *int64                `json:"user_id" binding:"omitempty,min=1"`
+	PhotoURL string                `json:"photo_url" binding:"omitempty,max=500"`
+	Terms    []PengurusTermRequest `json:"terms" binding:"omitempty,max=50,dive"`
+}
+
+// Validate validates the PengurusRequest
+func (r *PengurusRequest) Validate(c *gin.Context) error {
+	if err := c.ShouldBindJSON(r); err != nil {
+		utils.ValidationError(c, err.Error())
+		return err
+	}
+
+	if r.Name == "" && r.UserID == nil {
+		utils.ValidationError(c, gin.H{"name": "name is required when no user is linked"})
+		return errors.New("missing pengurus name")
+	}
+
+	return nil
+}
+
+// PengurusTermRequest represents the request payload for a position a pengurus holds in a period
+type PengurusTermRequest struct {
+	Level     string `json:"level" binding:"required,oneof=pusat wilayah cabang"`
+	Periode   string `json:"periode" binding:"required,min=1,max=50"` // e.g. 2023-2026
+	Bidang    string `json:"bidang" binding:"omitempty,max=255"`      // Empty for the executive board
+	Position  string `json:"position" binding:"required,min=1,max=255"`
+	SortOrder int    `json:"sort_order" binding:"omitempty"`
+}
+
+// Validate validates the PengurusTermRequest
+func (r *PengurusTermRequest) Validate(c *gin.Context) error {
+	if err := c.ShouldBindJSON(r); err != nil {
+		utils.ValidationError(c, err.Error())
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,33 @@
+package requests
+
+import (
+	"errors"
+
+	"github.com/cvudumbarainformatika/backend/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ProfilRequest represents the request payload for updating the organization profile
+// Sections left out are kept as they are, each section sent becomes a new version of it
+type ProfilRequest struct {
+	VisiMisi      *string `json:"visi_misi" binding:"omitempty,max=1000000"` // Markdown
+	Sejarah       *string `json:"sejarah" binding:"omitempty,max=1000000"`   // Markdown
+	AdArt         *string `json:"ad_art" binding:"omitempty,max=1000000"`    // Markdown
+	Note          string  `json:"note" binding:"omitempty,max=500"`          // e.g. the congress that amended the AD/ART
+	EffectiveDate string  `json:"effective_date" binding:"omitempty,datetime=2006-01-02"`
+}
+
+// Validate validates the ProfilRequest
+func (r *ProfilRequest) Validate(c *gin.Context) error {
+	if err := c.ShouldBindJSON(r); err != nil {
+		utils.ValidationError(c, err.Error())
+		return err
+	}
+
+	if r.VisiMisi == nil && r.Sejarah == nil && r.AdArt == nil {
+		utils.ValidationError(c, gin.H{"sections": "at least one of visi_misi, sejarah or ad_art is required"})
+		return errors.New("no profil section")
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package requests
+
+import (
+	"github.com/cvudumbarainformatika/backend/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// CreateRedirectRequest represents the request payload for creating a redirect
+type CreateRedirectRequest struct {
+	FromPath   string `json:"from_path" binding:"required,min=1,max=500"`
+	ToPath     string `json:"to_path" binding:"required,min=1,max=500"`
+	StatusCode int    `json:"status_code" binding:"omitempty,oneof=301 302 307 308"`
+	IsActive   *bool  `json:"is_active" binding:"omitempty"`
+}
+
+// Validate validates the CreateRedirectRequest
+func (r *CreateRedirectRequest) Validate(c *gin.Context) error {
+	if err := c.ShouldBindJSON(r); err != nil {
+		utils.ValidationError(c, err.Error())
+		return err
+	}
+
+	// Default to a permanent redirect
+	if r.StatusCode == 0 {
+		r.StatusCode = 301
+	}
+
+	return nil
+}
+
+// UpdateRedirectRequest represents the request payload for updating a redirect
+type UpdateRedirectRequest struct {
+	FromPath   string `json:"from_path" binding:"required,min=1,max=500"`
+	ToPath     string `json:"to_path" binding:"required,min=1,max=500"`
+	StatusCode int    `json:"status_code" binding:"required,oneof=301 302 307 308"`
+	IsActive   *bool  `json:"is_active" binding:"omitempty"`
+}
+
+// Validate validates the UpdateRedirectRequest
+func (r *UpdateRedirectRequest) Validate(c *gin.Context) error {
+	if err := c.ShouldBindJSON(r); err != nil {
+		utils.ValidationError(c, err.Error())
+		return err
+	}
+
+	return nil
+}
@@ -8,12 +8,14 @@ import (
 
 // UpdateProfileRequest represents the request payload for updating user profile
 type UpdateProfileRequest struct {
-	Name    string                `json:"name" form:"name" binding:"required,min=1,max=255"`
-	Phone   string                `json:"phone" form:"phone" binding:"max=20"`
-	Address string                `json:"address" form:"address" binding:"max=500"`
-	Bio     string                `json:"bio" form:"bio" binding:"max=1000"`
-	Cabang  string                `json:"cabang" form:"cabang" binding:"max=255"`
-	Avatar  *multipart.FileHeader `form:"avatar"`
+	Name     string                `json:"name" form:"name" binding:"required,min=1,max=255"`
+	Phone    string                `json:"phone" form:"phone" binding:"max=20"`
+	Address  string                `json:"address" form:"address" binding:"max=500"`
+	Province string                `json:"province" form:"province" binding:"max=100"`
+	City     string                `json:"city" form:"city" binding:"max=100"`
+	Bio      string                `json:"bio" form:"bio" binding:"max=1000"`
+	Cabang   string                `json:"cabang" form:"cabang" binding:"max=255"`
+	Avatar   *multipart.FileHeader `form:"avatar"`
 }
 
 // Validate validates the UpdateProfileRequest
@@ -25,11 +27,13 @@ func (r *UpdateProfileRequest) Validate(c *gin.Context) error {
 	if contentType == "application/json" {
 		// For JSON, we only parse form fields (not Avatar)
 		type jsonRequest struct {
-			Name    string `json:"name" binding:"required,min=1,max=255"`
-			Phone   string `json:"phone" binding:"max=20"`
-			Address string `json:"address" binding:"max=500"`
-			Bio     string `json:"bio" binding:"max=1000"`
-			Cabang  string `json:"cabang" binding:"max=255"`
+			Name     string `json:"name" binding:"required,min=1,max=255"`
+			Phone    string `json:"phone" binding:"max=20"`
+			Address  string `json:"address" binding:"max=500"`
+			Province string `json:"province" binding:"max=100"`
+			City     string `json:"city" binding:"max=100"`
+			Bio      string `json:"bio" binding:"max=1000"`
+			Cabang   string `json:"cabang" binding:"max=255"`
 		}
 
 		var req jsonRequest
@@ -42,6 +46,8 @@ func (r *UpdateProfileRequest) Validate(c *gin.Context) error {
 		r.Name = req.Name
 		r.Phone = req.Phone
 		r.Address = req.Address
+		r.Province = req.Province
+		r.City = req.City
 		r.Bio = req.Bio
 		r.Cabang = req.Cabang
 		r.Avatar = nil // No file in JSON request
@@ -0,0 +1,415 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	requests "github.com/cvudumbarainformatika/backend/app/Http/Requests"
+	models "github.com/cvudumbarainformatika/backend/app/Models"
+	"github.com/cvudumbarainformatika/backend/utils"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/jmoiron/sqlx"
+)
+
+// directoryImportProgressEvery is how many rows are processed between progress updates
+const directoryImportProgressEvery = 50
+
+// DirektoriImportFields are the direktori fields a spreadsheet column can be mapped to, in export column order
+var DirektoriImportFields = []string{"name", "type", "address", "phone", "email", "website", "city", "province", "latitude", "longitude", "has_respirologist", "facilities"}
+
+// DirektoriImportRequiredFields must be mapped to a column for an import to start
+var DirektoriImportRequiredFields = []string{"name", "type", "city", "province"}
+
+// directoryHeaderAliases are the column headers recognized for each field when suggesting a mapping
+var directoryHeaderAliases = map[string][]string{
+	"name":              {"name", "nama", "nama faskes", "nama fasilitas", "nama rs", "nama rumah sakit", "nama instansi"},
+	"type":              {"type", "tipe", "jenis", "jenis faskes", "kategori"},
+	"address":           {"address", "alamat", "alamat lengkap"},
+	"phone":             {"phone", "telepon", "telp", "no telp", "no telepon", "nomor telepon", "hp"},
+	"email":             {"email", "e mail", "surel"},
+	"website":           {"website", "situs", "web", "url"},
+	"city":              {"city", "kota", "kabupaten", "kota kabupaten", "kabupaten kota", "kab kota"},
+	"province":          {"province", "provinsi", "propinsi"},
+	"latitude":          {"latitude", "lat", "lintang"},
+	"longitude":         {"longitude", "lng", "lon", "long", "bujur"},
+	"has_respirologist": {"has respirologist", "respirologist", "dokter paru", "ada dokter paru", "sp p"},
+	"facilities":        {"facilities", "fasilitas", "layanan", "fasilitas layanan"},
+}
+
+var nonAlphanumeric = regexp.MustCompile(`[^a-z0-9]+`)
+
+// normalizeHeader lowercases a column header and collapses punctuation to single spaces
+func normalizeHeader(header string) string {
+	return strings.TrimSpace(nonAlphanumeric.ReplaceAllString(strings.ToLower(header), " "))
+}
+
+// SuggestDirektoriMapping maps direktori fields to the spreadsheet headers that look like them
+// Fields without a recognizable header are left out
+func SuggestDirektoriMapping(headers []string) models.ImportMapping {
+	mapping := models.ImportMapping{}
+	used := map[int]bool{}
+	for _, field := range DirektoriImportFields {
+		for _, alias := range directoryHeaderAliases[field] {
+			found := false
+			for i, header := range headers {
+				if !used[i] && normalizeHeader(header) == alias {
+					mapping[field] = header
+					used[i] = true
+					found = true
+					break
+				}
+			}
+			if found {
+				break
+			}
+		}
+	}
+	return mapping
+}
+
+// ResolveDirektoriMapping returns the column index of every mapped field
+// Unknown fields, headers missing from the spreadsheet and unmapped required fields are errors
+func ResolveDirektoriMapping(mapping models.ImportMapping, headers []string) (map[string]int, error) {
+	known := map[string]bool{}
+	for _, field := range DirektoriImportFields {
+		known[field] = true
+	}
+
+	columns := map[string]int{}
+	for field, header := range mapping {
+		if !known[field] {
+			return nil, fmt.Errorf("unknown direktori field %q", field)
+		}
+		if strings.TrimSpace(header) == "" {
+			continue
+		}
+		index := -1
+		for i, candidate := range headers {
+			if strings.EqualFold(strings.TrimSpace(candidate), strings.TrimSpace(header)) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return nil, fmt.Errorf("column %q of field %s is not in the spreadsheet", header, field)
+		}
+		columns[field] = index
+	}
+
+	for _, field := range DirektoriImportRequiredFields {
+		if _, ok := columns[field]; !ok {
+			return nil, fmt.Errorf("field %s must be mapped to a column", field)
+		}
+	}
+	return columns, nil
+}
+
+// RunDirektoriImport validates the data rows of a spreadsheet and upserts them on name + city
+// Rows with errors are skipped and recorded on the import, a dry run only counts what would be created or updated
+// It is started in its own goroutine after the import record is created, callers poll the record for progress
+func RunDirektoriImport(db *sqlx.DB, directoryImport *models.DirektoriImport, columns map[string]int, rows [][]string) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[RunDirektoriImport] Import #%d panicked: %v", directoryImport.ID, r)
+			_ = directoryImport.Finish(db, fmt.Errorf("import stopped unexpectedly"))
+		}
+	}()
+
+	if err := directoryImport.Start(db); err != nil {
+		log.Printf("[RunDirektoriImport] Cannot start import #%d: %v", directoryImport.ID, err)
+		return
+	}
+
+	// Provinces and cities are stored with their reference names
+	wilayah, err := models.LoadWilayahIndex(db)
+	if err != nil {
+		log.Printf("[RunDirektoriImport] Cannot load reference data for import #%d: %v", directoryImport.ID, err)
+		if err := directoryImport.Finish(db, fmt.Errorf("province and city reference data is not available")); err != nil {
+			log.Printf("[RunDirektoriImport] Cannot finish import #%d: %v", directoryImport.ID, err)
+		}
+		return
+	}
+
+	// Natural keys already seen in this file, a second row with the same key would overwrite the first
+	seen := map[string]int{}
+
+	for i, row := range rows {
+		rowNumber := i + 2 // the header is row 1
+
+		if isEmptyRow(row) {
+			directoryImport.ProcessedRows++
+			continue
+		}
+
+		req, field, err := parseDirektoriRow(row, columns, wilayah)
+		if err != nil {
+			directoryImport.AddError(rowNumber, field, err.Error())
+		} else {
+			key := strings.ToLower(req.Name) + "\x00" + strings.ToLower(req.City)
+			if first, duplicate := seen[key]; duplicate {
+				directoryImport.AddError(rowNumber, "name", fmt.Sprintf("duplicate of row %d with the same name and city", first))
+			} else {
+				seen[key] = rowNumber
+				if err := upsertDirektoriRow(db, directoryImport, req, columns); err != nil {
+					// A database error stops the import instead of failing every remaining row
+					directoryImport.ProcessedRows++
+					log.Printf("[RunDirektoriImport] Import #%d failed at row %d: %v", directoryImport.ID, rowNumber, err)
+					if err := directoryImport.Finish(db, fmt.Errorf("database error at row %d", rowNumber)); err != nil {
+						log.Printf("[RunDirektoriImport] Cannot finish import #%d: %v", directoryImport.ID, err)
+					}
+					return
+				}
+			}
+		}
+
+		directoryImport.ProcessedRows++
+		if directoryImport.ProcessedRows%directoryImportProgressEvery == 0 {
+			if err := directoryImport.SaveProgress(db); err != nil {
+				log.Printf("[RunDirektoriImport] Cannot save progress of import #%d: %v", directoryImport.ID, err)
+			}
+		}
+	}
+
+	if err := directoryImport.Finish(db, nil); err != nil {
+		log.Printf("[RunDirektoriImport] Cannot finish import #%d: %v", directoryImport.ID, err)
+		return
+	}
+	log.Printf("[RunDirektoriImport] Import #%d done: %d created, %d updated, %d errors (dry run: %t)",
+		directoryImport.ID, directoryImport.CreatedCount, directoryImport.UpdatedCount, directoryImport.ErrorCount, directoryImport.DryRun)
+}
+
+// upsertDirektoriRow creates the row or updates the entry with the same name and city
+// An update only overwrites the fields whose column is mapped, so a partial sheet keeps the other values
+func upsertDirektoriRow(db *sqlx.DB, directoryImport *models.DirektoriImport, req requests.DirektoriRequest, columns map[string]int) error {
+	existing, err := models.FindDirektoriByNameCity(db, req.Name, req.City)
+	if err != nil {
+		return err
+	}
+
+	if existing == nil {
+		directoryImport.CreatedCount++
+		if directoryImport.DryRun {
+			return nil
+		}
+		direktori := &models.Direktori{}
+		fillImportedDirektori(direktori, req, nil)
+		return direktori.Create(db)
+	}
+
+	directoryImport.UpdatedCount++
+	if directoryImport.DryRun {
+		return nil
+	}
+	fillImportedDirektori(existing, req, columns)
+	return existing.Update(db)
+}
+
+// fillImportedDirektori copies a validated row into a direktori entry
+// Only fields mapped in columns are copied, a nil columns map copies every field
+func fillImportedDirektori(direktori *models.Direktori, req requests.DirektoriRequest, columns map[string]int) {
+	mapped := func(fields ...string) bool {
+		if columns == nil {
+			return true
+		}
+		for _, field := range fields {
+			if _, ok := columns[field]; ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Required fields are always mapped
+	direktori.Name = req.Name
+	direktori.Type = req.Type
+	direktori.City = req.City
+	direktori.Province = req.Province
+
+	if mapped("address") {
+		direktori.Address = req.Address
+	}
+	if mapped("phone") {
+		direktori.Phone = req.Phone
+	}
+	if mapped("email") {
+		direktori.Email = req.Email
+	}
+	if mapped("website") {
+		direktori.Website = req.Website
+	}
+	// Coordinates are validated as a pair
+	if mapped("latitude", "longitude") {
+		direktori.Latitude = req.Latitude
+		direktori.Longitude = req.Longitude
+	}
+	if mapped("has_respirologist") {
+		direktori.HasRespirologist = req.HasRespirologist
+	}
+	if mapped("facilities") {
+		direktori.Facilities = models.NormalizeFacilities(req.Facilities)
+	}
+}
+
+// parseDirektoriRow converts a spreadsheet row to a validated DirektoriRequest
+// It returns the field of the first invalid cell along with the error, province and city get their reference names
+func parseDirektoriRow(row []string, columns map[string]int, wilayah *models.WilayahIndex) (requests.DirektoriRequest, string, error) {
+	cell := func(field string) string {
+		index, ok := columns[field]
+		if !ok || index >= len(row) {
+			return ""
+		}
+		return utils.UnescapeCSVCell(strings.TrimSpace(row[index]))
+	}
+
+	req := requests.DirektoriRequest{
+		Name:     strings.Join(strings.Fields(cell("name")), " "),
+		Address:  cell("address"),
+		Phone:    cell("phone"),
+		Email:    cell("email"),
+		Website:  cell("website"),
+		City:     strings.Join(strings.Fields(cell("city")), " "),
+		Province: strings.Join(strings.Fields(cell("province")), " "),
+	}
+
+	dirType, ok := parseDirektoriType(cell("type"))
+	if !ok {
+		return req, "type", fmt.Errorf("unknown type %q, use rumah_sakit, klinik or institusi", cell("type"))
+	}
+	req.Type = dirType
+
+	if value := cell("has_respirologist"); value != "" {
+		hasRespirologist, ok := parseImportBool(value)
+		if !ok {
+			return req, "has_respirologist", fmt.Errorf("%q is not yes or no", value)
+		}
+		req.HasRespirologist = hasRespirologist
+	}
+
+	if value := cell("facilities"); value != "" {
+		req.Facilities = strings.FieldsFunc(value, func(r rune) bool {
+			return r == ';' || r == ',' || r == '|' || r == '\n'
+		})
+	}
+
+	for _, field := range []string{"latitude", "longitude"} {
+		value := cell(field)
+		if value == "" {
+			continue
+		}
+		// Spreadsheets in Indonesian locale write decimal commas
+		if !strings.Contains(value, ".") {
+			value = strings.Replace(value, ",", ".", 1)
+		}
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return req, field, fmt.Errorf("%q is not a decimal degree", cell(field))
+		}
+		if field == "latitude" {
+			req.Latitude = &parsed
+		} else {
+			req.Longitude = &parsed
+		}
+	}
+	if (req.Latitude == nil) != (req.Longitude == nil) {
+		return req, "latitude", errors.New("latitude and longitude must be set together")
+	}
+
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		var validationErrors validator.ValidationErrors
+		if errors.As(err, &validationErrors) && len(validationErrors) > 0 {
+			fieldErr := validationErrors[0]
+			field := requestFieldName(fieldErr.StructField())
+			return req, field, errors.New(validationMessage(fieldErr))
+		}
+		return req, "", err
+	}
+
+	province, regency, err := wilayah.Resolve(req.Province, req.City)
+	if err != nil {
+		var regionErr *models.RegionError
+		if errors.As(err, &regionErr) {
+			return req, regionErr.Field, regionErr
+		}
+		return req, "province", err
+	}
+	req.Province = province.Name
+	if regency != nil {
+		req.City = regency.Name
+	}
+
+	return req, "", nil
+}
+
+// parseDirektoriType accepts a direktori type or its label, e.g. "Rumah Sakit" or "RS"
+func parseDirektoriType(value string) (string, bool) {
+	switch normalizeHeader(value) {
+	case "rumah sakit", "rs", "hospital":
+		return models.DirektoriTypeRumahSakit, true
+	case "klinik", "clinic":
+		return models.DirektoriTypeKlinik, true
+	case "institusi", "institution", "instansi":
+		return models.DirektoriTypeInstitusi, true
+	}
+	return "", false
+}
+
+// parseImportBool accepts the yes and no spellings commonly found in spreadsheets
+func parseImportBool(value string) (bool, bool) {
+	switch normalizeHeader(value) {
+	case "1", "true", "yes", "y", "ya", "ada":
+		return true, true
+	case "0", "false", "no", "n", "tidak", "tidak ada":
+		return false, true
+	}
+	return false, false
+}
+
+// isEmptyRow reports whether every cell of a row is blank
+func isEmptyRow(row []string) bool {
+	for _, value := range row {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// requestFieldName returns the JSON name of a DirektoriRequest field
+func requestFieldName(structField string) string {
+	field, ok := reflect.TypeOf(requests.DirektoriRequest{}).FieldByName(structField)
+	if !ok {
+		return structField
+	}
+	return strings.Split(field.Tag.Get("json"), ",")[0]
+}
+
+// validationMessage describes a failed validation rule of a cell
+func validationMessage(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "url":
+		return "must be a valid URL"
+	case "oneof":
+		return "must be one of " + fieldErr.Param()
+	case "max":
+		if fieldErr.Kind() == reflect.String || fieldErr.Kind() == reflect.Slice {
+			return "must be at most " + fieldErr.Param() + " long"
+		}
+		return "must be at most " + fieldErr.Param()
+	case "min":
+		if fieldErr.Kind() == reflect.String || fieldErr.Kind() == reflect.Slice {
+			return "must be at least " + fieldErr.Param() + " long"
+		}
+		return "must be at least " + fieldErr.Param()
+	}
+	return "failed the " + fieldErr.Tag() + " check"
+}
@@ -0,0 +1,264 @@
+package jobs
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	models "github.com/cvudumbarainformatika/backend/app/Models"
+)
+
+func TestSuggestDirektoriMapping(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers []string
+		want    models.ImportMapping
+	}{
+		{
+			name:    "no headers",
+			headers: nil,
+			want:    models.ImportMapping{},
+		},
+		{
+			name:    "english headers",
+			headers: []string{"Name", "Type", "City", "Province", "Latitude", "Longitude"},
+			want: models.ImportMapping{
+				"name": "Name", "type": "Type", "city": "City", "province": "Province",
+				"latitude": "Latitude", "longitude": "Longitude",
+			},
+		},
+		{
+			name:    "indonesian headers with punctuation",
+			headers: []string{"No.", "Nama Rumah Sakit", "Jenis", "Alamat Lengkap", "No. Telp", "E-mail", "Kab/Kota", "Propinsi", "Sp.P"},
+			want: models.ImportMapping{
+				"name": "Nama Rumah Sakit", "type": "Jenis", "address": "Alamat Lengkap", "phone": "No. Telp",
+				"email": "E-mail", "city": "Kab/Kota", "province": "Propinsi", "has_respirologist": "Sp.P",
+			},
+		},
+		{
+			name:    "earlier aliases win and a column is mapped once",
+			headers: []string{"Kota", "Kabupaten", "Lat", "Lintang"},
+			want:    models.ImportMapping{"city": "Kota", "latitude": "Lat"},
+		},
+		{
+			name:    "unrecognized headers are left out",
+			headers: []string{"Keterangan", "", "  "},
+			want:    models.ImportMapping{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SuggestDirektoriMapping(tt.headers); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestResolveDirektoriMapping(t *testing.T) {
+	headers := []string{"Nama", " Jenis ", "Kota", "Provinsi", "Telepon"}
+	required := models.ImportMapping{"name": "Nama", "type": "Jenis", "city": "kota", "province": "PROVINSI"}
+
+	withField := func(field, header string) models.ImportMapping {
+		mapping := models.ImportMapping{}
+		for k, v := range required {
+			mapping[k] = v
+		}
+		mapping[field] = header
+		return mapping
+	}
+	withoutField := func(field string) models.ImportMapping {
+		mapping := withField("phone", "")
+		delete(mapping, field)
+		return mapping
+	}
+
+	tests := []struct {
+		name    string
+		mapping models.ImportMapping
+		want    map[string]int
+		wantErr string // Substring of the expected error, empty when the mapping is valid
+	}{
+		{
+			name:    "required fields, headers matched case and space insensitive",
+			mapping: required,
+			want:    map[string]int{"name": 0, "type": 1, "city": 2, "province": 3},
+		},
+		{
+			name:    "optional field",
+			mapping: withField("phone", "Telepon"),
+			want:    map[string]int{"name": 0, "type": 1, "city": 2, "province": 3, "phone": 4},
+		},
+		{
+			name:    "blank header leaves a field unmapped",
+			mapping: withField("phone", "  "),
+			want:    map[string]int{"name": 0, "type": 1, "city": 2, "province": 3},
+		},
+		{
+			name:    "unknown field",
+			mapping: withField("rating", "Telepon"),
+			wantErr: `unknown direktori field "rating"`,
+		},
+		{
+			name:    "header missing from the spreadsheet",
+			mapping: withField("email", "Email"),
+			wantErr: `column "Email" of field email is not in the spreadsheet`,
+		},
+		{
+			name:    "required field not mapped",
+			mapping: withoutField("province"),
+			wantErr: "field province must be mapped to a column",
+		},
+		{
+			name:    "required field mapped to a blank header",
+			mapping: withField("city", ""),
+			wantErr: "field city must be mapped to a column",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			columns, err := ResolveDirektoriMapping(tt.mapping, headers)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(columns, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, columns)
+			}
+		})
+	}
+}
+
+func TestSuggestedMappingResolves(t *testing.T) {
+	headers := []string{"Nama Faskes", "Tipe", "Alamat", "Kota/Kabupaten", "Provinsi", "Fasilitas"}
+
+	columns, err := ResolveDirektoriMapping(SuggestDirektoriMapping(headers), headers)
+	if err != nil {
+		t.Fatalf("suggested mapping does not resolve: %v", err)
+	}
+	want := map[string]int{"name": 0, "type": 1, "address": 2, "city": 3, "province": 4, "facilities": 5}
+	if !reflect.DeepEqual(columns, want) {
+		t.Errorf("expected %v, got %v", want, columns)
+	}
+}
+
+// testWilayahIndex is a small slice of the reference data
+func testWilayahIndex() *models.WilayahIndex {
+	return models.NewWilayahIndex(
+		[]models.Province{{Code: "35", Name: "Jawa Timur", Aliases: models.Aliases{"Jatim"}}},
+		[]models.Regency{
+			{Code: "35.07", ProvinceCode: "35", Name: "Kabupaten Malang", Type: models.RegencyTypeKabupaten},
+			{Code: "35.73", ProvinceCode: "35", Name: "Kota Malang", Type: models.RegencyTypeKota},
+			{Code: "35.78", ProvinceCode: "35", Name: "Kota Surabaya", Type: models.RegencyTypeKota},
+		},
+	)
+}
+
+func TestFillImportedDirektoriKeepsUnmappedFields(t *testing.T) {
+	lat, lng := -7.2575, 112.7521
+	newLat, newLng := -7.2654, 112.7426
+	existing := func() models.Direktori {
+		return models.Direktori{
+			ID:               7,
+			Name:             "RS Sehat",
+			Type:             models.DirektoriTypeRumahSakit,
+			Address:          "Jl. Pahlawan 1",
+			Phone:            "031-123456",
+			Email:            "info@rssehat.id",
+			Website:          "https://rssehat.id",
+			City:             "Kota Surabaya",
+			Province:         "Jawa Timur",
+			Latitude:         &lat,
+			Longitude:        &lng,
+			HasRespirologist: true,
+			Facilities:       models.Facilities{"spirometri", "bronkoskopi"},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		headers []string
+		row     []string
+		want    func(d *models.Direktori) // Applies the expected changes to the existing entry
+	}{
+		{
+			name:    "required columns only",
+			headers: []string{"Nama", "Jenis", "Kota", "Provinsi"},
+			row:     []string{"RS Sehat", "Klinik", "surabaya", "Jatim"},
+			want: func(d *models.Direktori) {
+				d.Type = models.DirektoriTypeKlinik
+			},
+		},
+		{
+			name:    "mapped columns are overwritten, blank mapped cells clear the field",
+			headers: []string{"Nama", "Jenis", "Kota", "Provinsi", "Telepon", "Latitude", "Longitude", "Fasilitas"},
+			row:     []string{"RS Sehat", "RS", "Kota Surabaya", "Jawa Timur", "", "-7,2654", "112,7426", "Spirometri; Rehabilitasi Paru"},
+			want: func(d *models.Direktori) {
+				d.Phone = ""
+				d.Latitude = &newLat
+				d.Longitude = &newLng
+				d.Facilities = models.Facilities{"spirometri", "rehabilitasi paru"}
+			},
+		},
+		{
+			name:    "mapped boolean column",
+			headers: []string{"Nama", "Jenis", "Kota", "Provinsi", "Dokter Paru"},
+			row:     []string{"RS Sehat", "RS", "Kota Surabaya", "Jawa Timur", "tidak"},
+			want: func(d *models.Direktori) {
+				d.HasRespirologist = false
+			},
+		},
+	}
+
+	wilayah := testWilayahIndex()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			columns, err := ResolveDirektoriMapping(SuggestDirektoriMapping(tt.headers), tt.headers)
+			if err != nil {
+				t.Fatalf("ResolveDirektoriMapping: %v", err)
+			}
+			req, field, err := parseDirektoriRow(tt.row, columns, wilayah)
+			if err != nil {
+				t.Fatalf("parseDirektoriRow: %s: %v", field, err)
+			}
+
+			got := existing()
+			fillImportedDirektori(&got, req, columns)
+
+			want := existing()
+			tt.want(&want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("expected %+v, got %+v", want, got)
+			}
+		})
+	}
+}
+
+func TestFillImportedDirektoriCreate(t *testing.T) {
+	req, _, err := parseDirektoriRow([]string{"Klinik Paru", "klinik", "Kab. Malang", "35"},
+		map[string]int{"name": 0, "type": 1, "city": 2, "province": 3}, testWilayahIndex())
+	if err != nil {
+		t.Fatalf("parseDirektoriRow: %v", err)
+	}
+
+	var got models.Direktori
+	fillImportedDirektori(&got, req, nil)
+
+	want := models.Direktori{
+		Name:       "Klinik Paru",
+		Type:       models.DirektoriTypeKlinik,
+		City:       "Kabupaten Malang",
+		Province:   "Jawa Timur",
+		Facilities: models.Facilities{},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+}
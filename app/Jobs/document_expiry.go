@@ -0,0 +1,130 @@
+package jobs
+
+import (
+	"context"
+	"log"
+
+	mail "github.com/cvudumbarainformatika/backend/app/Mail"
+	models "github.com/cvudumbarainformatika/backend/app/Models"
+	"github.com/cvudumbarainformatika/backend/config"
+	"github.com/cvudumbarainformatika/backend/utils"
+	"github.com/jmoiron/sqlx"
+)
+
+// ExpireDocuments marks member documents past valid_until as expired and sends renewal reminders
+// The member is reminded per document, the admins of their cabang get one digest per run.
+// Reminders are claimed in the delivery log before they are sent, so they are never sent twice
+func ExpireDocuments(db *sqlx.DB, cfg *config.Config, mailer *mail.Mailer) JobFunc {
+	return func(ctx context.Context) error {
+		today := models.DocumentToday(utils.LoadTimezone(cfg.App.Timezone))
+
+		expired, err := models.ExpireDocuments(db, today)
+		if err != nil {
+			return err
+		}
+		if expired > 0 {
+			log.Printf("[ExpireDocuments] Marked %d document(s) as expired", expired)
+		}
+
+		maxLead := 0
+		for _, lead := range cfg.Document.ReminderDays {
+			if lead > maxLead {
+				maxLead = lead
+			}
+		}
+		if maxLead == 0 {
+			return nil
+		}
+
+		documents, err := models.GetDocumentsExpiringBy(db, today, today.AddDate(0, 0, maxLead))
+		if err != nil {
+			return err
+		}
+
+		sent, failed := 0, 0
+		byCabang := map[string][]models.ExpiringDocument{}
+		leads := map[int64]int{}
+		for _, document := range documents {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			lead, due := models.DocumentReminderLead(document.DaysLeft, cfg.Document.ReminderDays)
+			if !due {
+				continue
+			}
+			leads[document.ID] = lead
+			if document.UserCabang != nil && *document.UserCabang != "" {
+				byCabang[*document.UserCabang] = append(byCabang[*document.UserCabang], document)
+			}
+
+			member := models.User{ID: document.UserID, Name: document.UserName, Email: document.UserEmail}
+			claimed, err := models.ClaimDocumentReminder(db, document, member, lead)
+			if err != nil {
+				log.Printf("[ExpireDocuments] Cannot claim reminder of document #%d: %v", document.ID, err)
+				continue
+			}
+			if !claimed {
+				continue
+			}
+
+			sendErr := mailer.Send(mail.DocumentExpiryReminderMessage(member, document))
+			if sendErr != nil {
+				log.Printf("[ExpireDocuments] Failed to remind member of document #%d: %v", document.ID, sendErr)
+				failed++
+			} else {
+				sent++
+			}
+			if err := models.FinishDocumentReminder(db, document, member.ID, lead, sendErr); err != nil {
+				log.Printf("[ExpireDocuments] Cannot log reminder of document #%d: %v", document.ID, err)
+			}
+		}
+
+		for cabang, cabangDocuments := range byCabang {
+			admins, err := models.GetCabangAdmins(db, cabang)
+			if err != nil {
+				log.Printf("[ExpireDocuments] Cannot load admins of cabang %s: %v", cabang, err)
+				continue
+			}
+
+			for _, admin := range admins {
+				if ctx.Err() != nil {
+					return nil
+				}
+
+				claimed := []models.ExpiringDocument{}
+				for _, document := range cabangDocuments {
+					ok, err := models.ClaimDocumentReminder(db, document, admin, leads[document.ID])
+					if err != nil {
+						log.Printf("[ExpireDocuments] Cannot claim reminder of document #%d for admin #%d: %v", document.ID, admin.ID, err)
+						continue
+					}
+					if ok {
+						claimed = append(claimed, document)
+					}
+				}
+				if len(claimed) == 0 {
+					continue
+				}
+
+				sendErr := mailer.Send(mail.DocumentExpiryDigestMessage(admin, cabang, claimed))
+				if sendErr != nil {
+					log.Printf("[ExpireDocuments] Failed to send digest of cabang %s to admin #%d: %v", cabang, admin.ID, sendErr)
+					failed++
+				} else {
+					sent++
+				}
+				for _, document := range claimed {
+					if err := models.FinishDocumentReminder(db, document, admin.ID, leads[document.ID], sendErr); err != nil {
+						log.Printf("[ExpireDocuments] Cannot log reminder of document #%d for admin #%d: %v", document.ID, admin.ID, err)
+					}
+				}
+			}
+		}
+
+		if sent > 0 || failed > 0 {
+			log.Printf("[ExpireDocuments] Sent %d reminder(s), %d failed", sent, failed)
+		}
+		return nil
+	}
+}
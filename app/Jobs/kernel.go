@@ -0,0 +1,26 @@
+package jobs
+
+import (
+	"time"
+
+	mail "github.com/cvudumbarainformatika/backend/app/Mail"
+	payment "github.com/cvudumbarainformatika/backend/app/Payment"
+	"github.com/cvudumbarainformatika/backend/config"
+	"github.com/jmoiron/sqlx"
+)
+
+// Schedule registers all background jobs of the application
+func Schedule(s *Scheduler, db *sqlx.DB, cfg *config.Config) {
+	s.Every("purge_trash", time.Duration(cfg.Trash.PurgeIntervalMinutes)*time.Minute, PurgeTrash(db, cfg.Trash.RetentionDays))
+
+	mailer := mail.NewMailer(cfg.Mail)
+
+	gateway := payment.NewGateway(db, cfg, mailer)
+	paymentInterval := time.Duration(cfg.Payment.ExpireIntervalMinutes) * time.Minute
+	s.Every("expire_invoices", paymentInterval, ExpireInvoices(gateway, db))
+	s.Every("issue_pending_invoices", paymentInterval, IssuePendingInvoices(gateway, db))
+
+	s.Every("agenda_reminders", time.Duration(cfg.Reminder.IntervalMinutes)*time.Minute, SendAgendaReminders(db, cfg, mailer))
+
+	s.Every("expire_documents", time.Duration(cfg.Document.ExpiryIntervalMinutes)*time.Minute, ExpireDocuments(db, cfg, mailer))
+}
@@ -0,0 +1,86 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	models "github.com/cvudumbarainformatika/backend/app/Models"
+	payment "github.com/cvudumbarainformatika/backend/app/Payment"
+	"github.com/jmoiron/sqlx"
+)
+
+// paymentBatchSize limits how many invoices or registrations are handled per run
+const paymentBatchSize = 100
+
+// ExpireInvoices expires unpaid invoices past their deadline, releasing the seats to the waitlist
+// Invoices the provider reports as settled meanwhile are left alone (the expiry only applies to pending invoices)
+func ExpireInvoices(gateway *payment.Gateway, db *sqlx.DB) JobFunc {
+	return func(ctx context.Context) error {
+		invoices, err := models.GetLapsedInvoices(db, time.Now(), paymentBatchSize)
+		if err != nil {
+			return err
+		}
+
+		expired := 0
+		for _, invoice := range invoices {
+			if ctx.Err() != nil {
+				return nil
+			}
+			if err := gateway.ExpireInvoice(invoice); err != nil {
+				log.Printf("[ExpireInvoices] Failed to expire invoice %s: %v", invoice.Number, err)
+				continue
+			}
+			expired++
+		}
+
+		if expired > 0 {
+			log.Printf("[ExpireInvoices] Expired %d invoice(s)", expired)
+		}
+		return nil
+	}
+}
+
+// IssuePendingInvoices invoices pending paid registrations that have no open invoice,
+// such as members promoted from the waitlist or members whose last payment failed
+func IssuePendingInvoices(gateway *payment.Gateway, db *sqlx.DB) JobFunc {
+	return func(ctx context.Context) error {
+		if !gateway.Enabled() {
+			return nil
+		}
+
+		registrations, err := models.GetRegistrationsAwaitingInvoice(db, paymentBatchSize)
+		if err != nil {
+			return err
+		}
+
+		issued := 0
+		for _, registration := range registrations {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			agenda, err := models.FindAgendaByID(db, registration.AgendaID)
+			if err != nil || agenda == nil {
+				log.Printf("[IssuePendingInvoices] Cannot load agenda #%d: %v", registration.AgendaID, err)
+				continue
+			}
+			user, err := models.FindByID(db, registration.UserID)
+			if err != nil || user == nil {
+				log.Printf("[IssuePendingInvoices] Cannot load user #%d: %v", registration.UserID, err)
+				continue
+			}
+
+			if _, _, err := gateway.IssueInvoice(ctx, *agenda, registration, *user); err != nil {
+				log.Printf("[IssuePendingInvoices] Failed to invoice registration #%d: %v", registration.ID, err)
+				continue
+			}
+			issued++
+		}
+
+		if issued > 0 {
+			log.Printf("[IssuePendingInvoices] Issued %d invoice(s)", issued)
+		}
+		return nil
+	}
+}
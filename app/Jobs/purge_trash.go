@@ -0,0 +1,56 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	models "github.com/cvudumbarainformatika/backend/app/Models"
+	"github.com/jmoiron/sqlx"
+)
+
+// purgeTrashBatchSize limits how many items are purged per query
+const purgeTrashBatchSize = 100
+
+// PurgeTrash permanently deletes trashed items older than the retention period
+// A retention of 0 days disables purging
+func PurgeTrash(db *sqlx.DB, retentionDays int) JobFunc {
+	return func(ctx context.Context) error {
+		if retentionDays <= 0 {
+			return nil
+		}
+
+		cutoff := time.Now().AddDate(0, 0, -retentionDays)
+		purged := 0
+
+		for {
+			items, err := models.GetExpiredTrashItems(db, cutoff, purgeTrashBatchSize)
+			if err != nil {
+				return err
+			}
+
+			failed := 0
+			for _, item := range items {
+				if ctx.Err() != nil {
+					return nil
+				}
+				if err := models.PurgeTrashItem(db, item.Type, item.ID); err != nil {
+					log.Printf("[PurgeTrash] Failed to purge %s #%d: %v", item.Type, item.ID, err)
+					failed++
+					continue
+				}
+				purged++
+			}
+
+			// Stop when the last batch was partial or nothing could be purged
+			if len(items) < purgeTrashBatchSize || failed == len(items) {
+				break
+			}
+		}
+
+		if purged > 0 {
+			log.Printf("[PurgeTrash] Purged %d item(s) deleted before %s", purged, cutoff.Format(time.RFC3339))
+		}
+		return nil
+	}
+}
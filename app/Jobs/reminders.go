@@ -0,0 +1,86 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	mail "github.com/cvudumbarainformatika/backend/app/Mail"
+	models "github.com/cvudumbarainformatika/backend/app/Models"
+	"github.com/cvudumbarainformatika/backend/config"
+	"github.com/cvudumbarainformatika/backend/utils"
+	"github.com/jmoiron/sqlx"
+)
+
+// reminderBatchSize limits how many registrants of one agenda are reminded per run
+const reminderBatchSize = 200
+
+// SendAgendaReminders emails confirmed registrants of agenda whose reminder is due, attaching the agenda as ICS
+// Every reminder is claimed in the delivery log before it is sent, so restarts and concurrent instances never send it twice
+func SendAgendaReminders(db *sqlx.DB, cfg *config.Config, mailer *mail.Mailer) JobFunc {
+	return func(ctx context.Context) error {
+		now := time.Now()
+		agendas, err := models.GetAgendaAwaitingReminders(db, now)
+		if err != nil {
+			return err
+		}
+
+		sent, failed := 0, 0
+		for _, agenda := range agendas {
+			offset, due := agenda.DueReminderOffset(now)
+			if !due {
+				continue
+			}
+			scheduledFor := agenda.Date.Add(-time.Duration(offset) * time.Minute)
+
+			recipients, err := models.GetReminderRecipients(db, agenda.ID, offset, scheduledFor, reminderBatchSize)
+			if err != nil {
+				log.Printf("[SendAgendaReminders] Cannot load registrants of agenda #%d: %v", agenda.ID, err)
+				continue
+			}
+			if len(recipients) == 0 {
+				continue
+			}
+
+			calendar := utils.ICalendar{
+				Name:     agenda.Title,
+				Timezone: utils.LoadTimezone(cfg.App.Timezone),
+				Events:   []utils.ICalEvent{agenda.ICalEvent(cfg.SEO.SiteURL, utils.ICalUIDHost(cfg.App.URL))},
+			}
+			ics := []byte(calendar.String())
+
+			for _, recipient := range recipients {
+				if ctx.Err() != nil {
+					return nil
+				}
+
+				claimed, err := models.ClaimReminderDelivery(db, agenda.ID, recipient, offset, scheduledFor)
+				if err != nil {
+					log.Printf("[SendAgendaReminders] Cannot claim reminder of registration #%d: %v", recipient.RegistrationID, err)
+					continue
+				}
+				if !claimed {
+					continue
+				}
+
+				user := models.User{ID: recipient.UserID, Name: recipient.UserName, Email: recipient.UserEmail}
+				sendErr := mailer.Send(mail.AgendaReminderMessage(user, agenda, offset, cfg.SEO.SiteURL, ics))
+				if sendErr != nil {
+					log.Printf("[SendAgendaReminders] Failed to remind registration #%d: %v", recipient.RegistrationID, sendErr)
+					failed++
+				} else {
+					sent++
+				}
+
+				if err := models.FinishReminderDelivery(db, recipient.RegistrationID, offset, scheduledFor, sendErr); err != nil {
+					log.Printf("[SendAgendaReminders] Cannot log reminder of registration #%d: %v", recipient.RegistrationID, err)
+				}
+			}
+		}
+
+		if sent > 0 || failed > 0 {
+			log.Printf("[SendAgendaReminders] Sent %d reminder(s), %d failed", sent, failed)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,94 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// JobFunc is a unit of background work run by the scheduler
+type JobFunc func(ctx context.Context) error
+
+// scheduledJob is a job registered to run at a fixed interval
+type scheduledJob struct {
+	name     string
+	interval time.Duration
+	run      JobFunc
+}
+
+// Scheduler runs registered jobs periodically in background goroutines
+type Scheduler struct {
+	jobs   []scheduledJob
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewScheduler creates a new Scheduler instance
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// Every registers a job that runs once at start and then at every interval
+// Jobs with a non-positive interval are skipped
+func (s *Scheduler) Every(name string, interval time.Duration, run JobFunc) {
+	if interval <= 0 {
+		log.Printf("[Scheduler] Job %s disabled (interval %s)", name, interval)
+		return
+	}
+	s.jobs = append(s.jobs, scheduledJob{name: name, interval: interval, run: run})
+}
+
+// Start launches all registered jobs
+func (s *Scheduler) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, job)
+	}
+
+	log.Printf("[Scheduler] Started %d job(s)", len(s.jobs))
+}
+
+// Stop cancels all running jobs and waits for them to finish
+func (s *Scheduler) Stop() {
+	if s.cancel == nil {
+		return
+	}
+	s.cancel()
+	s.wg.Wait()
+	log.Println("[Scheduler] Stopped")
+}
+
+// loop runs a single job until the context is cancelled
+func (s *Scheduler) loop(ctx context.Context, job scheduledJob) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(job.interval)
+	defer ticker.Stop()
+
+	for {
+		s.runJob(ctx, job)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runJob runs a job once, recovering from panics so one failure does not stop the scheduler
+func (s *Scheduler) runJob(ctx context.Context, job scheduledJob) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[Scheduler] Job %s panicked: %v", job.name, r)
+		}
+	}()
+
+	if err := job.run(ctx); err != nil {
+		log.Printf("[Scheduler] Job %s failed: %v", job.name, err)
+	}
+}
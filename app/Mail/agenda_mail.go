@@ -0,0 +1,185 @@
+package mail
+
+import (
+	"fmt"
+	"html"
+
+	models "github.com/cvudumbarainformatika/backend/app/Models"
+	"github.com/cvudumbarainformatika/backend/utils"
+)
+
+// agendaTimeLayout is the date format used in agenda emails
+const agendaTimeLayout = "Monday, 02 January 2006 15:04 MST"
+
+// agendaURL returns the public page of an agenda
+func agendaURL(siteURL string, agenda models.Agenda) string {
+	return siteURL + "/agenda/" + agenda.Slug
+}
+
+// WaitlistPromotedMessage notifies a member that a seat became available for an agenda
+func WaitlistPromotedMessage(user models.User, agenda models.Agenda, siteURL string) Message {
+	url := agendaURL(siteURL, agenda)
+	when := agenda.Date.Format(agendaTimeLayout)
+
+	text := fmt.Sprintf(
+		"Hello %s,\n\nA seat has become available for \"%s\" (%s) and you have been moved off the waitlist.\n"+
+			"Your registration is now pending confirmation.\n\nDetails: %s\n",
+		user.Name, agenda.Title, when, url,
+	)
+
+	body := fmt.Sprintf(
+		"<p>Hello %s,</p><p>A seat has become available for <strong>%s</strong> (%s) and you have been moved off the waitlist.<br>"+
+			"Your registration is now pending confirmation.</p><p><a href=\"%s\">View agenda</a></p>",
+		html.EscapeString(user.Name), html.EscapeString(agenda.Title), html.EscapeString(when), html.EscapeString(url),
+	)
+
+	return Message{
+		To:      []string{user.Email},
+		Subject: "A seat is available: " + agenda.Title,
+		Text:    text,
+		HTML:    body,
+	}
+}
+
+// RegistrationConfirmedMessage sends a member their QR ticket once their registration is confirmed
+func RegistrationConfirmedMessage(user models.User, agenda models.Agenda, siteURL string, ticket string, qrPNG []byte) Message {
+	url := agendaURL(siteURL, agenda)
+	when := agenda.Date.Format(agendaTimeLayout)
+
+	text := fmt.Sprintf(
+		"Hello %s,\n\nYour registration for \"%s\" (%s) has been confirmed.\n"+
+			"Show the attached QR code at the registration desk to check in.\n\nTicket: %s\n\nDetails: %s\n",
+		user.Name, agenda.Title, when, ticket, url,
+	)
+
+	body := fmt.Sprintf(
+		"<p>Hello %s,</p><p>Your registration for <strong>%s</strong> (%s) has been confirmed.<br>"+
+			"Show the attached QR code at the registration desk to check in.</p><p><a href=\"%s\">View agenda</a></p>",
+		html.EscapeString(user.Name), html.EscapeString(agenda.Title), html.EscapeString(when), html.EscapeString(url),
+	)
+
+	message := Message{
+		To:      []string{user.Email},
+		Subject: "Registration confirmed: " + agenda.Title,
+		Text:    text,
+		HTML:    body,
+	}
+	if len(qrPNG) > 0 {
+		message.Attachments = []Attachment{{Filename: "ticket.png", ContentType: "image/png", Data: qrPNG}}
+	}
+	return message
+}
+
+// InvoiceIssuedMessage asks a member to pay for their registration before the invoice expires
+func InvoiceIssuedMessage(user models.User, agenda models.Agenda, invoice models.Invoice, siteURL string) Message {
+	url := agendaURL(siteURL, agenda)
+	when := agenda.Date.Format(agendaTimeLayout)
+	deadline := invoice.ExpiresAt.Format(agendaTimeLayout)
+	amount := utils.FormatRupiah(invoice.Amount)
+
+	payURL := url
+	if invoice.PaymentURL != nil {
+		payURL = *invoice.PaymentURL
+	}
+
+	text := fmt.Sprintf(
+		"Hello %s,\n\nYour seat for \"%s\" (%s) is reserved until %s.\n"+
+			"Please pay %s (invoice %s) before then to confirm your registration, unpaid seats are released.\n\nPay: %s\n\nDetails: %s\n",
+		user.Name, agenda.Title, when, deadline, amount, invoice.Number, payURL, url,
+	)
+
+	body := fmt.Sprintf(
+		"<p>Hello %s,</p><p>Your seat for <strong>%s</strong> (%s) is reserved until %s.<br>"+
+			"Please pay <strong>%s</strong> (invoice %s) before then to confirm your registration, unpaid seats are released.</p>"+
+			"<p><a href=\"%s\">Pay now</a> &middot; <a href=\"%s\">View agenda</a></p>",
+		html.EscapeString(user.Name), html.EscapeString(agenda.Title), html.EscapeString(when), html.EscapeString(deadline),
+		html.EscapeString(amount), html.EscapeString(invoice.Number), html.EscapeString(payURL), html.EscapeString(url),
+	)
+
+	return Message{
+		To:      []string{user.Email},
+		Subject: "Payment required: " + agenda.Title,
+		Text:    text,
+		HTML:    body,
+	}
+}
+
+// InvoiceExpiredMessage tells a member their unpaid registration was cancelled
+func InvoiceExpiredMessage(user models.User, agenda models.Agenda, invoice models.Invoice, siteURL string) Message {
+	url := agendaURL(siteURL, agenda)
+
+	text := fmt.Sprintf(
+		"Hello %s,\n\nInvoice %s for \"%s\" was not paid in time, so your registration has been cancelled and the seat released.\n"+
+			"You can register again while seats are available.\n\nDetails: %s\n",
+		user.Name, invoice.Number, agenda.Title, url,
+	)
+
+	body := fmt.Sprintf(
+		"<p>Hello %s,</p><p>Invoice %s for <strong>%s</strong> was not paid in time, so your registration has been cancelled and the seat released.<br>"+
+			"You can register again while seats are available.</p><p><a href=\"%s\">View agenda</a></p>",
+		html.EscapeString(user.Name), html.EscapeString(invoice.Number), html.EscapeString(agenda.Title), html.EscapeString(url),
+	)
+
+	return Message{
+		To:      []string{user.Email},
+		Subject: "Registration cancelled: " + agenda.Title,
+		Text:    text,
+		HTML:    body,
+	}
+}
+
+// AgendaReminderMessage reminds a confirmed registrant that an agenda starts soon, with the agenda as ICS attachment
+// Online agenda show their location as the link to join
+func AgendaReminderMessage(user models.User, agenda models.Agenda, offset int, siteURL string, ics []byte) Message {
+	url := agendaURL(siteURL, agenda)
+	when := agenda.Date.Format(agendaTimeLayout)
+	lead := reminderLeadTime(offset)
+
+	where := "Location: " + agenda.Location
+	if agenda.IsOnline {
+		where = "Join online: " + agenda.Location
+	}
+
+	text := fmt.Sprintf(
+		"Hello %s,\n\nThis is a reminder that \"%s\" starts in %s (%s).\n%s\n\n"+
+			"The attached calendar file adds the agenda to your calendar.\n\nDetails: %s\n",
+		user.Name, agenda.Title, lead, when, where, url,
+	)
+
+	whereHTML := "Location: " + html.EscapeString(agenda.Location)
+	if agenda.IsOnline {
+		whereHTML = fmt.Sprintf("Join online: <a href=\"%s\">%s</a>", html.EscapeString(agenda.Location), html.EscapeString(agenda.Location))
+	}
+
+	body := fmt.Sprintf(
+		"<p>Hello %s,</p><p>This is a reminder that <strong>%s</strong> starts in %s (%s).<br>%s</p>"+
+			"<p>The attached calendar file adds the agenda to your calendar.</p><p><a href=\"%s\">View agenda</a></p>",
+		html.EscapeString(user.Name), html.EscapeString(agenda.Title), html.EscapeString(lead), html.EscapeString(when), whereHTML, html.EscapeString(url),
+	)
+
+	message := Message{
+		To:      []string{user.Email},
+		Subject: "Reminder: " + agenda.Title + " starts in " + lead,
+		Text:    text,
+		HTML:    body,
+	}
+	if len(ics) > 0 {
+		message.Attachments = []Attachment{{Filename: agenda.Slug + ".ics", ContentType: "text/calendar; charset=utf-8; method=PUBLISH", Data: ics}}
+	}
+	return message
+}
+
+// reminderLeadTime formats a reminder offset in minutes, e.g. "1 day" or "2 hours"
+func reminderLeadTime(offset int) string {
+	value, unit := offset, "minute"
+	switch {
+	case offset%(24*60) == 0:
+		value, unit = offset/(24*60), "day"
+	case offset%60 == 0:
+		value, unit = offset/60, "hour"
+	}
+	if value != 1 {
+		unit += "s"
+	}
+	return fmt.Sprintf("%d %s", value, unit)
+}
@@ -0,0 +1,80 @@
+package mail
+
+import (
+	"fmt"
+	"html"
+	"strings"
+
+	models "github.com/cvudumbarainformatika/backend/app/Models"
+)
+
+// documentDateLayout is the date format used in document emails
+const documentDateLayout = "02 January 2006"
+
+// documentExpiresIn formats the days until a document expires, e.g. "in 30 days" or "today"
+func documentExpiresIn(daysLeft int) string {
+	switch daysLeft {
+	case 0:
+		return "today"
+	case 1:
+		return "tomorrow"
+	}
+	return fmt.Sprintf("in %d days", daysLeft)
+}
+
+// DocumentExpiryReminderMessage reminds a member to renew a document before it expires
+func DocumentExpiryReminderMessage(user models.User, document models.ExpiringDocument) Message {
+	when := document.ValidUntil.Format(documentDateLayout)
+	expiresIn := documentExpiresIn(document.DaysLeft)
+
+	text := fmt.Sprintf(
+		"Hello %s,\n\nYour %s \"%s\" expires %s (%s).\n"+
+			"Please renew it and upload the new document in your member area so it can be verified in time.\n",
+		user.Name, document.Type, document.Name, expiresIn, when,
+	)
+
+	body := fmt.Sprintf(
+		"<p>Hello %s,</p><p>Your %s <strong>%s</strong> expires %s (%s).<br>"+
+			"Please renew it and upload the new document in your member area so it can be verified in time.</p>",
+		html.EscapeString(user.Name), html.EscapeString(document.Type), html.EscapeString(document.Name), expiresIn, html.EscapeString(when),
+	)
+
+	return Message{
+		To:      []string{user.Email},
+		Subject: fmt.Sprintf("Your %s expires %s", document.Type, expiresIn),
+		Text:    text,
+		HTML:    body,
+	}
+}
+
+// DocumentExpiryDigestMessage lists documents of a cabang's members that expire soon for one of its admins
+func DocumentExpiryDigestMessage(admin models.User, cabang string, documents []models.ExpiringDocument) Message {
+	var lines, rows strings.Builder
+	for _, document := range documents {
+		when := document.ValidUntil.Format(documentDateLayout)
+		fmt.Fprintf(&lines, "- %s (%s): %s %s, expires %s (%s)\n",
+			document.UserName, document.UserEmail, document.Type, document.Name, documentExpiresIn(document.DaysLeft), when)
+		fmt.Fprintf(&rows, "<li>%s (%s): %s <strong>%s</strong>, expires %s (%s)</li>",
+			html.EscapeString(document.UserName), html.EscapeString(document.UserEmail), html.EscapeString(document.Type),
+			html.EscapeString(document.Name), documentExpiresIn(document.DaysLeft), html.EscapeString(when))
+	}
+
+	text := fmt.Sprintf(
+		"Hello %s,\n\nThe following documents of members of %s expire soon:\n\n%s\n"+
+			"The members have been reminded to upload renewed documents.\n",
+		admin.Name, cabang, lines.String(),
+	)
+
+	body := fmt.Sprintf(
+		"<p>Hello %s,</p><p>The following documents of members of <strong>%s</strong> expire soon:</p><ul>%s</ul>"+
+			"<p>The members have been reminded to upload renewed documents.</p>",
+		html.EscapeString(admin.Name), html.EscapeString(cabang), rows.String(),
+	)
+
+	return Message{
+		To:      []string{admin.Email},
+		Subject: fmt.Sprintf("%d member document(s) of %s expire soon", len(documents), cabang),
+		Text:    text,
+		HTML:    body,
+	}
+}
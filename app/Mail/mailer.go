@@ -0,0 +1,229 @@
+package mail
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cvudumbarainformatika/backend/config"
+)
+
+// Attachment is a file attached to an email
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Message is an email message with a plain text and an optional HTML body
+type Message struct {
+	To          []string
+	Subject     string
+	Text        string
+	HTML        string
+	Attachments []Attachment
+}
+
+// Mailer sends emails over SMTP
+type Mailer struct {
+	config config.MailConfig
+}
+
+// NewMailer creates a new Mailer instance
+func NewMailer(cfg config.MailConfig) *Mailer {
+	return &Mailer{
+		config: cfg,
+	}
+}
+
+// Enabled reports whether an SMTP host is configured
+func (m *Mailer) Enabled() bool {
+	return m.config.Host != ""
+}
+
+// Send sends a message, or only logs it when no SMTP host is configured
+func (m *Mailer) Send(msg Message) error {
+	if len(msg.To) == 0 {
+		return fmt.Errorf("email has no recipients")
+	}
+
+	if !m.Enabled() {
+		log.Printf("[Mail] MAIL_HOST not set, not sending %q to %s", msg.Subject, strings.Join(msg.To, ", "))
+		return nil
+	}
+
+	body, err := m.build(msg)
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(m.config.Host, strconv.Itoa(m.config.Port))
+
+	var auth smtp.Auth
+	if m.config.Username != "" {
+		auth = smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+	}
+
+	if m.config.Encryption == "ssl" {
+		return m.sendImplicitTLS(addr, auth, msg.To, body)
+	}
+
+	// smtp.SendMail upgrades the connection with STARTTLS when the server supports it
+	return smtp.SendMail(addr, auth, m.config.FromAddress, msg.To, body)
+}
+
+// SendAsync sends a message in the background, logging failures
+func (m *Mailer) SendAsync(msg Message) {
+	go func() {
+		if err := m.Send(msg); err != nil {
+			log.Printf("[Mail] Failed to send %q to %s: %v", msg.Subject, strings.Join(msg.To, ", "), err)
+		}
+	}()
+}
+
+// sendImplicitTLS sends a message over a TLS connection (usually port 465)
+func (m *Mailer) sendImplicitTLS(addr string, auth smtp.Auth, to []string, body []byte) error {
+	conn, err := tls.Dial("tcp", addr, &tls.Config{ServerName: m.config.Host})
+	if err != nil {
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, m.config.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(m.config.FromAddress); err != nil {
+		return err
+	}
+	for _, recipient := range to {
+		if err := client.Rcpt(recipient); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	return client.Quit()
+}
+
+// build renders a message as a MIME document
+func (m *Mailer) build(msg Message) ([]byte, error) {
+	var buf bytes.Buffer
+
+	from := m.config.FromAddress
+	if m.config.FromName != "" {
+		from = mime.QEncoding.Encode("utf-8", m.config.FromName) + " <" + m.config.FromAddress + ">"
+	}
+
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(msg.To, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+
+	mixed := multipart.NewWriter(&buf)
+	fmt.Fprintf(&buf, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mixed.Boundary())
+
+	// Text and HTML alternatives
+	var alternative bytes.Buffer
+	alt := multipart.NewWriter(&alternative)
+	if err := writeTextPart(alt, "text/plain; charset=utf-8", msg.Text); err != nil {
+		return nil, err
+	}
+	if msg.HTML != "" {
+		if err := writeTextPart(alt, "text/html; charset=utf-8", msg.HTML); err != nil {
+			return nil, err
+		}
+	}
+	if err := alt.Close(); err != nil {
+		return nil, err
+	}
+
+	part, err := mixed.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"multipart/alternative; boundary=" + alt.Boundary()},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(alternative.Bytes()); err != nil {
+		return nil, err
+	}
+
+	// Attachments
+	for _, attachment := range msg.Attachments {
+		contentType := attachment.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		part, err := mixed.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType + "; name=\"" + attachment.Filename + "\""},
+			"Content-Disposition":       {"attachment; filename=\"" + attachment.Filename + "\""},
+			"Content-Transfer-Encoding": {"base64"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeBase64(part, attachment.Data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := mixed.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeTextPart writes a base64 encoded text part
+func writeTextPart(w *multipart.Writer, contentType string, content string) error {
+	part, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return err
+	}
+	return writeBase64(part, []byte(content))
+}
+
+// writeBase64 writes data base64 encoded in 76 character lines
+func writeBase64(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		if _, err := w.Write([]byte(encoded[:76] + "\r\n")); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+	_, err := w.Write([]byte(encoded + "\r\n"))
+	return err
+}
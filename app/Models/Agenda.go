@@ -2,32 +2,108 @@ package models
 
 import (
 	"database/sql"
+	"fmt"
+	"strconv"
 	"time"
 
+	"github.com/cvudumbarainformatika/backend/utils"
 	"github.com/jmoiron/sqlx"
 )
 
+// DefaultAgendaDuration is used as the event length when an agenda has no end date
+const DefaultAgendaDuration = 2 * time.Hour
+
 // Agenda represents an event/agenda
 type Agenda struct {
-	ID              int64      `db:"id" json:"id"`
-	Slug            string     `db:"slug" json:"slug"`
-	Title           string     `db:"title" json:"title"`
-	Description     string     `db:"description" json:"description"`
-	Type            string     `db:"type" json:"type"`
-	Date            time.Time  `db:"date" json:"date"`
-	EndDate         *time.Time `db:"end_date" json:"end_date"`
-	IsOnline        bool       `db:"is_online" json:"is_online"`
-	Location        string     `db:"location" json:"location"`
-	SKP             float64    `db:"skp" json:"skp"`
-	Quota           int        `db:"quota" json:"quota"`
-	RegistrationURL string     `db:"registration_url" json:"registration_url"`
-	ImageURL        string     `db:"image_url" json:"image_url"`
-	Fee             string     `db:"fee" json:"fee"`
-	Status          string     `db:"status" json:"status"`
-	PublishedAt     *time.Time `db:"published_at" json:"published_at"`
-	CreatedAt       time.Time  `db:"created_at" json:"created_at"`
-	UpdatedAt       time.Time  `db:"updated_at" json:"updated_at"`
-	DeletedAt       *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+	ID               int64            `db:"id" json:"id"`
+	Slug             string           `db:"slug" json:"slug"`
+	Title            string           `db:"title" json:"title"`
+	Description      string           `db:"description" json:"description"`
+	Type             string           `db:"type" json:"type"`
+	Date             time.Time        `db:"date" json:"date"`
+	EndDate          *time.Time       `db:"end_date" json:"end_date"`
+	IsOnline         bool             `db:"is_online" json:"is_online"`
+	Location         string           `db:"location" json:"location"`
+	Province         *string          `db:"province" json:"province"` // Province of offline agenda
+	SKP              float64          `db:"skp" json:"skp"`
+	Quota            int              `db:"quota" json:"quota"`
+	RegistrationURL  string           `db:"registration_url" json:"registration_url"`
+	RegistrationForm RegistrationForm `db:"registration_form" json:"registration_form"` // Custom questions asked on registration
+	ImageURL         string           `db:"image_url" json:"image_url"`
+	Fee              string           `db:"fee" json:"fee"`                           // Free display text, e.g. "Rp 50.000 (anggota)"
+	Price            int64            `db:"price" json:"price"`                       // Registration price in IDR, 0 = free
+	ReminderOffsets  ReminderOffsets  `db:"reminder_offsets" json:"reminder_offsets"` // Minutes before start registrants are reminded, nil = defaults
+	Status           string           `db:"status" json:"status"`
+	Cabang           *string          `db:"cabang" json:"cabang"`               // Owning organization unit (nil = pusat)
+	SeriesID         *int64           `db:"series_id" json:"series_id"`         // Recurring series this agenda is an occurrence of
+	RecurrenceID     *time.Time       `db:"recurrence_id" json:"recurrence_id"` // Start the series rule generated for this occurrence
+	IsException      bool             `db:"is_exception" json:"is_exception"`   // Occurrence edited on its own, series edits skip it
+	CancelledAt      *time.Time       `db:"cancelled_at" json:"cancelled_at"`
+	Sequence         int              `db:"sequence" json:"sequence"` // iCalendar SEQUENCE, bumped on every update
+	PublishedAt      *time.Time       `db:"published_at" json:"published_at"`
+	CreatedAt        time.Time        `db:"created_at" json:"created_at"`
+	UpdatedAt        time.Time        `db:"updated_at" json:"updated_at"`
+	DeletedAt        *time.Time       `db:"deleted_at" json:"deleted_at,omitempty"`
+	SeatsTaken       int              `db:"seats_taken" json:"seats_taken"`       // Pending and confirmed registrations
+	WaitlistCount    int              `db:"waitlist_count" json:"waitlist_count"` // Waitlisted registrations
+}
+
+// agendaSeatColumns computes seat usage of each selected agenda row
+const agendaSeatColumns = `,
+	(SELECT COUNT(*) FROM agenda_registrations r WHERE r.agenda_id = agenda.id AND r.status IN ('pending', 'confirmed')) AS seats_taken,
+	(SELECT COUNT(*) FROM agenda_registrations r WHERE r.agenda_id = agenda.id AND r.status = 'waitlisted') AS waitlist_count`
+
+// ICalEvent converts the agenda to an iCalendar event linking to its page on siteURL
+// The UID only depends on the agenda ID so renames do not duplicate events in subscribed calendars
+func (a *Agenda) ICalEvent(siteURL string, uidHost string) utils.ICalEvent {
+	end := a.Date.Add(DefaultAgendaDuration)
+	if a.EndDate != nil && a.EndDate.After(a.Date) {
+		end = *a.EndDate
+	}
+
+	pageURL := siteURL + "/agenda/" + a.Slug
+
+	location := a.Location
+	if a.IsOnline && location == "" {
+		location = "Online"
+	}
+
+	description := utils.HTMLToText(a.Description)
+	if description != "" {
+		description += "\n\n"
+	}
+	description += pageURL
+
+	status := "CONFIRMED"
+	if a.CancelledAt != nil {
+		status = "CANCELLED"
+	}
+
+	return utils.ICalEvent{
+		UID:          fmt.Sprintf("agenda-%d@%s", a.ID, uidHost),
+		Sequence:     a.Sequence,
+		Summary:      a.Title,
+		Description:  description,
+		Location:     location,
+		URL:          pageURL,
+		Start:        a.Date,
+		End:          end,
+		Status:       status,
+		Created:      a.CreatedAt,
+		LastModified: a.UpdatedAt,
+	}
+}
+
+// RemainingSeats returns the number of free seats, or nil when the quota is unlimited
+func (a *Agenda) RemainingSeats() *int {
+	if a.Quota <= 0 {
+		return nil
+	}
+	remaining := a.Quota - a.SeatsTaken
+	if remaining < 0 {
+		remaining = 0
+	}
+	return &remaining
 }
 
 // Create creates a new agenda record
@@ -39,11 +115,16 @@ func (a *Agenda) Create(db *sqlx.DB) error {
 		a.PublishedAt = &now
 	}
 
+	return insertAgenda(db, a)
+}
+
+// insertAgenda inserts an agenda row (also used for series occurrences inside a transaction)
+func insertAgenda(db sqlx.Execer, a *Agenda) error {
 	query := `
-		INSERT INTO agenda (slug, title, description, type, date, end_date, is_online, location, skp, quota, registration_url, image_url, fee, status, published_at, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO agenda (slug, title, description, type, date, end_date, is_online, location, province, skp, quota, registration_url, registration_form, image_url, fee, price, reminder_offsets, status, cabang, series_id, recurrence_id, is_exception, published_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
-	result, err := db.Exec(query, a.Slug, a.Title, a.Description, a.Type, a.Date, a.EndDate, a.IsOnline, a.Location, a.SKP, a.Quota, a.RegistrationURL, a.ImageURL, a.Fee, a.Status, a.PublishedAt, a.CreatedAt, a.UpdatedAt)
+	result, err := db.Exec(query, a.Slug, a.Title, a.Description, a.Type, a.Date, a.EndDate, a.IsOnline, a.Location, a.Province, a.SKP, a.Quota, a.RegistrationURL, a.RegistrationForm, a.ImageURL, a.Fee, a.Price, a.ReminderOffsets, a.Status, a.Cabang, a.SeriesID, a.RecurrenceID, a.IsException, a.PublishedAt, a.CreatedAt, a.UpdatedAt)
 	if err != nil {
 		return err
 	}
@@ -60,7 +141,7 @@ func (a *Agenda) Create(db *sqlx.DB) error {
 func FindAgendaBySlug(db *sqlx.DB, slug string) (*Agenda, error) {
 	agenda := &Agenda{}
 	query := `
-		SELECT id, slug, title, description, type, date, end_date, is_online, location, skp, quota, registration_url, image_url, fee, status, published_at, created_at, updated_at, deleted_at 
+		SELECT id, slug, title, description, type, date, end_date, is_online, location, province, skp, quota, registration_url, registration_form, image_url, fee, price, reminder_offsets, status, cabang, series_id, recurrence_id, is_exception, cancelled_at, sequence, published_at, created_at, updated_at, deleted_at` + agendaSeatColumns + `
 		FROM agenda 
 		WHERE slug = ? AND deleted_at IS NULL
 	`
@@ -78,7 +159,7 @@ func FindAgendaBySlug(db *sqlx.DB, slug string) (*Agenda, error) {
 func FindAgendaByID(db *sqlx.DB, id int64) (*Agenda, error) {
 	agenda := &Agenda{}
 	query := `
-		SELECT id, slug, title, description, type, date, end_date, is_online, location, skp, quota, registration_url, image_url, fee, status, published_at, created_at, updated_at, deleted_at 
+		SELECT id, slug, title, description, type, date, end_date, is_online, location, province, skp, quota, registration_url, registration_form, image_url, fee, price, reminder_offsets, status, cabang, series_id, recurrence_id, is_exception, cancelled_at, sequence, published_at, created_at, updated_at, deleted_at` + agendaSeatColumns + `
 		FROM agenda 
 		WHERE id = ? AND deleted_at IS NULL
 	`
@@ -92,12 +173,26 @@ func FindAgendaByID(db *sqlx.DB, id int64) (*Agenda, error) {
 	return agenda, nil
 }
 
+// FindAgendaBySlugOrID finds an agenda by its numeric ID when value is all digits, by slug otherwise
+// Agenda slugs are never all digits, so both lookups are unambiguous
+func FindAgendaBySlugOrID(db *sqlx.DB, value string) (*Agenda, error) {
+	if !utils.IsNumericSlug(value) {
+		return FindAgendaBySlug(db, value)
+	}
+
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return nil, nil
+	}
+	return FindAgendaByID(db, id)
+}
+
 // GetAllAgenda retrieves all agenda with filters and pagination
 func GetAllAgenda(db *sqlx.DB, filters map[string]interface{}, offset int, limit int) ([]Agenda, int64, error) {
 	var agendas []Agenda
 
 	// Base Query
-	query := `SELECT id, slug, title, description, type, date, end_date, is_online, location, skp, quota, registration_url, image_url, fee, status, published_at, created_at, updated_at, deleted_at FROM agenda WHERE deleted_at IS NULL`
+	query := `SELECT id, slug, title, description, type, date, end_date, is_online, location, province, skp, quota, registration_url, registration_form, image_url, fee, price, reminder_offsets, status, cabang, series_id, recurrence_id, is_exception, cancelled_at, sequence, published_at, created_at, updated_at, deleted_at` + agendaSeatColumns + ` FROM agenda WHERE deleted_at IS NULL`
 	countQuery := `SELECT COUNT(*) FROM agenda WHERE deleted_at IS NULL`
 
 	args := []interface{}{}
@@ -112,10 +207,33 @@ func GetAllAgenda(db *sqlx.DB, filters map[string]interface{}, offset int, limit
 		countQuery += ` AND status = ?`
 		args = append(args, status)
 	}
+	if seriesID, ok := filters["series_id"].(int64); ok && seriesID > 0 {
+		query += ` AND series_id = ?`
+		countQuery += ` AND series_id = ?`
+		args = append(args, seriesID)
+	}
 	if upcoming, ok := filters["upcoming"].(bool); ok && upcoming {
-		// Filter for upcoming events (date >= now)
-		query += ` AND date >= NOW()`
-		countQuery += ` AND date >= NOW()`
+		// Filter for upcoming events (date >= now), cancelled occurrences are skipped
+		// and a recurring series is listed once, as its next occurrence
+		nextOccurrence := `SELECT n.id FROM agenda n WHERE n.series_id = agenda.series_id AND n.deleted_at IS NULL AND n.cancelled_at IS NULL AND n.date >= NOW()`
+		nextArgs := []interface{}{}
+		if status, ok := filters["status"].(string); ok && status != "" {
+			nextOccurrence += ` AND n.status = ?`
+			nextArgs = append(nextArgs, status)
+		}
+		if typeVal, ok := filters["type"].(string); ok && typeVal != "" {
+			nextOccurrence += ` AND n.type = ?`
+			nextArgs = append(nextArgs, typeVal)
+		}
+		nextOccurrence += ` ORDER BY n.date ASC, n.id ASC LIMIT 1`
+
+		upcomingWhere := ` AND date >= NOW() AND cancelled_at IS NULL`
+		if _, listSeries := filters["series_id"].(int64); !listSeries {
+			upcomingWhere += ` AND (series_id IS NULL OR id = (` + nextOccurrence + `))`
+			args = append(args, nextArgs...)
+		}
+		query += upcomingWhere
+		countQuery += upcomingWhere
 	}
 
 	// Get total count
@@ -149,11 +267,16 @@ func (a *Agenda) Update(db *sqlx.DB) error {
 	a.UpdatedAt = time.Now()
 	query := `
 		UPDATE agenda 
-		SET slug = ?, title = ?, description = ?, type = ?, date = ?, end_date = ?, is_online = ?, location = ?, skp = ?, quota = ?, registration_url = ?, image_url = ?, fee = ?, status = ?, published_at = ?, updated_at = ?
+		SET slug = ?, title = ?, description = ?, type = ?, date = ?, end_date = ?, is_online = ?, location = ?, province = ?, skp = ?, quota = ?, registration_url = ?, registration_form = ?, image_url = ?, fee = ?, price = ?, reminder_offsets = ?, status = ?, published_at = ?,
+			is_exception = ?, sequence = sequence + 1, updated_at = ?
 		WHERE id = ? AND deleted_at IS NULL
 	`
-	_, err := db.Exec(query, a.Slug, a.Title, a.Description, a.Type, a.Date, a.EndDate, a.IsOnline, a.Location, a.SKP, a.Quota, a.RegistrationURL, a.ImageURL, a.Fee, a.Status, a.PublishedAt, a.UpdatedAt, a.ID)
-	return err
+	_, err := db.Exec(query, a.Slug, a.Title, a.Description, a.Type, a.Date, a.EndDate, a.IsOnline, a.Location, a.Province, a.SKP, a.Quota, a.RegistrationURL, a.RegistrationForm, a.ImageURL, a.Fee, a.Price, a.ReminderOffsets, a.Status, a.PublishedAt, a.IsException, a.UpdatedAt, a.ID)
+	if err != nil {
+		return err
+	}
+	a.Sequence++
+	return nil
 }
 
 // Delete soft deletes an agenda record
@@ -168,11 +291,15 @@ func (a *Agenda) Delete(db *sqlx.DB) error {
 
 // AgendaRegistration represents a user registration for an agenda
 type AgendaRegistration struct {
-	ID           int64     `db:"id" json:"id"`
-	AgendaID     int64     `db:"agenda_id" json:"agenda_id"`
-	UserID       int64     `db:"user_id" json:"user_id"`
-	Status       string    `db:"status" json:"status"`
-	RegisteredAt time.Time `db:"registered_at" json:"registered_at"`
+	ID           int64               `db:"id" json:"id"`
+	AgendaID     int64               `db:"agenda_id" json:"agenda_id"`
+	UserID       int64               `db:"user_id" json:"user_id"`
+	Status       string              `db:"status" json:"status"`
+	RegisteredAt time.Time           `db:"registered_at" json:"registered_at"`
+	UpdatedAt    *time.Time          `db:"updated_at" json:"updated_at"`
+	CheckedInAt  *time.Time          `db:"checked_in_at" json:"checked_in_at"`
+	CheckedInBy  *int64              `db:"checked_in_by" json:"checked_in_by"` // Committee member who scanned the ticket
+	Answers      RegistrationAnswers `db:"answers" json:"answers"`             // Answers to the agenda's registration form
 }
 
 // Create creates a new agenda registration
@@ -195,11 +322,104 @@ func (ar *AgendaRegistration) Create(db *sqlx.DB) error {
 // GetRegistrations retrieves all registrations for an agenda
 func GetAgendaRegistrations(db *sqlx.DB, agendaID int64) ([]AgendaRegistration, error) {
 	var registrations []AgendaRegistration
-	query := `SELECT id, agenda_id, user_id, status, registered_at FROM agenda_registrations WHERE agenda_id = ? ORDER BY registered_at DESC`
+	query := `SELECT id, agenda_id, user_id, status, answers, registered_at, updated_at, checked_in_at, checked_in_by FROM agenda_registrations WHERE agenda_id = ? ORDER BY registered_at DESC`
 	err := db.Select(&registrations, query, agendaID)
 	return registrations, err
 }
 
+// agendaCalendarLimit caps the number of events in a calendar feed
+const agendaCalendarLimit = 1000
+
+// UserCalendarAgenda is an agenda in a member's private calendar with their registration status
+type UserCalendarAgenda struct {
+	Agenda
+	RegistrationStatus string `db:"registration_status" json:"registration_status"`
+}
+
+// GetCalendarAgenda retrieves published agenda ending after since for a calendar feed
+func GetCalendarAgenda(db *sqlx.DB, filters map[string]interface{}, since time.Time) ([]Agenda, error) {
+	agendas := []Agenda{}
+
+	query := `SELECT id, slug, title, description, type, date, end_date, is_online, location, province, skp, quota, registration_url, registration_form, image_url, fee, price, reminder_offsets, status, cabang, series_id, recurrence_id, is_exception, cancelled_at, sequence, published_at, created_at, updated_at, deleted_at FROM agenda WHERE deleted_at IS NULL AND status = 'published' AND COALESCE(end_date, date) >= ?`
+	args := []interface{}{since}
+
+	query, args = applyAgendaCalendarFilters(query, args, filters)
+
+	query += ` ORDER BY date ASC LIMIT ?`
+	args = append(args, agendaCalendarLimit)
+
+	err := db.Select(&agendas, query, args...)
+	return agendas, err
+}
+
+// GetAgendaInRange retrieves published agenda overlapping [from, to), multi-day agenda that started before from are included
+// Cancelled agenda are skipped unless filters["include_cancelled"] is true
+func GetAgendaInRange(db *sqlx.DB, filters map[string]interface{}, from time.Time, to time.Time) ([]Agenda, error) {
+	agendas := []Agenda{}
+
+	query := `SELECT id, slug, title, description, type, date, end_date, is_online, location, province, skp, quota, registration_url, registration_form, image_url, fee, price, reminder_offsets, status, cabang, series_id, recurrence_id, is_exception, cancelled_at, sequence, published_at, created_at, updated_at, deleted_at` + agendaSeatColumns + `
+		FROM agenda
+		WHERE deleted_at IS NULL AND status = 'published' AND date < ? AND COALESCE(end_date, date) >= ?`
+	args := []interface{}{to, from}
+
+	if includeCancelled, ok := filters["include_cancelled"].(bool); !ok || !includeCancelled {
+		query += ` AND cancelled_at IS NULL`
+	}
+	query, args = applyAgendaCalendarFilters(query, args, filters)
+
+	query += ` ORDER BY date ASC, id ASC LIMIT ?`
+	args = append(args, agendaCalendarLimit)
+
+	err := db.Select(&agendas, query, args...)
+	return agendas, err
+}
+
+// applyAgendaCalendarFilters adds the shared filters of calendar queries
+// (type, cabang with "pusat" for the central organization, is_online, province and location)
+func applyAgendaCalendarFilters(query string, args []interface{}, filters map[string]interface{}) (string, []interface{}) {
+	if typeVal, ok := filters["type"].(string); ok && typeVal != "" {
+		query += ` AND type = ?`
+		args = append(args, typeVal)
+	}
+	if cabang, ok := filters["cabang"].(string); ok && cabang != "" {
+		if cabang == "pusat" {
+			query += ` AND cabang IS NULL`
+		} else {
+			query += ` AND cabang = ?`
+			args = append(args, cabang)
+		}
+	}
+	if isOnline, ok := filters["is_online"].(bool); ok {
+		query += ` AND is_online = ?`
+		args = append(args, isOnline)
+	}
+	if province, ok := filters["province"].(string); ok && province != "" {
+		query += ` AND province = ?`
+		args = append(args, province)
+	}
+	if location, ok := filters["location"].(string); ok && location != "" {
+		query += ` AND location LIKE ?`
+		args = append(args, "%"+location+"%")
+	}
+	return query, args
+}
+
+// GetUserCalendarAgenda retrieves published agenda a user registered for (not cancelled) ending after since
+func GetUserCalendarAgenda(db *sqlx.DB, userID int64, since time.Time) ([]UserCalendarAgenda, error) {
+	agendas := []UserCalendarAgenda{}
+	query := `
+		SELECT a.id, a.slug, a.title, a.description, a.type, a.date, a.end_date, a.is_online, a.location, a.province, a.skp, a.quota, a.registration_url, a.registration_form, a.image_url, a.fee, a.price, a.reminder_offsets, a.status, a.cabang, a.series_id, a.recurrence_id, a.is_exception, a.cancelled_at, a.sequence, a.published_at, a.created_at, a.updated_at, a.deleted_at,
+			r.status AS registration_status
+		FROM agenda a
+		JOIN agenda_registrations r ON r.agenda_id = a.id
+		WHERE r.user_id = ? AND r.status != 'cancelled' AND a.deleted_at IS NULL AND a.status = 'published' AND COALESCE(a.end_date, a.date) >= ?
+		ORDER BY a.date ASC
+		LIMIT ?
+	`
+	err := db.Select(&agendas, query, userID, since, agendaCalendarLimit)
+	return agendas, err
+}
+
 // GetAgendaTypes retrieves all unique types
 func GetAgendaTypes(db *sqlx.DB) ([]string, error) {
 	var types []string
@@ -0,0 +1,493 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Agenda registration statuses
+const (
+	RegistrationStatusPending    = "pending"
+	RegistrationStatusConfirmed  = "confirmed"
+	RegistrationStatusCancelled  = "cancelled"
+	RegistrationStatusWaitlisted = "waitlisted" // Registered after the quota was reached
+)
+
+// Agenda registration errors
+var (
+	ErrAlreadyRegistered    = errors.New("already registered for this agenda")
+	ErrAgendaFull           = errors.New("agenda quota is full")
+	ErrAgendaNotFound       = errors.New("agenda not found")
+	ErrRegistrationNotFound = errors.New("registration not found")
+	ErrNotConfirmed         = errors.New("registration is not confirmed")
+	ErrAlreadyCheckedIn     = errors.New("registration has already checked in")
+)
+
+// AgendaRegistrationDetail is a registration joined with its user and agenda
+type AgendaRegistrationDetail struct {
+	AgendaRegistration
+	UserName    string    `db:"user_name" json:"user_name"`
+	UserEmail   string    `db:"user_email" json:"user_email"`
+	AgendaSlug  string    `db:"agenda_slug" json:"agenda_slug"`
+	AgendaTitle string    `db:"agenda_title" json:"agenda_title"`
+	AgendaDate  time.Time `db:"agenda_date" json:"agenda_date"`
+}
+
+const agendaRegistrationDetailSelect = `
+	SELECT r.id, r.agenda_id, r.user_id, r.status, r.answers, r.registered_at, r.updated_at, r.checked_in_at, r.checked_in_by,
+		u.name AS user_name, u.email AS user_email,
+		a.slug AS agenda_slug, a.title AS agenda_title, a.date AS agenda_date
+	FROM agenda_registrations r
+	JOIN users u ON u.id = r.user_id
+	JOIN agenda a ON a.id = r.agenda_id
+`
+
+// RegisterForAgenda registers a user for an agenda, placing them on the waitlist when the quota is reached
+// The agenda row is locked so concurrent registrations cannot oversell seats.
+// A cancelled registration is reactivated with the new answers, an active one returns ErrAlreadyRegistered (caught through uk_agenda_user)
+func RegisterForAgenda(db *sqlx.DB, agendaID int64, userID int64, answers RegistrationAnswers) (*AgendaRegistration, error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	quota, err := lockAgendaQuota(tx, agendaID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := RegistrationStatusPending
+	if quota > 0 {
+		taken, err := countSeatsTaken(tx, agendaID)
+		if err != nil {
+			return nil, err
+		}
+		if taken >= quota {
+			status = RegistrationStatusWaitlisted
+		}
+	}
+
+	now := time.Now()
+	_, err = tx.Exec(`INSERT INTO agenda_registrations (agenda_id, user_id, status, answers, registered_at) VALUES (?, ?, ?, ?, ?)`, agendaID, userID, status, answers, now)
+	if err != nil {
+		if !strings.Contains(err.Error(), "Duplicate") {
+			return nil, err
+		}
+
+		// Only a cancelled registration may be taken up again, at the end of the queue
+		result, err := tx.Exec(`
+			UPDATE agenda_registrations
+			SET status = ?, answers = ?, registered_at = ?, updated_at = ?
+			WHERE agenda_id = ? AND user_id = ? AND status = ?
+		`, status, answers, now, now, agendaID, userID, RegistrationStatusCancelled)
+		if err != nil {
+			return nil, err
+		}
+		if affected, _ := result.RowsAffected(); affected == 0 {
+			return nil, ErrAlreadyRegistered
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return FindAgendaRegistrationByUser(db, agendaID, userID)
+}
+
+// SetAgendaRegistrationStatus confirms or cancels a registration
+// Freed seats are given to the waitlist in order, the promoted registrations are returned.
+// Confirming a waitlisted registration returns ErrAgendaFull when no seat is available
+func SetAgendaRegistrationStatus(db *sqlx.DB, id int64, status string) (*AgendaRegistration, []AgendaRegistration, error) {
+	registration, err := FindAgendaRegistrationByID(db, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if registration == nil {
+		return nil, nil, ErrRegistrationNotFound
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	quota, err := lockAgendaQuota(tx, registration.AgendaID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Re-read under the lock, the status may have changed meanwhile
+	if err := tx.Get(registration, `SELECT id, agenda_id, user_id, status, answers, registered_at, updated_at, checked_in_at, checked_in_by FROM agenda_registrations WHERE id = ? FOR UPDATE`, id); err != nil {
+		return nil, nil, err
+	}
+	if registration.Status == status {
+		return registration, []AgendaRegistration{}, nil
+	}
+
+	if status == RegistrationStatusConfirmed && !holdsSeat(registration.Status) && quota > 0 {
+		taken, err := countSeatsTaken(tx, registration.AgendaID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if taken >= quota {
+			return nil, nil, ErrAgendaFull
+		}
+	}
+
+	now := time.Now()
+	if _, err := tx.Exec(`UPDATE agenda_registrations SET status = ?, updated_at = ? WHERE id = ?`, status, now, id); err != nil {
+		return nil, nil, err
+	}
+	// Confirming by hand (e.g. after a bank transfer) or cancelling closes any open invoice
+	if err := cancelOpenInvoices(tx, id, now); err != nil {
+		return nil, nil, err
+	}
+	registration.Status = status
+	registration.UpdatedAt = &now
+
+	promoted, err := promoteWaitlist(tx, registration.AgendaID, quota)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+
+	return registration, promoted, nil
+}
+
+// PromoteWaitlist gives free seats of an agenda to the waitlist in order (e.g. after the quota was raised)
+func PromoteWaitlist(db *sqlx.DB, agendaID int64) ([]AgendaRegistration, error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	quota, err := lockAgendaQuota(tx, agendaID)
+	if err != nil {
+		return nil, err
+	}
+
+	promoted, err := promoteWaitlist(tx, agendaID, quota)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return promoted, nil
+}
+
+// GetWaitlistPosition returns the 1-based position of a waitlisted registration (0 when not waitlisted)
+func GetWaitlistPosition(db *sqlx.DB, registration AgendaRegistration) (int, error) {
+	if registration.Status != RegistrationStatusWaitlisted {
+		return 0, nil
+	}
+
+	var ahead int
+	query := `
+		SELECT COUNT(*) FROM agenda_registrations
+		WHERE agenda_id = ? AND status = ? AND (registered_at < ? OR (registered_at = ? AND id < ?))
+	`
+	err := db.Get(&ahead, query, registration.AgendaID, RegistrationStatusWaitlisted, registration.RegisteredAt, registration.RegisteredAt, registration.ID)
+	return ahead + 1, err
+}
+
+// lockAgendaQuota locks the agenda row for the rest of the transaction and returns its quota (0 = unlimited)
+func lockAgendaQuota(tx *sqlx.Tx, agendaID int64) (int, error) {
+	var quota int
+	err := tx.Get(&quota, `SELECT quota FROM agenda WHERE id = ? AND deleted_at IS NULL FOR UPDATE`, agendaID)
+	if err == sql.ErrNoRows {
+		return 0, ErrAgendaNotFound
+	}
+	return quota, err
+}
+
+// countSeatsTaken counts the registrations holding a seat
+func countSeatsTaken(tx *sqlx.Tx, agendaID int64) (int, error) {
+	var taken int
+	err := tx.Get(&taken, `SELECT COUNT(*) FROM agenda_registrations WHERE agenda_id = ? AND status IN (?, ?)`, agendaID, RegistrationStatusPending, RegistrationStatusConfirmed)
+	return taken, err
+}
+
+// promoteWaitlist moves waitlisted registrations to pending while seats are free
+func promoteWaitlist(tx *sqlx.Tx, agendaID int64, quota int) ([]AgendaRegistration, error) {
+	promoted := []AgendaRegistration{}
+
+	query := `SELECT id, agenda_id, user_id, status, answers, registered_at, updated_at, checked_in_at, checked_in_by FROM agenda_registrations WHERE agenda_id = ? AND status = ? ORDER BY registered_at ASC, id ASC`
+	args := []interface{}{agendaID, RegistrationStatusWaitlisted}
+
+	// Without a quota everyone on the waitlist gets a seat
+	if quota > 0 {
+		taken, err := countSeatsTaken(tx, agendaID)
+		if err != nil {
+			return nil, err
+		}
+		free := quota - taken
+		if free <= 0 {
+			return promoted, nil
+		}
+		query += ` LIMIT ?`
+		args = append(args, free)
+	}
+
+	if err := tx.Select(&promoted, query+` FOR UPDATE`, args...); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i := range promoted {
+		if _, err := tx.Exec(`UPDATE agenda_registrations SET status = ?, updated_at = ? WHERE id = ?`, RegistrationStatusPending, now, promoted[i].ID); err != nil {
+			return nil, err
+		}
+		promoted[i].Status = RegistrationStatusPending
+		promoted[i].UpdatedAt = &now
+	}
+
+	return promoted, nil
+}
+
+// holdsSeat reports whether a registration status counts against the quota
+func holdsSeat(status string) bool {
+	return status == RegistrationStatusPending || status == RegistrationStatusConfirmed
+}
+
+// FindAgendaRegistrationByID finds a registration by ID
+func FindAgendaRegistrationByID(db *sqlx.DB, id int64) (*AgendaRegistration, error) {
+	registration := &AgendaRegistration{}
+	query := `SELECT id, agenda_id, user_id, status, answers, registered_at, updated_at, checked_in_at, checked_in_by FROM agenda_registrations WHERE id = ?`
+	err := db.Get(registration, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return registration, nil
+}
+
+// FindAgendaRegistrationByUser finds the registration of a user for an agenda
+func FindAgendaRegistrationByUser(db *sqlx.DB, agendaID int64, userID int64) (*AgendaRegistration, error) {
+	registration := &AgendaRegistration{}
+	query := `SELECT id, agenda_id, user_id, status, answers, registered_at, updated_at, checked_in_at, checked_in_by FROM agenda_registrations WHERE agenda_id = ? AND user_id = ?`
+	err := db.Get(registration, query, agendaID, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return registration, nil
+}
+
+// GetAgendaRegistrationList retrieves the registrations of an agenda with filters and pagination
+// Registrations are ordered by registration time, which is also the waitlist order (limit 0 returns all)
+func GetAgendaRegistrationList(db *sqlx.DB, agendaID int64, filters map[string]interface{}, offset int, limit int) ([]AgendaRegistrationDetail, int64, error) {
+	registrations := []AgendaRegistrationDetail{}
+
+	where := ` WHERE r.agenda_id = ?`
+	args := []interface{}{agendaID}
+
+	if status, ok := filters["status"].(string); ok && status != "" {
+		where += ` AND r.status = ?`
+		args = append(args, status)
+	}
+	if search, ok := filters["search"].(string); ok && search != "" {
+		where += ` AND (u.name LIKE ? OR u.email LIKE ?)`
+		like := "%" + search + "%"
+		args = append(args, like, like)
+	}
+	if from, ok := filters["registered_from"].(time.Time); ok {
+		where += ` AND r.registered_at >= ?`
+		args = append(args, from)
+	}
+	if to, ok := filters["registered_to"].(time.Time); ok {
+		where += ` AND r.registered_at < ?`
+		args = append(args, to)
+	}
+
+	// Get total count
+	var total int64
+	countQuery := `SELECT COUNT(*) FROM agenda_registrations r JOIN users u ON u.id = r.user_id` + where
+	if err := db.Get(&total, countQuery, args...); err != nil {
+		return nil, 0, err
+	}
+
+	query := agendaRegistrationDetailSelect + where + ` ORDER BY r.registered_at ASC, r.id ASC`
+	if limit > 0 {
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, limit, offset)
+	}
+	if err := db.Select(&registrations, query, args...); err != nil {
+		return nil, 0, err
+	}
+
+	return registrations, total, nil
+}
+
+// GetUserRegistrations retrieves the registrations of a user with filters and pagination
+func GetUserRegistrations(db *sqlx.DB, userID int64, filters map[string]interface{}, offset int, limit int) ([]AgendaRegistrationDetail, int64, error) {
+	registrations := []AgendaRegistrationDetail{}
+
+	where := ` WHERE r.user_id = ? AND a.deleted_at IS NULL`
+	args := []interface{}{userID}
+
+	if status, ok := filters["status"].(string); ok && status != "" {
+		where += ` AND r.status = ?`
+		args = append(args, status)
+	}
+	if upcoming, ok := filters["upcoming"].(bool); ok && upcoming {
+		where += ` AND COALESCE(a.end_date, a.date) >= NOW()`
+	}
+
+	// Get total count
+	var total int64
+	countQuery := `SELECT COUNT(*) FROM agenda_registrations r JOIN agenda a ON a.id = r.agenda_id` + where
+	if err := db.Get(&total, countQuery, args...); err != nil {
+		return nil, 0, err
+	}
+
+	query := agendaRegistrationDetailSelect + where + ` ORDER BY a.date DESC, r.id DESC LIMIT ? OFFSET ?`
+	if err := db.Select(&registrations, query, append(args, limit, offset)...); err != nil {
+		return nil, 0, err
+	}
+
+	return registrations, total, nil
+}
+
+// AttendanceEntry is a confirmed registration in an attendance report
+type AttendanceEntry struct {
+	AgendaRegistrationDetail
+	CheckedInByName *string `db:"checked_in_by_name" json:"checked_in_by_name"`
+}
+
+// AttendanceSummary counts attendance of an agenda
+type AttendanceSummary struct {
+	Confirmed int `db:"confirmed" json:"confirmed"`
+	CheckedIn int `db:"checked_in" json:"checked_in"`
+	NoShow    int `db:"no_show" json:"no_show"`
+}
+
+// CheckInRegistration records the check-in of a confirmed registration by a committee member
+// and posts the SKP earned to the member's ledger in the same transaction.
+// Double check-ins return ErrAlreadyCheckedIn together with the existing registration
+func CheckInRegistration(db *sqlx.DB, id int64, operatorID int64) (*AgendaRegistration, error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.Exec(`
+		UPDATE agenda_registrations
+		SET checked_in_at = ?, checked_in_by = ?
+		WHERE id = ? AND status = ? AND checked_in_at IS NULL
+	`, now, operatorID, id, RegistrationStatusConfirmed)
+	if err != nil {
+		return nil, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+
+	if affected > 0 {
+		if err := postSKPForRegistration(tx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	registration, err := FindAgendaRegistrationByID(db, id)
+	if err != nil {
+		return nil, err
+	}
+	if registration == nil {
+		return nil, ErrRegistrationNotFound
+	}
+
+	if affected == 0 {
+		if registration.CheckedInAt != nil {
+			return registration, ErrAlreadyCheckedIn
+		}
+		return registration, ErrNotConfirmed
+	}
+	return registration, nil
+}
+
+// GetAttendanceSummary counts confirmed, checked-in and no-show registrations of an agenda
+func GetAttendanceSummary(db *sqlx.DB, agendaID int64) (AttendanceSummary, error) {
+	var summary AttendanceSummary
+	query := `
+		SELECT
+			COUNT(*) AS confirmed,
+			COALESCE(SUM(checked_in_at IS NOT NULL), 0) AS checked_in,
+			COALESCE(SUM(checked_in_at IS NULL), 0) AS no_show
+		FROM agenda_registrations
+		WHERE agenda_id = ? AND status = ?
+	`
+	err := db.Get(&summary, query, agendaID, RegistrationStatusConfirmed)
+	return summary, err
+}
+
+// GetAttendanceReport retrieves confirmed registrations of an agenda with their check-in (limit 0 returns all)
+func GetAttendanceReport(db *sqlx.DB, agendaID int64, filters map[string]interface{}, offset int, limit int) ([]AttendanceEntry, int64, error) {
+	entries := []AttendanceEntry{}
+
+	where := ` WHERE r.agenda_id = ? AND r.status = ?`
+	args := []interface{}{agendaID, RegistrationStatusConfirmed}
+
+	switch filters["attendance"] {
+	case "checked_in":
+		where += ` AND r.checked_in_at IS NOT NULL`
+	case "no_show":
+		where += ` AND r.checked_in_at IS NULL`
+	}
+	if search, ok := filters["search"].(string); ok && search != "" {
+		where += ` AND (u.name LIKE ? OR u.email LIKE ?)`
+		like := "%" + search + "%"
+		args = append(args, like, like)
+	}
+
+	// Get total count
+	var total int64
+	countQuery := `SELECT COUNT(*) FROM agenda_registrations r JOIN users u ON u.id = r.user_id` + where
+	if err := db.Get(&total, countQuery, args...); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT r.id, r.agenda_id, r.user_id, r.status, r.answers, r.registered_at, r.updated_at, r.checked_in_at, r.checked_in_by,
+			u.name AS user_name, u.email AS user_email,
+			a.slug AS agenda_slug, a.title AS agenda_title, a.date AS agenda_date,
+			o.name AS checked_in_by_name
+		FROM agenda_registrations r
+		JOIN users u ON u.id = r.user_id
+		JOIN agenda a ON a.id = r.agenda_id
+		LEFT JOIN users o ON o.id = r.checked_in_by
+	` + where + ` ORDER BY u.name ASC, r.id ASC`
+	if limit > 0 {
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, limit, offset)
+	}
+
+	if err := db.Select(&entries, query, args...); err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
@@ -0,0 +1,235 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Reminder delivery statuses
+const (
+	ReminderStatusSending = "sending" // Claimed by an instance, the email is being sent
+	ReminderStatusSent    = "sent"
+	ReminderStatusFailed  = "failed"
+)
+
+// MaxReminderAttempts is how many times a failed reminder is retried before it is given up
+const MaxReminderAttempts = 3
+
+// MaxReminderOffset is the earliest a reminder can be sent before the start, in minutes (30 days)
+const MaxReminderOffset = 30 * 24 * 60
+
+// DefaultReminderOffsets are used by agenda without their own offsets: one day and one hour before the start
+var DefaultReminderOffsets = []int{24 * 60, 60}
+
+// ReminderOffsets handles JSON marshaling for the reminder offsets of an agenda, in minutes before the start
+// nil is stored as NULL (use the defaults) while an empty list disables reminders
+type ReminderOffsets []int
+
+func (o ReminderOffsets) Value() (driver.Value, error) {
+	if o == nil {
+		return nil, nil
+	}
+	return json.Marshal(o)
+}
+
+func (o *ReminderOffsets) Scan(value interface{}) error {
+	if value == nil {
+		*o = nil
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(b, o)
+}
+
+// ReminderSchedule returns the reminder offsets of the agenda, longest first and without duplicates
+func (a *Agenda) ReminderSchedule() []int {
+	offsets := []int(a.ReminderOffsets)
+	if offsets == nil {
+		offsets = DefaultReminderOffsets
+	}
+
+	seen := map[int]bool{}
+	schedule := []int{}
+	for _, offset := range offsets {
+		if offset > 0 && !seen[offset] {
+			seen[offset] = true
+			schedule = append(schedule, offset)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(schedule)))
+	return schedule
+}
+
+// DueReminderOffset returns the offset whose reminder is due at now, if any
+// A reminder is due from its scheduled time until the next shorter one (or the start) is due,
+// so members registering late only get the closest reminder instead of all missed ones at once
+func (a *Agenda) DueReminderOffset(now time.Time) (int, bool) {
+	schedule := a.ReminderSchedule()
+	for i, offset := range schedule {
+		due := a.Date.Add(-time.Duration(offset) * time.Minute)
+		until := a.Date
+		if i+1 < len(schedule) {
+			until = a.Date.Add(-time.Duration(schedule[i+1]) * time.Minute)
+		}
+		if !now.Before(due) && now.Before(until) {
+			return offset, true
+		}
+	}
+	return 0, false
+}
+
+// AgendaReminderDelivery is the delivery log entry of one reminder to one registrant
+type AgendaReminderDelivery struct {
+	ID             int64      `db:"id" json:"id"`
+	AgendaID       int64      `db:"agenda_id" json:"agenda_id"`
+	RegistrationID int64      `db:"registration_id" json:"registration_id"`
+	UserID         int64      `db:"user_id" json:"user_id"`
+	OffsetMinutes  int        `db:"offset_minutes" json:"offset_minutes"`
+	ScheduledFor   time.Time  `db:"scheduled_for" json:"scheduled_for"`
+	Recipient      string     `db:"recipient" json:"recipient"`
+	Status         string     `db:"status" json:"status"`
+	Attempts       int        `db:"attempts" json:"attempts"`
+	Error          *string    `db:"error" json:"error"`
+	SentAt         *time.Time `db:"sent_at" json:"sent_at"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// AgendaReminderDeliveryDetail is a delivery log entry joined with its registrant
+type AgendaReminderDeliveryDetail struct {
+	AgendaReminderDelivery
+	UserName string `db:"user_name" json:"user_name"`
+}
+
+// ReminderRecipient is a confirmed registrant who still has to receive a reminder
+type ReminderRecipient struct {
+	RegistrationID int64  `db:"registration_id"`
+	UserID         int64  `db:"user_id"`
+	UserName       string `db:"user_name"`
+	UserEmail      string `db:"user_email"`
+}
+
+// GetAgendaAwaitingReminders retrieves published agenda starting after now and at most MaxReminderOffset later
+func GetAgendaAwaitingReminders(db *sqlx.DB, now time.Time) ([]Agenda, error) {
+	agendas := []Agenda{}
+	query := `
+		SELECT id, slug, title, description, type, date, end_date, is_online, location, province, skp, quota, registration_url, registration_form, image_url, fee, price, reminder_offsets, status, cabang, series_id, recurrence_id, is_exception, cancelled_at, sequence, published_at, created_at, updated_at, deleted_at
+		FROM agenda
+		WHERE deleted_at IS NULL AND status = 'published' AND cancelled_at IS NULL AND date > ? AND date <= ?
+		ORDER BY date ASC
+	`
+	err := db.Select(&agendas, query, now, now.Add(MaxReminderOffset*time.Minute))
+	return agendas, err
+}
+
+// GetReminderRecipients retrieves confirmed registrants of an agenda who have not been sent a reminder yet,
+// including those whose earlier attempts failed fewer than MaxReminderAttempts times
+func GetReminderRecipients(db *sqlx.DB, agendaID int64, offset int, scheduledFor time.Time, limit int) ([]ReminderRecipient, error) {
+	recipients := []ReminderRecipient{}
+	query := `
+		SELECT r.id AS registration_id, r.user_id, u.name AS user_name, u.email AS user_email
+		FROM agenda_registrations r
+		JOIN users u ON u.id = r.user_id
+		LEFT JOIN agenda_reminder_deliveries d
+			ON d.registration_id = r.id AND d.offset_minutes = ? AND d.scheduled_for = ?
+		WHERE r.agenda_id = ? AND r.status = ?
+			AND (d.id IS NULL OR (d.status = ? AND d.attempts < ?))
+		ORDER BY r.id ASC
+		LIMIT ?
+	`
+	err := db.Select(&recipients, query, offset, scheduledFor, agendaID, RegistrationStatusConfirmed, ReminderStatusFailed, MaxReminderAttempts, limit)
+	return recipients, err
+}
+
+// ClaimReminderDelivery claims the reminder of a registrant before it is sent
+// Only one instance can claim a reminder: the first insert wins through uk_agenda_reminder_deliveries,
+// and a failed delivery is only retried by the instance whose update flips it back to sending
+func ClaimReminderDelivery(db *sqlx.DB, agendaID int64, recipient ReminderRecipient, offset int, scheduledFor time.Time) (bool, error) {
+	now := time.Now()
+	result, err := db.Exec(`
+		INSERT IGNORE INTO agenda_reminder_deliveries (agenda_id, registration_id, user_id, offset_minutes, scheduled_for, recipient, status, attempts, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, 1, ?, ?)
+	`, agendaID, recipient.RegistrationID, recipient.UserID, offset, scheduledFor, recipient.UserEmail, ReminderStatusSending, now, now)
+	if err != nil {
+		return false, err
+	}
+	if affected, _ := result.RowsAffected(); affected > 0 {
+		return true, nil
+	}
+
+	result, err = db.Exec(`
+		UPDATE agenda_reminder_deliveries
+		SET status = ?, attempts = attempts + 1, recipient = ?, error = NULL, updated_at = ?
+		WHERE registration_id = ? AND offset_minutes = ? AND scheduled_for = ? AND status = ? AND attempts < ?
+	`, ReminderStatusSending, recipient.UserEmail, now, recipient.RegistrationID, offset, scheduledFor, ReminderStatusFailed, MaxReminderAttempts)
+	if err != nil {
+		return false, err
+	}
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
+
+// FinishReminderDelivery records the outcome of a claimed reminder, a nil sendErr marks it as sent
+func FinishReminderDelivery(db *sqlx.DB, registrationID int64, offset int, scheduledFor time.Time, sendErr error) error {
+	now := time.Now()
+	if sendErr != nil {
+		message := sendErr.Error()
+		_, err := db.Exec(`
+			UPDATE agenda_reminder_deliveries SET status = ?, error = ?, updated_at = ?
+			WHERE registration_id = ? AND offset_minutes = ? AND scheduled_for = ?
+		`, ReminderStatusFailed, message, now, registrationID, offset, scheduledFor)
+		return err
+	}
+
+	_, err := db.Exec(`
+		UPDATE agenda_reminder_deliveries SET status = ?, error = NULL, sent_at = ?, updated_at = ?
+		WHERE registration_id = ? AND offset_minutes = ? AND scheduled_for = ?
+	`, ReminderStatusSent, now, now, registrationID, offset, scheduledFor)
+	return err
+}
+
+// GetAgendaReminderDeliveries retrieves the reminder delivery log of an agenda with optional filters (status, user_id)
+func GetAgendaReminderDeliveries(db *sqlx.DB, agendaID int64, filters map[string]interface{}, offset int, limit int) ([]AgendaReminderDeliveryDetail, int64, error) {
+	deliveries := []AgendaReminderDeliveryDetail{}
+	var total int64
+
+	where := ` WHERE d.agenda_id = ?`
+	args := []interface{}{agendaID}
+
+	if status, ok := filters["status"].(string); ok && status != "" {
+		where += ` AND d.status = ?`
+		args = append(args, status)
+	}
+	if userID, ok := filters["user_id"].(int64); ok && userID > 0 {
+		where += ` AND d.user_id = ?`
+		args = append(args, userID)
+	}
+
+	countQuery := `SELECT COUNT(*) FROM agenda_reminder_deliveries d` + where
+	if err := db.Get(&total, countQuery, args...); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT d.id, d.agenda_id, d.registration_id, d.user_id, d.offset_minutes, d.scheduled_for, d.recipient, d.status, d.attempts, d.error, d.sent_at, d.created_at, d.updated_at,
+			u.name AS user_name
+		FROM agenda_reminder_deliveries d
+		JOIN users u ON u.id = d.user_id` + where + `
+		ORDER BY d.scheduled_for DESC, d.id DESC
+		LIMIT ? OFFSET ?
+	`
+	args = append(args, limit, offset)
+	if err := db.Select(&deliveries, query, args...); err != nil {
+		return nil, 0, err
+	}
+
+	return deliveries, total, nil
+}
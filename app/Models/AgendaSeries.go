@@ -0,0 +1,298 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrSeriesNotFound is returned when a recurring series does not exist
+var ErrSeriesNotFound = errors.New("agenda series not found")
+
+// AgendaSeries is a recurring agenda, its occurrences are agenda rows linked by series_id
+type AgendaSeries struct {
+	ID              int64      `db:"id" json:"id"`
+	Slug            string     `db:"slug" json:"slug"` // Base of the occurrence slugs
+	RRule           string     `db:"rrule" json:"rrule"`
+	StartsAt        time.Time  `db:"starts_at" json:"starts_at"`               // Start of the first occurrence
+	DurationSeconds *int       `db:"duration_seconds" json:"duration_seconds"` // Occurrence length, nil when occurrences have no end date
+	Cabang          *string    `db:"cabang" json:"cabang"`
+	CancelledAt     *time.Time `db:"cancelled_at" json:"cancelled_at"`
+	CreatedAt       time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt       time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// SeriesSyncResult lists which occurrences changed when a series was edited
+type SeriesSyncResult struct {
+	Updated   []int64 `json:"updated"`
+	Created   []int64 `json:"created"`
+	Cancelled []int64 `json:"cancelled"`
+	Skipped   []int64 `json:"skipped"` // Occurrences edited on their own (exceptions)
+}
+
+const agendaSeriesColumns = `id, slug, rrule, starts_at, duration_seconds, cabang, cancelled_at, created_at, updated_at`
+
+// occurrenceDateLayout is the date suffix of occurrence slugs
+const occurrenceDateLayout = "2006-01-02"
+
+// OccurrenceSlug returns the slug of the occurrence of a series starting at start
+func OccurrenceSlug(baseSlug string, start time.Time, loc *time.Location) string {
+	return baseSlug + "-" + start.In(loc).Format(occurrenceDateLayout)
+}
+
+// applyOccurrenceTimes sets the start, end and recurrence ID of an occurrence
+func (s *AgendaSeries) applyOccurrenceTimes(occurrence *Agenda, start time.Time) {
+	recurrenceID := start
+	occurrence.Date = start
+	occurrence.RecurrenceID = &recurrenceID
+	occurrence.EndDate = nil
+	if s.DurationSeconds != nil {
+		end := start.Add(time.Duration(*s.DurationSeconds) * time.Second)
+		occurrence.EndDate = &end
+	}
+}
+
+// CreateAgendaSeries creates a series and one agenda per occurrence start from the template
+// Occurrence slugs are the series slug with the local date appended
+func CreateAgendaSeries(db *sqlx.DB, series *AgendaSeries, template Agenda, starts []time.Time, loc *time.Location) ([]Agenda, error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	series.StartsAt = starts[0]
+	series.Cabang = template.Cabang
+	series.CreatedAt = now
+	series.UpdatedAt = now
+
+	result, err := tx.Exec(`
+		INSERT INTO agenda_series (slug, rrule, starts_at, duration_seconds, cabang, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, series.Slug, series.RRule, series.StartsAt, series.DurationSeconds, series.Cabang, series.CreatedAt, series.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if series.ID, err = result.LastInsertId(); err != nil {
+		return nil, err
+	}
+
+	occurrences := make([]Agenda, len(starts))
+	for i, start := range starts {
+		occurrence := template
+		occurrence.ID = 0
+		occurrence.Slug = OccurrenceSlug(series.Slug, start, loc)
+		occurrence.SeriesID = &series.ID
+		occurrence.IsException = false
+		occurrence.CreatedAt = now
+		occurrence.UpdatedAt = now
+		series.applyOccurrenceTimes(&occurrence, start)
+
+		if err := insertAgenda(tx, &occurrence); err != nil {
+			return nil, err
+		}
+		occurrences[i] = occurrence
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return occurrences, nil
+}
+
+// SyncAgendaSeries applies an edited rule and template to the upcoming occurrences of a series
+// Past occurrences are left as they happened. Upcoming occurrences are matched to the new starts by local date:
+// matches are updated (exceptions are skipped), new dates are created and dates no longer in the rule are cancelled
+func SyncAgendaSeries(db *sqlx.DB, series *AgendaSeries, template Agenda, starts []time.Time, loc *time.Location) (*SeriesSyncResult, error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	series.StartsAt = starts[0]
+	series.UpdatedAt = now
+
+	if _, err := tx.Exec(`
+		UPDATE agenda_series SET rrule = ?, starts_at = ?, duration_seconds = ?, updated_at = ?
+		WHERE id = ?
+	`, series.RRule, series.StartsAt, series.DurationSeconds, series.UpdatedAt, series.ID); err != nil {
+		return nil, err
+	}
+
+	existing := []Agenda{}
+	if err := tx.Select(&existing, `
+		SELECT id, slug, title, description, type, date, end_date, is_online, location, province, skp, quota, registration_url, registration_form, image_url, fee, price, reminder_offsets, status, cabang, series_id, recurrence_id, is_exception, cancelled_at, sequence, published_at, created_at, updated_at, deleted_at
+		FROM agenda
+		WHERE series_id = ? AND deleted_at IS NULL AND (date >= ? OR recurrence_id >= ?)
+		FOR UPDATE
+	`, series.ID, now, now); err != nil {
+		return nil, err
+	}
+
+	byDate := map[string]Agenda{}
+	for _, occurrence := range existing {
+		key := occurrence.Date.In(loc).Format(occurrenceDateLayout)
+		if occurrence.RecurrenceID != nil {
+			key = occurrence.RecurrenceID.In(loc).Format(occurrenceDateLayout)
+		}
+		byDate[key] = occurrence
+	}
+
+	result := &SeriesSyncResult{Updated: []int64{}, Created: []int64{}, Cancelled: []int64{}, Skipped: []int64{}}
+	for _, start := range starts {
+		if start.Before(now) {
+			continue
+		}
+
+		key := start.In(loc).Format(occurrenceDateLayout)
+		occurrence, found := byDate[key]
+		if !found {
+			created := template
+			created.ID = 0
+			created.Slug = OccurrenceSlug(series.Slug, start, loc)
+			created.SeriesID = &series.ID
+			created.IsException = false
+			created.CreatedAt = now
+			created.UpdatedAt = now
+			series.applyOccurrenceTimes(&created, start)
+			if err := insertAgenda(tx, &created); err != nil {
+				return nil, err
+			}
+			result.Created = append(result.Created, created.ID)
+			continue
+		}
+		delete(byDate, key)
+
+		// Exceptions and cancelled occurrences keep their own state
+		if occurrence.IsException || occurrence.CancelledAt != nil {
+			result.Skipped = append(result.Skipped, occurrence.ID)
+			continue
+		}
+
+		updated := template
+		series.applyOccurrenceTimes(&updated, start)
+		if _, err := tx.Exec(`
+			UPDATE agenda
+			SET title = ?, description = ?, type = ?, date = ?, end_date = ?, recurrence_id = ?, is_online = ?, location = ?, province = ?, skp = ?, quota = ?,
+				registration_url = ?, registration_form = ?, image_url = ?, fee = ?, price = ?, reminder_offsets = ?, status = ?, published_at = COALESCE(published_at, ?),
+				sequence = sequence + 1, updated_at = ?
+			WHERE id = ?
+		`, updated.Title, updated.Description, updated.Type, updated.Date, updated.EndDate, updated.RecurrenceID, updated.IsOnline, updated.Location, updated.Province, updated.SKP, updated.Quota,
+			updated.RegistrationURL, updated.RegistrationForm, updated.ImageURL, updated.Fee, updated.Price, updated.ReminderOffsets, updated.Status, updated.PublishedAt,
+			now, occurrence.ID); err != nil {
+			return nil, err
+		}
+		result.Updated = append(result.Updated, occurrence.ID)
+	}
+
+	// Upcoming dates that are no longer part of the rule
+	for _, occurrence := range byDate {
+		if occurrence.IsException || occurrence.CancelledAt != nil {
+			result.Skipped = append(result.Skipped, occurrence.ID)
+			continue
+		}
+		if _, err := tx.Exec(`UPDATE agenda SET cancelled_at = ?, sequence = sequence + 1, updated_at = ? WHERE id = ?`, now, now, occurrence.ID); err != nil {
+			return nil, err
+		}
+		result.Cancelled = append(result.Cancelled, occurrence.ID)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CancelAgendaSeries cancels a series and all of its upcoming occurrences, returning the cancelled occurrence IDs
+func CancelAgendaSeries(db *sqlx.DB, seriesID int64) ([]int64, error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	result, err := tx.Exec(`UPDATE agenda_series SET cancelled_at = COALESCE(cancelled_at, ?), updated_at = ? WHERE id = ?`, now, now, seriesID)
+	if err != nil {
+		return nil, err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil, ErrSeriesNotFound
+	}
+
+	cancelled := []int64{}
+	if err := tx.Select(&cancelled, `
+		SELECT id FROM agenda
+		WHERE series_id = ? AND deleted_at IS NULL AND cancelled_at IS NULL AND date >= ?
+		FOR UPDATE
+	`, seriesID, now); err != nil {
+		return nil, err
+	}
+
+	if len(cancelled) > 0 {
+		query, args, err := sqlx.In(`UPDATE agenda SET cancelled_at = ?, sequence = sequence + 1, updated_at = ? WHERE id IN (?)`, now, now, cancelled)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(query, args...); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return cancelled, nil
+}
+
+// CancelAgendaOccurrence cancels a single agenda (restore = false) or takes the cancellation back
+func CancelAgendaOccurrence(db *sqlx.DB, agendaID int64, restore bool) error {
+	now := time.Now()
+	var cancelledAt *time.Time
+	if !restore {
+		cancelledAt = &now
+	}
+
+	result, err := db.Exec(`
+		UPDATE agenda SET cancelled_at = ?, sequence = sequence + 1, updated_at = ?
+		WHERE id = ? AND deleted_at IS NULL
+	`, cancelledAt, now, agendaID)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ErrAgendaNotFound
+	}
+	return nil
+}
+
+// FindAgendaSeriesByID finds a series by ID
+func FindAgendaSeriesByID(db *sqlx.DB, id int64) (*AgendaSeries, error) {
+	series := &AgendaSeries{}
+	err := db.Get(series, `SELECT `+agendaSeriesColumns+` FROM agenda_series WHERE id = ?`, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return series, nil
+}
+
+// GetAgendaSeriesOccurrences retrieves all occurrences of a series (excluding deleted) in chronological order
+func GetAgendaSeriesOccurrences(db *sqlx.DB, seriesID int64) ([]Agenda, error) {
+	occurrences := []Agenda{}
+	query := `
+		SELECT id, slug, title, description, type, date, end_date, is_online, location, province, skp, quota, registration_url, registration_form, image_url, fee, price, reminder_offsets, status, cabang, series_id, recurrence_id, is_exception, cancelled_at, sequence, published_at, created_at, updated_at, deleted_at` + agendaSeatColumns + `
+		FROM agenda
+		WHERE series_id = ? AND deleted_at IS NULL
+		ORDER BY date ASC, id ASC
+	`
+	err := db.Select(&occurrences, query, seriesID)
+	return occurrences, err
+}
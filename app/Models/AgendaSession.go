@@ -0,0 +1,222 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Session speaker roles
+const (
+	SessionRoleSpeaker   = "speaker"
+	SessionRoleModerator = "moderator"
+)
+
+// ErrSessionNotFound is returned when a session does not belong to the agenda
+var ErrSessionNotFound = errors.New("session not found")
+
+// AgendaSession is a slot in the rundown of an agenda
+type AgendaSession struct {
+	ID          int64            `db:"id" json:"id"`
+	AgendaID    int64            `db:"agenda_id" json:"agenda_id"`
+	Title       string           `db:"title" json:"title"`
+	Description string           `db:"description" json:"description"`
+	StartsAt    time.Time        `db:"starts_at" json:"starts_at"`
+	EndsAt      time.Time        `db:"ends_at" json:"ends_at"`
+	Room        string           `db:"room" json:"room"`
+	SKP         float64          `db:"skp" json:"skp"`
+	SortOrder   int              `db:"sort_order" json:"sort_order"`
+	Sequence    int              `db:"sequence" json:"sequence"` // iCalendar SEQUENCE, bumped on every update
+	CreatedAt   time.Time        `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time        `db:"updated_at" json:"updated_at"`
+	Speakers    []SessionSpeaker `db:"-" json:"speakers"` // Speakers and moderators, loaded separately
+}
+
+// SessionSpeaker is a speaker assigned to a session with a role
+type SessionSpeaker struct {
+	SessionID int64  `db:"session_id" json:"session_id"`
+	Role      string `db:"role" json:"role"`
+	AgendaSpeaker
+}
+
+const agendaSessionColumns = `id, agenda_id, title, description, starts_at, ends_at, room, skp, sort_order, sequence, created_at, updated_at`
+
+// CreateAgendaSession creates a session with its speakers and moderators (speaker IDs of the same agenda)
+func CreateAgendaSession(db *sqlx.DB, session *AgendaSession, speakerIDs []int64, moderatorIDs []int64) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	session.CreatedAt = time.Now()
+	session.UpdatedAt = session.CreatedAt
+
+	result, err := tx.Exec(`
+		INSERT INTO agenda_sessions (agenda_id, title, description, starts_at, ends_at, room, skp, sort_order, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, session.AgendaID, session.Title, session.Description, session.StartsAt, session.EndsAt, session.Room, session.SKP, session.SortOrder, session.CreatedAt, session.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if session.ID, err = result.LastInsertId(); err != nil {
+		return err
+	}
+
+	if err := setSessionSpeakers(tx, *session, speakerIDs, moderatorIDs); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateAgendaSession updates a session and replaces its speakers and moderators
+func UpdateAgendaSession(db *sqlx.DB, session *AgendaSession, speakerIDs []int64, moderatorIDs []int64) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	session.UpdatedAt = time.Now()
+	result, err := tx.Exec(`
+		UPDATE agenda_sessions
+		SET title = ?, description = ?, starts_at = ?, ends_at = ?, room = ?, skp = ?, sort_order = ?, sequence = sequence + 1, updated_at = ?
+		WHERE id = ? AND agenda_id = ?
+	`, session.Title, session.Description, session.StartsAt, session.EndsAt, session.Room, session.SKP, session.SortOrder, session.UpdatedAt, session.ID, session.AgendaID)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ErrSessionNotFound
+	}
+	session.Sequence++
+
+	if _, err := tx.Exec(`DELETE FROM agenda_session_speakers WHERE session_id = ?`, session.ID); err != nil {
+		return err
+	}
+	if err := setSessionSpeakers(tx, *session, speakerIDs, moderatorIDs); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// setSessionSpeakers links speakers and moderators to a session in the given order
+// Every ID must be a speaker of the session's agenda, otherwise ErrSpeakerNotFound is returned
+func setSessionSpeakers(tx *sqlx.Tx, session AgendaSession, speakerIDs []int64, moderatorIDs []int64) error {
+	roles := []struct {
+		role string
+		ids  []int64
+	}{
+		{SessionRoleSpeaker, speakerIDs},
+		{SessionRoleModerator, moderatorIDs},
+	}
+
+	for _, entry := range roles {
+		seen := map[int64]bool{}
+		for i, speakerID := range entry.ids {
+			if seen[speakerID] {
+				continue
+			}
+			seen[speakerID] = true
+
+			var found int
+			if err := tx.Get(&found, `SELECT COUNT(*) FROM agenda_speakers WHERE id = ? AND agenda_id = ?`, speakerID, session.AgendaID); err != nil {
+				return err
+			}
+			if found == 0 {
+				return ErrSpeakerNotFound
+			}
+
+			if _, err := tx.Exec(`INSERT INTO agenda_session_speakers (session_id, speaker_id, role, sort_order) VALUES (?, ?, ?, ?)`, session.ID, speakerID, entry.role, i); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// DeleteAgendaSession deletes a session of an agenda
+func DeleteAgendaSession(db *sqlx.DB, agendaID int64, id int64) error {
+	result, err := db.Exec(`DELETE FROM agenda_sessions WHERE id = ? AND agenda_id = ?`, id, agendaID)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// FindAgendaSession finds a session of an agenda by ID, with its speakers
+func FindAgendaSession(db *sqlx.DB, agendaID int64, id int64) (*AgendaSession, error) {
+	session := &AgendaSession{}
+	err := db.Get(session, `SELECT `+agendaSessionColumns+` FROM agenda_sessions WHERE id = ? AND agenda_id = ?`, id, agendaID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	sessions := []AgendaSession{*session}
+	if err := loadSessionSpeakers(db, sessions); err != nil {
+		return nil, err
+	}
+	return &sessions[0], nil
+}
+
+// GetAgendaSessions retrieves the rundown of an agenda in chronological order, with speakers
+func GetAgendaSessions(db *sqlx.DB, agendaID int64) ([]AgendaSession, error) {
+	sessions := []AgendaSession{}
+	err := db.Select(&sessions, `SELECT `+agendaSessionColumns+` FROM agenda_sessions WHERE agenda_id = ? ORDER BY starts_at ASC, sort_order ASC, id ASC`, agendaID)
+	if err != nil {
+		return nil, err
+	}
+	if err := loadSessionSpeakers(db, sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// loadSessionSpeakers fills the speakers of sessions with a single query
+func loadSessionSpeakers(db *sqlx.DB, sessions []AgendaSession) error {
+	if len(sessions) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(sessions))
+	for i := range sessions {
+		ids[i] = sessions[i].ID
+		sessions[i].Speakers = []SessionSpeaker{}
+	}
+
+	query, args, err := sqlx.In(`
+		SELECT ss.session_id, ss.role,
+			s.id, s.agenda_id, s.name, s.title, s.institution, s.bio, s.photo_url, s.user_id, s.pengurus_id, s.sort_order, s.created_at, s.updated_at
+		FROM agenda_session_speakers ss
+		JOIN agenda_speakers s ON s.id = ss.speaker_id
+		WHERE ss.session_id IN (?)
+		ORDER BY ss.session_id ASC, ss.role DESC, ss.sort_order ASC
+	`, ids)
+	if err != nil {
+		return err
+	}
+
+	speakers := []SessionSpeaker{}
+	if err := db.Select(&speakers, db.Rebind(query), args...); err != nil {
+		return err
+	}
+
+	index := make(map[int64]int, len(sessions))
+	for i := range sessions {
+		index[sessions[i].ID] = i
+	}
+	for _, speaker := range speakers {
+		if i, ok := index[speaker.SessionID]; ok {
+			sessions[i].Speakers = append(sessions[i].Speakers, speaker)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrSpeakerNotFound is returned when a speaker does not belong to the agenda
+var ErrSpeakerNotFound = errors.New("speaker not found")
+
+// AgendaSpeaker is a speaker or moderator of an agenda, optionally linked to a member or board member
+type AgendaSpeaker struct {
+	ID          int64     `db:"id" json:"id"`
+	AgendaID    int64     `db:"agenda_id" json:"agenda_id"`
+	Name        string    `db:"name" json:"name"`
+	Title       string    `db:"title" json:"title"` // Academic title or position
+	Institution string    `db:"institution" json:"institution"`
+	Bio         string    `db:"bio" json:"bio"`
+	PhotoURL    string    `db:"photo_url" json:"photo_url"`
+	UserID      *int64    `db:"user_id" json:"user_id"`
+	PengurusID  *int64    `db:"pengurus_id" json:"pengurus_id"`
+	SortOrder   int       `db:"sort_order" json:"sort_order"`
+	CreatedAt   time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt   time.Time `db:"updated_at" json:"updated_at"`
+}
+
+const agendaSpeakerColumns = `id, agenda_id, name, title, institution, bio, photo_url, user_id, pengurus_id, sort_order, created_at, updated_at`
+
+// Create creates a new speaker record
+func (s *AgendaSpeaker) Create(db *sqlx.DB) error {
+	s.CreatedAt = time.Now()
+	s.UpdatedAt = time.Now()
+
+	query := `
+		INSERT INTO agenda_speakers (agenda_id, name, title, institution, bio, photo_url, user_id, pengurus_id, sort_order, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	result, err := db.Exec(query, s.AgendaID, s.Name, s.Title, s.Institution, s.Bio, s.PhotoURL, s.UserID, s.PengurusID, s.SortOrder, s.CreatedAt, s.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	s.ID = id
+	return nil
+}
+
+// Update updates a speaker record
+func (s *AgendaSpeaker) Update(db *sqlx.DB) error {
+	s.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE agenda_speakers
+		SET name = ?, title = ?, institution = ?, bio = ?, photo_url = ?, user_id = ?, pengurus_id = ?, sort_order = ?, updated_at = ?
+		WHERE id = ? AND agenda_id = ?
+	`
+	_, err := db.Exec(query, s.Name, s.Title, s.Institution, s.Bio, s.PhotoURL, s.UserID, s.PengurusID, s.SortOrder, s.UpdatedAt, s.ID, s.AgendaID)
+	return err
+}
+
+// DeleteAgendaSpeaker deletes a speaker of an agenda, removing them from its sessions
+func DeleteAgendaSpeaker(db *sqlx.DB, agendaID int64, id int64) error {
+	result, err := db.Exec(`DELETE FROM agenda_speakers WHERE id = ? AND agenda_id = ?`, id, agendaID)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ErrSpeakerNotFound
+	}
+	return nil
+}
+
+// FindAgendaSpeaker finds a speaker of an agenda by ID
+func FindAgendaSpeaker(db *sqlx.DB, agendaID int64, id int64) (*AgendaSpeaker, error) {
+	speaker := &AgendaSpeaker{}
+	err := db.Get(speaker, `SELECT `+agendaSpeakerColumns+` FROM agenda_speakers WHERE id = ? AND agenda_id = ?`, id, agendaID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return speaker, nil
+}
+
+// GetAgendaSpeakers retrieves the speakers of an agenda in display order
+func GetAgendaSpeakers(db *sqlx.DB, agendaID int64) ([]AgendaSpeaker, error) {
+	speakers := []AgendaSpeaker{}
+	err := db.Select(&speakers, `SELECT `+agendaSpeakerColumns+` FROM agenda_speakers WHERE agenda_id = ? ORDER BY sort_order ASC, id ASC`, agendaID)
+	return speakers, err
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"database/sql"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -17,6 +18,7 @@ type Berita struct {
 	ImageURL    string     `db:"image_url" json:"image_url"`
 	Category    string     `db:"category" json:"category"`
 	Author      string     `db:"author" json:"author"`
+	Cabang      *string    `db:"cabang" json:"cabang"` // Owning organization unit (nil = pusat)
 	Status      string     `db:"status" json:"status"`
 	Views       int64      `db:"views" json:"views"`
 	PublishedAt *time.Time `db:"published_at" json:"published_at"`
@@ -36,10 +38,10 @@ func (b *Berita) Create(db *sqlx.DB) error {
 	}
 
 	query := `
-		INSERT INTO berita (slug, title, excerpt, content, image_url, category, author, status, views, published_at, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO berita (slug, title, excerpt, content, image_url, category, author, cabang, status, views, published_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
-	result, err := db.Exec(query, b.Slug, b.Title, b.Excerpt, b.Content, b.ImageURL, b.Category, b.Author, b.Status, b.Views, b.PublishedAt, b.CreatedAt, b.UpdatedAt)
+	result, err := db.Exec(query, b.Slug, b.Title, b.Excerpt, b.Content, b.ImageURL, b.Category, b.Author, b.Cabang, b.Status, b.Views, b.PublishedAt, b.CreatedAt, b.UpdatedAt)
 	if err != nil {
 		return err
 	}
@@ -56,7 +58,7 @@ func (b *Berita) Create(db *sqlx.DB) error {
 func FindBeritaBySlug(db *sqlx.DB, slug string) (*Berita, error) {
 	berita := &Berita{}
 	query := `
-		SELECT id, slug, title, excerpt, content, image_url, category, author, status, views, published_at, created_at, updated_at, deleted_at 
+		SELECT id, slug, title, excerpt, content, image_url, category, author, cabang, status, views, published_at, created_at, updated_at, deleted_at 
 		FROM berita 
 		WHERE slug = ? AND deleted_at IS NULL
 	`
@@ -74,7 +76,7 @@ func FindBeritaBySlug(db *sqlx.DB, slug string) (*Berita, error) {
 func FindBeritaByID(db *sqlx.DB, id int64) (*Berita, error) {
 	berita := &Berita{}
 	query := `
-		SELECT id, slug, title, excerpt, content, image_url, category, author, status, views, published_at, created_at, updated_at, deleted_at 
+		SELECT id, slug, title, excerpt, content, image_url, category, author, cabang, status, views, published_at, created_at, updated_at, deleted_at 
 		FROM berita 
 		WHERE id = ? AND deleted_at IS NULL
 	`
@@ -92,7 +94,7 @@ func FindBeritaByID(db *sqlx.DB, id int64) (*Berita, error) {
 func GetAllBerita(db *sqlx.DB, filters map[string]interface{}, offset int, limit int) ([]Berita, int64, error) {
 	var berita []Berita
 
-	query := `SELECT id, slug, title, excerpt, content, image_url, category, author, status, views, published_at, created_at, updated_at, deleted_at FROM berita WHERE deleted_at IS NULL`
+	query := `SELECT id, slug, title, excerpt, content, image_url, category, author, cabang, status, views, published_at, created_at, updated_at, deleted_at FROM berita WHERE deleted_at IS NULL`
 	countQuery := `SELECT COUNT(*) FROM berita WHERE deleted_at IS NULL`
 
 	// Build WHERE clause based on filters
@@ -161,3 +163,88 @@ func GetBeritaCategories(db *sqlx.DB) ([]string, error) {
 	err := db.Select(&categories, query)
 	return categories, err
 }
+
+// GetBeritaTags retrieves the tag names of a berita
+func GetBeritaTags(db *sqlx.DB, beritaID int64) ([]string, error) {
+	tags := []string{}
+	query := `
+		SELECT t.name FROM berita_tags t
+		JOIN berita_tag_map m ON m.tag_id = t.id
+		WHERE m.berita_id = ?
+		ORDER BY t.name
+	`
+	err := db.Select(&tags, query, beritaID)
+	return tags, err
+}
+
+// SyncBeritaTags replaces the tags of a berita
+func SyncBeritaTags(db *sqlx.DB, beritaID int64, tags []string) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM berita_tag_map WHERE berita_id = ?`, beritaID); err != nil {
+		return err
+	}
+	if err := AddBeritaTags(tx, beritaID, tags); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// AddBeritaTags attaches tags to a berita, creating missing tags
+func AddBeritaTags(q sqlx.Ext, beritaID int64, tags []string) error {
+	for _, name := range normalizeTagNames(tags) {
+		if _, err := q.Exec(`INSERT IGNORE INTO berita_tags (name) VALUES (?)`, name); err != nil {
+			return err
+		}
+
+		var tagID int64
+		if err := sqlx.Get(q, &tagID, `SELECT id FROM berita_tags WHERE name = ?`, name); err != nil {
+			return err
+		}
+
+		if _, err := q.Exec(`INSERT IGNORE INTO berita_tag_map (berita_id, tag_id) VALUES (?, ?)`, beritaID, tagID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveBeritaTags detaches tags from a berita
+func RemoveBeritaTags(q sqlx.Ext, beritaID int64, tags []string) error {
+	names := normalizeTagNames(tags)
+	if len(names) == 0 {
+		return nil
+	}
+
+	query, args, err := sqlx.In(`
+		DELETE m FROM berita_tag_map m
+		JOIN berita_tags t ON t.id = m.tag_id
+		WHERE m.berita_id = ? AND t.name IN (?)
+	`, beritaID, names)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.Exec(query, args...)
+	return err
+}
+
+// normalizeTagNames trims tag names and removes empty and duplicate entries
+func normalizeTagNames(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	names := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		name := strings.TrimSpace(tag)
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		names = append(names, name)
+	}
+	return names
+}
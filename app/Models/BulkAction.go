@@ -0,0 +1,208 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Bulk actions supported on berita and agenda
+const (
+	BulkActionPublish     = "publish"
+	BulkActionUnpublish   = "unpublish"
+	BulkActionSetCategory = "set_category" // berita: category, agenda: type
+	BulkActionAddTags     = "add_tags"     // berita only
+	BulkActionRemoveTags  = "remove_tags"  // berita only
+	BulkActionDelete      = "delete"
+	BulkActionRestore     = "restore"
+)
+
+// Bulk item result statuses
+const (
+	BulkStatusUpdated   = "updated"
+	BulkStatusUnchanged = "unchanged"
+	BulkStatusNotFound  = "not_found"
+	BulkStatusForbidden = "forbidden"
+	BulkStatusInvalid   = "invalid"
+)
+
+// bulkTable describes a table that supports bulk actions
+type bulkTable struct {
+	Table          string
+	CategoryColumn string
+	HasTags        bool
+	HasSequence    bool // iCalendar SEQUENCE is bumped on every change
+}
+
+var bulkTables = map[string]bulkTable{
+	"berita": {Table: "berita", CategoryColumn: "category", HasTags: true},
+	"agenda": {Table: "agenda", CategoryColumn: "type", HasSequence: true},
+}
+
+// BulkAction describes an action applied to many items at once
+type BulkAction struct {
+	Action   string
+	Category string   // For set_category
+	Tags     []string // For add_tags / remove_tags
+}
+
+// BulkItemResult is the outcome of a bulk action for a single item
+type BulkItemResult struct {
+	ID      int64   `json:"id"`
+	Success bool    `json:"success"`
+	Status  string  `json:"status"`
+	Message string  `json:"message,omitempty"`
+	Slug    *string `json:"slug,omitempty"` // Set when a restored item got a new slug
+}
+
+// bulkRow is the locked state of an item before the action is applied
+type bulkRow struct {
+	ID        int64      `db:"id"`
+	Slug      string     `db:"slug"`
+	Status    string     `db:"status"`
+	Category  *string    `db:"category"`
+	Cabang    *string    `db:"cabang"`
+	DeletedAt *time.Time `db:"deleted_at"`
+}
+
+// ApplyBulkAction applies an action to many berita or agenda items in a single transaction
+// Items outside the caller's organization scope or not found are reported but do not abort the batch
+func ApplyBulkAction(db *sqlx.DB, entity string, ids []int64, action BulkAction, scope OrgScope) ([]BulkItemResult, error) {
+	bt, ok := bulkTables[entity]
+	if !ok {
+		return nil, fmt.Errorf("bulk actions are not supported for %s", entity)
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Lock all affected rows (including trashed ones for restore)
+	query, args, err := sqlx.In(`SELECT id, slug, status, `+bt.CategoryColumn+` AS category, cabang, deleted_at FROM `+bt.Table+` WHERE id IN (?) FOR UPDATE`, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []bulkRow
+	if err := tx.Select(&rows, query, args...); err != nil {
+		return nil, err
+	}
+
+	rowsByID := make(map[int64]bulkRow, len(rows))
+	for _, row := range rows {
+		rowsByID[row.ID] = row
+	}
+
+	results := make([]BulkItemResult, 0, len(ids))
+	seen := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		row, exists := rowsByID[id]
+		if !exists {
+			results = append(results, BulkItemResult{ID: id, Status: BulkStatusNotFound, Message: "Item not found"})
+			continue
+		}
+
+		if !scope.CanManage(row.Cabang) {
+			results = append(results, BulkItemResult{ID: id, Status: BulkStatusForbidden, Message: "Item is outside your organization scope"})
+			continue
+		}
+
+		result, err := applyBulkActionToRow(tx, bt, row, action)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// applyBulkActionToRow applies an action to a single locked row
+func applyBulkActionToRow(tx *sqlx.Tx, bt bulkTable, row bulkRow, action BulkAction) (BulkItemResult, error) {
+	result := BulkItemResult{ID: row.ID, Success: true, Status: BulkStatusUpdated}
+	now := time.Now()
+
+	// Trashed items can only be restored
+	if row.DeletedAt != nil && action.Action != BulkActionRestore {
+		return BulkItemResult{ID: row.ID, Status: BulkStatusNotFound, Message: "Item is in the trash"}, nil
+	}
+
+	unchanged := func(message string) (BulkItemResult, error) {
+		return BulkItemResult{ID: row.ID, Success: true, Status: BulkStatusUnchanged, Message: message}, nil
+	}
+
+	var err error
+	switch action.Action {
+	case BulkActionPublish:
+		if row.Status == "published" {
+			return unchanged("Already published")
+		}
+		_, err = tx.Exec(`UPDATE `+bt.Table+` SET status = 'published', published_at = COALESCE(published_at, ?), updated_at = ? WHERE id = ?`, now, now, row.ID)
+
+	case BulkActionUnpublish:
+		if row.Status != "published" {
+			return unchanged("Already unpublished")
+		}
+		_, err = tx.Exec(`UPDATE `+bt.Table+` SET status = 'draft', updated_at = ? WHERE id = ?`, now, row.ID)
+
+	case BulkActionSetCategory:
+		if row.Category != nil && *row.Category == action.Category {
+			return unchanged("Category unchanged")
+		}
+		_, err = tx.Exec(`UPDATE `+bt.Table+` SET `+bt.CategoryColumn+` = ?, updated_at = ? WHERE id = ?`, action.Category, now, row.ID)
+
+	case BulkActionAddTags, BulkActionRemoveTags:
+		if !bt.HasTags {
+			return BulkItemResult{ID: row.ID, Status: BulkStatusInvalid, Message: "Tags are not supported"}, nil
+		}
+		if action.Action == BulkActionAddTags {
+			err = AddBeritaTags(tx, row.ID, action.Tags)
+		} else {
+			err = RemoveBeritaTags(tx, row.ID, action.Tags)
+		}
+		if err == nil {
+			_, err = tx.Exec(`UPDATE `+bt.Table+` SET updated_at = ? WHERE id = ?`, now, row.ID)
+		}
+
+	case BulkActionDelete:
+		_, err = tx.Exec(`UPDATE `+bt.Table+` SET deleted_at = ?, updated_at = ? WHERE id = ?`, now, now, row.ID)
+
+	case BulkActionRestore:
+		if row.DeletedAt == nil {
+			return unchanged("Item is not deleted")
+		}
+		var slug string
+		slug, err = restoreWithUniqueSlug(tx, bt.Table, row.ID, row.Slug)
+		if err == nil && slug != row.Slug {
+			result.Slug = &slug
+			result.Message = "Restored with a new slug because the original slug is in use"
+		}
+
+	default:
+		return BulkItemResult{ID: row.ID, Status: BulkStatusInvalid, Message: "Unknown action"}, nil
+	}
+
+	if err != nil {
+		return BulkItemResult{}, err
+	}
+
+	if bt.HasSequence {
+		if _, err := tx.Exec(`UPDATE `+bt.Table+` SET sequence = sequence + 1 WHERE id = ?`, row.ID); err != nil {
+			return BulkItemResult{}, err
+		}
+	}
+
+	return result, nil
+}
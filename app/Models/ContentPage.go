@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"time"
+
+	"github.com/cvudumbarainformatika/backend/utils"
 )
 
 type ContentAuthor struct {
@@ -30,13 +32,37 @@ func (a *ContentAuthors) Scan(value interface{}) error {
 	return json.Unmarshal(b, &a)
 }
 
+// ContentTOC handles JSON marshaling for a generated table of contents
+type ContentTOC []utils.TOCItem
+
+func (t ContentTOC) Value() (driver.Value, error) {
+	if t == nil {
+		return json.Marshal([]utils.TOCItem{})
+	}
+	return json.Marshal(t)
+}
+
+func (t *ContentTOC) Scan(value interface{}) error {
+	if value == nil {
+		*t = ContentTOC{}
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(b, t)
+}
+
 type ContentPage struct {
 	ID          int64          `db:"id" json:"id"`
 	Slug        string         `db:"slug" json:"slug"`
 	Title       string         `db:"title" json:"title"`
 	Description string         `db:"description" json:"description"`
 	Body        string         `db:"body" json:"body"` // Markdown content
-	HTML        string         `db:"html" json:"html"` // HTML content (from WYSIWYG)
+	HTML        string         `db:"html" json:"html"` // Sanitized HTML (rendered from body, or from WYSIWYG)
+	TOC         ContentTOC     `db:"toc" json:"toc"`
+	ReadingTime int            `db:"reading_time" json:"reading_time"` // in minutes
 	Date        time.Time      `db:"date" json:"date"`
 	ImageSrc    string         `db:"image_src" json:"image_src"`
 	BadgeLabel  string         `db:"badge_label" json:"badge_label"`
@@ -2,11 +2,72 @@ package models
 
 import (
 	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
 	"time"
 
 	"github.com/jmoiron/sqlx"
 )
 
+// Direktori types
+const (
+	DirektoriTypeRumahSakit = "rumah_sakit"
+	DirektoriTypeKlinik     = "klinik"
+	DirektoriTypeInstitusi  = "institusi"
+)
+
+// Facilities handles JSON marshaling for the facility list of a direktori entry
+// Facilities are stored trimmed and lowercase so they can be filtered with JSON_CONTAINS
+type Facilities []string
+
+// NormalizeFacilities trims, lowercases and deduplicates facility names, dropping empty ones
+func NormalizeFacilities(names []string) Facilities {
+	facilities := Facilities{}
+	seen := map[string]bool{}
+	for _, name := range names {
+		name = strings.ToLower(strings.Join(strings.Fields(name), " "))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		facilities = append(facilities, name)
+	}
+	return facilities
+}
+
+func (f Facilities) Value() (driver.Value, error) {
+	// Always store an array so JSON_CONTAINS works on every row
+	if f == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (f *Facilities) Scan(value interface{}) error {
+	if value == nil {
+		*f = Facilities{}
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	if err := json.Unmarshal(b, f); err != nil {
+		return err
+	}
+	if *f == nil {
+		*f = Facilities{}
+	}
+	return nil
+}
+
 // Direktori represents a directory entry (hospital, clinic, institution)
 type Direktori struct {
 	ID               int64      `db:"id" json:"id"`
@@ -18,23 +79,43 @@ type Direktori struct {
 	Website          string     `db:"website" json:"website"`
 	City             string     `db:"city" json:"city"`
 	Province         string     `db:"province" json:"province"`
+	Latitude         *float64   `db:"latitude" json:"latitude"`
+	Longitude        *float64   `db:"longitude" json:"longitude"`
 	HasRespirologist bool       `db:"has_respirologist" json:"has_respirologist"`
-	Facilities       string     `db:"facilities" json:"facilities"`
+	Facilities       Facilities `db:"facilities" json:"facilities"`
+	VerifiedAt       *time.Time `db:"verified_at" json:"verified_at"`
+	VerifiedBy       *int64     `db:"verified_by" json:"verified_by"`
 	CreatedAt        time.Time  `db:"created_at" json:"created_at"`
 	UpdatedAt        time.Time  `db:"updated_at" json:"updated_at"`
 	DeletedAt        *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
 }
 
+// DirektoriDistance is a direktori entry with its distance from a search point
+type DirektoriDistance struct {
+	Direktori
+	DistanceKm float64 `db:"distance_km" json:"distance_km"`
+}
+
+// kmPerDegree is the length of one degree of latitude
+const kmPerDegree = 111.045
+
+const direktoriColumns = `id, name, type, address, phone, email, website, city, province, latitude, longitude, has_respirologist, facilities, verified_at, verified_by, created_at, updated_at, deleted_at`
+
 // Create creates a new direktori record
 func (d *Direktori) Create(db *sqlx.DB) error {
+	return d.insert(db)
+}
+
+// insert creates the direktori record with a database or transaction
+func (d *Direktori) insert(exec sqlx.Execer) error {
 	d.CreatedAt = time.Now()
 	d.UpdatedAt = time.Now()
 
 	query := `
-		INSERT INTO direktori (name, type, address, phone, email, website, city, province, has_respirologist, facilities, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO direktori (name, type, address, phone, email, website, city, province, latitude, longitude, has_respirologist, facilities, verified_at, verified_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
-	result, err := db.Exec(query, d.Name, d.Type, d.Address, d.Phone, d.Email, d.Website, d.City, d.Province, d.HasRespirologist, d.Facilities, d.CreatedAt, d.UpdatedAt)
+	result, err := exec.Exec(query, d.Name, d.Type, d.Address, d.Phone, d.Email, d.Website, d.City, d.Province, d.Latitude, d.Longitude, d.HasRespirologist, d.Facilities, d.VerifiedAt, d.VerifiedBy, d.CreatedAt, d.UpdatedAt)
 	if err != nil {
 		return err
 	}
@@ -51,8 +132,8 @@ func (d *Direktori) Create(db *sqlx.DB) error {
 func FindDirektoriByID(db *sqlx.DB, id int64) (*Direktori, error) {
 	direktori := &Direktori{}
 	query := `
-		SELECT id, name, type, address, phone, email, website, city, province, has_respirologist, facilities, created_at, updated_at, deleted_at 
-		FROM direktori 
+		SELECT ` + direktoriColumns + `
+		FROM direktori
 		WHERE id = ? AND deleted_at IS NULL
 	`
 	err := db.Get(direktori, query, id)
@@ -65,44 +146,71 @@ func FindDirektoriByID(db *sqlx.DB, id int64) (*Direktori, error) {
 	return direktori, nil
 }
 
-// GetAllDirektori retrieves all direktori with filters and pagination
-func GetAllDirektori(db *sqlx.DB, filters map[string]interface{}, offset int, limit int) ([]Direktori, int64, error) {
-	var direktori []Direktori
-
-	query := `SELECT id, name, type, address, phone, email, website, city, province, has_respirologist, facilities, created_at, updated_at, deleted_at FROM direktori WHERE deleted_at IS NULL`
-	countQuery := `SELECT COUNT(*) FROM direktori WHERE deleted_at IS NULL`
+// GetDirektoriByIDs retrieves the direktori with the given IDs keyed by ID (excluding deleted)
+func GetDirektoriByIDs(db *sqlx.DB, ids []int64) (map[int64]Direktori, error) {
+	byID := map[int64]Direktori{}
+	if len(ids) == 0 {
+		return byID, nil
+	}
 
-	args := []interface{}{}
+	query, args, err := sqlx.In(`SELECT `+direktoriColumns+` FROM direktori WHERE id IN (?) AND deleted_at IS NULL`, ids)
+	if err != nil {
+		return nil, err
+	}
 
-	if province, ok := filters["province"].(string); ok && province != "" {
-		query += ` AND province = ?`
-		countQuery += ` AND province = ?`
-		args = append(args, province)
+	direktori := []Direktori{}
+	if err := db.Select(&direktori, db.Rebind(query), args...); err != nil {
+		return nil, err
 	}
-	if dirType, ok := filters["type"].(string); ok && dirType != "" {
-		query += ` AND type = ?`
-		countQuery += ` AND type = ?`
-		args = append(args, dirType)
+	for _, entry := range direktori {
+		byID[entry.ID] = entry
 	}
-	if search, ok := filters["search"].(string); ok && search != "" {
-		query += ` AND (name LIKE ? OR address LIKE ?)`
-		countQuery += ` AND (name LIKE ? OR address LIKE ?)`
-		searchTerm := "%" + search + "%"
-		args = append(args, searchTerm, searchTerm)
+	return byID, nil
+}
+
+// FindDirektoriByNameCity finds a direktori by its natural key, name and city (excluding deleted)
+// The table collation compares them case-insensitively
+func FindDirektoriByNameCity(db *sqlx.DB, name string, city string) (*Direktori, error) {
+	direktori := &Direktori{}
+	query := `
+		SELECT ` + direktoriColumns + `
+		FROM direktori
+		WHERE name = ? AND city = ? AND deleted_at IS NULL
+		ORDER BY id ASC
+		LIMIT 1
+	`
+	err := db.Get(direktori, query, name, city)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
 	}
+	return direktori, nil
+}
+
+// GetAllDirektori retrieves all direktori with filters and pagination
+// Supported filters: see applyDirektoriFilters
+func GetAllDirektori(db *sqlx.DB, filters map[string]interface{}, offset int, limit int) ([]Direktori, int64, error) {
+	direktori := []Direktori{}
+
+	where, args := applyDirektoriFilters(` WHERE deleted_at IS NULL`, []interface{}{}, filters)
 
 	// Get total count
 	var total int64
-	err := db.Get(&total, countQuery, args...)
+	err := db.Get(&total, `SELECT COUNT(*) FROM direktori`+where, args...)
 	if err != nil {
 		return nil, 0, err
 	}
 
-	// Add sorting and pagination
-	query += ` ORDER BY created_at DESC LIMIT ? OFFSET ?`
-	paginationArgs := append(args, limit, offset)
+	// Add sorting and pagination, a limit of 0 returns every matching entry
+	query := `SELECT ` + direktoriColumns + ` FROM direktori` + where + ` ORDER BY created_at DESC`
+	if limit > 0 {
+		query += ` LIMIT ? OFFSET ?`
+		args = append(args, limit, offset)
+	}
 
-	err = db.Select(&direktori, query, paginationArgs...)
+	err = db.Select(&direktori, query, args...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -110,15 +218,109 @@ func GetAllDirektori(db *sqlx.DB, filters map[string]interface{}, offset int, li
 	return direktori, total, nil
 }
 
+// GetNearbyDirektori retrieves direktori within radiusKm of a point, nearest first, with filters and pagination
+// A bounding box on the coordinate index narrows the rows before the haversine distance is computed
+func GetNearbyDirektori(db *sqlx.DB, lat float64, lng float64, radiusKm float64, filters map[string]interface{}, offset int, limit int) ([]DirektoriDistance, int64, error) {
+	direktori := []DirektoriDistance{}
+
+	latDelta := radiusKm / kmPerDegree
+	lngDelta := radiusKm / (kmPerDegree * math.Max(math.Cos(lat*math.Pi/180), 0.01))
+
+	where := ` WHERE deleted_at IS NULL AND latitude BETWEEN ? AND ? AND longitude BETWEEN ? AND ?`
+	args := []interface{}{lat - latDelta, lat + latDelta, lng - lngDelta, lng + lngDelta}
+	where, args = applyDirektoriFilters(where, args, filters)
+
+	// Haversine distance in km on a sphere with the mean Earth radius (6371 km)
+	distance := `(6371 * 2 * ASIN(SQRT(
+		POWER(SIN(RADIANS(latitude - ?) / 2), 2) +
+		COS(RADIANS(?)) * COS(RADIANS(latitude)) * POWER(SIN(RADIANS(longitude - ?) / 2), 2))))`
+	distanceArgs := []interface{}{lat, lat, lng}
+
+	where += ` AND ` + distance + ` <= ?`
+	args = append(args, append(distanceArgs, radiusKm)...)
+
+	var total int64
+	if err := db.Get(&total, `SELECT COUNT(*) FROM direktori`+where, args...); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + direktoriColumns + `, ` + distance + ` AS distance_km FROM direktori` + where + ` ORDER BY distance_km ASC, id ASC LIMIT ? OFFSET ?`
+	queryArgs := append(append(append([]interface{}{}, distanceArgs...), args...), limit, offset)
+	if err := db.Select(&direktori, query, queryArgs...); err != nil {
+		return nil, 0, err
+	}
+
+	return direktori, total, nil
+}
+
+// GetMappedDirektori retrieves direktori with coordinates for the map layer, with filters
+func GetMappedDirektori(db *sqlx.DB, filters map[string]interface{}, limit int) ([]Direktori, error) {
+	direktori := []Direktori{}
+
+	where, args := applyDirektoriFilters(` WHERE deleted_at IS NULL AND latitude IS NOT NULL AND longitude IS NOT NULL`, []interface{}{}, filters)
+	query := `SELECT ` + direktoriColumns + ` FROM direktori` + where + ` ORDER BY id ASC LIMIT ?`
+
+	err := db.Select(&direktori, query, append(args, limit)...)
+	return direktori, err
+}
+
+// applyDirektoriFilters appends the list filters to a WHERE clause
+// Supported filters: province, city, type, search, has_respirologist (bool) and facilities (Facilities, all must be present)
+func applyDirektoriFilters(where string, args []interface{}, filters map[string]interface{}) (string, []interface{}) {
+	if province, ok := filters["province"].(string); ok && province != "" {
+		where += ` AND province = ?`
+		args = append(args, province)
+	}
+	if dirType, ok := filters["type"].(string); ok && dirType != "" {
+		where += ` AND type = ?`
+		args = append(args, dirType)
+	}
+	if city, ok := filters["city"].(string); ok && city != "" {
+		where += ` AND city = ?`
+		args = append(args, city)
+	}
+	if search, ok := filters["search"].(string); ok && search != "" {
+		where += ` AND (name LIKE ? OR address LIKE ? OR city LIKE ?)`
+		searchTerm := "%" + search + "%"
+		args = append(args, searchTerm, searchTerm, searchTerm)
+	}
+	if hasRespirologist, ok := filters["has_respirologist"].(bool); ok {
+		where += ` AND has_respirologist = ?`
+		args = append(args, hasRespirologist)
+	}
+	if facilities, ok := filters["facilities"].(Facilities); ok && len(facilities) > 0 {
+		if candidate, err := json.Marshal(facilities); err == nil {
+			where += ` AND JSON_CONTAINS(facilities, ?)`
+			args = append(args, string(candidate))
+		}
+	}
+	return where, args
+}
+
 // Update updates a direktori record
 func (d *Direktori) Update(db *sqlx.DB) error {
+	return d.update(db)
+}
+
+// update updates the direktori record with a database or transaction, including its verification
+func (d *Direktori) update(exec sqlx.Execer) error {
 	d.UpdatedAt = time.Now()
 	query := `
 		UPDATE direktori 
-		SET name = ?, type = ?, address = ?, phone = ?, email = ?, website = ?, city = ?, province = ?, has_respirologist = ?, facilities = ?, updated_at = ?
+		SET name = ?, type = ?, address = ?, phone = ?, email = ?, website = ?, city = ?, province = ?, latitude = ?, longitude = ?, has_respirologist = ?, facilities = ?, verified_at = ?, verified_by = ?, updated_at = ?
 		WHERE id = ? AND deleted_at IS NULL
 	`
-	_, err := db.Exec(query, d.Name, d.Type, d.Address, d.Phone, d.Email, d.Website, d.City, d.Province, d.HasRespirologist, d.Facilities, d.UpdatedAt, d.ID)
+	_, err := exec.Exec(query, d.Name, d.Type, d.Address, d.Phone, d.Email, d.Website, d.City, d.Province, d.Latitude, d.Longitude, d.HasRespirologist, d.Facilities, d.VerifiedAt, d.VerifiedBy, d.UpdatedAt, d.ID)
+	return err
+}
+
+// MarkVerified records that an admin has checked the entry
+func (d *Direktori) MarkVerified(db *sqlx.DB, userID int64) error {
+	now := time.Now()
+	d.VerifiedAt = &now
+	d.VerifiedBy = &userID
+	d.UpdatedAt = now
+	_, err := db.Exec(`UPDATE direktori SET verified_at = ?, verified_by = ?, updated_at = ? WHERE id = ? AND deleted_at IS NULL`, d.VerifiedAt, d.VerifiedBy, d.UpdatedAt, d.ID)
 	return err
 }
 
@@ -0,0 +1,196 @@
+package models
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Direktori import statuses
+const (
+	DirektoriImportQueued    = "queued"
+	DirektoriImportRunning   = "running"
+	DirektoriImportCompleted = "completed"
+	DirektoriImportFailed    = "failed"
+)
+
+// MaxDirektoriImportErrors caps the row errors kept on an import, error_count still counts all of them
+const MaxDirektoriImportErrors = 500
+
+// ImportMapping handles JSON marshaling for the column mapping of an import, direktori field to column header
+type ImportMapping map[string]string
+
+func (m ImportMapping) Value() (driver.Value, error) {
+	if m == nil {
+		return "{}", nil
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (m *ImportMapping) Scan(value interface{}) error {
+	if value == nil {
+		*m = ImportMapping{}
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(b, m)
+}
+
+// ImportRowError is a validation error of one spreadsheet row
+type ImportRowError struct {
+	Row     int    `json:"row"` // Spreadsheet row number, the header is row 1
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportRowErrors handles JSON marshaling for the row errors of an import
+type ImportRowErrors []ImportRowError
+
+func (e ImportRowErrors) Value() (driver.Value, error) {
+	if e == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (e *ImportRowErrors) Scan(value interface{}) error {
+	if value == nil {
+		*e = ImportRowErrors{}
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(b, e)
+}
+
+// DirektoriImport is a spreadsheet import of direktori and its progress
+type DirektoriImport struct {
+	ID            int64           `db:"id" json:"id"`
+	UserID        *int64          `db:"user_id" json:"user_id"`
+	Filename      string          `db:"filename" json:"filename"`
+	Format        string          `db:"format" json:"format"`
+	Mapping       ImportMapping   `db:"mapping" json:"mapping"`
+	DryRun        bool            `db:"dry_run" json:"dry_run"`
+	Status        string          `db:"status" json:"status"`
+	TotalRows     int             `db:"total_rows" json:"total_rows"`
+	ProcessedRows int             `db:"processed_rows" json:"processed_rows"`
+	CreatedCount  int             `db:"created_count" json:"created_count"`
+	UpdatedCount  int             `db:"updated_count" json:"updated_count"`
+	ErrorCount    int             `db:"error_count" json:"error_count"`
+	Errors        ImportRowErrors `db:"errors" json:"errors"`
+	Message       *string         `db:"message" json:"message"`
+	StartedAt     *time.Time      `db:"started_at" json:"started_at"`
+	FinishedAt    *time.Time      `db:"finished_at" json:"finished_at"`
+	CreatedAt     time.Time       `db:"created_at" json:"created_at"`
+	UpdatedAt     time.Time       `db:"updated_at" json:"updated_at"`
+}
+
+// AddError records a row error, keeping at most MaxDirektoriImportErrors of them
+func (i *DirektoriImport) AddError(row int, field string, message string) {
+	i.ErrorCount++
+	if len(i.Errors) < MaxDirektoriImportErrors {
+		i.Errors = append(i.Errors, ImportRowError{Row: row, Field: field, Message: message})
+	}
+}
+
+// Create creates a new queued direktori import record
+func (i *DirektoriImport) Create(db *sqlx.DB) error {
+	i.Status = DirektoriImportQueued
+	i.CreatedAt = time.Now()
+	i.UpdatedAt = time.Now()
+
+	query := `
+		INSERT INTO direktori_imports (user_id, filename, format, mapping, dry_run, status, total_rows, errors, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	result, err := db.Exec(query, i.UserID, i.Filename, i.Format, i.Mapping, i.DryRun, i.Status, i.TotalRows, i.Errors, i.CreatedAt, i.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	i.ID = id
+	return nil
+}
+
+// Start marks the import as running
+func (i *DirektoriImport) Start(db *sqlx.DB) error {
+	now := time.Now()
+	i.Status = DirektoriImportRunning
+	i.StartedAt = &now
+	i.UpdatedAt = now
+	_, err := db.Exec(`UPDATE direktori_imports SET status = ?, started_at = ?, updated_at = ? WHERE id = ?`, i.Status, i.StartedAt, i.UpdatedAt, i.ID)
+	return err
+}
+
+// SaveProgress stores the counters and row errors of a running import
+func (i *DirektoriImport) SaveProgress(db *sqlx.DB) error {
+	i.UpdatedAt = time.Now()
+	query := `
+		UPDATE direktori_imports
+		SET processed_rows = ?, created_count = ?, updated_count = ?, error_count = ?, errors = ?, updated_at = ?
+		WHERE id = ?
+	`
+	_, err := db.Exec(query, i.ProcessedRows, i.CreatedCount, i.UpdatedCount, i.ErrorCount, i.Errors, i.UpdatedAt, i.ID)
+	return err
+}
+
+// Finish stores the final counters of the import, a non-nil importErr marks it as failed
+func (i *DirektoriImport) Finish(db *sqlx.DB, importErr error) error {
+	now := time.Now()
+	i.Status = DirektoriImportCompleted
+	i.Message = nil
+	if importErr != nil {
+		message := importErr.Error()
+		i.Status = DirektoriImportFailed
+		i.Message = &message
+	}
+	i.FinishedAt = &now
+	i.UpdatedAt = now
+
+	query := `
+		UPDATE direktori_imports
+		SET status = ?, processed_rows = ?, created_count = ?, updated_count = ?, error_count = ?, errors = ?, message = ?, finished_at = ?, updated_at = ?
+		WHERE id = ?
+	`
+	_, err := db.Exec(query, i.Status, i.ProcessedRows, i.CreatedCount, i.UpdatedCount, i.ErrorCount, i.Errors, i.Message, i.FinishedAt, i.UpdatedAt, i.ID)
+	return err
+}
+
+// FindDirektoriImportByID finds a direktori import by ID
+func FindDirektoriImportByID(db *sqlx.DB, id int64) (*DirektoriImport, error) {
+	directoryImport := &DirektoriImport{}
+	query := `
+		SELECT id, user_id, filename, format, mapping, dry_run, status, total_rows, processed_rows, created_count, updated_count, error_count, errors, message, started_at, finished_at, created_at, updated_at
+		FROM direktori_imports
+		WHERE id = ?
+	`
+	err := db.Get(directoryImport, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return directoryImport, nil
+}
@@ -0,0 +1,370 @@
+package models
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Direktori submission kinds
+const (
+	SubmissionKindNew        = "new"
+	SubmissionKindCorrection = "correction"
+)
+
+// Direktori submission statuses
+const (
+	SubmissionStatusPending  = "pending"
+	SubmissionStatusApproved = "approved"
+	SubmissionStatusRejected = "rejected"
+)
+
+var (
+	ErrSubmissionNotFound   = errors.New("submission not found")
+	ErrSubmissionNotPending = errors.New("submission has already been reviewed")
+	ErrDirektoriNotFound    = errors.New("direktori not found")
+	ErrDirektoriExists      = errors.New("a direktori with this name already exists in this city")
+)
+
+// DirektoriProposal holds the fields a member proposes, nil fields are left as they are
+type DirektoriProposal struct {
+	Name             *string     `json:"name,omitempty"`
+	Type             *string     `json:"type,omitempty"`
+	Address          *string     `json:"address,omitempty"`
+	Phone            *string     `json:"phone,omitempty"`
+	Email            *string     `json:"email,omitempty"`
+	Website          *string     `json:"website,omitempty"`
+	City             *string     `json:"city,omitempty"`
+	Province         *string     `json:"province,omitempty"`
+	Latitude         *float64    `json:"latitude,omitempty"`
+	Longitude        *float64    `json:"longitude,omitempty"`
+	HasRespirologist *bool       `json:"has_respirologist,omitempty"`
+	Facilities       *Facilities `json:"facilities,omitempty"`
+}
+
+func (p DirektoriProposal) Value() (driver.Value, error) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (p *DirektoriProposal) Scan(value interface{}) error {
+	if value == nil {
+		*p = DirektoriProposal{}
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(b, p)
+}
+
+// Apply copies the proposed fields into a direktori entry
+func (p DirektoriProposal) Apply(d *Direktori) {
+	if p.Name != nil {
+		d.Name = *p.Name
+	}
+	if p.Type != nil {
+		d.Type = *p.Type
+	}
+	if p.Address != nil {
+		d.Address = *p.Address
+	}
+	if p.Phone != nil {
+		d.Phone = *p.Phone
+	}
+	if p.Email != nil {
+		d.Email = *p.Email
+	}
+	if p.Website != nil {
+		d.Website = *p.Website
+	}
+	if p.City != nil {
+		d.City = *p.City
+	}
+	if p.Province != nil {
+		d.Province = *p.Province
+	}
+	if p.Latitude != nil && p.Longitude != nil {
+		latitude, longitude := *p.Latitude, *p.Longitude
+		d.Latitude = &latitude
+		d.Longitude = &longitude
+	}
+	if p.HasRespirologist != nil {
+		d.HasRespirologist = *p.HasRespirologist
+	}
+	if p.Facilities != nil {
+		d.Facilities = NormalizeFacilities(*p.Facilities)
+	}
+}
+
+// DirektoriChange is one field of a submission that differs from the current entry
+type DirektoriChange struct {
+	Field    string      `json:"field"`
+	Current  interface{} `json:"current"`
+	Proposed interface{} `json:"proposed"`
+}
+
+// Diff lists the fields the proposal changes on the current entry, current is nil for a new entry
+func (p DirektoriProposal) Diff(current *Direktori) []DirektoriChange {
+	before := Direktori{Facilities: Facilities{}}
+	if current != nil {
+		before = *current
+	}
+	after := before
+	p.Apply(&after)
+
+	fields := []struct {
+		name             string
+		current, updated interface{}
+	}{
+		{"name", before.Name, after.Name},
+		{"type", before.Type, after.Type},
+		{"address", before.Address, after.Address},
+		{"phone", before.Phone, after.Phone},
+		{"email", before.Email, after.Email},
+		{"website", before.Website, after.Website},
+		{"city", before.City, after.City},
+		{"province", before.Province, after.Province},
+		{"latitude", before.Latitude, after.Latitude},
+		{"longitude", before.Longitude, after.Longitude},
+		{"has_respirologist", before.HasRespirologist, after.HasRespirologist},
+		{"facilities", before.Facilities, after.Facilities},
+	}
+
+	changes := []DirektoriChange{}
+	for _, field := range fields {
+		if current != nil && reflect.DeepEqual(field.current, field.updated) {
+			continue
+		}
+		change := DirektoriChange{Field: field.name, Proposed: field.updated}
+		if current != nil {
+			change.Current = field.current
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// DirektoriSubmission is a member proposal for a new direktori entry or a correction of one
+type DirektoriSubmission struct {
+	ID           int64             `db:"id" json:"id"`
+	Kind         string            `db:"kind" json:"kind"`
+	DirektoriID  *int64            `db:"direktori_id" json:"direktori_id"` // Set for a new entry once it is approved
+	UserID       *int64            `db:"user_id" json:"user_id"`
+	Proposal     DirektoriProposal `db:"proposal" json:"proposal"`
+	Note         *string           `db:"note" json:"note"`
+	Status       string            `db:"status" json:"status"`
+	RejectReason *string           `db:"reject_reason" json:"reject_reason"`
+	ReviewedBy   *int64            `db:"reviewed_by" json:"reviewed_by"`
+	ReviewedAt   *time.Time        `db:"reviewed_at" json:"reviewed_at"`
+	CreatedAt    time.Time         `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time         `db:"updated_at" json:"updated_at"`
+}
+
+// DirektoriSubmissionDetail is a submission joined with its submitter and reviewer
+type DirektoriSubmissionDetail struct {
+	DirektoriSubmission
+	UserName     *string `db:"user_name" json:"user_name"`
+	ReviewerName *string `db:"reviewer_name" json:"reviewer_name"`
+}
+
+const direktoriSubmissionSelect = `
+	SELECT s.id, s.kind, s.direktori_id, s.user_id, s.proposal, s.note, s.status, s.reject_reason, s.reviewed_by, s.reviewed_at, s.created_at, s.updated_at,
+		u.name AS user_name, r.name AS reviewer_name
+	FROM direktori_submissions s
+	LEFT JOIN users u ON u.id = s.user_id
+	LEFT JOIN users r ON r.id = s.reviewed_by`
+
+// Create creates a new pending submission, its kind follows from DirektoriID
+func (s *DirektoriSubmission) Create(db *sqlx.DB) error {
+	s.Kind = SubmissionKindNew
+	if s.DirektoriID != nil {
+		s.Kind = SubmissionKindCorrection
+	}
+	s.Status = SubmissionStatusPending
+	s.CreatedAt = time.Now()
+	s.UpdatedAt = s.CreatedAt
+
+	result, err := db.Exec(`
+		INSERT INTO direktori_submissions (kind, direktori_id, user_id, proposal, note, status, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, s.Kind, s.DirektoriID, s.UserID, s.Proposal, s.Note, s.Status, s.CreatedAt, s.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	s.ID = id
+	return nil
+}
+
+// FindDirektoriSubmissionByID finds a submission with its submitter and reviewer
+func FindDirektoriSubmissionByID(db *sqlx.DB, id int64) (*DirektoriSubmissionDetail, error) {
+	submission := &DirektoriSubmissionDetail{}
+	err := db.Get(submission, direktoriSubmissionSelect+` WHERE s.id = ?`, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return submission, nil
+}
+
+// CountPendingDirektoriSubmissions counts the pending submissions of a member for one entry,
+// or the proposed new entries when direktoriID is nil
+func CountPendingDirektoriSubmissions(db *sqlx.DB, userID int64, direktoriID *int64) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM direktori_submissions WHERE user_id = ? AND status = ?`
+	args := []interface{}{userID, SubmissionStatusPending}
+	if direktoriID != nil {
+		query += ` AND kind = ? AND direktori_id = ?`
+		args = append(args, SubmissionKindCorrection, *direktoriID)
+	} else {
+		query += ` AND kind = ?`
+		args = append(args, SubmissionKindNew)
+	}
+	err := db.Get(&count, query, args...)
+	return count, err
+}
+
+// GetDirektoriSubmissions retrieves submissions with optional filters (status, kind, user_id, direktori_id) and pagination
+func GetDirektoriSubmissions(db *sqlx.DB, filters map[string]interface{}, offset int, limit int) ([]DirektoriSubmissionDetail, int64, error) {
+	submissions := []DirektoriSubmissionDetail{}
+	var total int64
+
+	where := ` WHERE 1 = 1`
+	args := []interface{}{}
+
+	if status, ok := filters["status"].(string); ok && status != "" {
+		where += ` AND s.status = ?`
+		args = append(args, status)
+	}
+	if kind, ok := filters["kind"].(string); ok && kind != "" {
+		where += ` AND s.kind = ?`
+		args = append(args, kind)
+	}
+	if userID, ok := filters["user_id"].(int64); ok && userID > 0 {
+		where += ` AND s.user_id = ?`
+		args = append(args, userID)
+	}
+	if direktoriID, ok := filters["direktori_id"].(int64); ok && direktoriID > 0 {
+		where += ` AND s.direktori_id = ?`
+		args = append(args, direktoriID)
+	}
+
+	if err := db.Get(&total, `SELECT COUNT(*) FROM direktori_submissions s`+where, args...); err != nil {
+		return nil, 0, err
+	}
+
+	// The queue is worked oldest first
+	query := direktoriSubmissionSelect + where + ` ORDER BY s.created_at ASC, s.id ASC LIMIT ? OFFSET ?`
+	args = append(args, limit, offset)
+	if err := db.Select(&submissions, query, args...); err != nil {
+		return nil, 0, err
+	}
+
+	return submissions, total, nil
+}
+
+// ApproveDirektoriSubmission applies a pending submission and marks the entry as verified by the reviewer
+// A proposed new entry is created unless an entry with the same name and city exists by now
+func ApproveDirektoriSubmission(db *sqlx.DB, submissionID int64, reviewerID int64) (*Direktori, error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	submission := &DirektoriSubmission{}
+	err = tx.Get(submission, `
+		SELECT id, kind, direktori_id, user_id, proposal, note, status, reject_reason, reviewed_by, reviewed_at, created_at, updated_at
+		FROM direktori_submissions WHERE id = ? FOR UPDATE
+	`, submissionID)
+	if err == sql.ErrNoRows {
+		return nil, ErrSubmissionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if submission.Status != SubmissionStatusPending {
+		return nil, ErrSubmissionNotPending
+	}
+
+	now := time.Now()
+	direktori := &Direktori{Facilities: Facilities{}}
+
+	if submission.Kind == SubmissionKindNew {
+		submission.Proposal.Apply(direktori)
+
+		var existing int
+		if err := tx.Get(&existing, `SELECT COUNT(*) FROM direktori WHERE name = ? AND city = ? AND deleted_at IS NULL`, direktori.Name, direktori.City); err != nil {
+			return nil, err
+		}
+		if existing > 0 {
+			return nil, ErrDirektoriExists
+		}
+
+		direktori.VerifiedAt = &now
+		direktori.VerifiedBy = &reviewerID
+		if err := direktori.insert(tx); err != nil {
+			return nil, err
+		}
+	} else {
+		err := tx.Get(direktori, `SELECT `+direktoriColumns+` FROM direktori WHERE id = ? AND deleted_at IS NULL FOR UPDATE`, *submission.DirektoriID)
+		if err == sql.ErrNoRows {
+			return nil, ErrDirektoriNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		submission.Proposal.Apply(direktori)
+		direktori.VerifiedAt = &now
+		direktori.VerifiedBy = &reviewerID
+		if err := direktori.update(tx); err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.Exec(`
+		UPDATE direktori_submissions SET direktori_id = ?, status = ?, reviewed_by = ?, reviewed_at = ?, updated_at = ?
+		WHERE id = ?
+	`, direktori.ID, SubmissionStatusApproved, reviewerID, now, now, submission.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return direktori, nil
+}
+
+// RejectDirektoriSubmission rejects a pending submission with the reason shown to the member
+func RejectDirektoriSubmission(db *sqlx.DB, submissionID int64, reviewerID int64, reason string) error {
+	now := time.Now()
+	result, err := db.Exec(`
+		UPDATE direktori_submissions SET status = ?, reject_reason = ?, reviewed_by = ?, reviewed_at = ?, updated_at = ?
+		WHERE id = ? AND status = ?
+	`, SubmissionStatusRejected, reason, reviewerID, now, now, submissionID, SubmissionStatusPending)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ErrSubmissionNotPending
+	}
+	return nil
+}
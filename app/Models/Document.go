@@ -2,35 +2,65 @@ package models
 
 import (
 	"database/sql"
+	"errors"
 	"time"
 
 	"github.com/jmoiron/sqlx"
 )
 
+// Document statuses
+const (
+	DocumentStatusPending  = "pending"
+	DocumentStatusValid    = "valid"
+	DocumentStatusRejected = "rejected"
+	DocumentStatusExpired  = "expired"
+)
+
+// ErrDocumentNotPending is returned when reviewing a document that has already been reviewed
+var ErrDocumentNotPending = errors.New("document has already been reviewed")
+
 // Document represents a user document (STR, SIP, etc.)
+// FileURL points into the private dokumen storage, the file is only served through the document endpoints
 type Document struct {
-	ID         int64      `db:"id" json:"id"`
-	UserID     int64      `db:"user_id" json:"user_id"`
-	Name       string     `db:"name" json:"name"`
-	Type       string     `db:"type" json:"type"`
-	ValidUntil *time.Time `db:"valid_until" json:"valid_until"`
-	Status     string     `db:"status" json:"status"`
-	FileURL    string     `db:"file_url" json:"file_url"`
-	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
-	UpdatedAt  time.Time  `db:"updated_at" json:"updated_at"`
-	DeletedAt  *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+	ID           int64      `db:"id" json:"id"`
+	UserID       int64      `db:"user_id" json:"user_id"`
+	Name         string     `db:"name" json:"name"`
+	Type         string     `db:"type" json:"type"`
+	ValidUntil   *time.Time `db:"valid_until" json:"valid_until"` // NULL for documents valid for life
+	Status       string     `db:"status" json:"status"`
+	RejectReason *string    `db:"reject_reason" json:"reject_reason"`
+	ReviewedBy   *int64     `db:"reviewed_by" json:"reviewed_by"`
+	ReviewedAt   *time.Time `db:"reviewed_at" json:"reviewed_at"`
+	FileURL      string     `db:"file_url" json:"-"`
+	FileName     *string    `db:"file_name" json:"file_name"`
+	CreatedAt    time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt    time.Time  `db:"updated_at" json:"updated_at"`
+	DeletedAt    *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
 }
 
-// Create creates a new document record
+// DocumentDetail is a document with the member who owns it, as shown in the moderation queue
+type DocumentDetail struct {
+	Document
+	UserName   string  `db:"user_name" json:"user_name"`
+	UserEmail  string  `db:"user_email" json:"user_email"`
+	UserCabang *string `db:"user_cabang" json:"user_cabang"`
+}
+
+const documentColumns = `d.id, d.user_id, d.name, d.type, d.valid_until, d.status, d.reject_reason, d.reviewed_by, d.reviewed_at, d.file_url, d.file_name, d.created_at, d.updated_at, d.deleted_at`
+
+const documentDetailColumns = documentColumns + `, u.name AS user_name, u.email AS user_email, u.cabang AS user_cabang`
+
+// Create creates a new document record, pending review
 func (d *Document) Create(db *sqlx.DB) error {
+	d.Status = DocumentStatusPending
 	d.CreatedAt = time.Now()
 	d.UpdatedAt = time.Now()
 
 	query := `
-		INSERT INTO documents (user_id, name, type, valid_until, status, file_url, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO documents (user_id, name, type, valid_until, status, file_url, file_name, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
-	result, err := db.Exec(query, d.UserID, d.Name, d.Type, d.ValidUntil, d.Status, d.FileURL, d.CreatedAt, d.UpdatedAt)
+	result, err := db.Exec(query, d.UserID, d.Name, d.Type, d.ValidUntil, d.Status, d.FileURL, d.FileName, d.CreatedAt, d.UpdatedAt)
 	if err != nil {
 		return err
 	}
@@ -46,11 +76,21 @@ func (d *Document) Create(db *sqlx.DB) error {
 // FindByID finds a document by ID (excluding deleted)
 func FindDocumentByID(db *sqlx.DB, id int64) (*Document, error) {
 	document := &Document{}
-	query := `
-		SELECT id, user_id, name, type, valid_until, status, file_url, created_at, updated_at, deleted_at 
-		FROM documents 
-		WHERE id = ? AND deleted_at IS NULL
-	`
+	query := `SELECT ` + documentColumns + ` FROM documents d WHERE d.id = ? AND d.deleted_at IS NULL`
+	err := db.Get(document, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return document, nil
+}
+
+// FindDocumentDetailByID finds a document with its owner by ID (excluding deleted)
+func FindDocumentDetailByID(db *sqlx.DB, id int64) (*DocumentDetail, error) {
+	document := &DocumentDetail{}
+	query := `SELECT ` + documentDetailColumns + ` FROM documents d JOIN users u ON u.id = d.user_id WHERE d.id = ? AND d.deleted_at IS NULL`
 	err := db.Get(document, query, id)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -65,21 +105,21 @@ func FindDocumentByID(db *sqlx.DB, id int64) (*Document, error) {
 func GetUserDocuments(db *sqlx.DB, userID int64, filters map[string]interface{}, offset int, limit int) ([]Document, int64, error) {
 	var documents []Document
 
-	query := `SELECT id, user_id, name, type, valid_until, status, file_url, created_at, updated_at, deleted_at FROM documents WHERE user_id = ? AND deleted_at IS NULL`
-	countQuery := `SELECT COUNT(*) FROM documents WHERE user_id = ? AND deleted_at IS NULL`
+	query := `SELECT ` + documentColumns + ` FROM documents d WHERE d.user_id = ? AND d.deleted_at IS NULL`
+	countQuery := `SELECT COUNT(*) FROM documents d WHERE d.user_id = ? AND d.deleted_at IS NULL`
 
 	args := []interface{}{userID}
 	countArgs := []interface{}{userID}
 
 	if docType, ok := filters["type"].(string); ok && docType != "" {
-		query += ` AND type = ?`
-		countQuery += ` AND type = ?`
+		query += ` AND d.type = ?`
+		countQuery += ` AND d.type = ?`
 		args = append(args, docType)
 		countArgs = append(countArgs, docType)
 	}
 	if status, ok := filters["status"].(string); ok && status != "" {
-		query += ` AND status = ?`
-		countQuery += ` AND status = ?`
+		query += ` AND d.status = ?`
+		countQuery += ` AND d.status = ?`
 		args = append(args, status)
 		countArgs = append(countArgs, status)
 	}
@@ -92,7 +132,7 @@ func GetUserDocuments(db *sqlx.DB, userID int64, filters map[string]interface{},
 	}
 
 	// Add sorting and pagination
-	query += ` ORDER BY created_at DESC LIMIT ? OFFSET ?`
+	query += ` ORDER BY d.created_at DESC LIMIT ? OFFSET ?`
 	paginationArgs := append(args, limit, offset)
 
 	err = db.Select(&documents, query, paginationArgs...)
@@ -103,18 +143,92 @@ func GetUserDocuments(db *sqlx.DB, userID int64, filters map[string]interface{},
 	return documents, total, nil
 }
 
+// GetDocumentQueue retrieves documents with their owners for moderation
+// Pending documents are listed oldest first so the longest waiting is reviewed first, others latest first.
+// cabang limits the queue to members of one cabang
+func GetDocumentQueue(db *sqlx.DB, filters map[string]interface{}, offset int, limit int) ([]DocumentDetail, int64, error) {
+	documents := []DocumentDetail{}
+
+	where := ` WHERE d.deleted_at IS NULL`
+	args := []interface{}{}
+
+	status, _ := filters["status"].(string)
+	if status != "" {
+		where += ` AND d.status = ?`
+		args = append(args, status)
+	}
+	if docType, ok := filters["type"].(string); ok && docType != "" {
+		where += ` AND d.type = ?`
+		args = append(args, docType)
+	}
+	if userID, ok := filters["user_id"].(int64); ok && userID > 0 {
+		where += ` AND d.user_id = ?`
+		args = append(args, userID)
+	}
+	if cabang, ok := filters["cabang"].(string); ok && cabang != "" {
+		where += ` AND u.cabang = ?`
+		args = append(args, cabang)
+	}
+	if search, ok := filters["search"].(string); ok && search != "" {
+		where += ` AND (d.name LIKE ? OR u.name LIKE ? OR u.email LIKE ?)`
+		pattern := "%" + search + "%"
+		args = append(args, pattern, pattern, pattern)
+	}
+
+	from := ` FROM documents d JOIN users u ON u.id = d.user_id`
+
+	var total int64
+	if err := db.Get(&total, `SELECT COUNT(*)`+from+where, args...); err != nil {
+		return nil, 0, err
+	}
+
+	order := ` ORDER BY d.updated_at DESC, d.id DESC`
+	if status == DocumentStatusPending {
+		order = ` ORDER BY d.updated_at ASC, d.id ASC`
+	}
+
+	query := `SELECT ` + documentDetailColumns + from + where + order + ` LIMIT ? OFFSET ?`
+	if err := db.Select(&documents, query, append(args, limit, offset)...); err != nil {
+		return nil, 0, err
+	}
+
+	return documents, total, nil
+}
+
 // Update updates a document record
+// The member changed the document, so it goes back to pending and the previous review is cleared
 func (d *Document) Update(db *sqlx.DB) error {
+	d.Status = DocumentStatusPending
+	d.RejectReason = nil
+	d.ReviewedBy = nil
+	d.ReviewedAt = nil
 	d.UpdatedAt = time.Now()
 	query := `
 		UPDATE documents 
-		SET name = ?, type = ?, valid_until = ?, status = ?, file_url = ?, updated_at = ?
+		SET name = ?, type = ?, valid_until = ?, status = ?, reject_reason = NULL, reviewed_by = NULL, reviewed_at = NULL,
+			file_url = ?, file_name = ?, updated_at = ?
 		WHERE id = ? AND deleted_at IS NULL
 	`
-	_, err := db.Exec(query, d.Name, d.Type, d.ValidUntil, d.Status, d.FileURL, d.UpdatedAt, d.ID)
+	_, err := db.Exec(query, d.Name, d.Type, d.ValidUntil, d.Status, d.FileURL, d.FileName, d.UpdatedAt, d.ID)
 	return err
 }
 
+// ReviewDocument marks a pending document valid, or rejected with the reason shown to the member
+func ReviewDocument(db *sqlx.DB, id int64, reviewerID int64, status string, reason *string) error {
+	now := time.Now()
+	result, err := db.Exec(`
+		UPDATE documents SET status = ?, reject_reason = ?, reviewed_by = ?, reviewed_at = ?, updated_at = ?
+		WHERE id = ? AND status = ? AND deleted_at IS NULL
+	`, status, reason, reviewerID, now, now, id, DocumentStatusPending)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ErrDocumentNotPending
+	}
+	return nil
+}
+
 // Delete soft deletes a document record
 func (d *Document) Delete(db *sqlx.DB) error {
 	now := time.Now()
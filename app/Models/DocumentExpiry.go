@@ -0,0 +1,175 @@
+package models
+
+import (
+	"sort"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// documentDateLayout is how DATE values are passed to queries, so comparisons use the application's day
+const documentDateLayout = "2006-01-02"
+
+// ExpiringDocument is a document with a valid_until date and the member who owns it
+type ExpiringDocument struct {
+	ID         int64     `db:"id" json:"id"`
+	UserID     int64     `db:"user_id" json:"user_id"`
+	Name       string    `db:"name" json:"name"`
+	Type       string    `db:"type" json:"type"`
+	Status     string    `db:"status" json:"status"`
+	ValidUntil time.Time `db:"valid_until" json:"valid_until"`
+	DaysLeft   int       `db:"days_left" json:"days_left"` // Negative once expired
+	UserName   string    `db:"user_name" json:"user_name"`
+	UserEmail  string    `db:"user_email" json:"user_email"`
+	UserCabang *string   `db:"user_cabang" json:"user_cabang"`
+}
+
+const expiringDocumentColumns = `d.id, d.user_id, d.name, d.type, d.status, d.valid_until, DATEDIFF(d.valid_until, ?) AS days_left,
+	u.name AS user_name, u.email AS user_email, u.cabang AS user_cabang`
+
+// DocumentToday returns the current date in loc at midnight UTC, comparable with scanned DATE columns
+func DocumentToday(loc *time.Location) time.Time {
+	now := time.Now().In(loc)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// ExpireDocuments marks documents whose valid_until is before today as expired
+// Rejected documents keep their status, the member has to upload a new one anyway
+func ExpireDocuments(db *sqlx.DB, today time.Time) (int64, error) {
+	result, err := db.Exec(`
+		UPDATE documents SET status = ?, updated_at = ?
+		WHERE valid_until < ? AND status IN (?, ?) AND deleted_at IS NULL
+	`, DocumentStatusExpired, time.Now(), today.Format(documentDateLayout), DocumentStatusValid, DocumentStatusPending)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// DocumentReminderLead returns the reminder lead time, in days, due for a document expiring in daysLeft days
+// Only the shortest lead time not yet passed is due, so a document uploaded 20 days before expiry
+// gets the 30 day reminder once instead of the 90 and 30 day reminders together
+func DocumentReminderLead(daysLeft int, leadDays []int) (int, bool) {
+	if daysLeft < 0 {
+		return 0, false
+	}
+
+	sorted := append([]int(nil), leadDays...)
+	sort.Ints(sorted)
+	for _, lead := range sorted {
+		if lead >= daysLeft {
+			return lead, true
+		}
+	}
+	return 0, false
+}
+
+// GetDocumentsExpiringBy retrieves valid and pending documents expiring between today and until, soonest first
+func GetDocumentsExpiringBy(db *sqlx.DB, today time.Time, until time.Time) ([]ExpiringDocument, error) {
+	documents := []ExpiringDocument{}
+	query := `
+		SELECT ` + expiringDocumentColumns + `
+		FROM documents d
+		JOIN users u ON u.id = d.user_id
+		WHERE d.deleted_at IS NULL AND d.status IN (?, ?) AND d.valid_until BETWEEN ? AND ?
+		ORDER BY d.valid_until ASC, d.id ASC
+	`
+	err := db.Select(&documents, query, today.Format(documentDateLayout), DocumentStatusValid, DocumentStatusPending,
+		today.Format(documentDateLayout), until.Format(documentDateLayout))
+	return documents, err
+}
+
+// ClaimDocumentReminder claims the reminder of a document for one recipient before it is sent
+// The first insert wins through uk_document_reminder_deliveries, a failed delivery is retried
+// by the instance whose update flips it back to sending
+func ClaimDocumentReminder(db *sqlx.DB, document ExpiringDocument, recipient User, leadDays int) (bool, error) {
+	now := time.Now()
+	validUntil := document.ValidUntil.Format(documentDateLayout)
+	result, err := db.Exec(`
+		INSERT IGNORE INTO document_reminder_deliveries (document_id, user_id, lead_days, valid_until, recipient, status, attempts, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, 1, ?, ?)
+	`, document.ID, recipient.ID, leadDays, validUntil, recipient.Email, ReminderStatusSending, now, now)
+	if err != nil {
+		return false, err
+	}
+	if affected, _ := result.RowsAffected(); affected > 0 {
+		return true, nil
+	}
+
+	result, err = db.Exec(`
+		UPDATE document_reminder_deliveries
+		SET status = ?, attempts = attempts + 1, recipient = ?, error = NULL, updated_at = ?
+		WHERE document_id = ? AND user_id = ? AND lead_days = ? AND valid_until = ? AND status = ? AND attempts < ?
+	`, ReminderStatusSending, recipient.Email, now, document.ID, recipient.ID, leadDays, validUntil, ReminderStatusFailed, MaxReminderAttempts)
+	if err != nil {
+		return false, err
+	}
+	affected, _ := result.RowsAffected()
+	return affected > 0, nil
+}
+
+// FinishDocumentReminder records the outcome of a claimed reminder, a nil sendErr marks it as sent
+func FinishDocumentReminder(db *sqlx.DB, document ExpiringDocument, recipientID int64, leadDays int, sendErr error) error {
+	now := time.Now()
+	validUntil := document.ValidUntil.Format(documentDateLayout)
+	if sendErr != nil {
+		message := sendErr.Error()
+		_, err := db.Exec(`
+			UPDATE document_reminder_deliveries SET status = ?, error = ?, updated_at = ?
+			WHERE document_id = ? AND user_id = ? AND lead_days = ? AND valid_until = ?
+		`, ReminderStatusFailed, message, now, document.ID, recipientID, leadDays, validUntil)
+		return err
+	}
+
+	_, err := db.Exec(`
+		UPDATE document_reminder_deliveries SET status = ?, error = NULL, sent_at = ?, updated_at = ?
+		WHERE document_id = ? AND user_id = ? AND lead_days = ? AND valid_until = ?
+	`, ReminderStatusSent, now, now, document.ID, recipientID, leadDays, validUntil)
+	return err
+}
+
+// GetDocumentExpiryReport retrieves expired documents and documents expiring by until,
+// grouped by the cabang of their owner and soonest first within a cabang
+// Filters: cabang, type and state (expiring or expired)
+func GetDocumentExpiryReport(db *sqlx.DB, today time.Time, until time.Time, filters map[string]interface{}) ([]ExpiringDocument, error) {
+	documents := []ExpiringDocument{}
+
+	todayDate := today.Format(documentDateLayout)
+	args := []interface{}{todayDate}
+	where := ` WHERE d.deleted_at IS NULL AND d.valid_until IS NOT NULL AND d.status != ?`
+	args = append(args, DocumentStatusRejected)
+
+	switch filters["state"] {
+	case "expired":
+		where += ` AND d.valid_until < ?`
+		args = append(args, todayDate)
+	case "expiring":
+		where += ` AND d.valid_until BETWEEN ? AND ?`
+		args = append(args, todayDate, until.Format(documentDateLayout))
+	default:
+		where += ` AND d.valid_until <= ?`
+		args = append(args, until.Format(documentDateLayout))
+	}
+
+	if cabang, ok := filters["cabang"].(string); ok && cabang != "" {
+		where += ` AND u.cabang = ?`
+		args = append(args, cabang)
+	}
+	if docType, ok := filters["type"].(string); ok && docType != "" {
+		where += ` AND d.type = ?`
+		args = append(args, docType)
+	}
+
+	query := `SELECT ` + expiringDocumentColumns + ` FROM documents d JOIN users u ON u.id = d.user_id` + where +
+		` ORDER BY u.cabang IS NULL, u.cabang ASC, d.valid_until ASC, d.id ASC`
+	err := db.Select(&documents, query, args...)
+	return documents, err
+}
+
+// GetCabangAdmins retrieves the active admins of a cabang, who are reminded about documents of its members
+func GetCabangAdmins(db *sqlx.DB, cabang string) ([]User, error) {
+	admins := []User{}
+	query := `SELECT id, name, email FROM users WHERE role = ? AND cabang = ? AND status = 'active' ORDER BY id ASC`
+	err := db.Select(&admins, query, RoleAdminCabang, cabang)
+	return admins, err
+}
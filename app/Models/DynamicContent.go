@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"time"
 
+	"github.com/cvudumbarainformatika/backend/utils"
 	"github.com/jmoiron/sqlx"
 )
 
@@ -15,6 +16,8 @@ type DynamicContent struct {
 	Description string     `db:"description" json:"description"`
 	Body        string     `db:"body" json:"body"`
 	HTML        string     `db:"html" json:"html"`
+	TOC         ContentTOC `db:"toc" json:"toc"`
+	ReadingTime int        `db:"reading_time" json:"reading_time"` // in minutes
 	Date        *time.Time `db:"date" json:"date"`
 	Image       string     `db:"image" json:"image"` // JSON
 	Authors     string     `db:"authors" json:"authors"` // JSON array
@@ -23,16 +26,31 @@ type DynamicContent struct {
 	UpdatedAt   time.Time  `db:"updated_at" json:"updated_at"`
 }
 
+// render renders the markdown body (or sanitizes the raw HTML) into HTML, TOC and reading time
+func (dc *DynamicContent) render() error {
+	rendered, err := utils.RenderContent(dc.Body, dc.HTML)
+	if err != nil {
+		return err
+	}
+	dc.HTML = rendered.HTML
+	dc.TOC = rendered.TOC
+	dc.ReadingTime = rendered.ReadingTime
+	return nil
+}
+
 // Create creates a new dynamic content record
 func (dc *DynamicContent) Create(db *sqlx.DB) error {
+	if err := dc.render(); err != nil {
+		return err
+	}
 	dc.CreatedAt = time.Now()
 	dc.UpdatedAt = time.Now()
 
 	query := `
-		INSERT INTO dynamic_contents (slug, title, description, body, html, date, image, authors, badge, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO dynamic_contents (slug, title, description, body, html, toc, reading_time, date, image, authors, badge, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
-	result, err := db.Exec(query, dc.Slug, dc.Title, dc.Description, dc.Body, dc.HTML, dc.Date, dc.Image, dc.Authors, dc.Badge, dc.CreatedAt, dc.UpdatedAt)
+	result, err := db.Exec(query, dc.Slug, dc.Title, dc.Description, dc.Body, dc.HTML, dc.TOC, dc.ReadingTime, dc.Date, dc.Image, dc.Authors, dc.Badge, dc.CreatedAt, dc.UpdatedAt)
 	if err != nil {
 		return err
 	}
@@ -49,7 +67,7 @@ func (dc *DynamicContent) Create(db *sqlx.DB) error {
 func FindDynamicContentBySlug(db *sqlx.DB, slug string) (*DynamicContent, error) {
 	content := &DynamicContent{}
 	query := `
-		SELECT id, slug, title, description, body, html, date, image, authors, badge, created_at, updated_at 
+		SELECT id, slug, title, description, body, html, toc, reading_time, date, image, authors, badge, created_at, updated_at 
 		FROM dynamic_contents 
 		WHERE slug = ?
 	`
@@ -67,7 +85,7 @@ func FindDynamicContentBySlug(db *sqlx.DB, slug string) (*DynamicContent, error)
 func FindDynamicContentByID(db *sqlx.DB, id int64) (*DynamicContent, error) {
 	content := &DynamicContent{}
 	query := `
-		SELECT id, slug, title, description, body, html, date, image, authors, badge, created_at, updated_at 
+		SELECT id, slug, title, description, body, html, toc, reading_time, date, image, authors, badge, created_at, updated_at 
 		FROM dynamic_contents 
 		WHERE id = ?
 	`
@@ -83,13 +101,16 @@ func FindDynamicContentByID(db *sqlx.DB, id int64) (*DynamicContent, error) {
 
 // Update updates a dynamic content record
 func (dc *DynamicContent) Update(db *sqlx.DB) error {
+	if err := dc.render(); err != nil {
+		return err
+	}
 	dc.UpdatedAt = time.Now()
 	query := `
 		UPDATE dynamic_contents 
-		SET slug = ?, title = ?, description = ?, body = ?, html = ?, date = ?, image = ?, authors = ?, badge = ?, updated_at = ?
+		SET slug = ?, title = ?, description = ?, body = ?, html = ?, toc = ?, reading_time = ?, date = ?, image = ?, authors = ?, badge = ?, updated_at = ?
 		WHERE id = ?
 	`
-	_, err := db.Exec(query, dc.Slug, dc.Title, dc.Description, dc.Body, dc.HTML, dc.Date, dc.Image, dc.Authors, dc.Badge, dc.UpdatedAt, dc.ID)
+	_, err := db.Exec(query, dc.Slug, dc.Title, dc.Description, dc.Body, dc.HTML, dc.TOC, dc.ReadingTime, dc.Date, dc.Image, dc.Authors, dc.Badge, dc.UpdatedAt, dc.ID)
 	return err
 }
 
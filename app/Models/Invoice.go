@@ -0,0 +1,452 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/cvudumbarainformatika/backend/utils"
+	"github.com/jmoiron/sqlx"
+)
+
+// Invoice statuses
+const (
+	InvoiceStatusPending   = "pending"
+	InvoiceStatusPaid      = "paid"
+	InvoiceStatusExpired   = "expired"   // Lapsed unpaid, the seat was released
+	InvoiceStatusFailed    = "failed"    // Payment was denied or cancelled at the provider
+	InvoiceStatusCancelled = "cancelled" // Registration was cancelled or confirmed manually
+)
+
+// Invoice errors
+var (
+	ErrInvoiceNotFound        = errors.New("invoice not found")
+	ErrInvoiceAmountMismatch  = errors.New("paid amount does not match the invoice")
+	ErrRegistrationNotPayable = errors.New("registration does not require payment")
+)
+
+// Invoice is a payment request for a paid agenda registration
+type Invoice struct {
+	ID             int64      `db:"id" json:"id"`
+	Number         string     `db:"number" json:"number"` // Order ID sent to the payment provider
+	RegistrationID int64      `db:"registration_id" json:"registration_id"`
+	AgendaID       int64      `db:"agenda_id" json:"agenda_id"`
+	UserID         int64      `db:"user_id" json:"user_id"`
+	Amount         int64      `db:"amount" json:"amount"` // In IDR
+	Currency       string     `db:"currency" json:"currency"`
+	Status         string     `db:"status" json:"status"`
+	Provider       string     `db:"provider" json:"provider"`
+	ProviderRef    *string    `db:"provider_ref" json:"provider_ref"`
+	PaymentURL     *string    `db:"payment_url" json:"payment_url"`
+	PaymentMethod  *string    `db:"payment_method" json:"payment_method"`
+	ExpiresAt      time.Time  `db:"expires_at" json:"expires_at"`
+	PaidAt         *time.Time `db:"paid_at" json:"paid_at"`
+	CreatedAt      time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt      time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// InvoiceDetail is an invoice joined with its agenda and user
+type InvoiceDetail struct {
+	Invoice
+	AgendaSlug  string    `db:"agenda_slug" json:"agenda_slug"`
+	AgendaTitle string    `db:"agenda_title" json:"agenda_title"`
+	AgendaDate  time.Time `db:"agenda_date" json:"agenda_date"`
+	UserName    string    `db:"user_name" json:"user_name"`
+	UserEmail   string    `db:"user_email" json:"user_email"`
+}
+
+// InvoiceSummary totals the invoices of an agenda
+type InvoiceSummary struct {
+	Paid       int   `db:"paid" json:"paid"`
+	Pending    int   `db:"pending" json:"pending"`
+	PaidAmount int64 `db:"paid_amount" json:"paid_amount"`
+}
+
+const invoiceColumns = `id, number, registration_id, agenda_id, user_id, amount, currency, status, provider, provider_ref, payment_url, payment_method, expires_at, paid_at, created_at, updated_at`
+
+const invoiceDetailSelect = `
+	SELECT i.id, i.number, i.registration_id, i.agenda_id, i.user_id, i.amount, i.currency, i.status, i.provider, i.provider_ref, i.payment_url, i.payment_method, i.expires_at, i.paid_at, i.created_at, i.updated_at,
+		a.slug AS agenda_slug, a.title AS agenda_title, a.date AS agenda_date,
+		u.name AS user_name, u.email AS user_email
+	FROM invoices i
+	JOIN agenda a ON a.id = i.agenda_id
+	JOIN users u ON u.id = i.user_id
+`
+
+// CreateRegistrationInvoice issues an invoice over the agenda price for a pending registration
+// The registration row is locked so concurrent requests cannot issue two invoices: an open invoice is
+// returned as is (created = false). ErrRegistrationNotPayable is returned for free agenda, registrations
+// that do not hold a pending seat and registrations that were already paid
+func CreateRegistrationInvoice(db *sqlx.DB, registrationID int64, provider string, expiresAt time.Time) (*Invoice, bool, error) {
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	var registration struct {
+		AgendaID int64  `db:"agenda_id"`
+		UserID   int64  `db:"user_id"`
+		Status   string `db:"status"`
+		Price    int64  `db:"price"`
+	}
+	err = tx.Get(&registration, `
+		SELECT r.agenda_id, r.user_id, r.status, a.price
+		FROM agenda_registrations r
+		JOIN agenda a ON a.id = r.agenda_id
+		WHERE r.id = ?
+		FOR UPDATE
+	`, registrationID)
+	if err == sql.ErrNoRows {
+		return nil, false, ErrRegistrationNotFound
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if registration.Status != RegistrationStatusPending || registration.Price <= 0 {
+		return nil, false, ErrRegistrationNotPayable
+	}
+
+	existing := &Invoice{}
+	err = tx.Get(existing, `SELECT `+invoiceColumns+` FROM invoices WHERE registration_id = ? AND status IN (?, ?) ORDER BY id DESC LIMIT 1`, registrationID, InvoiceStatusPending, InvoiceStatusPaid)
+	if err == nil {
+		if existing.Status == InvoiceStatusPaid {
+			return nil, false, ErrRegistrationNotPayable
+		}
+		return existing, false, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, false, err
+	}
+
+	suffix, err := utils.GenerateToken(4)
+	if err != nil {
+		return nil, false, err
+	}
+
+	now := time.Now()
+	invoice := &Invoice{
+		Number:         "INV-" + now.Format("20060102") + "-" + strings.ToUpper(suffix),
+		RegistrationID: registrationID,
+		AgendaID:       registration.AgendaID,
+		UserID:         registration.UserID,
+		Amount:         registration.Price,
+		Currency:       "IDR",
+		Status:         InvoiceStatusPending,
+		Provider:       provider,
+		ExpiresAt:      expiresAt,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	result, err := tx.Exec(`
+		INSERT INTO invoices (number, registration_id, agenda_id, user_id, amount, currency, status, provider, expires_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, invoice.Number, invoice.RegistrationID, invoice.AgendaID, invoice.UserID, invoice.Amount, invoice.Currency, invoice.Status, invoice.Provider, invoice.ExpiresAt, invoice.CreatedAt, invoice.UpdatedAt)
+	if err != nil {
+		return nil, false, err
+	}
+	if invoice.ID, err = result.LastInsertId(); err != nil {
+		return nil, false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, false, err
+	}
+	return invoice, true, nil
+}
+
+// SetInvoiceCharge stores the provider transaction of an invoice
+func SetInvoiceCharge(db *sqlx.DB, id int64, providerRef string, paymentURL string) error {
+	_, err := db.Exec(`UPDATE invoices SET provider_ref = ?, payment_url = ?, updated_at = ? WHERE id = ?`, providerRef, paymentURL, time.Now(), id)
+	return err
+}
+
+// SettleInvoice marks an invoice as paid and confirms its registration, returning the registration when it was confirmed
+// Settling is idempotent: repeated notifications of a paid invoice change nothing.
+// A late payment of an expired invoice reinstates the cancelled registration while seats are free,
+// otherwise the invoice is still recorded as paid and no registration is returned (the payment must be refunded)
+func SettleInvoice(db *sqlx.DB, number string, amount int64, providerRef string, method string) (*Invoice, *AgendaRegistration, error) {
+	invoice, err := FindInvoiceByNumber(db, number)
+	if err != nil {
+		return nil, nil, err
+	}
+	if invoice == nil {
+		return nil, nil, ErrInvoiceNotFound
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	// Lock the agenda first, like every other seat change
+	quota, err := lockAgendaQuota(tx, invoice.AgendaID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := tx.Get(invoice, `SELECT `+invoiceColumns+` FROM invoices WHERE id = ? FOR UPDATE`, invoice.ID); err != nil {
+		return nil, nil, err
+	}
+	if invoice.Status == InvoiceStatusPaid {
+		return invoice, nil, nil
+	}
+	if amount != invoice.Amount {
+		return invoice, nil, ErrInvoiceAmountMismatch
+	}
+
+	now := time.Now()
+	if _, err := tx.Exec(`
+		UPDATE invoices
+		SET status = ?, paid_at = ?, provider_ref = COALESCE(NULLIF(?, ''), provider_ref), payment_method = NULLIF(?, ''), updated_at = ?
+		WHERE id = ?
+	`, InvoiceStatusPaid, now, providerRef, method, now, invoice.ID); err != nil {
+		return nil, nil, err
+	}
+	invoice.Status = InvoiceStatusPaid
+	invoice.PaidAt = &now
+
+	registration := &AgendaRegistration{}
+	if err := tx.Get(registration, `SELECT id, agenda_id, user_id, status, answers, registered_at, updated_at, checked_in_at, checked_in_by FROM agenda_registrations WHERE id = ? FOR UPDATE`, invoice.RegistrationID); err != nil {
+		return nil, nil, err
+	}
+
+	confirm := registration.Status == RegistrationStatusPending
+	if registration.Status == RegistrationStatusCancelled {
+		confirm = quota <= 0
+		if quota > 0 {
+			taken, err := countSeatsTaken(tx, invoice.AgendaID)
+			if err != nil {
+				return nil, nil, err
+			}
+			confirm = taken < quota
+		}
+	}
+
+	if !confirm {
+		if err := tx.Commit(); err != nil {
+			return nil, nil, err
+		}
+		return invoice, nil, nil
+	}
+
+	if _, err := tx.Exec(`UPDATE agenda_registrations SET status = ?, updated_at = ? WHERE id = ?`, RegistrationStatusConfirmed, now, registration.ID); err != nil {
+		return nil, nil, err
+	}
+	registration.Status = RegistrationStatusConfirmed
+	registration.UpdatedAt = &now
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	return invoice, registration, nil
+}
+
+// FailInvoice marks a pending invoice as failed, the registration keeps its seat so the member can pay again
+func FailInvoice(db *sqlx.DB, number string) (*Invoice, error) {
+	invoice, err := FindInvoiceByNumber(db, number)
+	if err != nil {
+		return nil, err
+	}
+	if invoice == nil {
+		return nil, ErrInvoiceNotFound
+	}
+
+	now := time.Now()
+	result, err := db.Exec(`UPDATE invoices SET status = ?, updated_at = ? WHERE id = ? AND status = ?`, InvoiceStatusFailed, now, invoice.ID, InvoiceStatusPending)
+	if err != nil {
+		return nil, err
+	}
+	if affected, _ := result.RowsAffected(); affected > 0 {
+		invoice.Status = InvoiceStatusFailed
+		invoice.UpdatedAt = now
+	}
+	return invoice, nil
+}
+
+// ExpireInvoice expires a pending invoice and cancels its still unpaid registration, giving the seat to the waitlist
+// The cancelled registration (nil when it was not pending anymore) and the promoted registrations are returned
+func ExpireInvoice(db *sqlx.DB, id int64) (*AgendaRegistration, []AgendaRegistration, error) {
+	invoice, err := FindInvoiceByID(db, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if invoice == nil {
+		return nil, nil, ErrInvoiceNotFound
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer tx.Rollback()
+
+	quota, err := lockAgendaQuota(tx, invoice.AgendaID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	result, err := tx.Exec(`UPDATE invoices SET status = ?, updated_at = ? WHERE id = ? AND status = ?`, InvoiceStatusExpired, now, id, InvoiceStatusPending)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Settled or closed meanwhile
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil, []AgendaRegistration{}, tx.Commit()
+	}
+
+	registration := &AgendaRegistration{}
+	if err := tx.Get(registration, `SELECT id, agenda_id, user_id, status, answers, registered_at, updated_at, checked_in_at, checked_in_by FROM agenda_registrations WHERE id = ? FOR UPDATE`, invoice.RegistrationID); err != nil {
+		return nil, nil, err
+	}
+	if registration.Status != RegistrationStatusPending {
+		return nil, []AgendaRegistration{}, tx.Commit()
+	}
+
+	if _, err := tx.Exec(`UPDATE agenda_registrations SET status = ?, updated_at = ? WHERE id = ?`, RegistrationStatusCancelled, now, registration.ID); err != nil {
+		return nil, nil, err
+	}
+	registration.Status = RegistrationStatusCancelled
+	registration.UpdatedAt = &now
+
+	promoted, err := promoteWaitlist(tx, invoice.AgendaID, quota)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, err
+	}
+	return registration, promoted, nil
+}
+
+// cancelOpenInvoices cancels the pending invoices of a registration that was cancelled or confirmed without paying
+func cancelOpenInvoices(tx *sqlx.Tx, registrationID int64, now time.Time) error {
+	_, err := tx.Exec(`UPDATE invoices SET status = ?, updated_at = ? WHERE registration_id = ? AND status = ?`, InvoiceStatusCancelled, now, registrationID, InvoiceStatusPending)
+	return err
+}
+
+// GetLapsedInvoices retrieves pending invoices that expired before now
+func GetLapsedInvoices(db *sqlx.DB, now time.Time, limit int) ([]Invoice, error) {
+	invoices := []Invoice{}
+	err := db.Select(&invoices, `SELECT `+invoiceColumns+` FROM invoices WHERE status = ? AND expires_at <= ? ORDER BY expires_at ASC LIMIT ?`, InvoiceStatusPending, now, limit)
+	return invoices, err
+}
+
+// GetRegistrationsAwaitingInvoice retrieves pending registrations of paid agenda without an open or paid invoice
+// (members promoted from the waitlist and members whose last payment failed)
+func GetRegistrationsAwaitingInvoice(db *sqlx.DB, limit int) ([]AgendaRegistration, error) {
+	registrations := []AgendaRegistration{}
+	query := `
+		SELECT r.id, r.agenda_id, r.user_id, r.status, r.answers, r.registered_at, r.updated_at, r.checked_in_at, r.checked_in_by
+		FROM agenda_registrations r
+		JOIN agenda a ON a.id = r.agenda_id
+		WHERE r.status = ? AND a.price > 0 AND a.deleted_at IS NULL AND a.cancelled_at IS NULL
+			AND NOT EXISTS (SELECT 1 FROM invoices i WHERE i.registration_id = r.id AND i.status IN (?, ?))
+		ORDER BY r.id ASC
+		LIMIT ?
+	`
+	err := db.Select(&registrations, query, RegistrationStatusPending, InvoiceStatusPending, InvoiceStatusPaid, limit)
+	return registrations, err
+}
+
+// FindInvoiceByID finds an invoice by ID
+func FindInvoiceByID(db *sqlx.DB, id int64) (*Invoice, error) {
+	invoice := &Invoice{}
+	err := db.Get(invoice, `SELECT `+invoiceColumns+` FROM invoices WHERE id = ?`, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// FindInvoiceByNumber finds an invoice by its number
+func FindInvoiceByNumber(db *sqlx.DB, number string) (*Invoice, error) {
+	invoice := &Invoice{}
+	err := db.Get(invoice, `SELECT `+invoiceColumns+` FROM invoices WHERE number = ?`, number)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// GetUserInvoices retrieves the invoices of a user, newest first
+func GetUserInvoices(db *sqlx.DB, userID int64, filters map[string]interface{}, offset int, limit int) ([]InvoiceDetail, int64, error) {
+	invoices := []InvoiceDetail{}
+
+	where := ` WHERE i.user_id = ?`
+	args := []interface{}{userID}
+
+	if status, ok := filters["status"].(string); ok && status != "" {
+		where += ` AND i.status = ?`
+		args = append(args, status)
+	}
+
+	// Get total count
+	var total int64
+	if err := db.Get(&total, `SELECT COUNT(*) FROM invoices i`+where, args...); err != nil {
+		return nil, 0, err
+	}
+
+	query := invoiceDetailSelect + where + ` ORDER BY i.created_at DESC, i.id DESC LIMIT ? OFFSET ?`
+	if err := db.Select(&invoices, query, append(args, limit, offset)...); err != nil {
+		return nil, 0, err
+	}
+
+	return invoices, total, nil
+}
+
+// GetAgendaInvoices retrieves the invoices of an agenda with filters and pagination
+func GetAgendaInvoices(db *sqlx.DB, agendaID int64, filters map[string]interface{}, offset int, limit int) ([]InvoiceDetail, int64, error) {
+	invoices := []InvoiceDetail{}
+
+	where := ` WHERE i.agenda_id = ?`
+	args := []interface{}{agendaID}
+
+	if status, ok := filters["status"].(string); ok && status != "" {
+		where += ` AND i.status = ?`
+		args = append(args, status)
+	}
+	if search, ok := filters["search"].(string); ok && search != "" {
+		where += ` AND (i.number LIKE ? OR u.name LIKE ? OR u.email LIKE ?)`
+		like := "%" + search + "%"
+		args = append(args, like, like, like)
+	}
+
+	// Get total count
+	var total int64
+	if err := db.Get(&total, `SELECT COUNT(*) FROM invoices i JOIN users u ON u.id = i.user_id`+where, args...); err != nil {
+		return nil, 0, err
+	}
+
+	query := invoiceDetailSelect + where + ` ORDER BY i.created_at DESC, i.id DESC LIMIT ? OFFSET ?`
+	if err := db.Select(&invoices, query, append(args, limit, offset)...); err != nil {
+		return nil, 0, err
+	}
+
+	return invoices, total, nil
+}
+
+// GetAgendaInvoiceSummary counts paid and pending invoices of an agenda and sums the amount paid
+func GetAgendaInvoiceSummary(db *sqlx.DB, agendaID int64) (InvoiceSummary, error) {
+	var summary InvoiceSummary
+	query := `
+		SELECT
+			COALESCE(SUM(status = ?), 0) AS paid,
+			COALESCE(SUM(status = ?), 0) AS pending,
+			COALESCE(SUM(CASE WHEN status = ? THEN amount ELSE 0 END), 0) AS paid_amount
+		FROM invoices
+		WHERE agenda_id = ?
+	`
+	err := db.Get(&summary, query, InvoiceStatusPaid, InvoiceStatusPending, InvoiceStatusPaid, agendaID)
+	return summary, err
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"github.com/jmoiron/sqlx"
+)
+
+// Admin roles
+const (
+	RoleAdminPusat   = "admin_pusat"
+	RoleAdminWilayah = "admin_wilayah"
+	RoleAdminCabang  = "admin_cabang"
+	RoleAdmin        = "admin" // Legacy global admin role
+)
+
+// OrgScope describes which organization unit a user may manage
+type OrgScope struct {
+	UserID int64
+	Role   string
+	Cabang string
+}
+
+// LoadOrgScope loads the organization scope of a user
+// Returns nil if the user does not exist
+func LoadOrgScope(db *sqlx.DB, userID int64) (*OrgScope, error) {
+	user, err := FindByID(db, userID)
+	if err != nil || user == nil {
+		return nil, err
+	}
+
+	scope := &OrgScope{UserID: user.ID, Role: user.Role}
+	if user.Cabang.Valid {
+		scope.Cabang = user.Cabang.String
+	}
+	return scope, nil
+}
+
+// IsAdmin reports whether the user has any admin role
+func (s OrgScope) IsAdmin() bool {
+	switch s.Role {
+	case RoleAdminPusat, RoleAdminWilayah, RoleAdminCabang, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+// IsGlobal reports whether the user may manage items of every organization unit
+func (s OrgScope) IsGlobal() bool {
+	return s.Role == RoleAdminPusat || s.Role == RoleAdmin
+}
+
+// CanManage reports whether the user may manage an item owned by the given cabang (nil = pusat)
+func (s OrgScope) CanManage(cabang *string) bool {
+	if s.IsGlobal() {
+		return true
+	}
+	if !s.IsAdmin() || s.Cabang == "" || cabang == nil {
+		return false
+	}
+	return *cabang == s.Cabang
+}
+
+// OwnerCabang returns the cabang assigned to items created by the user (nil = pusat)
+func (s OrgScope) OwnerCabang() *string {
+	if s.IsGlobal() || s.Cabang == "" {
+		return nil
+	}
+	cabang := s.Cabang
+	return &cabang
+}
@@ -2,35 +2,84 @@ package models
 
 import (
 	"database/sql"
+	"errors"
 	"time"
 
 	"github.com/jmoiron/sqlx"
 )
 
-// Pengurus represents a leadership/staff member
+// Pengurus levels, from the top of the organization down
+const (
+	PengurusLevelPusat   = "pusat"
+	PengurusLevelWilayah = "wilayah"
+	PengurusLevelCabang  = "cabang"
+)
+
+// PengurusLevels lists the levels in org chart order
+var PengurusLevels = []string{PengurusLevelPusat, PengurusLevelWilayah, PengurusLevelCabang}
+
+var (
+	ErrPengurusTermNotFound = errors.New("pengurus term not found")
+	ErrPengurusUserLinked   = errors.New("user is already linked to another pengurus")
+)
+
+// Pengurus represents a leadership/staff member, what they hold in each period is a PengurusTerm
 type Pengurus struct {
-	ID        int64      `db:"id" json:"id"`
-	Name      string     `db:"name" json:"name"`
-	Position  string     `db:"position" json:"position"`
-	Bidang    string     `db:"bidang" json:"bidang"`
-	Level     string     `db:"level" json:"level"`
-	Periode   string     `db:"periode" json:"periode"`
-	Email     string     `db:"email" json:"email"`
-	CreatedAt time.Time  `db:"created_at" json:"created_at"`
-	UpdatedAt time.Time  `db:"updated_at" json:"updated_at"`
-	DeletedAt *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
-}
-
-// Create creates a new pengurus record
+	ID        int64          `db:"id" json:"id"`
+	Name      string         `db:"name" json:"name"`
+	Email     *string        `db:"email" json:"email"`
+	UserID    *int64         `db:"user_id" json:"user_id"`     // Linked member account
+	PhotoURL  *string        `db:"photo_url" json:"photo_url"` // Uploaded through /files
+	CreatedAt time.Time      `db:"created_at" json:"created_at"`
+	UpdatedAt time.Time      `db:"updated_at" json:"updated_at"`
+	DeletedAt *time.Time     `db:"deleted_at" json:"deleted_at,omitempty"`
+	Terms     []PengurusTerm `db:"-" json:"terms"`
+}
+
+// PengurusTerm is a position a pengurus holds in a period
+type PengurusTerm struct {
+	ID         int64     `db:"id" json:"id"`
+	PengurusID int64     `db:"pengurus_id" json:"pengurus_id"`
+	Level      string    `db:"level" json:"level"`     // pusat, wilayah, cabang
+	Periode    string    `db:"periode" json:"periode"` // e.g. 2023-2026
+	Bidang     string    `db:"bidang" json:"bidang"`   // Empty for the executive board
+	Position   string    `db:"position" json:"position"`
+	SortOrder  int       `db:"sort_order" json:"sort_order"` // Order of the position within its bidang
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updated_at"`
+}
+
+// PengurusChartEntry is a term in the org chart with the person holding it
+type PengurusChartEntry struct {
+	PengurusTerm
+	Name     string  `db:"name"`
+	PhotoURL *string `db:"photo_url"`
+	UserID   *int64  `db:"user_id"`
+}
+
+const pengurusColumns = `id, name, email, user_id, photo_url, created_at, updated_at, deleted_at`
+
+const pengurusTermColumns = `id, pengurus_id, level, periode, bidang, position, sort_order, created_at, updated_at`
+
+// pengurusLevelOrder sorts terms by level in org chart order
+const pengurusLevelOrder = `FIELD(level, 'pusat', 'wilayah', 'cabang')`
+
+// Create creates a new pengurus record along with its terms
 func (p *Pengurus) Create(db *sqlx.DB) error {
 	p.CreatedAt = time.Now()
 	p.UpdatedAt = time.Now()
 
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
 	query := `
-		INSERT INTO pengurus (name, position, bidang, level, periode, email, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO pengurus (name, email, user_id, photo_url, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
 	`
-	result, err := db.Exec(query, p.Name, p.Position, p.Bidang, p.Level, p.Periode, p.Email, p.CreatedAt, p.UpdatedAt)
+	result, err := tx.Exec(query, p.Name, p.Email, p.UserID, p.PhotoURL, p.CreatedAt, p.UpdatedAt)
 	if err != nil {
 		return err
 	}
@@ -40,15 +89,23 @@ func (p *Pengurus) Create(db *sqlx.DB) error {
 		return err
 	}
 	p.ID = id
-	return nil
+
+	for i := range p.Terms {
+		p.Terms[i].PengurusID = p.ID
+		if err := p.Terms[i].insert(tx); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
-// FindByID finds a pengurus by ID (excluding deleted)
+// FindByID finds a pengurus by ID with all terms, latest period first (excluding deleted)
 func FindPengurusByID(db *sqlx.DB, id int64) (*Pengurus, error) {
 	pengurus := &Pengurus{}
 	query := `
-		SELECT id, name, position, bidang, level, periode, email, created_at, updated_at, deleted_at 
-		FROM pengurus 
+		SELECT ` + pengurusColumns + `
+		FROM pengurus
 		WHERE id = ? AND deleted_at IS NULL
 	`
 	err := db.Get(pengurus, query, id)
@@ -58,57 +115,128 @@ func FindPengurusByID(db *sqlx.DB, id int64) (*Pengurus, error) {
 		}
 		return nil, err
 	}
+
+	terms, err := getPengurusTerms(db, []int64{pengurus.ID}, nil)
+	if err != nil {
+		return nil, err
+	}
+	pengurus.Terms = terms[pengurus.ID]
+	return pengurus, nil
+}
+
+// FindPengurusByUserID finds the pengurus linked to a user account (excluding deleted)
+func FindPengurusByUserID(db *sqlx.DB, userID int64) (*Pengurus, error) {
+	pengurus := &Pengurus{}
+	err := db.Get(pengurus, `SELECT `+pengurusColumns+` FROM pengurus WHERE user_id = ? AND deleted_at IS NULL LIMIT 1`, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
 	return pengurus, nil
 }
 
 // GetAllPengurus retrieves all pengurus with filters and pagination
+// level, periode and bidang select people with a matching term and the terms returned with them,
+// search matches the name. People are ordered by their highest matching position
 func GetAllPengurus(db *sqlx.DB, filters map[string]interface{}, offset int, limit int) ([]Pengurus, int64, error) {
-	var pengurus []Pengurus
+	pengurus := []Pengurus{}
 
-	query := `SELECT id, name, position, bidang, level, periode, email, created_at, updated_at, deleted_at FROM pengurus WHERE deleted_at IS NULL`
-	countQuery := `SELECT COUNT(*) FROM pengurus WHERE deleted_at IS NULL`
+	termWhere, termArgs := applyPengurusTermFilters(``, []interface{}{}, filters)
 
+	where := ` WHERE deleted_at IS NULL`
 	args := []interface{}{}
-
-	if level, ok := filters["level"].(string); ok && level != "" {
-		query += ` AND level = ?`
-		countQuery += ` AND level = ?`
-		args = append(args, level)
+	if termWhere != "" {
+		where += ` AND EXISTS (SELECT 1 FROM pengurus_terms WHERE pengurus_terms.pengurus_id = pengurus.id` + termWhere + `)`
+		args = append(args, termArgs...)
 	}
-	if periode, ok := filters["periode"].(string); ok && periode != "" {
-		query += ` AND periode = ?`
-		countQuery += ` AND periode = ?`
-		args = append(args, periode)
+	if search, ok := filters["search"].(string); ok && search != "" {
+		where += ` AND name LIKE ?`
+		args = append(args, "%"+search+"%")
 	}
 
 	// Get total count
 	var total int64
-	err := db.Get(&total, countQuery, args...)
-	if err != nil {
+	if err := db.Get(&total, `SELECT COUNT(*) FROM pengurus`+where, args...); err != nil {
 		return nil, 0, err
 	}
 
-	// Add sorting and pagination
-	query += ` ORDER BY created_at DESC LIMIT ? OFFSET ?`
-	paginationArgs := append(args, limit, offset)
+	// Highest level first, then position order, people without terms last
+	rank := `(SELECT MIN(` + pengurusLevelOrder + ` * 100000 + sort_order) FROM pengurus_terms WHERE pengurus_terms.pengurus_id = pengurus.id` + termWhere + `)`
+	query := `SELECT ` + pengurusColumns + ` FROM pengurus` + where + ` ORDER BY ` + rank + ` IS NULL, ` + rank + ` ASC, name ASC LIMIT ? OFFSET ?`
+	queryArgs := append(append(append([]interface{}{}, args...), termArgs...), termArgs...)
+	queryArgs = append(queryArgs, limit, offset)
 
-	err = db.Select(&pengurus, query, paginationArgs...)
+	if err := db.Select(&pengurus, query, queryArgs...); err != nil {
+		return nil, 0, err
+	}
+
+	ids := make([]int64, len(pengurus))
+	for i, p := range pengurus {
+		ids[i] = p.ID
+	}
+	terms, err := getPengurusTerms(db, ids, filters)
 	if err != nil {
 		return nil, 0, err
 	}
+	for i := range pengurus {
+		pengurus[i].Terms = terms[pengurus[i].ID]
+	}
 
 	return pengurus, total, nil
 }
 
-// Update updates a pengurus record
+// applyPengurusTermFilters appends the term filters (level, periode, bidang) to a WHERE clause
+func applyPengurusTermFilters(where string, args []interface{}, filters map[string]interface{}) (string, []interface{}) {
+	if level, ok := filters["level"].(string); ok && level != "" {
+		where += ` AND pengurus_terms.level = ?`
+		args = append(args, level)
+	}
+	if periode, ok := filters["periode"].(string); ok && periode != "" {
+		where += ` AND pengurus_terms.periode = ?`
+		args = append(args, periode)
+	}
+	if bidang, ok := filters["bidang"].(string); ok && bidang != "" {
+		where += ` AND pengurus_terms.bidang = ?`
+		args = append(args, bidang)
+	}
+	return where, args
+}
+
+// getPengurusTerms retrieves the terms of pengurus matching the term filters, keyed by pengurus ID
+// Terms are ordered latest period first, then by level and position order
+func getPengurusTerms(db *sqlx.DB, pengurusIDs []int64, filters map[string]interface{}) (map[int64][]PengurusTerm, error) {
+	byPengurus := map[int64][]PengurusTerm{}
+	if len(pengurusIDs) == 0 {
+		return byPengurus, nil
+	}
+
+	where, args := applyPengurusTermFilters(` WHERE pengurus_id IN (?)`, []interface{}{pengurusIDs}, filters)
+	query, args, err := sqlx.In(`SELECT `+pengurusTermColumns+` FROM pengurus_terms`+where+` ORDER BY periode DESC, `+pengurusLevelOrder+` ASC, sort_order ASC, id ASC`, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := []PengurusTerm{}
+	if err := db.Select(&terms, db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+	for _, term := range terms {
+		byPengurus[term.PengurusID] = append(byPengurus[term.PengurusID], term)
+	}
+	return byPengurus, nil
+}
+
+// Update updates a pengurus record, terms are changed on their own
 func (p *Pengurus) Update(db *sqlx.DB) error {
 	p.UpdatedAt = time.Now()
 	query := `
-		UPDATE pengurus 
-		SET name = ?, position = ?, bidang = ?, level = ?, periode = ?, email = ?, updated_at = ?
+		UPDATE pengurus
+		SET name = ?, email = ?, user_id = ?, photo_url = ?, updated_at = ?
 		WHERE id = ? AND deleted_at IS NULL
 	`
-	_, err := db.Exec(query, p.Name, p.Position, p.Bidang, p.Level, p.Periode, p.Email, p.UpdatedAt, p.ID)
+	_, err := db.Exec(query, p.Name, p.Email, p.UserID, p.PhotoURL, p.UpdatedAt, p.ID)
 	return err
 }
 
@@ -121,3 +249,102 @@ func (p *Pengurus) Delete(db *sqlx.DB) error {
 	_, err := db.Exec(query, p.DeletedAt, p.UpdatedAt, p.ID)
 	return err
 }
+
+// Create adds a term to a pengurus
+func (t *PengurusTerm) Create(db *sqlx.DB) error {
+	return t.insert(db)
+}
+
+// insert writes a new term with any executor, so it can run in a transaction
+func (t *PengurusTerm) insert(db sqlx.Execer) error {
+	t.CreatedAt = time.Now()
+	t.UpdatedAt = time.Now()
+
+	query := `
+		INSERT INTO pengurus_terms (pengurus_id, level, periode, bidang, position, sort_order, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	result, err := db.Exec(query, t.PengurusID, t.Level, t.Periode, t.Bidang, t.Position, t.SortOrder, t.CreatedAt, t.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	t.ID = id
+	return nil
+}
+
+// Update updates a term of a pengurus
+func (t *PengurusTerm) Update(db *sqlx.DB) error {
+	t.UpdatedAt = time.Now()
+
+	query := `
+		UPDATE pengurus_terms
+		SET level = ?, periode = ?, bidang = ?, position = ?, sort_order = ?, updated_at = ?
+		WHERE id = ? AND pengurus_id = ?
+	`
+	_, err := db.Exec(query, t.Level, t.Periode, t.Bidang, t.Position, t.SortOrder, t.UpdatedAt, t.ID, t.PengurusID)
+	return err
+}
+
+// FindPengurusTerm finds a term of a pengurus by ID
+func FindPengurusTerm(db *sqlx.DB, pengurusID int64, id int64) (*PengurusTerm, error) {
+	term := &PengurusTerm{}
+	err := db.Get(term, `SELECT `+pengurusTermColumns+` FROM pengurus_terms WHERE id = ? AND pengurus_id = ?`, id, pengurusID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return term, nil
+}
+
+// DeletePengurusTerm deletes a term of a pengurus
+func DeletePengurusTerm(db *sqlx.DB, pengurusID int64, id int64) error {
+	result, err := db.Exec(`DELETE FROM pengurus_terms WHERE id = ? AND pengurus_id = ?`, id, pengurusID)
+	if err != nil {
+		return err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return ErrPengurusTermNotFound
+	}
+	return nil
+}
+
+// GetPengurusPeriodes lists the periods that have terms, latest first
+func GetPengurusPeriodes(db *sqlx.DB) ([]string, error) {
+	periodes := []string{}
+	err := db.Select(&periodes, `
+		SELECT DISTINCT t.periode
+		FROM pengurus_terms t
+		JOIN pengurus p ON p.id = t.pengurus_id AND p.deleted_at IS NULL
+		ORDER BY t.periode DESC
+	`)
+	return periodes, err
+}
+
+// GetPengurusChartEntries retrieves the terms of a period with the people holding them, in org chart order:
+// by level, the executive board before the bidang, then by position order
+// An empty level returns all levels
+func GetPengurusChartEntries(db *sqlx.DB, periode string, level string) ([]PengurusChartEntry, error) {
+	query := `
+		SELECT t.id, t.pengurus_id, t.level, t.periode, t.bidang, t.position, t.sort_order, t.created_at, t.updated_at,
+			p.name, p.photo_url, p.user_id
+		FROM pengurus_terms t
+		JOIN pengurus p ON p.id = t.pengurus_id AND p.deleted_at IS NULL
+		WHERE t.periode = ?`
+	args := []interface{}{periode}
+	if level != "" {
+		query += ` AND t.level = ?`
+		args = append(args, level)
+	}
+	query += ` ORDER BY FIELD(t.level, 'pusat', 'wilayah', 'cabang') ASC, t.bidang != '' ASC, t.sort_order ASC, t.id ASC`
+
+	entries := []PengurusChartEntry{}
+	err := db.Select(&entries, query, args...)
+	return entries, err
+}
@@ -0,0 +1,237 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/cvudumbarainformatika/backend/utils"
+	"github.com/jmoiron/sqlx"
+)
+
+// Sections of the organization profile, each matches a column of profil_organisasi
+const (
+	ProfilSectionVisiMisi = "visi_misi"
+	ProfilSectionSejarah  = "sejarah"
+	ProfilSectionADART    = "ad_art"
+)
+
+// ProfilSections lists the sections in display order
+var ProfilSections = []string{ProfilSectionVisiMisi, ProfilSectionSejarah, ProfilSectionADART}
+
+// ErrProfilAttachmentNotFound is returned when an attachment does not belong to the version
+var ErrProfilAttachmentNotFound = errors.New("profil attachment not found")
+
+// ProfilSectionVersion is a saved revision of a profile section
+type ProfilSectionVersion struct {
+	ID            int64      `db:"id" json:"id"`
+	Section       string     `db:"section" json:"section"`
+	Version       int        `db:"version" json:"version"`
+	Body          string     `db:"body" json:"body"` // Markdown source
+	HTML          *string    `db:"html" json:"html"` // Sanitized HTML, NULL until rendered for imported versions
+	TOC           ContentTOC `db:"toc" json:"toc"`
+	ReadingTime   int        `db:"reading_time" json:"reading_time"`
+	Note          *string    `db:"note" json:"note"`
+	EffectiveDate *time.Time `db:"effective_date" json:"effective_date"`
+	CreatedBy     *int64     `db:"created_by" json:"created_by"`
+	CreatedAt     time.Time  `db:"created_at" json:"created_at"`
+}
+
+// ProfilAttachment is an official document attached to a profile section version, such as a signed AD/ART
+type ProfilAttachment struct {
+	ID        int64     `db:"id" json:"id"`
+	VersionID int64     `db:"version_id" json:"version_id"`
+	Title     string    `db:"title" json:"title"`
+	FileURL   string    `db:"file_url" json:"file_url"`
+	FileName  string    `db:"file_name" json:"file_name"`
+	FileSize  int64     `db:"file_size" json:"file_size"`
+	CreatedBy *int64    `db:"created_by" json:"created_by"`
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
+
+const profilSectionVersionColumns = `id, section, version, body, html, toc, reading_time, note, effective_date, created_by, created_at`
+
+const profilAttachmentColumns = `id, version_id, title, file_url, file_name, file_size, created_by, created_at`
+
+// GetCurrentProfilSections retrieves the latest version of every section that has one, keyed by section
+func GetCurrentProfilSections(db *sqlx.DB) (map[string]ProfilSectionVersion, error) {
+	versions := []ProfilSectionVersion{}
+	query := `
+		SELECT ` + profilSectionVersionColumns + `
+		FROM profil_section_versions v
+		WHERE version = (SELECT MAX(latest.version) FROM profil_section_versions latest WHERE latest.section = v.section)
+	`
+	if err := db.Select(&versions, query); err != nil {
+		return nil, err
+	}
+
+	current := map[string]ProfilSectionVersion{}
+	for _, version := range versions {
+		renderProfilVersion(db, &version)
+		current[version.Section] = version
+	}
+	return current, nil
+}
+
+// GetProfilSectionVersions retrieves the versions of a section, latest first
+func GetProfilSectionVersions(db *sqlx.DB, section string) ([]ProfilSectionVersion, error) {
+	versions := []ProfilSectionVersion{}
+	err := db.Select(&versions, `SELECT `+profilSectionVersionColumns+` FROM profil_section_versions WHERE section = ? ORDER BY version DESC`, section)
+	return versions, err
+}
+
+// FindProfilSectionVersion finds a version of a section by its number
+func FindProfilSectionVersion(db *sqlx.DB, section string, version int) (*ProfilSectionVersion, error) {
+	found := &ProfilSectionVersion{}
+	err := db.Get(found, `SELECT `+profilSectionVersionColumns+` FROM profil_section_versions WHERE section = ? AND version = ?`, section, version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	renderProfilVersion(db, found)
+	return found, nil
+}
+
+// renderProfilVersion renders and stores the HTML of a version imported without it
+// A failure is logged and the version is returned without HTML
+func renderProfilVersion(db *sqlx.DB, version *ProfilSectionVersion) {
+	if version.HTML != nil {
+		return
+	}
+
+	rendered, err := utils.RenderMarkdown(version.Body)
+	if err != nil {
+		log.Printf("[Profil] Cannot render %s version %d: %v", version.Section, version.Version, err)
+		return
+	}
+	version.HTML = &rendered.HTML
+	version.TOC = ContentTOC(rendered.TOC)
+	version.ReadingTime = rendered.ReadingTime
+
+	_, err = db.Exec(`UPDATE profil_section_versions SET html = ?, toc = ?, reading_time = ? WHERE id = ?`,
+		version.HTML, version.TOC, version.ReadingTime, version.ID)
+	if err != nil {
+		log.Printf("[Profil] Cannot store rendered %s version %d: %v", version.Section, version.Version, err)
+	}
+}
+
+// SaveProfilSections stores new versions of sections and makes them current
+// Version numbers are assigned here, one after the latest of each section. Sections saved together
+// share the transaction, so a partial update never leaves the profile half written
+func SaveProfilSections(db *sqlx.DB, versions []*ProfilSectionVersion) error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// The single profile row serializes concurrent saves
+	var profilID int64
+	err = tx.Get(&profilID, `SELECT id FROM profil_organisasi ORDER BY id ASC LIMIT 1 FOR UPDATE`)
+	if err == sql.ErrNoRows {
+		result, err := tx.Exec(`INSERT INTO profil_organisasi (created_at, updated_at) VALUES (NOW(), NOW())`)
+		if err != nil {
+			return err
+		}
+		if profilID, err = result.LastInsertId(); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, version := range versions {
+		var latest int
+		if err := tx.Get(&latest, `SELECT COALESCE(MAX(version), 0) FROM profil_section_versions WHERE section = ?`, version.Section); err != nil {
+			return err
+		}
+		version.Version = latest + 1
+		version.CreatedAt = now
+
+		result, err := tx.Exec(`
+			INSERT INTO profil_section_versions (section, version, body, html, toc, reading_time, note, effective_date, created_by, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, version.Section, version.Version, version.Body, version.HTML, version.TOC, version.ReadingTime, version.Note, version.EffectiveDate, version.CreatedBy, version.CreatedAt)
+		if err != nil {
+			return err
+		}
+		if version.ID, err = result.LastInsertId(); err != nil {
+			return err
+		}
+
+		// The section is one of ProfilSections, so it is a known column
+		if _, err := tx.Exec(`UPDATE profil_organisasi SET `+version.Section+` = ?, updated_at = ? WHERE id = ?`, version.Body, now, profilID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetProfilAttachments retrieves the attachments of versions keyed by version ID, oldest first
+func GetProfilAttachments(db *sqlx.DB, versionIDs []int64) (map[int64][]ProfilAttachment, error) {
+	byVersion := map[int64][]ProfilAttachment{}
+	if len(versionIDs) == 0 {
+		return byVersion, nil
+	}
+
+	query, args, err := sqlx.In(`SELECT `+profilAttachmentColumns+` FROM profil_attachments WHERE version_id IN (?) ORDER BY id ASC`, versionIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments := []ProfilAttachment{}
+	if err := db.Select(&attachments, db.Rebind(query), args...); err != nil {
+		return nil, err
+	}
+	for _, attachment := range attachments {
+		byVersion[attachment.VersionID] = append(byVersion[attachment.VersionID], attachment)
+	}
+	return byVersion, nil
+}
+
+// Create creates a new attachment record
+func (a *ProfilAttachment) Create(db *sqlx.DB) error {
+	a.CreatedAt = time.Now()
+
+	query := `
+		INSERT INTO profil_attachments (version_id, title, file_url, file_name, file_size, created_by, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+	result, err := db.Exec(query, a.VersionID, a.Title, a.FileURL, a.FileName, a.FileSize, a.CreatedBy, a.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	a.ID = id
+	return nil
+}
+
+// DeleteProfilAttachment deletes an attachment of a version and its file
+func DeleteProfilAttachment(db *sqlx.DB, versionID int64, id int64) error {
+	attachment := &ProfilAttachment{}
+	err := db.Get(attachment, `SELECT `+profilAttachmentColumns+` FROM profil_attachments WHERE id = ? AND version_id = ?`, id, versionID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrProfilAttachmentNotFound
+		}
+		return err
+	}
+
+	if _, err := db.Exec(`DELETE FROM profil_attachments WHERE id = ?`, attachment.ID); err != nil {
+		return err
+	}
+
+	if err := utils.DeleteFileByURL(attachment.FileURL); err != nil {
+		log.Printf("[Profil] Failed to delete file %s: %v", attachment.FileURL, err)
+	}
+	return nil
+}
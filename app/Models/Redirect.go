@@ -0,0 +1,148 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Redirect represents an admin-managed path-to-path redirect
+type Redirect struct {
+	ID         int64      `db:"id" json:"id"`
+	FromPath   string     `db:"from_path" json:"from_path"`
+	ToPath     string     `db:"to_path" json:"to_path"`
+	StatusCode int        `db:"status_code" json:"status_code"`
+	IsActive   bool       `db:"is_active" json:"is_active"`
+	Hits       int64      `db:"hits" json:"hits"`
+	LastHitAt  *time.Time `db:"last_hit_at" json:"last_hit_at"`
+	CreatedAt  time.Time  `db:"created_at" json:"created_at"`
+	UpdatedAt  time.Time  `db:"updated_at" json:"updated_at"`
+}
+
+// Create creates a new redirect record
+func (r *Redirect) Create(db *sqlx.DB) error {
+	r.CreatedAt = time.Now()
+	r.UpdatedAt = time.Now()
+
+	query := `
+		INSERT INTO redirects (from_path, to_path, status_code, is_active, hits, created_at, updated_at)
+		VALUES (?, ?, ?, ?, 0, ?, ?)
+	`
+	result, err := db.Exec(query, r.FromPath, r.ToPath, r.StatusCode, r.IsActive, r.CreatedAt, r.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	r.ID = id
+	return nil
+}
+
+// FindRedirectByID finds a redirect by ID
+func FindRedirectByID(db *sqlx.DB, id int64) (*Redirect, error) {
+	redirect := &Redirect{}
+	query := `
+		SELECT id, from_path, to_path, status_code, is_active, hits, last_hit_at, created_at, updated_at
+		FROM redirects
+		WHERE id = ?
+	`
+	err := db.Get(redirect, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return redirect, nil
+}
+
+// FindActiveRedirectByPath finds an active redirect by its source path
+func FindActiveRedirectByPath(db *sqlx.DB, fromPath string) (*Redirect, error) {
+	redirect := &Redirect{}
+	query := `
+		SELECT id, from_path, to_path, status_code, is_active, hits, last_hit_at, created_at, updated_at
+		FROM redirects
+		WHERE from_path = ? AND is_active = TRUE
+	`
+	err := db.Get(redirect, query, fromPath)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return redirect, nil
+}
+
+// GetAllRedirects retrieves all redirects with filters and pagination
+func GetAllRedirects(db *sqlx.DB, filters map[string]interface{}, offset int, limit int) ([]Redirect, int64, error) {
+	redirects := []Redirect{}
+
+	query := `SELECT id, from_path, to_path, status_code, is_active, hits, last_hit_at, created_at, updated_at FROM redirects WHERE 1=1`
+	countQuery := `SELECT COUNT(*) FROM redirects WHERE 1=1`
+
+	args := []interface{}{}
+	if search, ok := filters["search"].(string); ok && search != "" {
+		query += ` AND (from_path LIKE ? OR to_path LIKE ?)`
+		countQuery += ` AND (from_path LIKE ? OR to_path LIKE ?)`
+		searchPattern := "%" + search + "%"
+		args = append(args, searchPattern, searchPattern)
+	}
+	if isActive, ok := filters["is_active"].(bool); ok {
+		query += ` AND is_active = ?`
+		countQuery += ` AND is_active = ?`
+		args = append(args, isActive)
+	}
+
+	// Get total count
+	var total int64
+	err := db.Get(&total, countQuery, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Add sorting and pagination
+	query += ` ORDER BY created_at DESC LIMIT ? OFFSET ?`
+	paginationArgs := append(args, limit, offset)
+
+	err = db.Select(&redirects, query, paginationArgs...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return redirects, total, nil
+}
+
+// Update updates a redirect record
+func (r *Redirect) Update(db *sqlx.DB) error {
+	r.UpdatedAt = time.Now()
+	query := `
+		UPDATE redirects
+		SET from_path = ?, to_path = ?, status_code = ?, is_active = ?, updated_at = ?
+		WHERE id = ?
+	`
+	_, err := db.Exec(query, r.FromPath, r.ToPath, r.StatusCode, r.IsActive, r.UpdatedAt, r.ID)
+	return err
+}
+
+// Delete permanently deletes a redirect record
+func (r *Redirect) Delete(db *sqlx.DB) error {
+	_, err := db.Exec(`DELETE FROM redirects WHERE id = ?`, r.ID)
+	return err
+}
+
+// RecordHit increments the hit counter of a redirect
+func (r *Redirect) RecordHit(db *sqlx.DB) error {
+	now := time.Now()
+	_, err := db.Exec(`UPDATE redirects SET hits = hits + 1, last_hit_at = ? WHERE id = ?`, now, r.ID)
+	if err != nil {
+		return err
+	}
+	r.Hits++
+	r.LastHitAt = &now
+	return nil
+}
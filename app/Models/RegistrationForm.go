@@ -0,0 +1,54 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+
+	"github.com/cvudumbarainformatika/backend/utils"
+)
+
+// RegistrationForm handles JSON marshaling for the custom registration form of an agenda
+type RegistrationForm []utils.FormField
+
+func (f RegistrationForm) Value() (driver.Value, error) {
+	// An empty form is stored as NULL
+	if len(f) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(f)
+}
+
+func (f *RegistrationForm) Scan(value interface{}) error {
+	if value == nil {
+		*f = nil
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(b, f)
+}
+
+// RegistrationAnswers handles JSON marshaling for the answers of a registration, keyed by field name
+type RegistrationAnswers map[string]interface{}
+
+func (a RegistrationAnswers) Value() (driver.Value, error) {
+	if len(a) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(a)
+}
+
+func (a *RegistrationAnswers) Scan(value interface{}) error {
+	if value == nil {
+		*a = nil
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(b, a)
+}
@@ -0,0 +1,153 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/cvudumbarainformatika/backend/utils"
+	"github.com/jmoiron/sqlx"
+)
+
+// SKPEntry is a credit (SKP) posting in a member's ledger, each one backed by a certificate
+type SKPEntry struct {
+	ID              int64     `db:"id" json:"id"`
+	UserID          int64     `db:"user_id" json:"user_id"`
+	AgendaID        *int64    `db:"agenda_id" json:"agenda_id"`
+	RegistrationID  *int64    `db:"registration_id" json:"registration_id"`
+	Points          float64   `db:"points" json:"points"`
+	Description     string    `db:"description" json:"description"`
+	MemberName      string    `db:"member_name" json:"member_name"`
+	AgendaTitle     string    `db:"agenda_title" json:"agenda_title"`
+	AgendaDate      time.Time `db:"agenda_date" json:"agenda_date"`
+	EarnedAt        time.Time `db:"earned_at" json:"earned_at"`
+	CertificateCode string    `db:"certificate_code" json:"certificate_code"`
+	CreatedAt       time.Time `db:"created_at" json:"created_at"`
+}
+
+// SKPYearTotal sums the SKP earned in a year
+type SKPYearTotal struct {
+	Year    int     `db:"year" json:"year"`
+	Points  float64 `db:"points" json:"points"`
+	Entries int     `db:"entries" json:"entries"`
+}
+
+const skpEntryColumns = `id, user_id, agenda_id, registration_id, points, description, member_name, agenda_title, agenda_date, earned_at, certificate_code, created_at`
+
+// skpYearExpr is the year of earned_at (stored in UTC) in a time zone, the offset in seconds is bound as parameter
+const skpYearExpr = `YEAR(DATE_ADD(earned_at, INTERVAL ? SECOND))`
+
+// utcOffset returns the current UTC offset in seconds of a time zone
+func utcOffset(loc *time.Location) int {
+	_, offset := time.Now().In(loc).Zone()
+	return offset
+}
+
+// skpCertificateCodeAttempts is how often a certificate code is regenerated after a collision
+const skpCertificateCodeAttempts = 3
+
+// ErrSKPNotPosted is returned when no ledger entry could be posted for a registration
+var ErrSKPNotPosted = errors.New("skp ledger entry could not be posted")
+
+// postSKPForRegistration posts the SKP of an attended agenda to the member's ledger
+// Posting is idempotent per registration (uk_skp_ledger_registration). A duplicate key only
+// leaves the existing row as it is, so the entry of the registration is checked afterwards and
+// a certificate code collision is retried with a new code
+func postSKPForRegistration(tx *sqlx.Tx, registrationID int64) error {
+	for attempt := 0; attempt < skpCertificateCodeAttempts; attempt++ {
+		code, err := utils.GenerateToken(8)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO skp_ledger (user_id, agenda_id, registration_id, points, description, member_name, agenda_title, agenda_date, earned_at, certificate_code, created_at)
+			SELECT r.user_id, a.id, r.id, COALESCE(a.skp, 0), LEFT(CONCAT('Attendance: ', a.title), 255), u.name, a.title, a.date, a.date, ?, ?
+			FROM agenda_registrations r
+			JOIN agenda a ON a.id = r.agenda_id
+			JOIN users u ON u.id = r.user_id
+			WHERE r.id = ?
+			ON DUPLICATE KEY UPDATE id = id
+		`, strings.ToUpper(code), time.Now(), registrationID)
+		if err != nil {
+			return err
+		}
+
+		var count int
+		if err := tx.Get(&count, `SELECT COUNT(*) FROM skp_ledger WHERE registration_id = ?`, registrationID); err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+	}
+	return ErrSKPNotPosted
+}
+
+// FindSKPEntryByID finds a ledger entry by ID
+func FindSKPEntryByID(db *sqlx.DB, id int64) (*SKPEntry, error) {
+	entry := &SKPEntry{}
+	err := db.Get(entry, `SELECT `+skpEntryColumns+` FROM skp_ledger WHERE id = ?`, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return entry, nil
+}
+
+// FindSKPEntryByCertificateCode finds a ledger entry by its certificate verification code
+func FindSKPEntryByCertificateCode(db *sqlx.DB, code string) (*SKPEntry, error) {
+	entry := &SKPEntry{}
+	err := db.Get(entry, `SELECT `+skpEntryColumns+` FROM skp_ledger WHERE certificate_code = ?`, strings.ToUpper(code))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return entry, nil
+}
+
+// GetUserSKPEntries retrieves the ledger of a user with filters and pagination, newest first
+// The year filter is evaluated in the given time zone
+func GetUserSKPEntries(db *sqlx.DB, userID int64, filters map[string]interface{}, loc *time.Location, offset int, limit int) ([]SKPEntry, int64, error) {
+	entries := []SKPEntry{}
+
+	where := ` WHERE user_id = ?`
+	args := []interface{}{userID}
+
+	if year, ok := filters["year"].(int); ok && year > 0 {
+		where += ` AND ` + skpYearExpr + ` = ?`
+		args = append(args, utcOffset(loc), year)
+	}
+
+	// Get total count
+	var total int64
+	if err := db.Get(&total, `SELECT COUNT(*) FROM skp_ledger`+where, args...); err != nil {
+		return nil, 0, err
+	}
+
+	query := `SELECT ` + skpEntryColumns + ` FROM skp_ledger` + where + ` ORDER BY earned_at DESC, id DESC LIMIT ? OFFSET ?`
+	if err := db.Select(&entries, query, append(args, limit, offset)...); err != nil {
+		return nil, 0, err
+	}
+
+	return entries, total, nil
+}
+
+// GetUserSKPYearTotals sums the SKP of a user per year in the given time zone, newest year first
+func GetUserSKPYearTotals(db *sqlx.DB, userID int64, loc *time.Location) ([]SKPYearTotal, error) {
+	totals := []SKPYearTotal{}
+	query := `
+		SELECT ` + skpYearExpr + ` AS year, COALESCE(SUM(points), 0) AS points, COUNT(*) AS entries
+		FROM skp_ledger
+		WHERE user_id = ?
+		GROUP BY year
+		ORDER BY year DESC
+	`
+	err := db.Select(&totals, query, utcOffset(loc), userID)
+	return totals, err
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// SitemapEntry represents a single public page listed in the sitemap
+type SitemapEntry struct {
+	Path     string    `db:"path" json:"path"`
+	LastMod  time.Time `db:"lastmod" json:"lastmod"`
+	ImageURL string    `db:"image_url" json:"image_url"`
+}
+
+// GetSitemapEntries collects all public pages (berita, agenda, dynamic content and menu targets)
+func GetSitemapEntries(db *sqlx.DB) ([]SitemapEntry, error) {
+	var entries []SitemapEntry
+
+	// Published berita
+	var berita []SitemapEntry
+	query := `
+		SELECT CONCAT('/berita/', slug) AS path, updated_at AS lastmod, COALESCE(image_url, '') AS image_url
+		FROM berita
+		WHERE deleted_at IS NULL AND status = 'published'
+		ORDER BY updated_at DESC
+	`
+	if err := db.Select(&berita, query); err != nil {
+		return nil, err
+	}
+	entries = append(entries, berita...)
+
+	// Published agenda
+	var agenda []SitemapEntry
+	query = `
+		SELECT CONCAT('/agenda/', slug) AS path, updated_at AS lastmod, COALESCE(image_url, '') AS image_url
+		FROM agenda
+		WHERE deleted_at IS NULL AND status = 'published'
+		ORDER BY updated_at DESC
+	`
+	if err := db.Select(&agenda, query); err != nil {
+		return nil, err
+	}
+	entries = append(entries, agenda...)
+
+	// Dynamic content pages
+	var pages []SitemapEntry
+	query = `
+		SELECT CONCAT('/', slug) AS path, updated_at AS lastmod, COALESCE(image_src, '') AS image_url
+		FROM content_pages
+		ORDER BY updated_at DESC
+	`
+	if err := db.Select(&pages, query); err != nil {
+		return nil, err
+	}
+	entries = append(entries, pages...)
+
+	// Menu targets (internal links visible to the public)
+	var menus []Menu
+	query = "SELECT id, label, slug, `to`, icon, parent_id, position, `order`, is_active, is_fixed, roles, created_at, updated_at FROM menus WHERE is_active = TRUE AND `to` LIKE '/%'"
+	if err := db.Select(&menus, query); err != nil {
+		return nil, err
+	}
+	for _, menu := range menus {
+		if !isPublicMenu(menu) {
+			continue
+		}
+		entries = append(entries, SitemapEntry{Path: menu.To, LastMod: menu.UpdatedAt})
+	}
+
+	return dedupeSitemapEntries(entries), nil
+}
+
+// isPublicMenu checks whether a menu is visible to anonymous visitors
+func isPublicMenu(menu Menu) bool {
+	if menu.Roles == "" {
+		return true
+	}
+	var roles []string
+	if err := json.Unmarshal([]byte(menu.Roles), &roles); err != nil || len(roles) == 0 {
+		return true
+	}
+	for _, role := range roles {
+		if role == "public" {
+			return true
+		}
+	}
+	return false
+}
+
+// dedupeSitemapEntries removes duplicate paths, keeping the latest lastmod and first known image
+func dedupeSitemapEntries(entries []SitemapEntry) []SitemapEntry {
+	index := make(map[string]int)
+	result := make([]SitemapEntry, 0, len(entries))
+
+	for _, entry := range entries {
+		// Normalize path: strip query/fragment and trailing slash (except root)
+		path := entry.Path
+		if i := strings.IndexAny(path, "?#"); i >= 0 {
+			path = path[:i]
+		}
+		if len(path) > 1 {
+			path = strings.TrimRight(path, "/")
+		}
+		entry.Path = path
+
+		if i, exists := index[path]; exists {
+			if entry.LastMod.After(result[i].LastMod) {
+				result[i].LastMod = entry.LastMod
+			}
+			if result[i].ImageURL == "" {
+				result[i].ImageURL = entry.ImageURL
+			}
+			continue
+		}
+
+		index[path] = len(result)
+		result = append(result, entry)
+	}
+
+	return result
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Entity types tracked in slug history
+const (
+	SlugEntityBerita  = "berita"
+	SlugEntityAgenda  = "agenda"
+	SlugEntityContent = "content"
+)
+
+// slugEntityQueries maps an entity type to the query resolving its current slug by ID
+var slugEntityQueries = map[string]string{
+	SlugEntityBerita:  `SELECT slug FROM berita WHERE id = ? AND deleted_at IS NULL`,
+	SlugEntityAgenda:  `SELECT slug FROM agenda WHERE id = ? AND deleted_at IS NULL`,
+	SlugEntityContent: `SELECT slug FROM content_pages WHERE id = ?`,
+}
+
+// SlugHistory represents a previous slug of an entity
+type SlugHistory struct {
+	ID         int64     `db:"id" json:"id"`
+	EntityType string    `db:"entity_type" json:"entity_type"`
+	EntityID   int64     `db:"entity_id" json:"entity_id"`
+	OldSlug    string    `db:"old_slug" json:"old_slug"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at"`
+}
+
+// RecordSlugChange stores the old slug of an entity when its slug changes
+// The new slug is removed from history so it can no longer redirect elsewhere
+func RecordSlugChange(db *sqlx.DB, entityType string, entityID int64, oldSlug string, newSlug string) error {
+	if oldSlug == "" || oldSlug == newSlug {
+		return nil
+	}
+
+	_, err := db.Exec(`DELETE FROM slug_histories WHERE entity_type = ? AND old_slug = ?`, entityType, newSlug)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO slug_histories (entity_type, entity_id, old_slug, created_at)
+		VALUES (?, ?, ?, ?)
+		ON DUPLICATE KEY UPDATE entity_id = VALUES(entity_id), created_at = VALUES(created_at)
+	`
+	_, err = db.Exec(query, entityType, entityID, oldSlug, time.Now())
+	return err
+}
+
+// FindCurrentSlug resolves an old slug to the current slug of the same entity
+// Returns an empty string if the slug has no history or the entity no longer exists
+func FindCurrentSlug(db *sqlx.DB, entityType string, oldSlug string) (string, error) {
+	slugQuery, ok := slugEntityQueries[entityType]
+	if !ok {
+		return "", nil
+	}
+
+	var entityID int64
+	err := db.Get(&entityID, `SELECT entity_id FROM slug_histories WHERE entity_type = ? AND old_slug = ?`, entityType, oldSlug)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", err
+	}
+
+	var currentSlug string
+	err = db.Get(&currentSlug, slugQuery, entityID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", nil
+		}
+		return "", err
+	}
+
+	if currentSlug == oldSlug {
+		return "", nil
+	}
+	return currentSlug, nil
+}
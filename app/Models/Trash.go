@@ -0,0 +1,290 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/cvudumbarainformatika/backend/utils"
+	"github.com/jmoiron/sqlx"
+)
+
+// ErrTrashItemNotFound is returned when an item is not in the trash
+var ErrTrashItemNotFound = errors.New("trash item not found")
+
+// trashType describes how a soft-deletable table is exposed in the trash bin
+type trashType struct {
+	Table       string
+	TitleColumn string
+	SlugEntity  string // Slug history entity type (empty when the table has no slug)
+	FileColumn  string // Column holding an uploaded file URL (empty when none)
+	CabangExpr  string // Expression selecting the owning cabang (empty when items belong to pusat)
+}
+
+// TrashTypes lists all soft-deletable types keyed by their trash type name
+var TrashTypes = map[string]trashType{
+	"berita":    {Table: "berita", TitleColumn: "title", SlugEntity: SlugEntityBerita, FileColumn: "image_url", CabangExpr: "cabang"},
+	"agenda":    {Table: "agenda", TitleColumn: "title", SlugEntity: SlugEntityAgenda, FileColumn: "image_url", CabangExpr: "cabang"},
+	"direktori": {Table: "direktori", TitleColumn: "name"},
+	"pengurus":  {Table: "pengurus", TitleColumn: "name", FileColumn: "photo_url"},
+	"documents": {Table: "documents", TitleColumn: "name", FileColumn: "file_url", CabangExpr: "(SELECT u.cabang FROM users u WHERE u.id = documents.user_id)"},
+}
+
+// trashTypeOrder keeps the union query and listings deterministic
+var trashTypeOrder = []string{"berita", "agenda", "direktori", "pengurus", "documents"}
+
+// cabangColumn returns the select expression of the owning cabang
+func (tt trashType) cabangColumn() string {
+	if tt.CabangExpr == "" {
+		return "NULL"
+	}
+	return tt.CabangExpr
+}
+
+// TrashItem represents a soft-deleted item of any type
+type TrashItem struct {
+	Type      string    `db:"type" json:"type"`
+	ID        int64     `db:"id" json:"id"`
+	Title     string    `db:"title" json:"title"`
+	Slug      *string   `db:"slug" json:"slug"`
+	Cabang    *string   `db:"cabang" json:"cabang"` // Owning cabang, nil = pusat
+	DeletedAt time.Time `db:"deleted_at" json:"deleted_at"`
+}
+
+// GetTrashItems retrieves soft-deleted items across all types with filters and pagination
+// Supported filters: type, search, cabang, deleted_from, deleted_to (time.Time)
+// With a cabang filter, types without an owning cabang are left out
+func GetTrashItems(db *sqlx.DB, filters map[string]interface{}, offset int, limit int) ([]TrashItem, int64, error) {
+	items := []TrashItem{}
+
+	types := trashTypeOrder
+	if typeVal, ok := filters["type"].(string); ok && typeVal != "" {
+		types = []string{typeVal}
+	}
+
+	parts := []string{}
+	args := []interface{}{}
+	for _, name := range types {
+		tt, ok := TrashTypes[name]
+		if !ok {
+			continue
+		}
+		cabang, hasCabang := filters["cabang"].(string)
+		if hasCabang && cabang != "" && tt.CabangExpr == "" {
+			continue
+		}
+
+		slugColumn := "NULL"
+		if tt.SlugEntity != "" {
+			slugColumn = "slug"
+		}
+
+		part := `SELECT '` + name + `' AS type, id, ` + tt.TitleColumn + ` AS title, ` + slugColumn + ` AS slug, ` + tt.cabangColumn() + ` AS cabang, deleted_at FROM ` + tt.Table + ` WHERE deleted_at IS NOT NULL`
+		if hasCabang && cabang != "" {
+			part += ` AND ` + tt.CabangExpr + ` = ?`
+			args = append(args, cabang)
+		}
+		if search, ok := filters["search"].(string); ok && search != "" {
+			part += ` AND ` + tt.TitleColumn + ` LIKE ?`
+			args = append(args, "%"+search+"%")
+		}
+		if from, ok := filters["deleted_from"].(time.Time); ok {
+			part += ` AND deleted_at >= ?`
+			args = append(args, from)
+		}
+		if to, ok := filters["deleted_to"].(time.Time); ok {
+			part += ` AND deleted_at < ?`
+			args = append(args, to)
+		}
+		parts = append(parts, part)
+	}
+
+	if len(parts) == 0 {
+		return items, 0, nil
+	}
+
+	union := strings.Join(parts, ` UNION ALL `)
+
+	// Get total count
+	var total int64
+	err := db.Get(&total, `SELECT COUNT(*) FROM (`+union+`) AS trash`, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Add sorting and pagination
+	query := `SELECT type, id, title, slug, cabang, deleted_at FROM (` + union + `) AS trash ORDER BY deleted_at DESC, id DESC LIMIT ? OFFSET ?`
+	paginationArgs := append(args, limit, offset)
+
+	err = db.Select(&items, query, paginationArgs...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return items, total, nil
+}
+
+// FindTrashItem finds a soft-deleted item by type and ID
+func FindTrashItem(db *sqlx.DB, itemType string, id int64) (*TrashItem, error) {
+	tt, ok := TrashTypes[itemType]
+	if !ok {
+		return nil, nil
+	}
+
+	slugColumn := "NULL"
+	if tt.SlugEntity != "" {
+		slugColumn = "slug"
+	}
+
+	item := &TrashItem{}
+	query := `SELECT '` + itemType + `' AS type, id, ` + tt.TitleColumn + ` AS title, ` + slugColumn + ` AS slug, ` + tt.cabangColumn() + ` AS cabang, deleted_at FROM ` + tt.Table + ` WHERE id = ? AND deleted_at IS NOT NULL`
+	err := db.Get(item, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return item, nil
+}
+
+// RestoreTrashItem restores a soft-deleted item
+// If another live item took the slug in the meantime, a unique slug is assigned
+func RestoreTrashItem(db *sqlx.DB, itemType string, id int64) (*TrashItem, error) {
+	item, err := FindTrashItem(db, itemType, id)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, ErrTrashItemNotFound
+	}
+
+	tt := TrashTypes[itemType]
+	now := time.Now()
+
+	if item.Slug == nil {
+		_, err = db.Exec(`UPDATE `+tt.Table+` SET deleted_at = NULL, updated_at = ? WHERE id = ? AND deleted_at IS NOT NULL`, now, id)
+		return item, err
+	}
+
+	slug, err := restoreWithUniqueSlug(db, tt.Table, id, *item.Slug)
+	if err != nil {
+		return nil, err
+	}
+
+	item.Slug = &slug
+	return item, nil
+}
+
+// restoreWithUniqueSlug clears deleted_at of a row, renaming its slug when a live row took it
+// Returns the slug the row was restored with
+func restoreWithUniqueSlug(q sqlx.Ext, table string, id int64, slug string) (string, error) {
+	// Collect live slugs that could conflict with the restored one
+	var takenSlugs []string
+	err := sqlx.Select(q, &takenSlugs, `SELECT slug FROM `+table+` WHERE deleted_at IS NULL AND (slug = ? OR slug LIKE ?)`, slug, slug+"-%")
+	if err != nil {
+		return "", err
+	}
+
+	existing := make(map[string]bool, len(takenSlugs))
+	for _, taken := range takenSlugs {
+		existing[taken] = true
+	}
+	uniqueSlug := utils.GenerateUniqueSlug(slug, existing)
+
+	_, err = q.Exec(`UPDATE `+table+` SET slug = ?, deleted_at = NULL, updated_at = ? WHERE id = ? AND deleted_at IS NOT NULL`, uniqueSlug, time.Now(), id)
+	if err != nil {
+		return "", err
+	}
+
+	return uniqueSlug, nil
+}
+
+// PurgeTrashItem permanently deletes a soft-deleted item
+// Its uploaded file is removed as well unless another record still uses it
+func PurgeTrashItem(db *sqlx.DB, itemType string, id int64) error {
+	fileURL, err := forceDeleteTrashItem(db, itemType, id)
+	if err != nil || fileURL == "" {
+		return err
+	}
+
+	referenced, err := IsFileReferenced(db, fileURL)
+	if err != nil || referenced {
+		return err
+	}
+
+	if err := utils.DeleteFileByURL(fileURL); err != nil {
+		log.Printf("[Trash] Failed to delete file %s: %v", fileURL, err)
+	}
+	return nil
+}
+
+// forceDeleteTrashItem hard deletes a soft-deleted item
+// Returns the uploaded file URL that belonged to the item (empty when none)
+func forceDeleteTrashItem(db *sqlx.DB, itemType string, id int64) (string, error) {
+	tt, ok := TrashTypes[itemType]
+	if !ok {
+		return "", ErrTrashItemNotFound
+	}
+
+	var fileURL sql.NullString
+	if tt.FileColumn != "" {
+		err := db.Get(&fileURL, `SELECT `+tt.FileColumn+` FROM `+tt.Table+` WHERE id = ? AND deleted_at IS NOT NULL`, id)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return "", ErrTrashItemNotFound
+			}
+			return "", err
+		}
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM `+tt.Table+` WHERE id = ? AND deleted_at IS NOT NULL`, id)
+	if err != nil {
+		return "", err
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return "", ErrTrashItemNotFound
+	}
+
+	if tt.SlugEntity != "" {
+		if _, err := tx.Exec(`DELETE FROM slug_histories WHERE entity_type = ? AND entity_id = ?`, tt.SlugEntity, id); err != nil {
+			return "", err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+
+	return fileURL.String, nil
+}
+
+// GetExpiredTrashItems retrieves items deleted before the given time
+func GetExpiredTrashItems(db *sqlx.DB, before time.Time, limit int) ([]TrashItem, error) {
+	items, _, err := GetTrashItems(db, map[string]interface{}{"deleted_to": before}, 0, limit)
+	return items, err
+}
+
+// IsFileReferenced checks whether an uploaded file URL is still used by any record
+func IsFileReferenced(db *sqlx.DB, fileURL string) (bool, error) {
+	var count int64
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM berita WHERE image_url = ?) +
+			(SELECT COUNT(*) FROM agenda WHERE image_url = ?) +
+			(SELECT COUNT(*) FROM documents WHERE file_url = ?) +
+			(SELECT COUNT(*) FROM content_pages WHERE image_src = ?) +
+			(SELECT COUNT(*) FROM pengurus WHERE photo_url = ?) +
+			(SELECT COUNT(*) FROM agenda_speakers WHERE photo_url = ?)
+	`
+	err := db.Get(&count, query, fileURL, fileURL, fileURL, fileURL, fileURL, fileURL)
+	return count > 0, err
+}
@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	"time"
 
+	"github.com/cvudumbarainformatika/backend/utils"
 	"github.com/jmoiron/sqlx"
 )
 
@@ -18,6 +19,8 @@ type User struct {
 	Cabang    sql.NullString `db:"cabang" json:"cabang"` // Branch/office location
 	Phone     sql.NullString `db:"phone" json:"phone"`
 	Address   sql.NullString `db:"address" json:"address"`
+	Province  sql.NullString `db:"province" json:"province"` // Reference name of the province
+	City      sql.NullString `db:"city" json:"city"`         // Reference name of the regency or city
 	Bio       sql.NullString `db:"bio" json:"bio"`
 	Avatar    sql.NullString `db:"avatar" json:"avatar"`
 	CreatedAt time.Time      `db:"created_at" json:"created_at"`
@@ -36,10 +39,10 @@ func (u *User) Create(db *sqlx.DB) error {
 	}
 
 	query := `
-		INSERT INTO users (name, email, password, role, status, cabang, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO users (name, email, password, role, status, cabang, phone, address, province, city, bio, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
-	result, err := db.Exec(query, u.Name, u.Email, u.Password, u.Role, u.Status, u.Cabang.String, u.CreatedAt, u.UpdatedAt)
+	result, err := db.Exec(query, u.Name, u.Email, u.Password, u.Role, u.Status, u.Cabang.String, u.Phone, u.Address, u.Province, u.City, u.Bio, u.CreatedAt, u.UpdatedAt)
 	if err != nil {
 		return err
 	}
@@ -55,7 +58,7 @@ func (u *User) Create(db *sqlx.DB) error {
 // FindByEmail finds a user by email
 func FindByEmail(db *sqlx.DB, email string) (*User, error) {
 	user := &User{}
-	query := `SELECT id, name, email, password, role, status, cabang, phone, address, bio, avatar, created_at, updated_at FROM users WHERE email = ?`
+	query := `SELECT id, name, email, password, role, status, cabang, phone, address, province, city, bio, avatar, created_at, updated_at FROM users WHERE email = ?`
 	err := db.Get(user, query, email)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -69,7 +72,7 @@ func FindByEmail(db *sqlx.DB, email string) (*User, error) {
 // FindByID finds a user by ID
 func FindByID(db *sqlx.DB, id int64) (*User, error) {
 	user := &User{}
-	query := `SELECT id, name, email, password, role, status, cabang, phone, address, bio, avatar, created_at, updated_at FROM users WHERE id = ?`
+	query := `SELECT id, name, email, password, role, status, cabang, phone, address, province, city, bio, avatar, created_at, updated_at FROM users WHERE id = ?`
 	err := db.Get(user, query, id)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -92,7 +95,7 @@ func GetAll(db *sqlx.DB, offset int, limit int) ([]User, int64, error) {
 	}
 
 	// Get paginated results
-	query := `SELECT id, name, email, password, role, status, cabang, phone, address, bio, avatar, created_at, updated_at FROM users WHERE status != 'deleted' ORDER BY created_at DESC LIMIT ? OFFSET ?`
+	query := `SELECT id, name, email, password, role, status, cabang, phone, address, province, city, bio, avatar, created_at, updated_at FROM users WHERE status != 'deleted' ORDER BY created_at DESC LIMIT ? OFFSET ?`
 	err := db.Select(&users, query, limit, offset)
 	if err != nil {
 		return nil, 0, err
@@ -106,10 +109,10 @@ func (u *User) Update(db *sqlx.DB) error {
 	u.UpdatedAt = time.Now()
 	query := `
 		UPDATE users 
-		SET name = ?, email = ?, role = ?, status = ?, cabang = ?, phone = ?, address = ?, bio = ?, avatar = ?, updated_at = ?
+		SET name = ?, email = ?, role = ?, status = ?, cabang = ?, phone = ?, address = ?, province = ?, city = ?, bio = ?, avatar = ?, updated_at = ?
 		WHERE id = ?
 	`
-	_, err := db.Exec(query, u.Name, u.Email, u.Role, u.Status, u.Cabang.String, u.Phone.String, u.Address.String, u.Bio.String, u.Avatar.String, u.UpdatedAt, u.ID)
+	_, err := db.Exec(query, u.Name, u.Email, u.Role, u.Status, u.Cabang.String, u.Phone.String, u.Address.String, u.Province, u.City, u.Bio.String, u.Avatar.String, u.UpdatedAt, u.ID)
 	return err
 }
 
@@ -121,15 +124,15 @@ func (u *User) UpdatePassword(db *sqlx.DB, newPassword string) error {
 	return err
 }
 
-// UpdateProfile updates a user's profile information (name, phone, address, bio, avatar, cabang)
+// UpdateProfile updates a user's profile information (name, phone, address, province, city, bio, avatar, cabang)
 func (u *User) UpdateProfile(db *sqlx.DB) error {
 	u.UpdatedAt = time.Now()
 	query := `
 		UPDATE users 
-		SET name = ?, phone = ?, address = ?, bio = ?, avatar = ?, cabang = ?, updated_at = ?
+		SET name = ?, phone = ?, address = ?, province = ?, city = ?, bio = ?, avatar = ?, cabang = ?, updated_at = ?
 		WHERE id = ?
 	`
-	_, err := db.Exec(query, u.Name, u.Phone.String, u.Address.String, u.Bio.String, u.Avatar.String, u.Cabang.String, u.UpdatedAt, u.ID)
+	_, err := db.Exec(query, u.Name, u.Phone.String, u.Address.String, u.Province, u.City, u.Bio.String, u.Avatar.String, u.Cabang.String, u.UpdatedAt, u.ID)
 	return err
 }
 
@@ -141,3 +144,42 @@ func (u *User) Delete(db *sqlx.DB) error {
 	_, err := db.Exec(query, u.Status, u.UpdatedAt, u.ID)
 	return err
 }
+
+// GetCalendarToken returns the private calendar feed token of a user, creating one if needed
+func GetCalendarToken(db *sqlx.DB, userID int64) (string, error) {
+	var token sql.NullString
+	if err := db.Get(&token, `SELECT calendar_token FROM users WHERE id = ?`, userID); err != nil {
+		return "", err
+	}
+	if token.Valid && token.String != "" {
+		return token.String, nil
+	}
+	return ResetCalendarToken(db, userID)
+}
+
+// ResetCalendarToken replaces the private calendar feed token of a user, invalidating the old feed URL
+func ResetCalendarToken(db *sqlx.DB, userID int64) (string, error) {
+	token, err := utils.GenerateToken(24)
+	if err != nil {
+		return "", err
+	}
+	_, err = db.Exec(`UPDATE users SET calendar_token = ?, updated_at = ? WHERE id = ?`, token, time.Now(), userID)
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// FindByCalendarToken finds an active user by private calendar feed token
+func FindByCalendarToken(db *sqlx.DB, token string) (*User, error) {
+	user := &User{}
+	query := `SELECT id, name, email, password, role, status, cabang, phone, address, province, city, bio, avatar, created_at, updated_at FROM users WHERE calendar_token = ? AND status = 'active'`
+	err := db.Get(user, query, token)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return user, nil
+}
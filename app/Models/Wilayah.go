@@ -0,0 +1,390 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Regency types
+const (
+	RegencyTypeKabupaten = "kabupaten"
+	RegencyTypeKota      = "kota"
+)
+
+var (
+	ErrWilayahNotSeeded = errors.New("province and regency reference data has not been seeded")
+	ErrUnknownProvince  = errors.New("unknown province")
+	ErrUnknownRegency   = errors.New("unknown regency or city")
+	ErrAmbiguousRegency = errors.New("ambiguous regency or city")
+)
+
+// Aliases handles JSON marshaling for the alternative spellings of a province or regency
+type Aliases []string
+
+func (a Aliases) Value() (driver.Value, error) {
+	if a == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+func (a *Aliases) Scan(value interface{}) error {
+	if value == nil {
+		*a = Aliases{}
+		return nil
+	}
+	b, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+	return json.Unmarshal(b, a)
+}
+
+// Province is an Indonesian province with its Kemendagri code
+type Province struct {
+	Code    string  `db:"code" json:"code"`
+	Name    string  `db:"name" json:"name"`
+	Aliases Aliases `db:"aliases" json:"aliases"`
+}
+
+// Regency is a regency (kabupaten) or city (kota) with its Kemendagri code
+type Regency struct {
+	Code         string  `db:"code" json:"code"`
+	ProvinceCode string  `db:"province_code" json:"province_code"`
+	Name         string  `db:"name" json:"name"`
+	Type         string  `db:"type" json:"type"`
+	Aliases      Aliases `db:"aliases" json:"aliases"`
+}
+
+// RegionError explains why a province or city could not be normalized
+// Candidates lists the regencies an ambiguous name could mean, or those of other provinces for an unknown one
+type RegionError struct {
+	Field      string
+	Value      string
+	Err        error
+	Candidates []string
+}
+
+func (e *RegionError) Error() string {
+	switch {
+	case errors.Is(e.Err, ErrAmbiguousRegency):
+		return fmt.Sprintf("%q could be %s", e.Value, strings.Join(e.Candidates, " or "))
+	case errors.Is(e.Err, ErrUnknownProvince):
+		return fmt.Sprintf("%q is not a known province", e.Value)
+	case len(e.Candidates) > 0:
+		return fmt.Sprintf("%q is not in the province, did you mean %s", e.Value, strings.Join(e.Candidates, " or "))
+	default:
+		return fmt.Sprintf("%q is not a known regency or city of the province", e.Value)
+	}
+}
+
+func (e *RegionError) Unwrap() error {
+	return e.Err
+}
+
+// WilayahIndex resolves free text province and regency names against the reference data
+type WilayahIndex struct {
+	Provinces []Province
+	Regencies []Regency
+
+	provinceByKey  map[string]int   // Normalized name, alias or code to Provinces index
+	regencyByKey   map[string][]int // Province code + full normalized name or alias to Regencies indexes
+	regencyByBare  map[string][]int // Province code + name without kabupaten/kota prefix to Regencies indexes
+	regencyByCode  map[string]int
+	regencyOfCode  map[string][]int // Province code to Regencies indexes
+	provinceOfCode map[string]int
+}
+
+var (
+	wilayahMu    sync.Mutex
+	wilayahCache *WilayahIndex
+)
+
+var regionPunctuation = regexp.MustCompile(`[^a-z0-9]+`)
+
+// provincePrefixes are stripped before a province is looked up
+var provincePrefixes = []string{"provinsi ", "propinsi ", "prov "}
+
+// regencyPrefixes are stripped before a regency is looked up, with the type they imply
+var regencyPrefixes = []struct {
+	prefix string
+	kind   string
+}{
+	{"kabupaten administrasi ", RegencyTypeKabupaten},
+	{"kota administrasi ", RegencyTypeKota},
+	{"kabupaten ", RegencyTypeKabupaten},
+	{"kab ", RegencyTypeKabupaten},
+	{"kota madya ", RegencyTypeKota},
+	{"kotamadya ", RegencyTypeKota},
+	{"kodya ", RegencyTypeKota},
+	{"kota ", RegencyTypeKota},
+}
+
+// normalizeRegionKey lowercases a name and collapses punctuation to single spaces
+func normalizeRegionKey(value string) string {
+	return strings.TrimSpace(regionPunctuation.ReplaceAllString(strings.ToLower(value), " "))
+}
+
+// splitRegencyPrefix removes a kabupaten/kota prefix from a normalized name, returning the implied type
+func splitRegencyPrefix(key string) (string, string) {
+	for _, prefix := range regencyPrefixes {
+		if strings.HasPrefix(key, prefix.prefix) {
+			return strings.TrimPrefix(key, prefix.prefix), prefix.kind
+		}
+	}
+	return key, ""
+}
+
+// LoadWilayahIndex returns the reference data index, loading it on first use
+// The reference data only changes when the seeder runs at startup, so the index is kept for the process lifetime
+func LoadWilayahIndex(db *sqlx.DB) (*WilayahIndex, error) {
+	wilayahMu.Lock()
+	defer wilayahMu.Unlock()
+
+	if wilayahCache != nil {
+		return wilayahCache, nil
+	}
+
+	provinces := []Province{}
+	if err := db.Select(&provinces, `SELECT code, name, aliases FROM provinces ORDER BY code ASC`); err != nil {
+		return nil, err
+	}
+	regencies := []Regency{}
+	if err := db.Select(&regencies, `SELECT code, province_code, name, type, aliases FROM regencies ORDER BY code ASC`); err != nil {
+		return nil, err
+	}
+	if len(provinces) == 0 || len(regencies) == 0 {
+		return nil, ErrWilayahNotSeeded
+	}
+
+	wilayahCache = NewWilayahIndex(provinces, regencies)
+	return wilayahCache, nil
+}
+
+// ResetWilayahIndex drops the cached index so the next lookup reloads the reference data
+func ResetWilayahIndex() {
+	wilayahMu.Lock()
+	wilayahCache = nil
+	wilayahMu.Unlock()
+}
+
+// NewWilayahIndex builds the lookup tables of the reference data
+func NewWilayahIndex(provinces []Province, regencies []Regency) *WilayahIndex {
+	index := &WilayahIndex{
+		Provinces:      provinces,
+		Regencies:      regencies,
+		provinceByKey:  map[string]int{},
+		regencyByKey:   map[string][]int{},
+		regencyByBare:  map[string][]int{},
+		regencyByCode:  map[string]int{},
+		regencyOfCode:  map[string][]int{},
+		provinceOfCode: map[string]int{},
+	}
+
+	for i, province := range provinces {
+		index.provinceOfCode[province.Code] = i
+		index.provinceByKey[province.Code] = i
+		index.provinceByKey[normalizeRegionKey(province.Name)] = i
+		for _, alias := range province.Aliases {
+			index.provinceByKey[normalizeRegionKey(alias)] = i
+		}
+	}
+
+	addUnique := func(m map[string][]int, key string, i int) {
+		for _, existing := range m[key] {
+			if existing == i {
+				return
+			}
+		}
+		m[key] = append(m[key], i)
+	}
+
+	for i, regency := range regencies {
+		index.regencyByCode[regency.Code] = i
+		index.regencyOfCode[regency.ProvinceCode] = append(index.regencyOfCode[regency.ProvinceCode], i)
+
+		names := append([]string{regency.Name}, regency.Aliases...)
+		for _, name := range names {
+			key := normalizeRegionKey(name)
+			addUnique(index.regencyByKey, regency.ProvinceCode+"|"+key, i)
+			bare, _ := splitRegencyPrefix(key)
+			addUnique(index.regencyByBare, regency.ProvinceCode+"|"+bare, i)
+		}
+	}
+
+	return index
+}
+
+// FindProvince returns the province with a code
+func (w *WilayahIndex) FindProvince(code string) (Province, bool) {
+	i, ok := w.provinceOfCode[code]
+	if !ok {
+		return Province{}, false
+	}
+	return w.Provinces[i], true
+}
+
+// FindRegency returns the regency with a code
+func (w *WilayahIndex) FindRegency(code string) (Regency, bool) {
+	i, ok := w.regencyByCode[code]
+	if !ok {
+		return Regency{}, false
+	}
+	return w.Regencies[i], true
+}
+
+// ResolveProvince matches a province name, alias or code, e.g. "Jatim", "Prov. Jawa Timur" or "35"
+func (w *WilayahIndex) ResolveProvince(value string) (Province, error) {
+	key := normalizeRegionKey(value)
+	if i, ok := w.provinceByKey[key]; ok {
+		return w.Provinces[i], nil
+	}
+	for _, prefix := range provincePrefixes {
+		if strings.HasPrefix(key, prefix) {
+			if i, ok := w.provinceByKey[strings.TrimPrefix(key, prefix)]; ok {
+				return w.Provinces[i], nil
+			}
+		}
+	}
+	return Province{}, &RegionError{Field: "province", Value: value, Err: ErrUnknownProvince}
+}
+
+// ResolveRegency matches a regency or city of a province by name, alias or code, e.g. "Kab. Malang" or "35.07"
+// A name without kabupaten/kota prefix that exists as both, like "Malang", is ambiguous.
+// An unknown name lists the regencies of other provinces with that name, e.g. after a province split
+func (w *WilayahIndex) ResolveRegency(provinceCode string, value string) (Regency, error) {
+	candidates := w.matchRegencies(provinceCode, value)
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+
+	names := []string{}
+	if len(candidates) == 0 {
+		for _, regency := range w.RegenciesNamed(value) {
+			province, _ := w.FindProvince(regency.ProvinceCode)
+			names = append(names, regency.Name+" ("+province.Name+")")
+		}
+		return Regency{}, &RegionError{Field: "city", Value: value, Err: ErrUnknownRegency, Candidates: names}
+	}
+
+	for _, candidate := range candidates {
+		names = append(names, candidate.Name)
+	}
+	sort.Strings(names)
+	return Regency{}, &RegionError{Field: "city", Value: value, Err: ErrAmbiguousRegency, Candidates: names}
+}
+
+// RegenciesNamed returns the regencies of any province matching a name, alias or code
+func (w *WilayahIndex) RegenciesNamed(value string) []Regency {
+	regencies := []Regency{}
+	for _, province := range w.Provinces {
+		regencies = append(regencies, w.matchRegencies(province.Code, value)...)
+	}
+	return regencies
+}
+
+// matchRegencies returns the regencies of a province a name could mean, an exact name or alias wins over a bare name
+func (w *WilayahIndex) matchRegencies(provinceCode string, value string) []Regency {
+	if i, ok := w.regencyByCode[strings.TrimSpace(value)]; ok {
+		if w.Regencies[i].ProvinceCode != provinceCode {
+			return nil
+		}
+		return []Regency{w.Regencies[i]}
+	}
+
+	key := normalizeRegionKey(value)
+	if matches := w.regencyByKey[provinceCode+"|"+key]; len(matches) == 1 {
+		return []Regency{w.Regencies[matches[0]]}
+	}
+
+	bare, kind := splitRegencyPrefix(key)
+	candidates := []Regency{}
+	for _, i := range w.regencyByBare[provinceCode+"|"+bare] {
+		if kind == "" || w.Regencies[i].Type == kind {
+			candidates = append(candidates, w.Regencies[i])
+		}
+	}
+	return candidates
+}
+
+// Resolve normalizes a province and a regency or city of it, the city may be empty
+func (w *WilayahIndex) Resolve(province string, city string) (Province, *Regency, error) {
+	resolvedProvince, err := w.ResolveProvince(province)
+	if err != nil {
+		return Province{}, nil, err
+	}
+	if strings.TrimSpace(city) == "" {
+		return resolvedProvince, nil, nil
+	}
+
+	regency, err := w.ResolveRegency(resolvedProvince.Code, city)
+	if err != nil {
+		return resolvedProvince, nil, err
+	}
+	return resolvedProvince, &regency, nil
+}
+
+// RegenciesOf returns the regencies and cities of a province ordered by code
+func (w *WilayahIndex) RegenciesOf(provinceCode string) []Regency {
+	regencies := make([]Regency, 0, len(w.regencyOfCode[provinceCode]))
+	for _, i := range w.regencyOfCode[provinceCode] {
+		regencies = append(regencies, w.Regencies[i])
+	}
+	return regencies
+}
+
+// SearchProvinces returns the provinces whose name or alias contains the search term
+func (w *WilayahIndex) SearchProvinces(search string) []Province {
+	term := normalizeRegionKey(search)
+	provinces := []Province{}
+	for _, province := range w.Provinces {
+		if term == "" || matchesRegionTerm(term, province.Name, province.Aliases) {
+			provinces = append(provinces, province)
+		}
+	}
+	return provinces
+}
+
+// SearchRegencies returns the regencies of a province (all provinces when empty) of a type (any when empty)
+// whose name or alias contains the search term
+func (w *WilayahIndex) SearchRegencies(provinceCode string, kind string, search string) []Regency {
+	term := normalizeRegionKey(search)
+	regencies := []Regency{}
+	for _, regency := range w.Regencies {
+		if provinceCode != "" && regency.ProvinceCode != provinceCode {
+			continue
+		}
+		if kind != "" && regency.Type != kind {
+			continue
+		}
+		if term == "" || matchesRegionTerm(term, regency.Name, regency.Aliases) {
+			regencies = append(regencies, regency)
+		}
+	}
+	return regencies
+}
+
+// matchesRegionTerm reports whether a normalized search term appears in a name or one of its aliases
+func matchesRegionTerm(term string, name string, aliases Aliases) bool {
+	if strings.Contains(normalizeRegionKey(name), term) {
+		return true
+	}
+	for _, alias := range aliases {
+		if strings.Contains(normalizeRegionKey(alias), term) {
+			return true
+		}
+	}
+	return false
+}
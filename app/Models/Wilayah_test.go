@@ -0,0 +1,144 @@
+package models
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// testWilayahIndex is a small slice of the reference data
+func testWilayahIndex() *WilayahIndex {
+	return NewWilayahIndex(
+		[]Province{
+			{Code: "31", Name: "DKI Jakarta", Aliases: Aliases{"Jakarta"}},
+			{Code: "32", Name: "Jawa Barat", Aliases: Aliases{"Jabar"}},
+			{Code: "35", Name: "Jawa Timur", Aliases: Aliases{"Jatim"}},
+		},
+		[]Regency{
+			{Code: "31.71", ProvinceCode: "31", Name: "Kota Administrasi Jakarta Pusat", Type: RegencyTypeKota, Aliases: Aliases{"Jakpus"}},
+			{Code: "32.04", ProvinceCode: "32", Name: "Kabupaten Bandung", Type: RegencyTypeKabupaten},
+			{Code: "32.17", ProvinceCode: "32", Name: "Kabupaten Bandung Barat", Type: RegencyTypeKabupaten},
+			{Code: "32.73", ProvinceCode: "32", Name: "Kota Bandung", Type: RegencyTypeKota},
+			{Code: "35.07", ProvinceCode: "35", Name: "Kabupaten Malang", Type: RegencyTypeKabupaten},
+			{Code: "35.73", ProvinceCode: "35", Name: "Kota Malang", Type: RegencyTypeKota},
+			{Code: "35.78", ProvinceCode: "35", Name: "Kota Surabaya", Type: RegencyTypeKota},
+		},
+	)
+}
+
+func TestWilayahIndexResolve(t *testing.T) {
+	tests := []struct {
+		name         string
+		province     string
+		city         string
+		wantProvince string // Expected province code
+		wantRegency  string // Expected regency code, empty when no city is resolved
+		wantErr      *RegionError
+	}{
+		{name: "province name", province: "Jawa Timur", wantProvince: "35"},
+		{name: "province alias", province: "Jatim", wantProvince: "35"},
+		{name: "province alias case and spacing", province: "  JATIM ", wantProvince: "35"},
+		{name: "province prefix", province: "Prov. Jawa Timur", wantProvince: "35"},
+		{name: "province long prefix", province: "Provinsi Jawa Barat", wantProvince: "32"},
+		{name: "province code", province: "35", wantProvince: "35"},
+		{
+			name:     "unknown province",
+			province: "Jawa Tenggara", city: "Kota Malang",
+			wantErr: &RegionError{Field: "province", Value: "Jawa Tenggara", Err: ErrUnknownProvince},
+		},
+		{
+			name:     "city without province",
+			province: "", city: "Kota Surabaya",
+			wantErr: &RegionError{Field: "province", Value: "", Err: ErrUnknownProvince},
+		},
+		{name: "full city name", province: "Jatim", city: "Kota Surabaya", wantProvince: "35", wantRegency: "35.78"},
+		{name: "unique bare city name", province: "Jatim", city: "surabaya", wantProvince: "35", wantRegency: "35.78"},
+		{name: "kab. prefix", province: "Jatim", city: "Kab. Malang", wantProvince: "35", wantRegency: "35.07"},
+		{name: "kabupaten prefix", province: "Jatim", city: "KABUPATEN MALANG", wantProvince: "35", wantRegency: "35.07"},
+		{name: "kota prefix", province: "Jatim", city: "Kota Malang", wantProvince: "35", wantRegency: "35.73"},
+		{name: "kotamadya prefix", province: "Jatim", city: "Kotamadya Malang", wantProvince: "35", wantRegency: "35.73"},
+		{name: "regency code", province: "35", city: "35.07", wantProvince: "35", wantRegency: "35.07"},
+		{name: "regency alias", province: "Jakarta", city: "Jakpus", wantProvince: "31", wantRegency: "31.71"},
+		{name: "kota administrasi without prefix", province: "DKI Jakarta", city: "Jakarta Pusat", wantProvince: "31", wantRegency: "31.71"},
+		{name: "exact name wins over a longer bare match", province: "Jabar", city: "Kabupaten Bandung", wantProvince: "32", wantRegency: "32.04"},
+		{
+			name:     "ambiguous bare city name",
+			province: "Jatim", city: "Malang",
+			wantErr: &RegionError{Field: "city", Value: "Malang", Err: ErrAmbiguousRegency, Candidates: []string{"Kabupaten Malang", "Kota Malang"}},
+		},
+		{
+			name:     "ambiguous bare city name of another province",
+			province: "Jabar", city: "Bandung",
+			wantErr: &RegionError{Field: "city", Value: "Bandung", Err: ErrAmbiguousRegency, Candidates: []string{"Kabupaten Bandung", "Kota Bandung"}},
+		},
+		{
+			name:     "city of another province",
+			province: "Jabar", city: "Kota Surabaya",
+			wantErr: &RegionError{Field: "city", Value: "Kota Surabaya", Err: ErrUnknownRegency, Candidates: []string{"Kota Surabaya (Jawa Timur)"}},
+		},
+		{
+			name:     "regency code of another province",
+			province: "Jabar", city: "35.07",
+			wantErr: &RegionError{Field: "city", Value: "35.07", Err: ErrUnknownRegency, Candidates: []string{"Kabupaten Malang (Jawa Timur)"}},
+		},
+		{
+			name:     "unknown city",
+			province: "Jatim", city: "Atlantis",
+			wantErr: &RegionError{Field: "city", Value: "Atlantis", Err: ErrUnknownRegency, Candidates: []string{}},
+		},
+	}
+
+	index := testWilayahIndex()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			province, regency, err := index.Resolve(tt.province, tt.city)
+			if tt.wantErr != nil {
+				var regionErr *RegionError
+				if !errors.As(err, &regionErr) {
+					t.Fatalf("expected a RegionError, got %v", err)
+				}
+				if !reflect.DeepEqual(regionErr, tt.wantErr) {
+					t.Errorf("expected %+v, got %+v", tt.wantErr, regionErr)
+				}
+				if !errors.Is(err, tt.wantErr.Err) {
+					t.Errorf("expected errors.Is(%v), got %v", tt.wantErr.Err, err)
+				}
+				if regency != nil {
+					t.Errorf("expected no regency, got %+v", regency)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve: %v", err)
+			}
+			if province.Code != tt.wantProvince {
+				t.Errorf("expected province %s, got %s", tt.wantProvince, province.Code)
+			}
+			gotRegency := ""
+			if regency != nil {
+				gotRegency = regency.Code
+			}
+			if gotRegency != tt.wantRegency {
+				t.Errorf("expected regency %q, got %q", tt.wantRegency, gotRegency)
+			}
+		})
+	}
+}
+
+func TestRegionErrorMessage(t *testing.T) {
+	tests := []struct {
+		err  *RegionError
+		want string
+	}{
+		{&RegionError{Field: "province", Value: "X", Err: ErrUnknownProvince}, `"X" is not a known province`},
+		{&RegionError{Field: "city", Value: "Malang", Err: ErrAmbiguousRegency, Candidates: []string{"Kabupaten Malang", "Kota Malang"}}, `"Malang" could be Kabupaten Malang or Kota Malang`},
+		{&RegionError{Field: "city", Value: "Surabaya", Err: ErrUnknownRegency, Candidates: []string{"Kota Surabaya (Jawa Timur)"}}, `"Surabaya" is not in the province, did you mean Kota Surabaya (Jawa Timur)`},
+		{&RegionError{Field: "city", Value: "Atlantis", Err: ErrUnknownRegency}, `"Atlantis" is not a known regency or city of the province`},
+	}
+
+	for _, tt := range tests {
+		if got := tt.err.Error(); got != tt.want {
+			t.Errorf("expected %q, got %q", tt.want, got)
+		}
+	}
+}
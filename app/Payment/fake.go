@@ -0,0 +1,82 @@
+package payment
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+)
+
+// FakeSignatureHeader carries the signature of fake provider notifications
+const FakeSignatureHeader = "X-Fake-Signature"
+
+// FakeProvider is a local stand-in for a payment gateway
+// Charges always succeed and notifications are JSON signed with HMAC-SHA256 over the raw body
+type FakeProvider struct {
+	secret string
+	appURL string
+}
+
+// FakeNotification is the notification payload of the fake provider
+type FakeNotification struct {
+	OrderID   string `json:"order_id"`
+	Status    string `json:"status"` // settled, expired, failed or pending
+	Amount    int64  `json:"amount"`
+	Reference string `json:"reference"`
+	Method    string `json:"method"`
+}
+
+// NewFakeProvider creates a new FakeProvider instance
+func NewFakeProvider(secret string, appURL string) *FakeProvider {
+	return &FakeProvider{
+		secret: secret,
+		appURL: appURL,
+	}
+}
+
+// Name returns the provider name stored on invoices
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+// CreateCharge returns the simulation endpoint of the invoice as payment page
+func (p *FakeProvider) CreateCharge(ctx context.Context, req ChargeRequest) (*Charge, error) {
+	return &Charge{
+		Reference:  "fake-" + req.OrderID,
+		PaymentURL: p.appURL + "/api/v1/payments/fake/" + req.OrderID,
+	}, nil
+}
+
+// ParseNotification verifies and parses a fake provider notification
+func (p *FakeProvider) ParseNotification(body []byte, header http.Header) (*Notification, error) {
+	if !hmac.Equal([]byte(p.Sign(body)), []byte(header.Get(FakeSignatureHeader))) {
+		return nil, ErrInvalidSignature
+	}
+
+	var payload FakeNotification
+	if err := json.Unmarshal(body, &payload); err != nil || payload.OrderID == "" {
+		return nil, ErrInvalidNotification
+	}
+	switch payload.Status {
+	case StatusPending, StatusSettled, StatusExpired, StatusFailed:
+	default:
+		return nil, ErrInvalidNotification
+	}
+
+	return &Notification{
+		OrderID:   payload.OrderID,
+		Reference: payload.Reference,
+		Status:    payload.Status,
+		Amount:    payload.Amount,
+		Method:    payload.Method,
+	}, nil
+}
+
+// Sign returns the hex HMAC-SHA256 signature of a notification body
+func (p *FakeProvider) Sign(body []byte) string {
+	mac := hmac.New(sha256.New, []byte(p.secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,111 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	mail "github.com/cvudumbarainformatika/backend/app/Mail"
+	models "github.com/cvudumbarainformatika/backend/app/Models"
+	"github.com/cvudumbarainformatika/backend/config"
+	"github.com/jmoiron/sqlx"
+)
+
+// Gateway issues and expires invoices of paid registrations through the configured provider and emails the members
+type Gateway struct {
+	db       *sqlx.DB
+	provider Provider
+	mailer   *mail.Mailer
+	siteURL  string
+	expiry   time.Duration
+}
+
+// NewGateway creates a new Gateway instance
+func NewGateway(db *sqlx.DB, cfg *config.Config, mailer *mail.Mailer) *Gateway {
+	return &Gateway{
+		db:       db,
+		provider: NewProvider(cfg.Payment, cfg.App.URL),
+		mailer:   mailer,
+		siteURL:  cfg.SEO.SiteURL,
+		expiry:   time.Duration(cfg.Payment.InvoiceExpiryMinutes) * time.Minute,
+	}
+}
+
+// Provider returns the payment provider of the gateway (nil when none is configured)
+func (g *Gateway) Provider() Provider {
+	return g.provider
+}
+
+// Enabled reports whether a payment provider is configured, paid registrations are rejected otherwise
+func (g *Gateway) Enabled() bool {
+	return g.provider != nil
+}
+
+// IssueInvoice returns the open invoice of a pending paid registration, issuing one when there is none
+// The charge is created at the provider when the invoice has no payment page yet, so a failed provider
+// call can be retried by issuing again. The member is emailed the payment link of a newly issued invoice
+func (g *Gateway) IssueInvoice(ctx context.Context, agenda models.Agenda, registration models.AgendaRegistration, user models.User) (*models.Invoice, bool, error) {
+	if g.provider == nil {
+		return nil, false, ErrNoProvider
+	}
+
+	invoice, created, err := models.CreateRegistrationInvoice(g.db, registration.ID, g.provider.Name(), time.Now().Add(g.expiry))
+	if err != nil {
+		return nil, false, err
+	}
+	if invoice.PaymentURL != nil {
+		return invoice, created, nil
+	}
+
+	charge, err := g.provider.CreateCharge(ctx, ChargeRequest{
+		OrderID:       invoice.Number,
+		Amount:        invoice.Amount,
+		ItemID:        fmt.Sprintf("agenda-%d", agenda.ID),
+		ItemName:      agenda.Title,
+		CustomerName:  user.Name,
+		CustomerEmail: user.Email,
+		ExpiresAt:     invoice.ExpiresAt,
+	})
+	if err != nil {
+		return invoice, created, fmt.Errorf("failed to create %s charge: %w", g.provider.Name(), err)
+	}
+
+	if err := models.SetInvoiceCharge(g.db, invoice.ID, charge.Reference, charge.PaymentURL); err != nil {
+		return invoice, created, err
+	}
+	invoice.ProviderRef = &charge.Reference
+	invoice.PaymentURL = &charge.PaymentURL
+
+	if created {
+		g.mailer.SendAsync(mail.InvoiceIssuedMessage(user, agenda, *invoice, g.siteURL))
+	}
+	return invoice, created, nil
+}
+
+// ExpireInvoice expires a lapsed invoice, releasing the seat of its unpaid registration
+// The member and the members promoted from the waitlist are notified
+func (g *Gateway) ExpireInvoice(invoice models.Invoice) error {
+	cancelled, promoted, err := models.ExpireInvoice(g.db, invoice.ID)
+	if err != nil || cancelled == nil {
+		return err
+	}
+
+	agenda, err := models.FindAgendaByID(g.db, invoice.AgendaID)
+	if err != nil || agenda == nil {
+		return err
+	}
+
+	if user, err := models.FindByID(g.db, cancelled.UserID); err == nil && user != nil {
+		g.mailer.SendAsync(mail.InvoiceExpiredMessage(*user, *agenda, invoice, g.siteURL))
+	}
+	for _, registration := range promoted {
+		user, err := models.FindByID(g.db, registration.UserID)
+		if err != nil || user == nil {
+			log.Printf("[Payment] Cannot notify user #%d about promotion: %v", registration.UserID, err)
+			continue
+		}
+		g.mailer.SendAsync(mail.WaitlistPromotedMessage(*user, *agenda, g.siteURL))
+	}
+	return nil
+}
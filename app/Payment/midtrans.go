@@ -0,0 +1,172 @@
+package payment
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Midtrans Snap endpoints
+const (
+	midtransSandboxURL    = "https://app.sandbox.midtrans.com/snap/v1/transactions"
+	midtransProductionURL = "https://app.midtrans.com/snap/v1/transactions"
+)
+
+// midtransItemNameLength is the longest item name Midtrans accepts
+const midtransItemNameLength = 50
+
+// MidtransProvider creates Snap transactions and verifies Midtrans HTTP notifications
+type MidtransProvider struct {
+	serverKey string
+	endpoint  string
+	client    *http.Client
+}
+
+// NewMidtransProvider creates a new MidtransProvider instance
+func NewMidtransProvider(serverKey string, production bool) *MidtransProvider {
+	endpoint := midtransSandboxURL
+	if production {
+		endpoint = midtransProductionURL
+	}
+	return &MidtransProvider{
+		serverKey: serverKey,
+		endpoint:  endpoint,
+		client:    &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// Name returns the provider name stored on invoices
+func (p *MidtransProvider) Name() string {
+	return "midtrans"
+}
+
+// CreateCharge creates a Snap transaction, its redirect URL is the payment page
+func (p *MidtransProvider) CreateCharge(ctx context.Context, req ChargeRequest) (*Charge, error) {
+	name := req.ItemName
+	if len([]rune(name)) > midtransItemNameLength {
+		name = string([]rune(name)[:midtransItemNameLength])
+	}
+
+	// Snap counts the expiry from start_time in whole minutes
+	now := time.Now()
+	minutes := int(math.Ceil(req.ExpiresAt.Sub(now).Minutes()))
+	if minutes < 1 {
+		minutes = 1
+	}
+
+	payload := map[string]interface{}{
+		"transaction_details": map[string]interface{}{
+			"order_id":     req.OrderID,
+			"gross_amount": req.Amount,
+		},
+		"item_details": []map[string]interface{}{{
+			"id":       req.ItemID,
+			"price":    req.Amount,
+			"quantity": 1,
+			"name":     name,
+		}},
+		"customer_details": map[string]interface{}{
+			"first_name": req.CustomerName,
+			"email":      req.CustomerEmail,
+		},
+		"expiry": map[string]interface{}{
+			"start_time": now.Format("2006-01-02 15:04:05 -0700"),
+			"unit":       "minute",
+			"duration":   minutes,
+		},
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.SetBasicAuth(p.serverKey, "")
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Token         string   `json:"token"`
+		RedirectURL   string   `json:"redirect_url"`
+		ErrorMessages []string `json:"error_messages"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("midtrans: unexpected response (HTTP %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode >= 300 || result.RedirectURL == "" {
+		return nil, fmt.Errorf("midtrans: HTTP %d: %s", resp.StatusCode, strings.Join(result.ErrorMessages, "; "))
+	}
+
+	return &Charge{Reference: result.Token, PaymentURL: result.RedirectURL}, nil
+}
+
+// ParseNotification verifies and parses a Midtrans HTTP notification
+// signature_key is SHA512(order_id + status_code + gross_amount + server key)
+func (p *MidtransProvider) ParseNotification(body []byte, header http.Header) (*Notification, error) {
+	var payload struct {
+		OrderID           string `json:"order_id"`
+		StatusCode        string `json:"status_code"`
+		GrossAmount       string `json:"gross_amount"`
+		SignatureKey      string `json:"signature_key"`
+		TransactionID     string `json:"transaction_id"`
+		TransactionStatus string `json:"transaction_status"`
+		FraudStatus       string `json:"fraud_status"`
+		PaymentType       string `json:"payment_type"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil || payload.OrderID == "" {
+		return nil, ErrInvalidNotification
+	}
+
+	sum := sha512.Sum512([]byte(payload.OrderID + payload.StatusCode + payload.GrossAmount + p.serverKey))
+	expected := hex.EncodeToString(sum[:])
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(strings.ToLower(payload.SignatureKey))) != 1 {
+		return nil, ErrInvalidSignature
+	}
+
+	amount, err := strconv.ParseFloat(payload.GrossAmount, 64)
+	if err != nil {
+		return nil, ErrInvalidNotification
+	}
+
+	notification := &Notification{
+		OrderID:   payload.OrderID,
+		Reference: payload.TransactionID,
+		Amount:    int64(math.Round(amount)),
+		Method:    payload.PaymentType,
+		Status:    StatusPending,
+	}
+
+	switch payload.TransactionStatus {
+	case "settlement":
+		notification.Status = StatusSettled
+	case "capture":
+		// Card payments are only final when the fraud check accepted them
+		if payload.FraudStatus == "" || payload.FraudStatus == "accept" {
+			notification.Status = StatusSettled
+		}
+	case "expire":
+		notification.Status = StatusExpired
+	case "deny", "cancel", "failure":
+		notification.Status = StatusFailed
+	}
+
+	return notification, nil
+}
@@ -0,0 +1,131 @@
+package payment
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testServerKey = "SB-Mid-server-test"
+
+// midtransSignature signs a notification the way Midtrans does
+func midtransSignature(orderID, statusCode, grossAmount string) string {
+	sum := sha512.Sum512([]byte(orderID + statusCode + grossAmount + testServerKey))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestMidtransParseNotification(t *testing.T) {
+	signed := midtransSignature("INV-20260101-0001", "200", "150000.00")
+
+	tests := []struct {
+		name    string
+		payload map[string]string
+		want    *Notification
+		wantErr error
+	}{
+		{
+			name: "valid settlement",
+			payload: map[string]string{
+				"order_id": "INV-20260101-0001", "status_code": "200", "gross_amount": "150000.00",
+				"signature_key": signed, "transaction_id": "trx-1", "transaction_status": "settlement", "payment_type": "qris",
+			},
+			want: &Notification{OrderID: "INV-20260101-0001", Reference: "trx-1", Status: StatusSettled, Amount: 150000, Method: "qris"},
+		},
+		{
+			name: "uppercase signature",
+			payload: map[string]string{
+				"order_id": "INV-20260101-0001", "status_code": "200", "gross_amount": "150000.00",
+				"signature_key": strings.ToUpper(signed), "transaction_id": "trx-1", "transaction_status": "settlement", "payment_type": "bank_transfer",
+			},
+			want: &Notification{OrderID: "INV-20260101-0001", Reference: "trx-1", Status: StatusSettled, Amount: 150000, Method: "bank_transfer"},
+		},
+		{
+			name: "tampered gross_amount",
+			payload: map[string]string{
+				"order_id": "INV-20260101-0001", "status_code": "200", "gross_amount": "1000.00",
+				"signature_key": signed, "transaction_status": "settlement",
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "capture accepted by the fraud check",
+			payload: map[string]string{
+				"order_id": "INV-20260101-0001", "status_code": "200", "gross_amount": "150000.00",
+				"signature_key": signed, "transaction_id": "trx-2", "transaction_status": "capture", "fraud_status": "accept", "payment_type": "credit_card",
+			},
+			want: &Notification{OrderID: "INV-20260101-0001", Reference: "trx-2", Status: StatusSettled, Amount: 150000, Method: "credit_card"},
+		},
+		{
+			name: "capture challenged by the fraud check",
+			payload: map[string]string{
+				"order_id": "INV-20260101-0001", "status_code": "200", "gross_amount": "150000.00",
+				"signature_key": signed, "transaction_id": "trx-2", "transaction_status": "capture", "fraud_status": "challenge", "payment_type": "credit_card",
+			},
+			want: &Notification{OrderID: "INV-20260101-0001", Reference: "trx-2", Status: StatusPending, Amount: 150000, Method: "credit_card"},
+		},
+		{
+			name: "expire",
+			payload: map[string]string{
+				"order_id": "INV-20260101-0001", "status_code": "407", "gross_amount": "150000.00",
+				"signature_key": midtransSignature("INV-20260101-0001", "407", "150000.00"), "transaction_status": "expire",
+			},
+			want: &Notification{OrderID: "INV-20260101-0001", Status: StatusExpired, Amount: 150000},
+		},
+		{
+			name: "deny",
+			payload: map[string]string{
+				"order_id": "INV-20260101-0001", "status_code": "202", "gross_amount": "150000.00",
+				"signature_key": midtransSignature("INV-20260101-0001", "202", "150000.00"), "transaction_status": "deny",
+			},
+			want: &Notification{OrderID: "INV-20260101-0001", Status: StatusFailed, Amount: 150000},
+		},
+		{
+			name:    "missing order id",
+			payload: map[string]string{"status_code": "200", "gross_amount": "150000.00"},
+			wantErr: ErrInvalidNotification,
+		},
+		{
+			name: "non-numeric gross_amount",
+			payload: map[string]string{
+				"order_id": "INV-20260101-0001", "status_code": "200", "gross_amount": "abc",
+				"signature_key": midtransSignature("INV-20260101-0001", "200", "abc"), "transaction_status": "settlement",
+			},
+			wantErr: ErrInvalidNotification,
+		},
+	}
+
+	provider := NewMidtransProvider(testServerKey, false)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := json.Marshal(tt.payload)
+			if err != nil {
+				t.Fatalf("marshal: %v", err)
+			}
+
+			got, err := provider.ParseNotification(body, nil)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseNotification: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestMidtransParseNotificationMalformedBody(t *testing.T) {
+	provider := NewMidtransProvider(testServerKey, false)
+	if _, err := provider.ParseNotification([]byte("not json"), nil); !errors.Is(err, ErrInvalidNotification) {
+		t.Errorf("expected ErrInvalidNotification, got %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/cvudumbarainformatika/backend/config"
+)
+
+// Notification statuses, provider specific statuses are mapped to these
+const (
+	StatusPending = "pending" // Nothing to do yet (e.g. waiting for a bank transfer)
+	StatusSettled = "settled"
+	StatusExpired = "expired"
+	StatusFailed  = "failed" // Denied, cancelled or failed at the provider
+)
+
+// Payment provider errors
+var (
+	ErrInvalidSignature    = errors.New("invalid notification signature")
+	ErrInvalidNotification = errors.New("invalid notification payload")
+	ErrNoProvider          = errors.New("no payment provider configured")
+)
+
+// ChargeRequest describes the payment of an invoice
+type ChargeRequest struct {
+	OrderID       string // Invoice number, echoed back in notifications
+	Amount        int64  // In IDR
+	ItemID        string
+	ItemName      string
+	CustomerName  string
+	CustomerEmail string
+	ExpiresAt     time.Time
+}
+
+// Charge is a payment created at the provider
+type Charge struct {
+	Reference  string // Transaction or token ID at the provider
+	PaymentURL string // Page where the member pays
+}
+
+// Notification is a verified payment status notification (webhook)
+type Notification struct {
+	OrderID   string
+	Reference string
+	Status    string
+	Amount    int64
+	Method    string // Payment channel, e.g. bank_transfer or qris
+}
+
+// Provider is a payment gateway
+// Implementations create charges through the provider API and verify the signature of its webhook notifications
+type Provider interface {
+	Name() string
+	CreateCharge(ctx context.Context, req ChargeRequest) (*Charge, error)
+	ParseNotification(body []byte, header http.Header) (*Notification, error)
+}
+
+// NewProvider creates the payment provider selected in the configuration
+// Returns nil when no provider is configured
+func NewProvider(cfg config.PaymentConfig, appURL string) Provider {
+	switch cfg.Provider {
+	case "midtrans":
+		return NewMidtransProvider(cfg.ServerKey, cfg.Production)
+	case "fake":
+		return NewFakeProvider(cfg.FakeSecret, appURL)
+	default:
+		return nil
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	exceptions "github.com/cvudumbarainformatika/backend/app/Exceptions"
 	middleware "github.com/cvudumbarainformatika/backend/app/Http/Middleware"
+	jobs "github.com/cvudumbarainformatika/backend/app/Jobs"
 	"github.com/cvudumbarainformatika/backend/config"
 	"github.com/cvudumbarainformatika/backend/database"
 	"github.com/cvudumbarainformatika/backend/database/seeders"
@@ -16,10 +17,11 @@ import (
 
 // Application represents the main application
 type Application struct {
-	Router *gin.Engine
-	DB     *database.Database
-	Redis  *redis.Client
-	Config *config.Config
+	Router    *gin.Engine
+	DB        *database.Database
+	Redis     *redis.Client
+	Config    *config.Config
+	Scheduler *jobs.Scheduler
 }
 
 // NewApplication creates and initializes a new application instance
@@ -90,11 +92,17 @@ func NewApplication() (*Application, error) {
 	// Setup routes
 	routes.SetupRoutes(router, db.DB, rdb, cfg)
 
+	// Start background jobs
+	scheduler := jobs.NewScheduler()
+	jobs.Schedule(scheduler, db.DB, cfg)
+	scheduler.Start()
+
 	return &Application{
-		Router: router,
-		DB:     db,
-		Redis:  rdb,
-		Config: cfg,
+		Router:    router,
+		DB:        db,
+		Redis:     rdb,
+		Config:    cfg,
+		Scheduler: scheduler,
 	}, nil
 }
 
@@ -109,6 +117,11 @@ func (app *Application) Run() error {
 func (app *Application) Shutdown() error {
 	log.Println("Shutting down application...")
 
+	// Stop background jobs before closing connections they use
+	if app.Scheduler != nil {
+		app.Scheduler.Stop()
+	}
+
 	// Close database connections
 	if app.DB != nil {
 		if err := app.DB.Close(); err != nil {
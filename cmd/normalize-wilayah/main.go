@@ -0,0 +1,426 @@
+// Command normalize-wilayah maps the free text provinces and cities stored before the
+// reference data existed to their reference names and reports the values it cannot map.
+//
+// It covers direktori (province and city), agenda (province) and users (province and city,
+// filled from the address when empty). Nothing is written without -apply:
+//
+//	go run ./cmd/normalize-wilayah                      # dry run, prints the summary
+//	go run ./cmd/normalize-wilayah -report unmatched.csv
+//	go run ./cmd/normalize-wilayah -apply
+//
+// The reference data is seeded when the server starts, run it once against the database first.
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	models "github.com/cvudumbarainformatika/backend/app/Models"
+	"github.com/cvudumbarainformatika/backend/config"
+	"github.com/cvudumbarainformatika/backend/database"
+	"github.com/jmoiron/sqlx"
+)
+
+// unmatchedShown is the number of distinct unmatched values printed per table
+const unmatchedShown = 20
+
+// postalCode matches the postal code that often follows the city or province in an address
+var postalCode = regexp.MustCompile(`\b\d{5}\b`)
+
+// unmatchedRow is a row whose province or city could not be mapped
+type unmatchedRow struct {
+	Table  string
+	ID     int64
+	Field  string
+	Value  string
+	Reason string
+}
+
+// tableSummary counts the outcome of one table
+type tableSummary struct {
+	Table     string
+	Scanned   int
+	Unchanged int
+	Changed   int
+	Unmatched []unmatchedRow
+}
+
+// regionUpdate is the normalized province and city of a row
+type regionUpdate struct {
+	ID       int64
+	Province string
+	City     string
+}
+
+func main() {
+	apply := flag.Bool("apply", false, "write the normalized values, without it only the summary is printed")
+	reportPath := flag.String("report", "", "write the unmatched rows to this CSV file")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	db, err := database.NewDatabase(cfg.Database)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	index, err := models.LoadWilayahIndex(db.DB)
+	if errors.Is(err, models.ErrWilayahNotSeeded) {
+		log.Fatalf("The province and regency reference data is empty, start the server once to seed it")
+	}
+	if err != nil {
+		log.Fatalf("Failed to load reference data: %v", err)
+	}
+
+	mapper := &regionMapper{index: index}
+	summaries := []tableSummary{}
+	for _, normalize := range []func(*sqlx.DB, *regionMapper, bool) (tableSummary, error){
+		normalizeDirektori,
+		normalizeAgenda,
+		normalizeUsers,
+	} {
+		summary, err := normalize(db.DB, mapper, *apply)
+		if err != nil {
+			log.Fatalf("Failed to normalize %s: %v", summary.Table, err)
+		}
+		summaries = append(summaries, summary)
+	}
+
+	printSummaries(summaries, *apply)
+
+	if *reportPath != "" {
+		if err := writeReport(*reportPath, summaries); err != nil {
+			log.Fatalf("Failed to write report: %v", err)
+		}
+		fmt.Printf("\nUnmatched rows written to %s\n", *reportPath)
+	}
+}
+
+// regionMapper resolves stored values, repairing what a plain lookup rejects
+type regionMapper struct {
+	index *models.WilayahIndex
+}
+
+// resolve maps a province and city, the city may be empty
+// A city that exists in exactly one province is accepted when the stored province is unknown
+// or is the province it belonged to before a split, e.g. Merauke stored under Papua
+func (m *regionMapper) resolve(province string, city string) (string, string, error) {
+	resolvedProvince, regency, err := m.index.Resolve(province, city)
+	if err == nil {
+		if regency == nil {
+			return resolvedProvince.Name, "", nil
+		}
+		return resolvedProvince.Name, regency.Name, nil
+	}
+
+	if strings.TrimSpace(city) != "" && !errors.Is(err, models.ErrAmbiguousRegency) {
+		if matches := m.index.RegenciesNamed(city); len(matches) == 1 {
+			owner, _ := m.index.FindProvince(matches[0].ProvinceCode)
+			return owner.Name, matches[0].Name, nil
+		}
+	}
+	return "", "", err
+}
+
+// fromAddress finds the province and city in a comma separated address, scanning from its end
+// A city is looked up in the named province first, then in all provinces when its name is unique.
+// Both are returned empty when the address names neither unambiguously
+func (m *regionMapper) fromAddress(address string) (string, string) {
+	parts := strings.FieldsFunc(address, func(r rune) bool {
+		return r == ',' || r == '\n' || r == ';'
+	})
+	for i := range parts {
+		parts[i] = strings.TrimSpace(postalCode.ReplaceAllString(parts[i], ""))
+	}
+
+	var province *models.Province
+	provinceAt := len(parts)
+	for i := len(parts) - 1; i >= 0; i-- {
+		if resolved, err := m.index.ResolveProvince(parts[i]); err == nil {
+			province = &resolved
+			provinceAt = i
+			break
+		}
+	}
+
+	if province != nil {
+		for i := provinceAt - 1; i >= 0; i-- {
+			if regency, err := m.index.ResolveRegency(province.Code, parts[i]); err == nil {
+				return province.Name, regency.Name
+			}
+		}
+	}
+
+	// A city of another province, such as one that moved in a province split
+	for i := provinceAt - 1; i >= 0; i-- {
+		if parts[i] == "" {
+			continue
+		}
+		if matches := m.index.RegenciesNamed(parts[i]); len(matches) == 1 {
+			owner, _ := m.index.FindProvince(matches[0].ProvinceCode)
+			return owner.Name, matches[0].Name
+		}
+	}
+
+	if province != nil {
+		return province.Name, ""
+	}
+	return "", ""
+}
+
+// unmatchedReason describes a resolve error for the report
+func unmatchedReason(err error) (string, string) {
+	var regionErr *models.RegionError
+	if errors.As(err, &regionErr) {
+		return regionErr.Field, regionErr.Error()
+	}
+	return "province", err.Error()
+}
+
+// normalizeDirektori maps the province and city of every direktori entry, including trashed ones
+func normalizeDirektori(db *sqlx.DB, mapper *regionMapper, apply bool) (tableSummary, error) {
+	summary := tableSummary{Table: "direktori"}
+
+	var rows []struct {
+		ID       int64  `db:"id"`
+		Province string `db:"province"`
+		City     string `db:"city"`
+	}
+	if err := db.Select(&rows, `SELECT id, province, city FROM direktori ORDER BY id ASC`); err != nil {
+		return summary, err
+	}
+
+	updates := []regionUpdate{}
+	for _, row := range rows {
+		summary.Scanned++
+		if strings.TrimSpace(row.Province) == "" && strings.TrimSpace(row.City) == "" {
+			summary.Unchanged++
+			continue
+		}
+
+		province, city, err := mapper.resolve(row.Province, row.City)
+		if err != nil {
+			field, reason := unmatchedReason(err)
+			value := row.Province
+			if field == "city" {
+				value = row.City
+			}
+			summary.Unmatched = append(summary.Unmatched, unmatchedRow{summary.Table, row.ID, field, value, reason})
+			continue
+		}
+		if province == row.Province && city == row.City {
+			summary.Unchanged++
+			continue
+		}
+		summary.Changed++
+		updates = append(updates, regionUpdate{ID: row.ID, Province: province, City: city})
+	}
+
+	if !apply {
+		return summary, nil
+	}
+	return summary, applyUpdates(db, updates, `UPDATE direktori SET province = ?, city = ? WHERE id = ?`, func(u regionUpdate) []interface{} {
+		return []interface{}{u.Province, u.City, u.ID}
+	})
+}
+
+// normalizeAgenda maps the province of every agenda that has one
+func normalizeAgenda(db *sqlx.DB, mapper *regionMapper, apply bool) (tableSummary, error) {
+	summary := tableSummary{Table: "agenda"}
+
+	var rows []struct {
+		ID       int64  `db:"id"`
+		Province string `db:"province"`
+	}
+	if err := db.Select(&rows, `SELECT id, province FROM agenda WHERE province IS NOT NULL AND province != '' ORDER BY id ASC`); err != nil {
+		return summary, err
+	}
+
+	updates := []regionUpdate{}
+	for _, row := range rows {
+		summary.Scanned++
+		province, _, err := mapper.resolve(row.Province, "")
+		if err != nil {
+			_, reason := unmatchedReason(err)
+			summary.Unmatched = append(summary.Unmatched, unmatchedRow{summary.Table, row.ID, "province", row.Province, reason})
+			continue
+		}
+		if province == row.Province {
+			summary.Unchanged++
+			continue
+		}
+		summary.Changed++
+		updates = append(updates, regionUpdate{ID: row.ID, Province: province})
+	}
+
+	if !apply {
+		return summary, nil
+	}
+	return summary, applyUpdates(db, updates, `UPDATE agenda SET province = ? WHERE id = ?`, func(u regionUpdate) []interface{} {
+		return []interface{}{u.Province, u.ID}
+	})
+}
+
+// normalizeUsers maps the province and city of users, filling them from the address when both are empty
+func normalizeUsers(db *sqlx.DB, mapper *regionMapper, apply bool) (tableSummary, error) {
+	summary := tableSummary{Table: "users"}
+
+	var rows []struct {
+		ID       int64  `db:"id"`
+		Address  string `db:"address"`
+		Province string `db:"province"`
+		City     string `db:"city"`
+	}
+	query := `SELECT id, COALESCE(address, '') AS address, COALESCE(province, '') AS province, COALESCE(city, '') AS city FROM users WHERE status != 'deleted' ORDER BY id ASC`
+	if err := db.Select(&rows, query); err != nil {
+		return summary, err
+	}
+
+	updates := []regionUpdate{}
+	for _, row := range rows {
+		summary.Scanned++
+
+		var province, city string
+		switch {
+		case strings.TrimSpace(row.Province) != "" || strings.TrimSpace(row.City) != "":
+			var err error
+			province, city, err = mapper.resolve(row.Province, row.City)
+			if err != nil {
+				field, reason := unmatchedReason(err)
+				value := row.Province
+				if field == "city" {
+					value = row.City
+				}
+				summary.Unmatched = append(summary.Unmatched, unmatchedRow{summary.Table, row.ID, field, value, reason})
+				continue
+			}
+		case strings.TrimSpace(row.Address) != "":
+			province, city = mapper.fromAddress(row.Address)
+			if province == "" {
+				summary.Unmatched = append(summary.Unmatched, unmatchedRow{summary.Table, row.ID, "address", row.Address, "no province or city found in the address"})
+				continue
+			}
+		default:
+			summary.Unchanged++
+			continue
+		}
+
+		if province == row.Province && city == row.City {
+			summary.Unchanged++
+			continue
+		}
+		summary.Changed++
+		updates = append(updates, regionUpdate{ID: row.ID, Province: province, City: city})
+	}
+
+	if !apply {
+		return summary, nil
+	}
+	return summary, applyUpdates(db, updates, `UPDATE users SET province = ?, city = NULLIF(?, '') WHERE id = ?`, func(u regionUpdate) []interface{} {
+		return []interface{}{u.Province, u.City, u.ID}
+	})
+}
+
+// applyUpdates runs one statement per update in a single transaction
+func applyUpdates(db *sqlx.DB, updates []regionUpdate, query string, args func(regionUpdate) []interface{}) error {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Preparex(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, update := range updates {
+		if _, err := stmt.Exec(args(update)...); err != nil {
+			return fmt.Errorf("row %d: %w", update.ID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// printSummaries prints the counts per table and its most frequent unmatched values
+func printSummaries(summaries []tableSummary, apply bool) {
+	changedLabel := "would change"
+	if apply {
+		changedLabel = "changed"
+	}
+
+	for _, summary := range summaries {
+		fmt.Printf("\n%s: %d scanned, %d unchanged, %d %s, %d unmatched\n",
+			summary.Table, summary.Scanned, summary.Unchanged, summary.Changed, changedLabel, len(summary.Unmatched))
+
+		counts := map[string]int{}
+		reasons := map[string]string{}
+		for _, row := range summary.Unmatched {
+			key := row.Field + ": " + row.Value
+			counts[key]++
+			reasons[key] = row.Reason
+		}
+		keys := make([]string, 0, len(counts))
+		for key := range counts {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if counts[keys[i]] != counts[keys[j]] {
+				return counts[keys[i]] > counts[keys[j]]
+			}
+			return keys[i] < keys[j]
+		})
+
+		for i, key := range keys {
+			if i == unmatchedShown {
+				fmt.Printf("  ... and %d more distinct values\n", len(keys)-unmatchedShown)
+				break
+			}
+			fmt.Printf("  %4d× %s (%s)\n", counts[key], key, reasons[key])
+		}
+	}
+
+	if !apply {
+		fmt.Println("\nDry run, nothing was written. Run again with -apply to save the changes.")
+	}
+}
+
+// writeReport writes every unmatched row to a CSV file
+func writeReport(path string, summaries []tableSummary) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.Write([]string{"table", "id", "field", "value", "reason"}); err != nil {
+		return err
+	}
+	for _, summary := range summaries {
+		for _, row := range summary.Unmatched {
+			record := []string{row.Table, fmt.Sprint(row.ID), row.Field, row.Value, row.Reason}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
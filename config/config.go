@@ -1,6 +1,9 @@
 package config
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"strconv"
@@ -17,13 +20,22 @@ type Config struct {
 	RateLimit RateLimitConfig
 	CORS      CORSConfig
 	Redis     RedisConfig
+	SEO       SEOConfig
+	Trash     TrashConfig
+	Mail      MailConfig
+	Ticket    TicketConfig
+	Payment   PaymentConfig
+	Reminder  ReminderConfig
+	Document  DocumentConfig
 }
 
 // AppConfig holds application-specific configuration
 type AppConfig struct {
-	Name string
-	Env  string
-	Port string
+	Name     string
+	Env      string
+	Port     string
+	URL      string // Public base URL of this API (used to build absolute file URLs)
+	Timezone string // IANA time zone events are published in (e.g. in iCalendar feeds)
 }
 
 // DatabaseConfig holds database connection configuration
@@ -67,6 +79,58 @@ type RedisConfig struct {
 	DB       int
 }
 
+// SEOConfig holds sitemap and robots.txt configuration
+type SEOConfig struct {
+	SiteURL         string   // Public base URL of the frontend site (used for sitemap <loc>)
+	SitemapCacheTTL int      // in seconds
+	RobotsAllow     []string // Paths listed as Allow in robots.txt
+	RobotsDisallow  []string // Paths listed as Disallow in robots.txt
+	RobotsBlockAll  bool     // Disallow everything (e.g. for staging)
+}
+
+// TrashConfig holds trash bin retention configuration
+type TrashConfig struct {
+	RetentionDays        int // Days a soft-deleted item is kept before being purged (0 disables purge)
+	PurgeIntervalMinutes int // How often the purge job runs
+}
+
+// MailConfig holds outgoing email (SMTP) configuration
+type MailConfig struct {
+	Host        string // Empty disables sending (messages are only logged)
+	Port        int
+	Username    string
+	Password    string
+	Encryption  string // tls (STARTTLS), ssl (implicit TLS) or none
+	FromAddress string
+	FromName    string
+}
+
+// TicketConfig holds event ticket signing configuration
+type TicketConfig struct {
+	Secret string // HMAC key of QR tickets (defaults to a key derived from JWT_SECRET)
+}
+
+// PaymentConfig holds payment gateway configuration of paid agenda registrations
+type PaymentConfig struct {
+	Provider              string // midtrans, fake (local testing only) or empty when paid registrations are disabled
+	ServerKey             string // Midtrans server key, used for API calls and webhook signatures
+	Production            bool   // Use the production API instead of the sandbox
+	FakeSecret            string // Signing key of fake provider notifications, required for the fake provider
+	InvoiceExpiryMinutes  int    // How long a member has to pay before the seat is released
+	ExpireIntervalMinutes int    // How often lapsed invoices are expired
+}
+
+// ReminderConfig holds agenda reminder configuration
+type ReminderConfig struct {
+	IntervalMinutes int // How often due reminders are sent (0 disables reminders)
+}
+
+// DocumentConfig holds member document expiry configuration
+type DocumentConfig struct {
+	ExpiryIntervalMinutes int   // How often documents are expired and reminders sent (0 disables the job)
+	ReminderDays          []int // Days before valid_until a reminder is sent, e.g. 90, 30 and 7
+}
+
 // LoadConfig loads configuration from .env file and environment variables
 func LoadConfig() (*Config, error) {
 	// Load .env file if it exists (ignore error if file doesn't exist)
@@ -74,9 +138,11 @@ func LoadConfig() (*Config, error) {
 
 	config := &Config{
 		App: AppConfig{
-			Name: getEnv("APP_NAME", "Go Gin Starter Kit"),
-			Env:  getEnv("APP_ENV", "local"),
-			Port: getEnv("APP_PORT", "8080"),
+			Name:     getEnv("APP_NAME", "Go Gin Starter Kit"),
+			Env:      getEnv("APP_ENV", "local"),
+			Port:     getEnv("APP_PORT", "8080"),
+			URL:      strings.TrimRight(getEnv("APP_URL", "http://localhost:8080"), "/"),
+			Timezone: getEnv("APP_TIMEZONE", "Asia/Jakarta"),
 		},
 		Database: DatabaseConfig{
 			Connection:      getEnv("DB_CONNECTION", "mysql"),
@@ -109,6 +175,44 @@ func LoadConfig() (*Config, error) {
 			Password: getEnv("REDIS_PASSWORD", ""),
 			DB:       getEnvAsInt("REDIS_DB", 0),
 		},
+		SEO: SEOConfig{
+			SiteURL:         strings.TrimRight(getEnv("SITE_URL", "http://localhost:3000"), "/"),
+			SitemapCacheTTL: getEnvAsInt("SITEMAP_CACHE_TTL", 3600),
+			RobotsAllow:     getEnvAsSlice("ROBOTS_ALLOW", []string{"/"}),
+			RobotsDisallow:  getEnvAsSlice("ROBOTS_DISALLOW", []string{"/admin", "/dashboard"}),
+			RobotsBlockAll:  getEnvAsBool("ROBOTS_BLOCK_ALL", false),
+		},
+		Trash: TrashConfig{
+			RetentionDays:        getEnvAsInt("TRASH_RETENTION_DAYS", 30),
+			PurgeIntervalMinutes: getEnvAsInt("TRASH_PURGE_INTERVAL_MINUTES", 60),
+		},
+		Mail: MailConfig{
+			Host:        getEnv("MAIL_HOST", ""),
+			Port:        getEnvAsInt("MAIL_PORT", 587),
+			Username:    getEnv("MAIL_USERNAME", ""),
+			Password:    getEnv("MAIL_PASSWORD", ""),
+			Encryption:  strings.ToLower(getEnv("MAIL_ENCRYPTION", "tls")),
+			FromAddress: getEnv("MAIL_FROM_ADDRESS", "no-reply@localhost"),
+			FromName:    getEnv("MAIL_FROM_NAME", getEnv("APP_NAME", "Go Gin Starter Kit")),
+		},
+		Ticket: TicketConfig{
+			Secret: getEnv("TICKET_SECRET", deriveSecret(getEnv("JWT_SECRET", ""), "ticket")),
+		},
+		Payment: PaymentConfig{
+			Provider:              strings.ToLower(getEnv("PAYMENT_PROVIDER", "")),
+			ServerKey:             getEnv("PAYMENT_SERVER_KEY", ""),
+			Production:            getEnvAsBool("PAYMENT_PRODUCTION", false),
+			FakeSecret:            getEnv("PAYMENT_FAKE_SECRET", ""),
+			InvoiceExpiryMinutes:  getEnvAsInt("PAYMENT_INVOICE_EXPIRY_MINUTES", 1440),
+			ExpireIntervalMinutes: getEnvAsInt("PAYMENT_EXPIRE_INTERVAL_MINUTES", 5),
+		},
+		Reminder: ReminderConfig{
+			IntervalMinutes: getEnvAsInt("REMINDER_INTERVAL_MINUTES", 5),
+		},
+		Document: DocumentConfig{
+			ExpiryIntervalMinutes: getEnvAsInt("DOCUMENT_EXPIRY_INTERVAL_MINUTES", 1440),
+			ReminderDays:          getEnvAsIntSlice("DOCUMENT_REMINDER_DAYS", []int{90, 30, 7}),
+		},
 	}
 
 	// Validate required fields
@@ -130,9 +234,37 @@ func (c *Config) Validate() error {
 	if c.JWT.Secret == "" {
 		return fmt.Errorf("JWT_SECRET is required")
 	}
+	switch c.Payment.Provider {
+	case "":
+		// Paid registrations are rejected until a provider is configured
+	case "midtrans":
+		if c.Payment.ServerKey == "" {
+			return fmt.Errorf("PAYMENT_SERVER_KEY is required for the midtrans payment provider")
+		}
+	case "fake":
+		if c.App.Env == "production" {
+			return fmt.Errorf("PAYMENT_PROVIDER=fake cannot be used in production")
+		}
+		if c.Payment.FakeSecret == "" {
+			return fmt.Errorf("PAYMENT_FAKE_SECRET is required for the fake payment provider")
+		}
+	default:
+		return fmt.Errorf("PAYMENT_PROVIDER must be midtrans, fake or empty")
+	}
 	return nil
 }
 
+// deriveSecret derives a purpose-specific key from a secret, so the secret itself is never reused as a key
+// Returns an empty string when the secret is empty
+func deriveSecret(secret, purpose string) string {
+	if secret == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(purpose))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
 // getEnv retrieves an environment variable or returns a default value
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -167,3 +299,20 @@ func getEnvAsSlice(key string, defaultValue []string) []string {
 	}
 	return strings.Split(valueStr, ",")
 }
+
+// getEnvAsIntSlice retrieves an environment variable as a slice of integers (comma-separated) or returns a default value
+// Entries that are not positive integers are ignored
+func getEnvAsIntSlice(key string, defaultValue []int) []int {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	values := []int{}
+	for _, part := range strings.Split(valueStr, ",") {
+		if value, err := strconv.Atoi(strings.TrimSpace(part)); err == nil && value > 0 {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,29 @@
+-- Create Slug Histories Table
+-- Stores previous slugs of berita, agenda and content pages so old links can be redirected
+CREATE TABLE IF NOT EXISTS slug_histories (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    entity_type VARCHAR(50) NOT NULL COMMENT 'berita, agenda, content',
+    entity_id BIGINT NOT NULL,
+    old_slug VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE KEY uk_entity_old_slug (entity_type, old_slug),
+    INDEX idx_entity (entity_type, entity_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+-- Create Redirects Table
+-- Arbitrary path-to-path redirects managed by admins
+CREATE TABLE IF NOT EXISTS redirects (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    from_path VARCHAR(500) NOT NULL,
+    to_path VARCHAR(500) NOT NULL,
+    status_code INT NOT NULL DEFAULT 301 COMMENT '301, 302, 307, 308',
+    is_active BOOLEAN DEFAULT TRUE,
+    hits BIGINT DEFAULT 0,
+    last_hit_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    UNIQUE KEY uk_from_path (from_path),
+    INDEX idx_is_active (is_active)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
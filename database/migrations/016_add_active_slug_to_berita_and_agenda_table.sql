@@ -0,0 +1,14 @@
+-- Release slugs of soft-deleted berita and agenda
+-- The plain UNIQUE index on slug also covered trashed rows, so a deleted item blocked its slug forever.
+-- active_slug is NULL for trashed rows, and MySQL allows multiple NULLs in a UNIQUE index.
+-- Conflicts are resolved when an item is restored from the trash.
+
+ALTER TABLE berita
+DROP INDEX slug,
+ADD COLUMN active_slug VARCHAR(255) GENERATED ALWAYS AS (IF(deleted_at IS NULL, slug, NULL)) STORED,
+ADD UNIQUE KEY uk_berita_active_slug (active_slug);
+
+ALTER TABLE agenda
+DROP INDEX slug,
+ADD COLUMN active_slug VARCHAR(255) GENERATED ALWAYS AS (IF(deleted_at IS NULL, slug, NULL)) STORED,
+ADD UNIQUE KEY uk_agenda_active_slug (active_slug);
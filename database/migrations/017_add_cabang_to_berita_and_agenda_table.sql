@@ -0,0 +1,8 @@
+-- Add cabang (organization unit) to berita and agenda
+-- NULL means the item belongs to pusat. Admins of a cabang/wilayah may only manage their own items.
+
+ALTER TABLE berita ADD COLUMN cabang VARCHAR(255) NULL COMMENT 'Owning organization unit, NULL = pusat' AFTER author;
+ALTER TABLE berita ADD INDEX idx_berita_cabang (cabang);
+
+ALTER TABLE agenda ADD COLUMN cabang VARCHAR(255) NULL COMMENT 'Owning organization unit, NULL = pusat' AFTER status;
+ALTER TABLE agenda ADD INDEX idx_agenda_cabang (cabang);
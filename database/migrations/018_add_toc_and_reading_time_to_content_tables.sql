@@ -0,0 +1,27 @@
+-- Add rendered table of contents and reading time to content tables
+-- content_pages was previously only created at runtime by ContentController.InitTable,
+-- so make sure it exists before altering it.
+
+CREATE TABLE IF NOT EXISTS content_pages (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    slug VARCHAR(255) NOT NULL UNIQUE,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    body LONGTEXT,
+    html LONGTEXT,
+    date DATETIME,
+    image_src VARCHAR(255),
+    badge_label VARCHAR(100),
+    authors JSON,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    INDEX idx_slug (slug)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+ALTER TABLE content_pages
+ADD COLUMN toc JSON NULL COMMENT 'Table of contents generated from headings' AFTER html,
+ADD COLUMN reading_time INT NOT NULL DEFAULT 0 COMMENT 'Estimated reading time in minutes' AFTER toc;
+
+ALTER TABLE dynamic_contents
+ADD COLUMN toc JSON NULL COMMENT 'Table of contents generated from headings' AFTER html,
+ADD COLUMN reading_time INT NOT NULL DEFAULT 0 COMMENT 'Estimated reading time in minutes' AFTER toc;
@@ -0,0 +1,3 @@
+-- Track when a registration was last confirmed or cancelled
+
+ALTER TABLE agenda_registrations ADD COLUMN updated_at TIMESTAMP NULL DEFAULT NULL AFTER registered_at;
@@ -0,0 +1,8 @@
+-- iCalendar support
+-- sequence is bumped on every agenda update so calendar clients replace the event (RFC 5545 SEQUENCE)
+-- calendar_token is the secret of a member's private calendar feed URL
+
+ALTER TABLE agenda ADD COLUMN sequence INT NOT NULL DEFAULT 0 AFTER cabang;
+
+ALTER TABLE users ADD COLUMN calendar_token VARCHAR(64) NULL DEFAULT NULL;
+ALTER TABLE users ADD UNIQUE KEY uk_users_calendar_token (calendar_token);
@@ -0,0 +1,8 @@
+-- Event check-in with signed QR tickets
+-- checked_in_by is the committee member (user) who scanned the ticket
+
+ALTER TABLE agenda_registrations ADD COLUMN checked_in_at TIMESTAMP NULL DEFAULT NULL AFTER updated_at;
+ALTER TABLE agenda_registrations ADD COLUMN checked_in_by BIGINT NULL DEFAULT NULL AFTER checked_in_at;
+ALTER TABLE agenda_registrations ADD INDEX idx_agenda_registrations_checked_in (agenda_id, checked_in_at);
+ALTER TABLE agenda_registrations ADD CONSTRAINT fk_agenda_registrations_checked_in_by
+    FOREIGN KEY (checked_in_by) REFERENCES users(id) ON DELETE SET NULL;
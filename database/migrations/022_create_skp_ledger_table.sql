@@ -0,0 +1,28 @@
+-- SKP (professional credit) ledger
+-- One entry is posted per attended registration. Member name, agenda title and date are
+-- copied so issued certificates do not change when the agenda or profile is edited later.
+
+CREATE TABLE IF NOT EXISTS skp_ledger (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    agenda_id BIGINT NULL,
+    registration_id BIGINT NULL,
+    points DECIMAL(6,2) NOT NULL DEFAULT 0,
+    description VARCHAR(255) NOT NULL,
+    member_name VARCHAR(255) NOT NULL,
+    agenda_title VARCHAR(255) NOT NULL,
+    agenda_date DATETIME NOT NULL,
+    earned_at DATETIME NOT NULL,
+    certificate_code VARCHAR(32) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE KEY uk_skp_ledger_registration (registration_id),
+    UNIQUE KEY uk_skp_ledger_certificate_code (certificate_code),
+    INDEX idx_skp_ledger_user_earned (user_id, earned_at),
+    CONSTRAINT fk_skp_ledger_user_id
+        FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT fk_skp_ledger_agenda_id
+        FOREIGN KEY (agenda_id) REFERENCES agenda(id) ON DELETE SET NULL,
+    CONSTRAINT fk_skp_ledger_registration_id
+        FOREIGN KEY (registration_id) REFERENCES agenda_registrations(id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,6 @@
+-- Custom registration forms
+-- registration_form holds the field schema of an agenda (NULL = no extra questions),
+-- answers holds what a member filled in when registering
+
+ALTER TABLE agenda ADD COLUMN registration_form JSON NULL AFTER registration_url;
+ALTER TABLE agenda_registrations ADD COLUMN answers JSON NULL AFTER status;
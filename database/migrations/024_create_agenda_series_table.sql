@@ -0,0 +1,25 @@
+-- Recurring agenda series
+-- A series holds the recurrence rule (RFC 5545 RRULE subset), every occurrence is a regular agenda row
+-- so registrations, tickets and SKP keep working per occurrence.
+-- recurrence_id is the start the rule generated for an occurrence, is_exception marks occurrences
+-- edited on their own (series edits skip them) and cancelled_at marks cancelled occurrences.
+
+CREATE TABLE IF NOT EXISTS agenda_series (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    slug VARCHAR(255) NOT NULL COMMENT 'Base of the occurrence slugs',
+    rrule VARCHAR(255) NOT NULL,
+    starts_at DATETIME NOT NULL,
+    duration_seconds INT NULL,
+    cabang VARCHAR(255) NULL,
+    cancelled_at TIMESTAMP NULL DEFAULT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+ALTER TABLE agenda ADD COLUMN series_id BIGINT NULL DEFAULT NULL AFTER cabang;
+ALTER TABLE agenda ADD COLUMN recurrence_id DATETIME NULL DEFAULT NULL AFTER series_id;
+ALTER TABLE agenda ADD COLUMN is_exception BOOLEAN NOT NULL DEFAULT FALSE AFTER recurrence_id;
+ALTER TABLE agenda ADD COLUMN cancelled_at TIMESTAMP NULL DEFAULT NULL AFTER is_exception;
+ALTER TABLE agenda ADD INDEX idx_agenda_series_date (series_id, date);
+ALTER TABLE agenda ADD CONSTRAINT fk_agenda_series_id
+    FOREIGN KEY (series_id) REFERENCES agenda_series(id) ON DELETE SET NULL;
@@ -0,0 +1,6 @@
+-- Province of offline agenda for calendar filters
+-- end_date is indexed for range (overlap) queries of the calendar view
+
+ALTER TABLE agenda ADD COLUMN province VARCHAR(100) NULL DEFAULT NULL AFTER location;
+ALTER TABLE agenda ADD INDEX idx_agenda_province (province);
+ALTER TABLE agenda ADD INDEX idx_agenda_end_date (end_date);
@@ -0,0 +1,36 @@
+-- Paid agenda registrations
+-- agenda.price is the registration price in whole rupiah (0 = free), fee stays as free display text.
+-- An invoice is issued for every paid registration holding a seat. The payment provider reports
+-- settlement through a signed webhook, lapsed invoices expire and release the seat.
+
+ALTER TABLE agenda ADD COLUMN price BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT 'Registration price in IDR (0 = free)' AFTER fee;
+
+CREATE TABLE IF NOT EXISTS invoices (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    number VARCHAR(50) NOT NULL COMMENT 'Order ID sent to the payment provider',
+    registration_id BIGINT NOT NULL,
+    agenda_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    amount BIGINT UNSIGNED NOT NULL,
+    currency CHAR(3) NOT NULL DEFAULT 'IDR',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' COMMENT 'pending, paid, expired, failed, cancelled',
+    provider VARCHAR(30) NOT NULL,
+    provider_ref VARCHAR(100) NULL COMMENT 'Transaction or invoice ID at the provider',
+    payment_url VARCHAR(500) NULL,
+    payment_method VARCHAR(50) NULL,
+    expires_at DATETIME NOT NULL,
+    paid_at DATETIME NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    UNIQUE KEY uk_invoices_number (number),
+    INDEX idx_invoices_registration (registration_id, status),
+    INDEX idx_invoices_status_expires (status, expires_at),
+    INDEX idx_invoices_agenda (agenda_id, status),
+    CONSTRAINT fk_invoices_registration_id
+        FOREIGN KEY (registration_id) REFERENCES agenda_registrations(id) ON DELETE CASCADE,
+    CONSTRAINT fk_invoices_agenda_id
+        FOREIGN KEY (agenda_id) REFERENCES agenda(id) ON DELETE CASCADE,
+    CONSTRAINT fk_invoices_user_id
+        FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
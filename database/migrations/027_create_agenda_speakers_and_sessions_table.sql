@@ -0,0 +1,60 @@
+-- Agenda speakers and rundown
+-- A speaker may be linked to a member account (users) or a board member (pengurus).
+-- Sessions form the rundown of an agenda, each with its own time slot, room and SKP value.
+-- agenda_session_speakers links speakers to sessions with their role (speaker or moderator).
+
+CREATE TABLE IF NOT EXISTS agenda_speakers (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    agenda_id BIGINT NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    title VARCHAR(255) NOT NULL DEFAULT '' COMMENT 'Academic title or position, e.g. Sp.P(K)',
+    institution VARCHAR(255) NOT NULL DEFAULT '',
+    bio TEXT NOT NULL,
+    photo_url VARCHAR(500) NOT NULL DEFAULT '',
+    user_id BIGINT NULL,
+    pengurus_id BIGINT NULL,
+    sort_order INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    INDEX idx_agenda_speakers_agenda (agenda_id, sort_order),
+    CONSTRAINT fk_agenda_speakers_agenda_id
+        FOREIGN KEY (agenda_id) REFERENCES agenda(id) ON DELETE CASCADE,
+    CONSTRAINT fk_agenda_speakers_user_id
+        FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT fk_agenda_speakers_pengurus_id
+        FOREIGN KEY (pengurus_id) REFERENCES pengurus(id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS agenda_sessions (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    agenda_id BIGINT NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL,
+    starts_at DATETIME NOT NULL,
+    ends_at DATETIME NOT NULL,
+    room VARCHAR(255) NOT NULL DEFAULT '',
+    skp DECIMAL(6,2) NOT NULL DEFAULT 0,
+    sort_order INT NOT NULL DEFAULT 0,
+    sequence INT NOT NULL DEFAULT 0 COMMENT 'iCalendar SEQUENCE, bumped on every update',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    INDEX idx_agenda_sessions_agenda (agenda_id, starts_at),
+    CONSTRAINT fk_agenda_sessions_agenda_id
+        FOREIGN KEY (agenda_id) REFERENCES agenda(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS agenda_session_speakers (
+    session_id BIGINT NOT NULL,
+    speaker_id BIGINT NOT NULL,
+    role VARCHAR(20) NOT NULL DEFAULT 'speaker' COMMENT 'speaker, moderator',
+    sort_order INT NOT NULL DEFAULT 0,
+
+    PRIMARY KEY (session_id, speaker_id, role),
+    INDEX idx_agenda_session_speakers_speaker (speaker_id),
+    CONSTRAINT fk_agenda_session_speakers_session_id
+        FOREIGN KEY (session_id) REFERENCES agenda_sessions(id) ON DELETE CASCADE,
+    CONSTRAINT fk_agenda_session_speakers_speaker_id
+        FOREIGN KEY (speaker_id) REFERENCES agenda_speakers(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
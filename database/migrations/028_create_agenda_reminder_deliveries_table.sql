@@ -0,0 +1,33 @@
+-- Agenda reminders
+-- agenda.reminder_offsets lists the minutes before the start at which confirmed registrants are
+-- reminded by email. NULL uses the defaults (one day and one hour before), an empty list disables reminders.
+-- Every reminder is claimed by inserting its delivery row first. The unique key makes the claim
+-- atomic across instances so a reminder is never sent twice. A changed start date gives new
+-- scheduled_for values so rescheduled agenda are reminded again.
+
+ALTER TABLE agenda ADD COLUMN reminder_offsets JSON NULL COMMENT 'Minutes before start to remind registrants, NULL = defaults' AFTER price;
+
+CREATE TABLE IF NOT EXISTS agenda_reminder_deliveries (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    agenda_id BIGINT NOT NULL,
+    registration_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    offset_minutes INT NOT NULL,
+    scheduled_for DATETIME NOT NULL COMMENT 'Agenda start minus the offset',
+    recipient VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'sending' COMMENT 'sending, sent, failed',
+    attempts INT NOT NULL DEFAULT 1,
+    error TEXT NULL,
+    sent_at DATETIME NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    UNIQUE KEY uk_agenda_reminder_deliveries (registration_id, offset_minutes, scheduled_for),
+    INDEX idx_agenda_reminder_deliveries_agenda (agenda_id, status),
+    CONSTRAINT fk_agenda_reminder_deliveries_agenda_id
+        FOREIGN KEY (agenda_id) REFERENCES agenda(id) ON DELETE CASCADE,
+    CONSTRAINT fk_agenda_reminder_deliveries_registration_id
+        FOREIGN KEY (registration_id) REFERENCES agenda_registrations(id) ON DELETE CASCADE,
+    CONSTRAINT fk_agenda_reminder_deliveries_user_id
+        FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,17 @@
+-- Direktori facilities are a JSON array of lowercase facility names.
+-- Rows written before the API existed may hold NULL or a non-array value, and NULL text
+-- columns cannot be read into the model, so both are normalized here.
+
+UPDATE direktori SET facilities = JSON_ARRAY() WHERE facilities IS NULL OR JSON_TYPE(facilities) <> 'ARRAY';
+
+UPDATE direktori SET
+    type = COALESCE(type, ''),
+    address = COALESCE(address, ''),
+    phone = COALESCE(phone, ''),
+    email = COALESCE(email, ''),
+    website = COALESCE(website, ''),
+    city = COALESCE(city, ''),
+    province = COALESCE(province, ''),
+    has_respirologist = COALESCE(has_respirologist, FALSE);
+
+CREATE INDEX idx_direktori_has_respirologist ON direktori (has_respirologist);
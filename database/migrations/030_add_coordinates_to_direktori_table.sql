@@ -0,0 +1,8 @@
+-- Coordinates of direktori entries for nearby search and the map layer (WGS84 degrees).
+-- Entries without coordinates are left out of both.
+
+ALTER TABLE direktori
+    ADD COLUMN latitude DECIMAL(10,7) NULL AFTER province,
+    ADD COLUMN longitude DECIMAL(10,7) NULL AFTER latitude;
+
+CREATE INDEX idx_direktori_coordinates ON direktori (latitude, longitude);
@@ -0,0 +1,31 @@
+-- Spreadsheet imports of direktori
+-- An import runs in the background on the instance that received the upload and reports its
+-- progress here so any instance can answer polling requests. Rows are upserted on name + city.
+-- Dry runs validate and count without writing. errors holds the first row errors as JSON.
+
+CREATE TABLE IF NOT EXISTS direktori_imports (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id BIGINT NULL,
+    filename VARCHAR(255) NOT NULL,
+    format VARCHAR(10) NOT NULL COMMENT 'csv, xlsx',
+    mapping JSON NOT NULL COMMENT 'Direktori field to spreadsheet column header',
+    dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+    status VARCHAR(20) NOT NULL DEFAULT 'queued' COMMENT 'queued, running, completed, failed',
+    total_rows INT NOT NULL DEFAULT 0,
+    processed_rows INT NOT NULL DEFAULT 0,
+    created_count INT NOT NULL DEFAULT 0,
+    updated_count INT NOT NULL DEFAULT 0,
+    error_count INT NOT NULL DEFAULT 0,
+    errors JSON NULL,
+    message TEXT NULL COMMENT 'Reason a failed import stopped',
+    started_at DATETIME NULL,
+    finished_at DATETIME NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    INDEX idx_direktori_imports_user (user_id, created_at),
+    CONSTRAINT fk_direktori_imports_user_id
+        FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE INDEX idx_direktori_name_city ON direktori (name(100), city);
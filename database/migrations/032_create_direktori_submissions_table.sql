@@ -0,0 +1,35 @@
+-- Member proposals for new direktori entries and corrections, moderated by admins
+-- direktori_id of a new entry is set when it is approved. proposal holds only the proposed fields
+-- so approving a correction leaves fields edited since then untouched.
+-- Approved entries are marked verified with the reviewing admin.
+
+ALTER TABLE direktori
+    ADD COLUMN verified_at DATETIME NULL AFTER facilities,
+    ADD COLUMN verified_by BIGINT NULL AFTER verified_at,
+    ADD CONSTRAINT fk_direktori_verified_by
+        FOREIGN KEY (verified_by) REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE TABLE IF NOT EXISTS direktori_submissions (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL COMMENT 'new, correction',
+    direktori_id BIGINT NULL COMMENT 'Corrected entry, or the entry created by approving a new one',
+    user_id BIGINT NULL,
+    proposal JSON NOT NULL COMMENT 'Proposed direktori fields',
+    note TEXT NULL COMMENT 'Explanation or source from the member',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' COMMENT 'pending, approved, rejected',
+    reject_reason TEXT NULL,
+    reviewed_by BIGINT NULL,
+    reviewed_at DATETIME NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    INDEX idx_direktori_submissions_status (status, created_at),
+    INDEX idx_direktori_submissions_user (user_id, status, kind),
+    INDEX idx_direktori_submissions_direktori (direktori_id, status),
+    CONSTRAINT fk_direktori_submissions_direktori_id
+        FOREIGN KEY (direktori_id) REFERENCES direktori(id) ON DELETE CASCADE,
+    CONSTRAINT fk_direktori_submissions_user_id
+        FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL,
+    CONSTRAINT fk_direktori_submissions_reviewed_by
+        FOREIGN KEY (reviewed_by) REFERENCES users(id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,34 @@
+-- Reference data of Indonesian provinces and regencies/cities (kabupaten/kota)
+-- Codes follow Kemendagri, e.g. 35 for Jawa Timur and 35.73 for Kota Malang.
+-- Rows are filled by the wilayah seeder from database/seeders/data. aliases hold common
+-- abbreviations and spellings (Jatim, Solo) used to normalize free text input.
+-- Users get a structured province and city next to their free text address.
+
+CREATE TABLE IF NOT EXISTS provinces (
+    code CHAR(2) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    aliases JSON NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    UNIQUE KEY uk_provinces_name (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS regencies (
+    code CHAR(5) PRIMARY KEY,
+    province_code CHAR(2) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    type VARCHAR(10) NOT NULL COMMENT 'kabupaten, kota',
+    aliases JSON NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    INDEX idx_regencies_province (province_code, name),
+    CONSTRAINT fk_regencies_province_code
+        FOREIGN KEY (province_code) REFERENCES provinces(code) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+ALTER TABLE users
+    ADD COLUMN province VARCHAR(100) NULL AFTER address,
+    ADD COLUMN city VARCHAR(100) NULL AFTER province,
+    ADD INDEX idx_users_province_city (province, city);
@@ -0,0 +1,39 @@
+-- A pengurus is a person, what they hold in a period is a term, so one person keeps
+-- their history across periods and may hold more than one position in a period.
+-- The person may be linked to a member account and gets a photo.
+-- Existing rows become a person with one term, then the term columns move off pengurus.
+
+CREATE TABLE IF NOT EXISTS pengurus_terms (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    pengurus_id BIGINT NOT NULL,
+    level VARCHAR(50) NOT NULL COMMENT 'pusat, wilayah, cabang',
+    periode VARCHAR(50) NOT NULL,
+    bidang VARCHAR(255) NOT NULL DEFAULT '' COMMENT 'empty for the executive board',
+    position VARCHAR(255) NOT NULL,
+    sort_order INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    INDEX idx_pengurus_terms_pengurus (pengurus_id),
+    INDEX idx_pengurus_terms_periode (periode, level, bidang, sort_order),
+    CONSTRAINT fk_pengurus_terms_pengurus_id
+        FOREIGN KEY (pengurus_id) REFERENCES pengurus(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+INSERT INTO pengurus_terms (pengurus_id, level, periode, bidang, position, sort_order, created_at, updated_at)
+SELECT id, COALESCE(NULLIF(level, ''), 'pusat'), COALESCE(periode, ''), COALESCE(bidang, ''), COALESCE(position, ''), 0, created_at, updated_at
+FROM pengurus
+WHERE COALESCE(periode, '') != '' OR COALESCE(position, '') != '';
+
+ALTER TABLE pengurus
+    DROP INDEX idx_level,
+    DROP INDEX idx_periode,
+    DROP COLUMN position,
+    DROP COLUMN bidang,
+    DROP COLUMN level,
+    DROP COLUMN periode,
+    ADD COLUMN user_id BIGINT NULL AFTER email,
+    ADD COLUMN photo_url VARCHAR(500) NULL AFTER user_id,
+    ADD INDEX idx_pengurus_user_id (user_id),
+    ADD CONSTRAINT fk_pengurus_user_id
+        FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL;
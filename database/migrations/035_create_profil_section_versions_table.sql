@@ -0,0 +1,57 @@
+-- Versioned sections of the organization profile (visi_misi, sejarah, ad_art).
+-- Every saved change of a section is a new version, so AD/ART amendments stay traceable.
+-- profil_organisasi keeps the markdown of the current versions in its single row.
+-- Official PDF documents are attached to AD/ART versions.
+
+CREATE TABLE IF NOT EXISTS profil_section_versions (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    section VARCHAR(20) NOT NULL COMMENT 'visi_misi, sejarah, ad_art',
+    version INT NOT NULL,
+    body MEDIUMTEXT NOT NULL COMMENT 'Markdown source',
+    html MEDIUMTEXT NULL COMMENT 'Sanitized HTML rendered from body',
+    toc JSON NULL,
+    reading_time INT NOT NULL DEFAULT 0,
+    note VARCHAR(500) NULL COMMENT 'What changed, e.g. the congress that amended the AD/ART',
+    effective_date DATE NULL,
+    created_by BIGINT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    UNIQUE KEY uk_profil_section_versions (section, version),
+    CONSTRAINT fk_profil_section_versions_created_by
+        FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS profil_attachments (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    version_id BIGINT NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    file_url VARCHAR(255) NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    file_size BIGINT NOT NULL DEFAULT 0,
+    created_by BIGINT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    INDEX idx_profil_attachments_version (version_id),
+    CONSTRAINT fk_profil_attachments_version_id
+        FOREIGN KEY (version_id) REFERENCES profil_section_versions(id) ON DELETE CASCADE,
+    CONSTRAINT fk_profil_attachments_created_by
+        FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+ALTER TABLE profil_organisasi
+    MODIFY visi_misi MEDIUMTEXT,
+    MODIFY sejarah MEDIUMTEXT,
+    MODIFY ad_art MEDIUMTEXT;
+
+-- Text stored before versioning becomes version 1, its HTML is rendered when it is first read
+INSERT INTO profil_section_versions (section, version, body, note)
+SELECT 'visi_misi', 1, visi_misi, 'Imported' FROM profil_organisasi
+WHERE COALESCE(visi_misi, '') != '' ORDER BY id DESC LIMIT 1;
+
+INSERT INTO profil_section_versions (section, version, body, note)
+SELECT 'sejarah', 1, sejarah, 'Imported' FROM profil_organisasi
+WHERE COALESCE(sejarah, '') != '' ORDER BY id DESC LIMIT 1;
+
+INSERT INTO profil_section_versions (section, version, body, note)
+SELECT 'ad_art', 1, ad_art, 'Imported' FROM profil_organisasi
+WHERE COALESCE(ad_art, '') != '' ORDER BY id DESC LIMIT 1;
@@ -0,0 +1,15 @@
+-- Moderation of member documents (STR, SIP, Serkom, ...)
+-- Members upload documents as pending, admins mark them valid or rejected with a reason.
+-- Editing a document sends it back to pending. Files are private, stored under the dokumen upload type.
+
+UPDATE documents SET status = 'pending' WHERE status IS NULL;
+
+ALTER TABLE documents
+    MODIFY status VARCHAR(50) NOT NULL DEFAULT 'pending' COMMENT 'pending, valid, rejected, expired',
+    ADD COLUMN file_name VARCHAR(255) NULL COMMENT 'Original filename of the upload' AFTER file_url,
+    ADD COLUMN reject_reason TEXT NULL AFTER status,
+    ADD COLUMN reviewed_by BIGINT NULL AFTER reject_reason,
+    ADD COLUMN reviewed_at DATETIME NULL AFTER reviewed_by,
+    ADD INDEX idx_documents_status_updated (status, updated_at),
+    ADD CONSTRAINT fk_documents_reviewed_by
+        FOREIGN KEY (reviewed_by) REFERENCES users(id) ON DELETE SET NULL;
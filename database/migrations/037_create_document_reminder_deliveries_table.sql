@@ -0,0 +1,28 @@
+-- Document expiry reminders
+-- A daily job marks documents past valid_until as expired and reminds the member and the admins
+-- of their cabang at the configured lead times (DOCUMENT_REMINDER_DAYS).
+-- Every reminder is claimed by inserting its delivery row first, the unique key makes the claim
+-- atomic across instances. valid_until is part of the key so a renewed document is reminded again.
+
+CREATE TABLE IF NOT EXISTS document_reminder_deliveries (
+    id BIGINT AUTO_INCREMENT PRIMARY KEY,
+    document_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL COMMENT 'Recipient, the member or a branch admin',
+    lead_days INT NOT NULL,
+    valid_until DATE NOT NULL,
+    recipient VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'sending' COMMENT 'sending, sent, failed',
+    attempts INT NOT NULL DEFAULT 1,
+    error TEXT NULL,
+    sent_at DATETIME NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+
+    UNIQUE KEY uk_document_reminder_deliveries (document_id, user_id, lead_days, valid_until),
+    CONSTRAINT fk_document_reminder_deliveries_document_id
+        FOREIGN KEY (document_id) REFERENCES documents(id) ON DELETE CASCADE,
+    CONSTRAINT fk_document_reminder_deliveries_user_id
+        FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE INDEX idx_documents_valid_until ON documents (valid_until, status);
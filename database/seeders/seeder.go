@@ -26,6 +26,10 @@ func RunSeeders(db *sqlx.DB) error {
 			name: "Menus",
 			run:  SeedMenus,
 		},
+		{
+			name: "Wilayah",
+			run:  SeedWilayah,
+		},
 		// Add more seeders here as needed
 		// {
 		//     name: "Berita",
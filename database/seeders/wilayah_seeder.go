@@ -0,0 +1,101 @@
+package seeders
+
+import (
+	"bytes"
+	"embed"
+	"encoding/csv"
+	"fmt"
+	"log"
+	"strings"
+
+	models "github.com/cvudumbarainformatika/backend/app/Models"
+	"github.com/jmoiron/sqlx"
+)
+
+// wilayahData holds the province and regency reference data with Kemendagri codes
+//
+//go:embed data/provinces.csv data/regencies.csv
+var wilayahData embed.FS
+
+// SeedWilayah seeds the province and regency reference data
+// Rows are upserted on every run so corrections to the data files reach existing databases
+func SeedWilayah(db *sqlx.DB) error {
+	log.Println("🌱 Seeding provinces and regencies...")
+
+	provinces, err := readWilayahCSV("data/provinces.csv")
+	if err != nil {
+		return err
+	}
+	regencies, err := readWilayahCSV("data/regencies.csv")
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Beginx()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, row := range provinces {
+		_, err := tx.Exec(`
+			INSERT INTO provinces (code, name, aliases) VALUES (?, ?, ?)
+			ON DUPLICATE KEY UPDATE name = VALUES(name), aliases = VALUES(aliases)
+		`, row[0], row[1], splitAliases(row[2]))
+		if err != nil {
+			return fmt.Errorf("failed to seed province %s: %w", row[0], err)
+		}
+	}
+
+	for _, row := range regencies {
+		regencyType := models.RegencyTypeKabupaten
+		if strings.HasPrefix(row[1], "Kota ") {
+			regencyType = models.RegencyTypeKota
+		}
+		_, err := tx.Exec(`
+			INSERT INTO regencies (code, province_code, name, type, aliases) VALUES (?, ?, ?, ?, ?)
+			ON DUPLICATE KEY UPDATE province_code = VALUES(province_code), name = VALUES(name), type = VALUES(type), aliases = VALUES(aliases)
+		`, row[0], row[0][:2], row[1], regencyType, splitAliases(row[2]))
+		if err != nil {
+			return fmt.Errorf("failed to seed regency %s: %w", row[0], err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	models.ResetWilayahIndex()
+
+	log.Printf("✓ Seeded %d provinces and %d regencies", len(provinces), len(regencies))
+	return nil
+}
+
+// readWilayahCSV reads the data rows of an embedded code,name,aliases file
+func readWilayahCSV(name string) ([][]string, error) {
+	data, err := wilayahData.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = 3
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	if len(rows) < 2 {
+		return nil, fmt.Errorf("%s has no rows", name)
+	}
+	return rows[1:], nil
+}
+
+// splitAliases splits a pipe separated alias list
+func splitAliases(value string) models.Aliases {
+	aliases := models.Aliases{}
+	for _, alias := range strings.Split(value, "|") {
+		if alias = strings.TrimSpace(alias); alias != "" {
+			aliases = append(aliases, alias)
+		}
+	}
+	return aliases
+}
@@ -3,6 +3,8 @@ package routes
 import (
 	controllers "github.com/cvudumbarainformatika/backend/app/Http/Controllers"
 	middleware "github.com/cvudumbarainformatika/backend/app/Http/Middleware"
+	mail "github.com/cvudumbarainformatika/backend/app/Mail"
+	payment "github.com/cvudumbarainformatika/backend/app/Payment"
 	"github.com/cvudumbarainformatika/backend/config"
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
@@ -11,18 +13,51 @@ import (
 
 // SetupRoutes configures all application routes
 func SetupRoutes(router *gin.Engine, db *sqlx.DB, redis *redis.Client, cfg *config.Config) {
+	// Outgoing email
+	mailer := mail.NewMailer(cfg.Mail)
+
+	// Payment gateway of paid agenda registrations
+	gateway := payment.NewGateway(db, cfg, mailer)
+
 	// Initialize controllers
 	authController := controllers.NewAuthController(db, cfg)
 	avatarController := controllers.NewAvatarController()
 	fileController := controllers.NewFileController()
 	userController := controllers.NewUserController(db)
 	beritaController := controllers.NewBeritaController(db)
-	agendaController := controllers.NewAgendaController(db)
+	agendaController := controllers.NewAgendaController(db, cfg, mailer)
 	uploadController := controllers.NewUploadController()
 	homepageController := controllers.NewHomepageController(db)
 	menuController := controllers.NewMenuController(db)
 	contentController := controllers.NewContentController(db)
 	contentController.InitTable()
+	sitemapController := controllers.NewSitemapController(db, redis, cfg)
+	redirectController := controllers.NewRedirectController(db, cfg)
+	trashController := controllers.NewTrashController(db, cfg)
+	registrationController := controllers.NewAgendaRegistrationController(db, cfg, mailer, gateway)
+	calendarController := controllers.NewCalendarController(db, cfg)
+	attendanceController := controllers.NewAttendanceController(db, cfg)
+	skpController := controllers.NewSKPController(db, cfg)
+	seriesController := controllers.NewAgendaSeriesController(db, cfg, mailer)
+	paymentController := controllers.NewPaymentController(db, cfg, mailer, gateway)
+	rundownController := controllers.NewAgendaRundownController(db, cfg)
+	direktoriController := controllers.NewDirektoriController(db)
+	direktoriImportController := controllers.NewDirektoriImportController(db)
+	direktoriSubmissionController := controllers.NewDirektoriSubmissionController(db)
+	wilayahController := controllers.NewWilayahController(db)
+	pengurusController := controllers.NewPengurusController(db)
+	profilController := controllers.NewProfilController(db)
+	documentController := controllers.NewDocumentController(db, cfg)
+
+	// ==============================
+	// SEO Routes (Public)
+	// ==============================
+	router.GET("/sitemap.xml", sitemapController.Sitemap)
+	router.GET("/sitemaps/:page", sitemapController.SitemapPage)
+	router.GET("/robots.txt", sitemapController.Robots)
+
+	// Clears the cached sitemap after content changes
+	sitemapInvalidation := middleware.InvalidateCacheMiddleware(redis, controllers.SitemapCachePrefix)
 
 	// API v1 routes
 	v1 := router.Group("/api/v1")
@@ -75,7 +110,71 @@ func SetupRoutes(router *gin.Engine, db *sqlx.DB, redis *redis.Client, cfg *conf
 		{
 			agenda.GET("", agendaController.GetList)
 			agenda.GET("/types", agendaController.GetTypes)
+			agenda.GET("/calendar", calendarController.Range)
+			agenda.GET("/calendar/month", calendarController.Month)
 			agenda.GET("/:slug", agendaController.GetBySlug)
+			agenda.GET("/:slug/rundown.ics", rundownController.ExportRundownICS)
+		}
+
+		// ==============================
+		// Direktori Routes (Public GET)
+		// ==============================
+		direktori := v1.Group("/direktori")
+		{
+			direktori.GET("", direktoriController.GetList)
+			direktori.GET("/nearby", direktoriController.Nearby)
+			direktori.GET("/:id", direktoriController.GetByID)
+		}
+		v1.GET("/direktori.geojson", direktoriController.GeoJSON)
+
+		// ==============================
+		// Province and City Reference Data (Public)
+		// ==============================
+		wilayah := v1.Group("/wilayah")
+		{
+			wilayah.GET("/provinces", wilayahController.GetProvinces)
+			wilayah.GET("/provinces/:code/regencies", wilayahController.GetProvinceRegencies)
+			wilayah.GET("/regencies", wilayahController.GetRegencies)
+			wilayah.GET("/resolve", wilayahController.Resolve)
+		}
+
+		// ==============================
+		// Pengurus Routes (Public GET)
+		// ==============================
+		pengurus := v1.Group("/pengurus")
+		{
+			pengurus.GET("", pengurusController.GetList)
+			pengurus.GET("/org-chart", pengurusController.OrgChart)
+			pengurus.GET("/:id", pengurusController.GetByID)
+		}
+
+		// ==============================
+		// Profil Organisasi Routes (Public GET)
+		// ==============================
+		profil := v1.Group("/profil")
+		{
+			profil.GET("", profilController.Get)
+			profil.GET("/:section/versions", profilController.GetVersions)
+			profil.GET("/:section/versions/:version", profilController.GetVersion)
+		}
+
+		// ==============================
+		// Calendar Feeds (Public, private feeds use a secret token)
+		// ==============================
+		v1.GET("/calendar.ics", calendarController.Feed)
+		v1.GET("/calendar/:token", calendarController.PrivateFeed)
+
+		// ==============================
+		// Certificate Verification (Public)
+		// ==============================
+		v1.GET("/certificates/verify/:code", skpController.Verify)
+
+		// ==============================
+		// Payment Notifications (Public, verified by the provider signature)
+		// ==============================
+		v1.POST("/payments/notifications", paymentController.Webhook)
+		if cfg.Payment.Provider == "fake" {
+			v1.POST("/payments/fake/:number", paymentController.Simulate)
 		}
 
 		// ==============================
@@ -92,6 +191,11 @@ func SetupRoutes(router *gin.Engine, db *sqlx.DB, redis *redis.Client, cfg *conf
 		// ==============================
 		v1.GET("/dynamic-content/*slug", contentController.GetContentBySlug)
 
+		// ==============================
+		// Redirect Routes (Public GET)
+		// ==============================
+		v1.GET("/redirects/resolve", redirectController.Resolve)
+
 		// ==============================
 		// Protected Routes (JWT Required)
 		// ==============================
@@ -125,8 +229,10 @@ func SetupRoutes(router *gin.Engine, db *sqlx.DB, redis *redis.Client, cfg *conf
 
 			// Berita Management routes (Admin only)
 			beritaAdmin := protected.Group("/berita")
+			beritaAdmin.Use(sitemapInvalidation)
 			{
 				beritaAdmin.POST("", beritaController.Create)
+				beritaAdmin.POST("/bulk", beritaController.Bulk)
 				beritaAdmin.PUT("/:id", beritaController.Update)
 				beritaAdmin.PATCH("/:id", beritaController.Patch)
 				beritaAdmin.DELETE("/:id", beritaController.Delete)
@@ -134,15 +240,119 @@ func SetupRoutes(router *gin.Engine, db *sqlx.DB, redis *redis.Client, cfg *conf
 
 			// Agenda Management routes (Admin only)
 			agendaAdmin := protected.Group("/agenda")
+			agendaAdmin.Use(sitemapInvalidation)
 			{
 				agendaAdmin.POST("", agendaController.Create)
+				agendaAdmin.POST("/bulk", agendaController.Bulk)
 				agendaAdmin.PUT("/:id", agendaController.Update)
 				agendaAdmin.PATCH("/:id", agendaController.Patch)
 				agendaAdmin.DELETE("/:id", agendaController.Delete)
+				agendaAdmin.POST("/:id/cancel", seriesController.CancelOccurrence)
+				agendaAdmin.DELETE("/:id/cancel", seriesController.RestoreOccurrence)
+				agendaAdmin.POST("/series", seriesController.Create)
+				agendaAdmin.GET("/series/:id", seriesController.GetByID)
+				agendaAdmin.PUT("/series/:id", seriesController.Update)
+				agendaAdmin.DELETE("/series/:id", seriesController.Cancel)
+			}
+
+			// Direktori Management routes (Admin only)
+			direktoriAdmin := protected.Group("/direktori")
+			{
+				direktoriAdmin.POST("", direktoriController.Create)
+				direktoriAdmin.PUT("/:id", direktoriController.Update)
+				direktoriAdmin.DELETE("/:id", direktoriController.Delete)
+				direktoriAdmin.GET("/export", direktoriImportController.Export)
+				direktoriAdmin.POST("/import/preview", direktoriImportController.Preview)
+				direktoriAdmin.POST("/import", direktoriImportController.Import)
+				direktoriAdmin.GET("/import/:id", direktoriImportController.GetImport)
+				direktoriAdmin.POST("/:id/verify", direktoriController.Verify)
+			}
+
+			// Direktori Submission routes (Members propose entries and corrections, moderation Admin only)
+			direktoriSubmissions := protected.Group("/direktori")
+			{
+				direktoriSubmissions.POST("/submissions", direktoriSubmissionController.Propose)
+				direktoriSubmissions.POST("/:id/submissions", direktoriSubmissionController.Correct)
+				direktoriSubmissions.GET("/submissions/mine", direktoriSubmissionController.GetMine)
+				direktoriSubmissions.GET("/submissions", direktoriSubmissionController.GetQueue)
+				direktoriSubmissions.GET("/submissions/:id", direktoriSubmissionController.GetByID)
+				direktoriSubmissions.POST("/submissions/:id/approve", direktoriSubmissionController.Approve)
+				direktoriSubmissions.POST("/submissions/:id/reject", direktoriSubmissionController.Reject)
+			}
+
+			// Pengurus Management routes (Admin only)
+			pengurusAdmin := protected.Group("/pengurus")
+			{
+				pengurusAdmin.POST("", pengurusController.Create)
+				pengurusAdmin.PUT("/:id", pengurusController.Update)
+				pengurusAdmin.DELETE("/:id", pengurusController.Delete)
+				pengurusAdmin.POST("/:id/terms", pengurusController.CreateTerm)
+				pengurusAdmin.PUT("/:id/terms/:termId", pengurusController.UpdateTerm)
+				pengurusAdmin.DELETE("/:id/terms/:termId", pengurusController.DeleteTerm)
+			}
+
+			// Profil Organisasi Management routes (Admin pusat only)
+			profilAdmin := protected.Group("/profil")
+			{
+				profilAdmin.POST("", profilController.Update)
+				profilAdmin.POST("/:section/versions/:version/attachments", profilController.CreateAttachment)
+				profilAdmin.DELETE("/:section/versions/:version/attachments/:attachmentId", profilController.DeleteAttachment)
+			}
+
+			// Agenda Registration routes (Members, listing, confirmation, check-in and rundown Admin only)
+			// GET uses :slug because the public GET /agenda/:slug route owns that wildcard,
+			// those handlers read an all-digit value as the agenda ID and anything else as its slug
+			agendaRegistrations := protected.Group("/agenda")
+			{
+				agendaRegistrations.POST("/:id/registrations", registrationController.Register)
+				agendaRegistrations.DELETE("/:id/registrations", registrationController.Cancel)
+				agendaRegistrations.GET("/:slug/registrations", registrationController.GetList)
+				agendaRegistrations.PATCH("/:id/registrations/:regId", registrationController.UpdateStatus)
+				agendaRegistrations.POST("/:id/check-in", attendanceController.CheckIn)
+				agendaRegistrations.GET("/:slug/attendance", attendanceController.GetReport)
+				agendaRegistrations.GET("/:slug/invoices", paymentController.GetAgendaInvoices)
+				agendaRegistrations.GET("/:slug/reminders", registrationController.GetReminders)
+				agendaRegistrations.POST("/:id/speakers", rundownController.CreateSpeaker)
+				agendaRegistrations.PUT("/:id/speakers/:speakerId", rundownController.UpdateSpeaker)
+				agendaRegistrations.DELETE("/:id/speakers/:speakerId", rundownController.DeleteSpeaker)
+				agendaRegistrations.POST("/:id/sessions", rundownController.CreateSession)
+				agendaRegistrations.PUT("/:id/sessions/:sessionId", rundownController.UpdateSession)
+				agendaRegistrations.DELETE("/:id/sessions/:sessionId", rundownController.DeleteSession)
+			}
+
+			// Member routes
+			me := protected.Group("/me")
+			{
+				me.GET("/registrations", registrationController.GetMine)
+				me.GET("/registrations/:id/ticket", registrationController.GetTicket)
+				me.POST("/registrations/:id/invoice", paymentController.Pay)
+				me.GET("/invoices", paymentController.GetMine)
+				me.GET("/calendar", calendarController.GetMyFeed)
+				me.POST("/calendar/reset", calendarController.ResetMyFeed)
+				me.GET("/skp", skpController.GetMine)
+				me.GET("/skp/:id/certificate", skpController.DownloadCertificate)
+				me.GET("/documents", documentController.GetMine)
+				me.POST("/documents", documentController.Create)
+				me.GET("/documents/:id", documentController.GetMineByID)
+				me.PUT("/documents/:id", documentController.Update)
+				me.DELETE("/documents/:id", documentController.Delete)
+				me.GET("/documents/:id/file", documentController.DownloadMine)
+			}
+
+			// Document Moderation routes (Admin only, cabang admins see their own members)
+			documentAdmin := protected.Group("/documents")
+			{
+				documentAdmin.GET("", documentController.GetQueue)
+				documentAdmin.GET("/expiry-report", documentController.ExpiryReport)
+				documentAdmin.GET("/:id", documentController.GetByID)
+				documentAdmin.GET("/:id/file", documentController.Download)
+				documentAdmin.POST("/:id/approve", documentController.Approve)
+				documentAdmin.POST("/:id/reject", documentController.Reject)
 			}
 
 			// Menu Management routes (Admin only)
 			menuAdmin := protected.Group("/menus")
+			menuAdmin.Use(sitemapInvalidation)
 			{
 				menuAdmin.POST("", menuController.SaveMenus)
 				menuAdmin.DELETE("/:id", menuController.DeleteMenu)
@@ -150,9 +360,29 @@ func SetupRoutes(router *gin.Engine, db *sqlx.DB, redis *redis.Client, cfg *conf
 
 			// Content Management routes (Admin only)
 			contentAdmin := protected.Group("/dynamic-content")
+			contentAdmin.Use(sitemapInvalidation)
 			{
 				contentAdmin.POST("", contentController.SaveContent)
 			}
+
+			// Trash Bin routes (Admin only)
+			trash := protected.Group("/trash")
+			trash.Use(sitemapInvalidation)
+			{
+				trash.GET("", trashController.GetList)
+				trash.POST("/:type/:id/restore", trashController.Restore)
+				trash.DELETE("/:type/:id", trashController.Delete)
+			}
+
+			// Redirect Management routes (Admin only)
+			redirectAdmin := protected.Group("/redirects")
+			{
+				redirectAdmin.GET("", redirectController.GetList)
+				redirectAdmin.GET("/:id", redirectController.GetByID)
+				redirectAdmin.POST("", redirectController.Create)
+				redirectAdmin.PUT("/:id", redirectController.Update)
+				redirectAdmin.DELETE("/:id", redirectController.Delete)
+			}
 		}
 	}
 
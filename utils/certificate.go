@@ -0,0 +1,140 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// indonesianMonths are month names used on certificates
+var indonesianMonths = [...]string{
+	"Januari", "Februari", "Maret", "April", "Mei", "Juni",
+	"Juli", "Agustus", "September", "Oktober", "November", "Desember",
+}
+
+// CertificateData is the content of an attendance certificate
+type CertificateData struct {
+	Code       string
+	Issuer     string // Organization name printed in the header
+	MemberName string
+	EventTitle string
+	EventDate  time.Time
+	Points     float64
+	VerifyURL  string // Encoded in the verification QR code
+	Timezone   *time.Location
+}
+
+// FormatIndonesianDate formats a date as "2 Januari 2006"
+func FormatIndonesianDate(t time.Time) string {
+	return fmt.Sprintf("%d %s %d", t.Day(), indonesianMonths[t.Month()-1], t.Year())
+}
+
+// FormatPoints formats credit points without trailing zeros (2, 2.5)
+func FormatPoints(points float64) string {
+	return strconv.FormatFloat(points, 'f', -1, 64)
+}
+
+// RenderCertificatePDF renders an A4 landscape attendance certificate with a verification QR code
+func RenderCertificatePDF(data CertificateData) ([]byte, error) {
+	loc := data.Timezone
+	if loc == nil {
+		loc = LoadTimezone(DefaultTimezone)
+	}
+
+	pdf := fpdf.New("L", "mm", "A4", "")
+	pdf.SetTitle("Sertifikat "+data.Code, true)
+	pdf.SetAuthor(data.Issuer, true)
+	pdf.SetMargins(20, 20, 20)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.AddPage()
+
+	// Core fonts only support cp1252
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pageWidth, pageHeight := pdf.GetPageSize()
+	contentWidth := pageWidth - 40
+
+	// Border
+	pdf.SetDrawColor(20, 80, 140)
+	pdf.SetLineWidth(1.5)
+	pdf.Rect(10, 10, pageWidth-20, pageHeight-20, "D")
+	pdf.SetLineWidth(0.4)
+	pdf.Rect(14, 14, pageWidth-28, pageHeight-28, "D")
+
+	pdf.SetTextColor(20, 80, 140)
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.SetXY(20, 28)
+	pdf.CellFormat(contentWidth, 8, tr(strings.ToUpper(data.Issuer)), "", 1, "C", false, 0, "")
+
+	pdf.SetFont("Helvetica", "B", 34)
+	pdf.SetXY(20, 42)
+	pdf.CellFormat(contentWidth, 16, "SERTIFIKAT", "", 1, "C", false, 0, "")
+
+	pdf.SetTextColor(60, 60, 60)
+	pdf.SetFont("Helvetica", "", 11)
+	pdf.SetXY(20, 60)
+	pdf.CellFormat(contentWidth, 6, tr("Nomor: "+data.Code), "", 1, "C", false, 0, "")
+
+	pdf.SetFont("Helvetica", "", 14)
+	pdf.SetXY(20, 76)
+	pdf.CellFormat(contentWidth, 8, "Diberikan kepada", "", 1, "C", false, 0, "")
+
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFont("Helvetica", "B", 26)
+	pdf.SetXY(20, 88)
+	pdf.CellFormat(contentWidth, 14, tr(data.MemberName), "", 1, "C", false, 0, "")
+
+	pdf.SetTextColor(60, 60, 60)
+	pdf.SetFont("Helvetica", "", 14)
+	pdf.SetXY(20, 108)
+	pdf.CellFormat(contentWidth, 8, "atas partisipasinya sebagai peserta dalam", "", 1, "C", false, 0, "")
+
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.SetXY(40, 120)
+	pdf.MultiCell(contentWidth-40, 9, tr(data.EventTitle), "", "C", false)
+
+	pdf.SetTextColor(60, 60, 60)
+	pdf.SetFont("Helvetica", "", 13)
+	pdf.SetX(20)
+	pdf.CellFormat(contentWidth, 8, tr(FormatIndonesianDate(data.EventDate.In(loc))), "", 1, "C", false, 0, "")
+
+	if data.Points > 0 {
+		pdf.SetTextColor(20, 80, 140)
+		pdf.SetFont("Helvetica", "B", 15)
+		pdf.SetX(20)
+		pdf.CellFormat(contentWidth, 10, "Nilai SKP: "+FormatPoints(data.Points), "", 1, "C", false, 0, "")
+	}
+
+	// Verification QR code in the bottom right corner
+	if data.VerifyURL != "" {
+		png, err := QRCodePNG(data.VerifyURL, 256)
+		if err != nil {
+			return nil, err
+		}
+		pdf.RegisterImageOptionsReader("verify-qr", fpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(png))
+
+		qrSize := 34.0
+		qrX := pageWidth - 20 - qrSize
+		qrY := pageHeight - 24 - qrSize
+		pdf.ImageOptions("verify-qr", qrX, qrY, qrSize, qrSize, false, fpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+
+		pdf.SetFont("Helvetica", "", 7)
+		pdf.SetTextColor(90, 90, 90)
+		pdf.SetXY(20, pageHeight-27)
+		pdf.CellFormat(contentWidth-qrSize-4, 4, tr("Verifikasi keaslian sertifikat: "+data.VerifyURL), "", 0, "R", false, 0, "")
+	}
+
+	if err := pdf.Error(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+)
+
+// EscapeCSVCell guards a CSV export cell against formula injection
+// Spreadsheet apps evaluate cells starting with =, +, - or @, so those get a leading quote
+// Plain numbers such as negative coordinates or +62 phone numbers are left as they are
+func EscapeCSVCell(value string) string {
+	if value == "" || !strings.ContainsRune("=+-@", rune(value[0])) {
+		return value
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+	return "'" + value
+}
+
+// EscapeCSVRow escapes every cell of a CSV export row in place and returns it
+func EscapeCSVRow(row []string) []string {
+	for i := range row {
+		row[i] = EscapeCSVCell(row[i])
+	}
+	return row
+}
+
+// UnescapeCSVCell removes the quote EscapeCSVCell adds, so an exported file can be imported again
+func UnescapeCSVCell(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune("=+-@", rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
@@ -0,0 +1,30 @@
+package utils
+
+import "testing"
+
+func TestEscapeCSVCell(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", ""},
+		{"RSUD Kota", "RSUD Kota"},
+		{"=HYPERLINK(\"http://evil\")", "'=HYPERLINK(\"http://evil\")"},
+		{"+cmd|' /C calc'!A0", "'+cmd|' /C calc'!A0"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1:A2)", "'@SUM(A1:A2)"},
+		{"-7.2575", "-7.2575"},
+		{"+6281234567", "+6281234567"},
+		{"a=b", "a=b"},
+	}
+
+	for _, tt := range tests {
+		got := EscapeCSVCell(tt.value)
+		if got != tt.want {
+			t.Errorf("EscapeCSVCell(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+		if back := UnescapeCSVCell(got); back != tt.value {
+			t.Errorf("UnescapeCSVCell(%q) = %q, want %q", got, back, tt.value)
+		}
+	}
+}
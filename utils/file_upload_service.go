@@ -34,6 +34,7 @@ type FileUploadConfig struct {
 	CreateThumb  bool           // Whether to create thumbnail
 	ThumbWidth   int            // Thumbnail width
 	ThumbHeight  int            // Thumbnail height
+	Private      bool           // Never served by the public file route, only through endpoints that check access
 }
 
 // FileUploadConfigs contains all file type configurations (without StoragePath - lazy loaded)
@@ -78,6 +79,7 @@ var FileUploadConfigs = map[FileUploadType]FileUploadConfig{
 		AllowedTypes: []string{"application/pdf", "application/msword", "application/vnd.openxmlformats-officedocument.wordprocessingml.document"},
 		AllowedExts:  []string{".pdf", ".doc", ".docx"},
 		CreateThumb:  false,
+		Private:      true, // Member documents (STR, SIP, ...) hold personal data
 	},
 	FileTypeGaleri: {
 		FileType:     FileTypeGaleri,
@@ -276,6 +278,63 @@ func (s *FileUploadService) Delete(filename string) error {
 	return nil
 }
 
+// DeleteFileByURL removes an uploaded file given its public URL (/api/v1/files/:file_type/:filename)
+// URLs that do not point to a managed upload (e.g. external images) are ignored
+func DeleteFileByURL(fileURL string) error {
+	service, filename := serviceForURL(fileURL)
+	if service == nil {
+		return nil
+	}
+	return service.Delete(filename)
+}
+
+// FilePathByURL returns the storage path of an uploaded file given its URL, for serving private files
+func FilePathByURL(fileURL string) (string, error) {
+	service, filename := serviceForURL(fileURL)
+	if service == nil {
+		return "", fmt.Errorf("not a managed upload: %s", fileURL)
+	}
+	return service.GetFilePath(filename)
+}
+
+// serviceForURL resolves the upload service and filename of a managed upload URL
+// Returns a nil service for URLs that do not point to a managed upload
+func serviceForURL(fileURL string) (*FileUploadService, string) {
+	idx := strings.Index(fileURL, "/api/v1/files/")
+	if idx < 0 {
+		return nil, ""
+	}
+
+	parts := strings.SplitN(fileURL[idx+len("/api/v1/files/"):], "/", 2)
+	if len(parts) != 2 {
+		return nil, ""
+	}
+
+	config, exists := FileUploadConfigs[FileUploadType(parts[0])]
+	if !exists {
+		return nil, ""
+	}
+	config.StoragePath = GetStoragePathForConfig(config)
+
+	// Strip query string and any directory components
+	filename := filepath.Base(strings.SplitN(parts[1], "?", 2)[0])
+
+	return &FileUploadService{config: config, fileType: config.FileType}, filename
+}
+
+// UploadFileOfType validates and stores a file with the configuration of a file type
+// The storage path is resolved per call, so the shared configs are left untouched
+func UploadFileOfType(file *multipart.FileHeader, fileType FileUploadType, identifier string) (*UploadedFileInfo, error) {
+	config, exists := FileUploadConfigs[fileType]
+	if !exists {
+		return nil, fmt.Errorf("unknown file type: %s", fileType)
+	}
+	config.StoragePath = GetStoragePathForConfig(config)
+
+	service := &FileUploadService{config: config, fileType: fileType}
+	return service.Upload(file, identifier)
+}
+
 // GetFilePath returns the full storage path for a filename
 func (s *FileUploadService) GetFilePath(filename string) (string, error) {
 	filePath := filepath.Join(s.config.StoragePath, filename)
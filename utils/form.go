@@ -0,0 +1,330 @@
+package utils
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Form field types of custom registration forms
+const (
+	FormFieldText     = "text"
+	FormFieldTextarea = "textarea"
+	FormFieldNumber   = "number"
+	FormFieldEmail    = "email"
+	FormFieldPhone    = "phone"
+	FormFieldDate     = "date"     // YYYY-MM-DD
+	FormFieldSelect   = "select"   // One of Options
+	FormFieldRadio    = "radio"    // One of Options
+	FormFieldCheckbox = "checkbox" // Any number of Options
+	FormFieldBoolean  = "boolean"
+)
+
+// maxFormFields limits the size of a form schema
+const maxFormFields = 50
+
+// maxFormTextLength limits text answers without an explicit max_length
+const maxFormTextLength = 5000
+
+var (
+	formFieldNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_]{0,63}$`)
+	formPhoneRegex     = regexp.MustCompile(`^\+?[0-9][0-9 \-]{5,19}$`)
+)
+
+// FormField describes one field of a custom form
+type FormField struct {
+	Name        string   `json:"name"` // Answer key, snake_case
+	Label       string   `json:"label"`
+	Type        string   `json:"type"`
+	Required    bool     `json:"required"`
+	Options     []string `json:"options,omitempty"`    // select, radio and checkbox
+	MinLength   *int     `json:"min_length,omitempty"` // text types
+	MaxLength   *int     `json:"max_length,omitempty"`
+	Min         *float64 `json:"min,omitempty"` // number
+	Max         *float64 `json:"max,omitempty"`
+	Pattern     string   `json:"pattern,omitempty"` // Regular expression for text types
+	Placeholder string   `json:"placeholder,omitempty"`
+	Help        string   `json:"help,omitempty"`
+}
+
+// isTextType reports whether answers of a field type are free text
+func (f FormField) isTextType() bool {
+	switch f.Type {
+	case FormFieldText, FormFieldTextarea, FormFieldEmail, FormFieldPhone:
+		return true
+	}
+	return false
+}
+
+// hasOptions reports whether answers of a field type are picked from Options
+func (f FormField) hasOptions() bool {
+	return f.Type == FormFieldSelect || f.Type == FormFieldRadio || f.Type == FormFieldCheckbox
+}
+
+// ValidateFormSchema checks a form schema, returning errors keyed by field position (nil when valid)
+func ValidateFormSchema(fields []FormField) map[string]string {
+	errs := map[string]string{}
+	if len(fields) > maxFormFields {
+		errs["fields"] = fmt.Sprintf("a form can have at most %d fields", maxFormFields)
+		return errs
+	}
+
+	seen := map[string]bool{}
+	for i, field := range fields {
+		key := fmt.Sprintf("fields[%d]", i)
+
+		switch {
+		case !formFieldNameRegex.MatchString(field.Name):
+			errs[key+".name"] = "name must be snake_case, start with a letter and be at most 64 characters"
+		case seen[field.Name]:
+			errs[key+".name"] = "duplicate field name " + field.Name
+		}
+		seen[field.Name] = true
+
+		if strings.TrimSpace(field.Label) == "" {
+			errs[key+".label"] = "label is required"
+		}
+
+		switch field.Type {
+		case FormFieldText, FormFieldTextarea, FormFieldNumber, FormFieldEmail, FormFieldPhone,
+			FormFieldDate, FormFieldSelect, FormFieldRadio, FormFieldCheckbox, FormFieldBoolean:
+		default:
+			errs[key+".type"] = "unknown field type " + field.Type
+			continue
+		}
+
+		if field.hasOptions() {
+			if len(field.Options) == 0 {
+				errs[key+".options"] = "options are required for " + field.Type + " fields"
+			}
+			options := map[string]bool{}
+			for _, option := range field.Options {
+				if strings.TrimSpace(option) == "" || options[option] {
+					errs[key+".options"] = "options must be unique and not empty"
+					break
+				}
+				options[option] = true
+			}
+		} else if len(field.Options) > 0 {
+			errs[key+".options"] = "options are only allowed for select, radio and checkbox fields"
+		}
+
+		if field.MinLength != nil && field.MaxLength != nil && *field.MinLength > *field.MaxLength {
+			errs[key+".min_length"] = "min_length cannot be greater than max_length"
+		}
+		if field.Min != nil && field.Max != nil && *field.Min > *field.Max {
+			errs[key+".min"] = "min cannot be greater than max"
+		}
+		if field.Pattern != "" {
+			if !field.isTextType() {
+				errs[key+".pattern"] = "pattern is only allowed for text fields"
+			} else if _, err := regexp.Compile(field.Pattern); err != nil {
+				errs[key+".pattern"] = "invalid pattern: " + err.Error()
+			}
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// ValidateFormAnswers validates answers against a form schema
+// Returns the normalized answers of known fields, and errors keyed by field name (nil when valid)
+func ValidateFormAnswers(fields []FormField, answers map[string]interface{}) (map[string]interface{}, map[string]string) {
+	normalized := map[string]interface{}{}
+	errs := map[string]string{}
+
+	for _, field := range fields {
+		value, present := answers[field.Name]
+		if !present || isEmptyAnswer(value) {
+			if field.Required {
+				errs[field.Name] = field.Label + " is required"
+			}
+			continue
+		}
+
+		answer, err := normalizeFormAnswer(field, value)
+		if err != "" {
+			errs[field.Name] = err
+			continue
+		}
+		normalized[field.Name] = answer
+	}
+
+	if len(errs) == 0 {
+		return normalized, nil
+	}
+	return normalized, errs
+}
+
+// normalizeFormAnswer validates a single non-empty answer, returning the value to store or an error message
+func normalizeFormAnswer(field FormField, value interface{}) (interface{}, string) {
+	switch field.Type {
+	case FormFieldNumber:
+		var number float64
+		switch v := value.(type) {
+		case float64:
+			number = v
+		case string:
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, "must be a number"
+			}
+			number = parsed
+		default:
+			return nil, "must be a number"
+		}
+		if field.Min != nil && number < *field.Min {
+			return nil, "must be at least " + FormatPoints(*field.Min)
+		}
+		if field.Max != nil && number > *field.Max {
+			return nil, "must be at most " + FormatPoints(*field.Max)
+		}
+		return number, ""
+
+	case FormFieldBoolean:
+		switch v := value.(type) {
+		case bool:
+			if field.Required && !v {
+				return nil, field.Label + " must be accepted"
+			}
+			return v, ""
+		case string:
+			parsed, err := strconv.ParseBool(v)
+			if err != nil {
+				return nil, "must be true or false"
+			}
+			if field.Required && !parsed {
+				return nil, field.Label + " must be accepted"
+			}
+			return parsed, ""
+		}
+		return nil, "must be true or false"
+
+	case FormFieldDate:
+		text, ok := value.(string)
+		if !ok {
+			return nil, "must be a date (YYYY-MM-DD)"
+		}
+		if _, err := time.Parse("2006-01-02", strings.TrimSpace(text)); err != nil {
+			return nil, "must be a date (YYYY-MM-DD)"
+		}
+		return strings.TrimSpace(text), ""
+
+	case FormFieldSelect, FormFieldRadio:
+		text, ok := value.(string)
+		if !ok || !containsString(field.Options, text) {
+			return nil, "must be one of: " + strings.Join(field.Options, ", ")
+		}
+		return text, ""
+
+	case FormFieldCheckbox:
+		items, ok := value.([]interface{})
+		if !ok {
+			return nil, "must be a list of options"
+		}
+		selected := []string{}
+		for _, item := range items {
+			text, ok := item.(string)
+			if !ok || !containsString(field.Options, text) {
+				return nil, "must only contain: " + strings.Join(field.Options, ", ")
+			}
+			if !containsString(selected, text) {
+				selected = append(selected, text)
+			}
+		}
+		return selected, ""
+	}
+
+	// Text types
+	text, ok := value.(string)
+	if !ok {
+		return nil, "must be text"
+	}
+	text = strings.TrimSpace(text)
+	length := len([]rune(text))
+
+	maxLength := maxFormTextLength
+	if field.MaxLength != nil && *field.MaxLength < maxLength {
+		maxLength = *field.MaxLength
+	}
+	if field.MinLength != nil && length < *field.MinLength {
+		return nil, fmt.Sprintf("must be at least %d characters", *field.MinLength)
+	}
+	if length > maxLength {
+		return nil, fmt.Sprintf("must be at most %d characters", maxLength)
+	}
+
+	switch field.Type {
+	case FormFieldEmail:
+		if address, err := mail.ParseAddress(text); err != nil || address.Address != text {
+			return nil, "must be a valid email address"
+		}
+	case FormFieldPhone:
+		if !formPhoneRegex.MatchString(text) {
+			return nil, "must be a valid phone number"
+		}
+	}
+
+	if field.Pattern != "" {
+		if pattern, err := regexp.Compile(field.Pattern); err != nil || !pattern.MatchString(text) {
+			return nil, "has an invalid format"
+		}
+	}
+
+	return text, ""
+}
+
+// FormatFormAnswer renders a stored answer as plain text (for exports)
+func FormatFormAnswer(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		if v {
+			return "Ya"
+		}
+		return "Tidak"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = FormatFormAnswer(item)
+		}
+		return strings.Join(parts, "; ")
+	case []string:
+		return strings.Join(v, "; ")
+	}
+	return fmt.Sprint(value)
+}
+
+// isEmptyAnswer reports whether an answer counts as not given
+func isEmptyAnswer(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return strings.TrimSpace(v) == ""
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// containsString reports whether a slice contains a string
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
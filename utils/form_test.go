@@ -0,0 +1,217 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func intPtr(v int) *int { return &v }
+
+func floatPtr(v float64) *float64 { return &v }
+
+func TestValidateFormSchema(t *testing.T) {
+	tests := []struct {
+		name    string
+		fields  []FormField
+		wantErr []string // Expected error keys, nil when the schema is valid
+	}{
+		{
+			name:   "empty form",
+			fields: nil,
+		},
+		{
+			name: "valid fields",
+			fields: []FormField{
+				{Name: "institution", Label: "Institution", Type: FormFieldText, MaxLength: intPtr(100)},
+				{Name: "shirt_size", Label: "Shirt size", Type: FormFieldSelect, Options: []string{"S", "M", "L"}},
+			},
+		},
+		{
+			name: "invalid and duplicate names",
+			fields: []FormField{
+				{Name: "Institution", Label: "Institution", Type: FormFieldText},
+				{Name: "phone", Label: "Phone", Type: FormFieldPhone},
+				{Name: "phone", Label: "Phone 2", Type: FormFieldPhone},
+			},
+			wantErr: []string{"fields[0].name", "fields[2].name"},
+		},
+		{
+			name:    "missing label and unknown type",
+			fields:  []FormField{{Name: "notes", Label: " ", Type: "file"}},
+			wantErr: []string{"fields[0].label", "fields[0].type"},
+		},
+		{
+			name: "options",
+			fields: []FormField{
+				{Name: "size", Label: "Size", Type: FormFieldRadio},
+				{Name: "days", Label: "Days", Type: FormFieldCheckbox, Options: []string{"Mon", "Mon"}},
+				{Name: "notes", Label: "Notes", Type: FormFieldText, Options: []string{"a"}},
+			},
+			wantErr: []string{"fields[0].options", "fields[1].options", "fields[2].options"},
+		},
+		{
+			name: "inverted bounds and bad pattern",
+			fields: []FormField{
+				{Name: "code", Label: "Code", Type: FormFieldText, MinLength: intPtr(5), MaxLength: intPtr(2), Pattern: "("},
+				{Name: "age", Label: "Age", Type: FormFieldNumber, Min: floatPtr(10), Max: floatPtr(1), Pattern: "[0-9]+"},
+			},
+			wantErr: []string{"fields[0].min_length", "fields[0].pattern", "fields[1].min", "fields[1].pattern"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := ValidateFormSchema(tt.fields)
+			if tt.wantErr == nil {
+				if errs != nil {
+					t.Fatalf("expected no errors, got %v", errs)
+				}
+				return
+			}
+			if len(errs) != len(tt.wantErr) {
+				t.Fatalf("expected errors %v, got %v", tt.wantErr, errs)
+			}
+			for _, key := range tt.wantErr {
+				if _, ok := errs[key]; !ok {
+					t.Errorf("expected error for %s, got %v", key, errs)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateFormAnswers(t *testing.T) {
+	fields := []FormField{
+		{Name: "institution", Label: "Institution", Type: FormFieldText, Required: true, MinLength: intPtr(3), MaxLength: intPtr(20)},
+		{Name: "email", Label: "Email", Type: FormFieldEmail},
+		{Name: "phone", Label: "Phone", Type: FormFieldPhone},
+		{Name: "str_number", Label: "STR number", Type: FormFieldText, Pattern: `^[0-9]{4}$`},
+		{Name: "age", Label: "Age", Type: FormFieldNumber, Min: floatPtr(17), Max: floatPtr(99)},
+		{Name: "birth_date", Label: "Birth date", Type: FormFieldDate},
+		{Name: "size", Label: "Size", Type: FormFieldSelect, Options: []string{"S", "M", "L"}},
+		{Name: "days", Label: "Days", Type: FormFieldCheckbox, Required: true, Options: []string{"Sat", "Sun"}},
+		{Name: "consent", Label: "Consent", Type: FormFieldBoolean, Required: true},
+	}
+	valid := func() map[string]interface{} {
+		return map[string]interface{}{
+			"institution": "RSUD Kota",
+			"days":        []interface{}{"Sat"},
+			"consent":     true,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		answers map[string]interface{}
+		wantErr []string
+		want    map[string]interface{} // Checked answers of a valid submission
+	}{
+		{
+			name:    "required answers missing",
+			answers: map[string]interface{}{},
+			wantErr: []string{"institution", "days", "consent"},
+		},
+		{
+			name:    "blank and empty answers count as missing",
+			answers: map[string]interface{}{"institution": "   ", "days": []interface{}{}, "consent": nil},
+			wantErr: []string{"institution", "days", "consent"},
+		},
+		{
+			name:    "optional answers may be empty",
+			answers: mergeAnswers(valid(), map[string]interface{}{"email": "", "age": nil}),
+			want:    map[string]interface{}{"institution": "RSUD Kota", "consent": true},
+		},
+		{
+			name:    "answers are normalized and unknown keys dropped",
+			answers: mergeAnswers(valid(), map[string]interface{}{"institution": "  RSUD Kota  ", "age": "30", "consent": "true", "days": []interface{}{"Sun", "Sun"}, "unknown": "x"}),
+			want:    map[string]interface{}{"institution": "RSUD Kota", "age": float64(30), "consent": true, "days": []string{"Sun"}},
+		},
+		{
+			name:    "required boolean must be accepted",
+			answers: mergeAnswers(valid(), map[string]interface{}{"consent": false}),
+			wantErr: []string{"consent"},
+		},
+		{
+			name:    "text length",
+			answers: mergeAnswers(valid(), map[string]interface{}{"institution": "RS"}),
+			wantErr: []string{"institution"},
+		},
+		{
+			name: "invalid formats",
+			answers: mergeAnswers(valid(), map[string]interface{}{
+				"email":      "Member <member@example.com>",
+				"phone":      "call me",
+				"str_number": "12a4",
+				"birth_date": "17-08-1990",
+			}),
+			wantErr: []string{"email", "phone", "str_number", "birth_date"},
+		},
+		{
+			name:    "number bounds",
+			answers: mergeAnswers(valid(), map[string]interface{}{"age": float64(16)}),
+			wantErr: []string{"age"},
+		},
+		{
+			name:    "options not on the form",
+			answers: mergeAnswers(valid(), map[string]interface{}{"size": "XL", "days": []interface{}{"Mon"}}),
+			wantErr: []string{"size", "days"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalized, errs := ValidateFormAnswers(fields, tt.answers)
+			if tt.wantErr == nil {
+				if errs != nil {
+					t.Fatalf("expected no errors, got %v", errs)
+				}
+				for key, want := range tt.want {
+					if got := normalized[key]; !reflect.DeepEqual(got, want) {
+						t.Errorf("%s: expected %#v, got %#v", key, want, got)
+					}
+				}
+				if _, ok := normalized["unknown"]; ok {
+					t.Errorf("unknown answer was kept")
+				}
+				return
+			}
+			if len(errs) != len(tt.wantErr) {
+				t.Fatalf("expected errors %v, got %v", tt.wantErr, errs)
+			}
+			for _, key := range tt.wantErr {
+				if _, ok := errs[key]; !ok {
+					t.Errorf("expected error for %s, got %v", key, errs)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatFormAnswer(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{nil, ""},
+		{"text", "text"},
+		{true, "Ya"},
+		{false, "Tidak"},
+		{float64(2.5), "2.5"},
+		{[]interface{}{"Sat", "Sun"}, "Sat; Sun"},
+		{[]string{"S"}, "S"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatFormAnswer(tt.value); got != tt.want {
+			t.Errorf("FormatFormAnswer(%#v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+// mergeAnswers returns base with the values of override applied
+func mergeAnswers(base, override map[string]interface{}) map[string]interface{} {
+	for key, value := range override {
+		base[key] = value
+	}
+	return base
+}
@@ -0,0 +1,185 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // Time zones must resolve even on hosts without a zoneinfo database
+)
+
+// DefaultTimezone is the time zone events are published in
+const DefaultTimezone = "Asia/Jakarta"
+
+// icalTimeLayout is the local DATE-TIME format used with TZID
+const icalTimeLayout = "20060102T150405"
+
+// icalUTCLayout is the UTC DATE-TIME format
+const icalUTCLayout = "20060102T150405Z"
+
+// ICalEvent is a single VEVENT
+type ICalEvent struct {
+	UID          string
+	Sequence     int
+	Summary      string
+	Description  string
+	Location     string
+	URL          string
+	Start        time.Time
+	End          time.Time
+	Status       string // CONFIRMED, TENTATIVE or CANCELLED
+	Created      time.Time
+	LastModified time.Time
+}
+
+// ICalendar is a VCALENDAR with its events
+type ICalendar struct {
+	Name     string
+	Timezone *time.Location
+	Events   []ICalEvent
+}
+
+// LoadTimezone loads a time zone, falling back to a fixed WIB (UTC+7) zone
+func LoadTimezone(name string) *time.Location {
+	if name == "" {
+		name = DefaultTimezone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.FixedZone("WIB", 7*60*60)
+	}
+	return loc
+}
+
+// ICalUIDHost returns the host part of event UIDs from the application URL
+func ICalUIDHost(appURL string) string {
+	if parsed, err := url.Parse(appURL); err == nil && parsed.Hostname() != "" {
+		return parsed.Hostname()
+	}
+	return "localhost"
+}
+
+// GenerateToken returns a random hex token of n bytes
+func GenerateToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// String renders the calendar as an RFC 5545 document
+func (cal ICalendar) String() string {
+	loc := cal.Timezone
+	if loc == nil {
+		loc = LoadTimezone(DefaultTimezone)
+	}
+
+	var b strings.Builder
+	writeICalLine(&b, "BEGIN:VCALENDAR")
+	writeICalLine(&b, "VERSION:2.0")
+	writeICalLine(&b, "PRODID:-//PDPI//Agenda//ID")
+	writeICalLine(&b, "CALSCALE:GREGORIAN")
+	writeICalLine(&b, "METHOD:PUBLISH")
+	if cal.Name != "" {
+		writeICalLine(&b, "X-WR-CALNAME:"+escapeICalText(cal.Name))
+	}
+	writeICalLine(&b, "X-WR-TIMEZONE:"+loc.String())
+	writeICalTimezone(&b, loc)
+
+	now := time.Now().UTC().Format(icalUTCLayout)
+	tzid := ";TZID=" + loc.String()
+
+	for _, event := range cal.Events {
+		writeICalLine(&b, "BEGIN:VEVENT")
+		writeICalLine(&b, "UID:"+event.UID)
+		writeICalLine(&b, "DTSTAMP:"+now)
+		writeICalLine(&b, "SEQUENCE:"+strconv.Itoa(event.Sequence))
+		writeICalLine(&b, "DTSTART"+tzid+":"+event.Start.In(loc).Format(icalTimeLayout))
+		writeICalLine(&b, "DTEND"+tzid+":"+event.End.In(loc).Format(icalTimeLayout))
+		writeICalLine(&b, "SUMMARY:"+escapeICalText(event.Summary))
+		if event.Description != "" {
+			writeICalLine(&b, "DESCRIPTION:"+escapeICalText(event.Description))
+		}
+		if event.Location != "" {
+			writeICalLine(&b, "LOCATION:"+escapeICalText(event.Location))
+		}
+		if event.URL != "" {
+			writeICalLine(&b, "URL:"+event.URL)
+		}
+		if event.Status != "" {
+			writeICalLine(&b, "STATUS:"+event.Status)
+		}
+		if !event.Created.IsZero() {
+			writeICalLine(&b, "CREATED:"+event.Created.UTC().Format(icalUTCLayout))
+		}
+		if !event.LastModified.IsZero() {
+			writeICalLine(&b, "LAST-MODIFIED:"+event.LastModified.UTC().Format(icalUTCLayout))
+		}
+		writeICalLine(&b, "END:VEVENT")
+	}
+
+	writeICalLine(&b, "END:VCALENDAR")
+	return b.String()
+}
+
+// writeICalTimezone writes a VTIMEZONE for a zone without daylight saving time (such as WIB, WITA and WIT)
+func writeICalTimezone(b *strings.Builder, loc *time.Location) {
+	name, offset := time.Now().In(loc).Zone()
+	sign := "+"
+	if offset < 0 {
+		sign = "-"
+		offset = -offset
+	}
+	utcOffset := sign + twoDigits(offset/3600) + twoDigits(offset%3600/60)
+
+	writeICalLine(b, "BEGIN:VTIMEZONE")
+	writeICalLine(b, "TZID:"+loc.String())
+	writeICalLine(b, "BEGIN:STANDARD")
+	writeICalLine(b, "DTSTART:19700101T000000")
+	writeICalLine(b, "TZOFFSETFROM:"+utcOffset)
+	writeICalLine(b, "TZOFFSETTO:"+utcOffset)
+	writeICalLine(b, "TZNAME:"+name)
+	writeICalLine(b, "END:STANDARD")
+	writeICalLine(b, "END:VTIMEZONE")
+}
+
+// writeICalLine writes a content line folded at 75 octets without splitting UTF-8 characters
+func writeICalLine(b *strings.Builder, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = 74 // Continuation lines start with a space
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+// escapeICalText escapes a TEXT property value
+func escapeICalText(text string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+		"\r", `\n`,
+	)
+	return replacer.Replace(text)
+}
+
+// twoDigits formats a number with a leading zero
+func twoDigits(n int) string {
+	if n < 10 {
+		return "0" + strconv.Itoa(n)
+	}
+	return strconv.Itoa(n)
+}
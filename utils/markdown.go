@@ -0,0 +1,297 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	goldmarkhtml "github.com/yuin/goldmark/renderer/html"
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// wordsPerMinute is the average reading speed used for reading time estimates
+const wordsPerMinute = 200
+
+// TOCItem is a heading entry in a table of contents
+type TOCItem struct {
+	Level int    `json:"level"`
+	ID    string `json:"id"`
+	Text  string `json:"text"`
+}
+
+// RenderedContent is the result of rendering and sanitizing content
+type RenderedContent struct {
+	HTML        string    `json:"html"`
+	TOC         []TOCItem `json:"toc"`
+	ReadingTime int       `json:"reading_time"` // in minutes
+}
+
+var (
+	markdownRenderer = goldmark.New(
+		goldmark.WithExtensions(
+			// GFM, with table alignment as align attributes because the sanitizer drops style
+			extension.Linkify,
+			extension.NewTable(extension.WithTableCellAlignMethod(extension.TableCellAlignAttribute)),
+			extension.Strikethrough,
+			extension.TaskList,
+		),
+		goldmark.WithRendererOptions(
+			// Raw HTML is allowed through here and removed by the sanitizer afterwards
+			goldmarkhtml.WithUnsafe(),
+		),
+	)
+
+	sanitizerPolicy     *bluemonday.Policy
+	sanitizerPolicyOnce sync.Once
+
+	codeLanguageClass = regexp.MustCompile(`^language-[a-zA-Z0-9_+-]+$`)
+)
+
+// contentPolicy returns the allowlist policy used for all user-authored HTML
+func contentPolicy() *bluemonday.Policy {
+	sanitizerPolicyOnce.Do(func() {
+		p := bluemonday.UGCPolicy()
+
+		// Heading anchors and GFM task lists
+		p.AllowAttrs("id").OnElements("h1", "h2", "h3", "h4", "h5", "h6")
+		p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+		p.AllowAttrs("checked", "disabled").OnElements("input")
+
+		// Syntax highlighting hints on code blocks
+		p.AllowAttrs("class").Matching(codeLanguageClass).OnElements("code")
+
+		// Table alignment produced by GFM tables
+		p.AllowAttrs("align").Matching(regexp.MustCompile(`^(left|right|center)$`)).OnElements("th", "td")
+
+		// External links open safely
+		p.RequireNoFollowOnLinks(true)
+		p.AddTargetBlankToFullyQualifiedLinks(true)
+
+		sanitizerPolicy = p
+	})
+	return sanitizerPolicy
+}
+
+// SanitizeHTML removes everything not in the content allowlist (scripts, event handlers, javascript: URLs, ...)
+func SanitizeHTML(input string) string {
+	if input == "" {
+		return ""
+	}
+	return contentPolicy().Sanitize(input)
+}
+
+// RenderMarkdown renders GFM markdown to sanitized HTML with heading anchors, a TOC and reading time
+func RenderMarkdown(markdown string) (*RenderedContent, error) {
+	var buf bytes.Buffer
+	if err := markdownRenderer.Convert([]byte(markdown), &buf); err != nil {
+		return nil, fmt.Errorf("failed to render markdown: %v", err)
+	}
+	return ProcessHTML(buf.String())
+}
+
+// RenderContent renders content authored either as markdown or as HTML
+// Markdown wins when present so the stored HTML always matches the markdown source
+func RenderContent(markdown string, rawHTML string) (*RenderedContent, error) {
+	if strings.TrimSpace(markdown) != "" {
+		return RenderMarkdown(markdown)
+	}
+	return ProcessHTML(rawHTML)
+}
+
+// ProcessHTML sanitizes HTML and adds heading anchors, a TOC and reading time
+func ProcessHTML(input string) (*RenderedContent, error) {
+	sanitized := SanitizeHTML(input)
+	if strings.TrimSpace(sanitized) == "" {
+		return &RenderedContent{HTML: "", TOC: []TOCItem{}, ReadingTime: 0}, nil
+	}
+
+	nodes, err := html.ParseFragment(strings.NewReader(sanitized), &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse html: %v", err)
+	}
+
+	toc := []TOCItem{}
+	usedIDs := make(map[string]bool)
+	var text strings.Builder
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			text.WriteString(n.Data)
+			text.WriteString(" ")
+		}
+
+		if level := headingLevel(n); level > 0 {
+			headingText := strings.TrimSpace(nodeText(n))
+			id := uniqueAnchorID(headingAnchorID(n, headingText), usedIDs)
+			setAttr(n, "id", id)
+			if headingText != "" {
+				toc = append(toc, TOCItem{Level: level, ID: id, Text: headingText})
+			}
+		}
+
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+
+	var out bytes.Buffer
+	for _, node := range nodes {
+		walk(node)
+		if err := html.Render(&out, node); err != nil {
+			return nil, fmt.Errorf("failed to render html: %v", err)
+		}
+	}
+
+	return &RenderedContent{
+		HTML:        out.String(),
+		TOC:         toc,
+		ReadingTime: ReadingTime(text.String()),
+	}, nil
+}
+
+// ReadingTime estimates the reading time in minutes of plain text (at least 1 for non-empty text)
+func ReadingTime(text string) int {
+	words := len(strings.Fields(text))
+	if words == 0 {
+		return 0
+	}
+	return int(math.Ceil(float64(words) / wordsPerMinute))
+}
+
+// headingLevel returns 1-6 for heading elements and 0 otherwise
+func headingLevel(n *html.Node) int {
+	if n.Type != html.ElementNode {
+		return 0
+	}
+	switch n.DataAtom {
+	case atom.H1:
+		return 1
+	case atom.H2:
+		return 2
+	case atom.H3:
+		return 3
+	case atom.H4:
+		return 4
+	case atom.H5:
+		return 5
+	case atom.H6:
+		return 6
+	}
+	return 0
+}
+
+// headingAnchorID returns the existing id of a heading or one derived from its text
+func headingAnchorID(n *html.Node, text string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == "id" {
+			if id := NormalizeSlug(attr.Val); id != "" {
+				return id
+			}
+		}
+	}
+	if id := NormalizeSlug(text); id != "" {
+		return id
+	}
+	return "section"
+}
+
+// uniqueAnchorID appends -2, -3, ... when an anchor id is already used
+func uniqueAnchorID(id string, used map[string]bool) string {
+	candidate := GenerateUniqueSlug(id, used)
+	used[candidate] = true
+	return candidate
+}
+
+// nodeText returns the concatenated text of a node and its descendants
+func nodeText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(nodeText(child))
+	}
+	return b.String()
+}
+
+// setAttr sets or replaces an attribute on an element
+func setAttr(n *html.Node, key string, value string) {
+	for i, attr := range n.Attr {
+		if attr.Key == key {
+			n.Attr[i].Val = value
+			return
+		}
+	}
+	n.Attr = append(n.Attr, html.Attribute{Key: key, Val: value})
+}
+
+// HTMLToText converts HTML to plain text, keeping line breaks between block elements
+func HTMLToText(input string) string {
+	nodes, err := html.ParseFragment(strings.NewReader(input), &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div})
+	if err != nil {
+		return input
+	}
+
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			b.WriteString(n.Data)
+		case html.ElementNode:
+			switch n.DataAtom {
+			case atom.Script, atom.Style:
+				return
+			case atom.Br:
+				b.WriteString("\n")
+			case atom.Li:
+				b.WriteString("\n- ")
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+		if n.Type == html.ElementNode && isBlockElement(n.DataAtom) {
+			b.WriteString("\n")
+		}
+	}
+	for _, node := range nodes {
+		walk(node)
+	}
+
+	// Collapse runs of blank lines and surrounding spaces
+	lines := strings.Split(b.String(), "\n")
+	out := make([]string, 0, len(lines))
+	blank := false
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if !blank && len(out) > 0 {
+				out = append(out, "")
+			}
+			blank = true
+			continue
+		}
+		out = append(out, line)
+		blank = false
+	}
+	return strings.TrimSpace(strings.Join(out, "\n"))
+}
+
+// isBlockElement reports whether an element starts a new line in plain text
+func isBlockElement(a atom.Atom) bool {
+	switch a {
+	case atom.P, atom.Div, atom.H1, atom.H2, atom.H3, atom.H4, atom.H5, atom.H6,
+		atom.Ul, atom.Ol, atom.Blockquote, atom.Pre, atom.Table, atom.Tr, atom.Hr:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,256 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSanitizeHTML(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    []string // Substrings the output must contain
+		notWant []string // Substrings the output must not contain
+	}{
+		{
+			name:    "script removed",
+			input:   `<p>Hello</p><script>alert(1)</script>`,
+			want:    []string{"<p>Hello</p>"},
+			notWant: []string{"<script", "alert(1)"},
+		},
+		{
+			name:    "event handler removed",
+			input:   `<img src="/uploads/a.png" onerror="alert(1)">`,
+			want:    []string{`src="/uploads/a.png"`},
+			notWant: []string{"onerror", "alert"},
+		},
+		{
+			name:    "javascript href removed",
+			input:   `<a href="javascript:alert(1)">click</a>`,
+			want:    []string{"click"},
+			notWant: []string{"javascript:"},
+		},
+		{
+			name:  "external links get rel and target",
+			input: `<a href="https://example.com">site</a>`,
+			want:  []string{`href="https://example.com"`, `nofollow`, `target="_blank"`},
+		},
+		{
+			name:    "code language class kept, other classes dropped",
+			input:   `<pre><code class="language-go">x</code></pre><p class="red">y</p>`,
+			want:    []string{`<code class="language-go">`, "<p>y</p>"},
+			notWant: []string{`class="red"`},
+		},
+		{
+			name:  "empty input",
+			input: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SanitizeHTML(tt.input)
+			for _, want := range tt.want {
+				if !strings.Contains(got, want) {
+					t.Errorf("expected %q in %q", want, got)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got, notWant) {
+					t.Errorf("unexpected %q in %q", notWant, got)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     []string
+		notWant  []string
+		wantTOC  []TOCItem
+	}{
+		{
+			name:     "headings get anchors and a TOC",
+			markdown: "# Pendahuluan\n\ntext\n\n## Latar Belakang\n\n### Tujuan",
+			want:     []string{`<h1 id="pendahuluan">`, `<h2 id="latar-belakang">`, `<h3 id="tujuan">`},
+			wantTOC: []TOCItem{
+				{Level: 1, ID: "pendahuluan", Text: "Pendahuluan"},
+				{Level: 2, ID: "latar-belakang", Text: "Latar Belakang"},
+				{Level: 3, ID: "tujuan", Text: "Tujuan"},
+			},
+		},
+		{
+			name:     "duplicate headings get unique anchors",
+			markdown: "## Jadwal\n\n## Jadwal\n\n## Jadwal",
+			want:     []string{`id="jadwal"`, `id="jadwal-2"`, `id="jadwal-3"`},
+			wantTOC: []TOCItem{
+				{Level: 2, ID: "jadwal", Text: "Jadwal"},
+				{Level: 2, ID: "jadwal-2", Text: "Jadwal"},
+				{Level: 2, ID: "jadwal-3", Text: "Jadwal"},
+			},
+		},
+		{
+			name:     "raw html in markdown is sanitized",
+			markdown: "Intro\n\n<script>alert(1)</script>\n\n<img src=\"/a.png\" onerror=\"alert(2)\">\n\n[link](javascript:alert(3))",
+			want:     []string{"<p>Intro</p>", `src="/a.png"`},
+			notWant:  []string{"<script", "onerror", "javascript:"},
+			wantTOC:  []TOCItem{},
+		},
+		{
+			name:     "allowed raw html is kept",
+			markdown: "<h2 id=\"Custom Anchor\">Raw heading</h2>\n\n<em>emphasis</em>",
+			want:     []string{`<h2 id="custom-anchor">`, "<em>emphasis</em>"},
+			wantTOC:  []TOCItem{{Level: 2, ID: "custom-anchor", Text: "Raw heading"}},
+		},
+		{
+			name:     "GFM task list and table",
+			markdown: "- [x] done\n\n| a | b |\n|:-|-:|\n| 1 | 2 |",
+			want:     []string{`type="checkbox"`, "checked", `<th align="left">`, `<td align="right">`},
+			wantTOC:  []TOCItem{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderMarkdown(tt.markdown)
+			if err != nil {
+				t.Fatalf("RenderMarkdown: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(got.HTML, want) {
+					t.Errorf("expected %q in %q", want, got.HTML)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(got.HTML, notWant) {
+					t.Errorf("unexpected %q in %q", notWant, got.HTML)
+				}
+			}
+			if !reflect.DeepEqual(got.TOC, tt.wantTOC) {
+				t.Errorf("expected TOC %+v, got %+v", tt.wantTOC, got.TOC)
+			}
+		})
+	}
+}
+
+func TestProcessHTML(t *testing.T) {
+	tests := []struct {
+		name            string
+		input           string
+		wantHTML        string
+		wantTOC         []TOCItem
+		wantReadingTime int
+	}{
+		{
+			name:     "only unsafe content",
+			input:    `<script>alert(1)</script>`,
+			wantHTML: "",
+			wantTOC:  []TOCItem{},
+		},
+		{
+			name:            "heading without text gets a fallback anchor and no TOC entry",
+			input:           `<h2></h2><p>isi</p>`,
+			wantHTML:        `<h2 id="section"></h2><p>isi</p>`,
+			wantTOC:         []TOCItem{},
+			wantReadingTime: 1,
+		},
+		{
+			name:            "existing ids are normalized and deduplicated",
+			input:           `<h2 id="Bagian">A</h2><h2 id="bagian">B</h2>`,
+			wantHTML:        `<h2 id="bagian">A</h2><h2 id="bagian-2">B</h2>`,
+			wantTOC:         []TOCItem{{Level: 2, ID: "bagian", Text: "A"}, {Level: 2, ID: "bagian-2", Text: "B"}},
+			wantReadingTime: 1,
+		},
+		{
+			name:            "reading time counts words",
+			input:           "<p>" + strings.Repeat("kata ", 401) + "</p>",
+			wantHTML:        "<p>" + strings.Repeat("kata ", 401) + "</p>",
+			wantTOC:         []TOCItem{},
+			wantReadingTime: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ProcessHTML(tt.input)
+			if err != nil {
+				t.Fatalf("ProcessHTML: %v", err)
+			}
+			if got.HTML != tt.wantHTML {
+				t.Errorf("expected HTML %q, got %q", tt.wantHTML, got.HTML)
+			}
+			if !reflect.DeepEqual(got.TOC, tt.wantTOC) {
+				t.Errorf("expected TOC %+v, got %+v", tt.wantTOC, got.TOC)
+			}
+			if got.ReadingTime != tt.wantReadingTime {
+				t.Errorf("expected reading time %d, got %d", tt.wantReadingTime, got.ReadingTime)
+			}
+		})
+	}
+}
+
+func TestReadingTime(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"   \n\t ", 0},
+		{"satu", 1},
+		{strings.Repeat("kata ", 200), 1},
+		{strings.Repeat("kata ", 201), 2},
+		{strings.Repeat("kata\n", 1000), 5},
+	}
+
+	for _, tt := range tests {
+		if got := ReadingTime(tt.text); got != tt.want {
+			t.Errorf("ReadingTime(%d words) = %d, want %d", len(strings.Fields(tt.text)), got, tt.want)
+		}
+	}
+}
+
+func TestHTMLToText(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "paragraphs",
+			input: "<p>Halo</p><p>Dunia</p>",
+			want:  "Halo\nDunia",
+		},
+		{
+			name:  "line breaks and lists",
+			input: "<p>Baris 1<br>Baris 2</p><ul><li>satu</li><li>dua</li></ul>",
+			want:  "Baris 1\nBaris 2\n\n- satu\n- dua",
+		},
+		{
+			name:  "scripts and styles dropped",
+			input: "<style>p{}</style><p>Isi</p><script>alert(1)</script>",
+			want:  "Isi",
+		},
+		{
+			name:  "runs of blank lines collapsed to one",
+			input: "<div><p>A</p><p></p><p></p><p>B</p></div>",
+			want:  "A\n\nB",
+		},
+		{
+			name:  "entities decoded",
+			input: "<p>Tom &amp; Jerry &lt;3</p>",
+			want:  "Tom & Jerry <3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := HTMLToText(tt.input); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
@@ -43,6 +43,17 @@ func Error(c *gin.Context, statusCode int, err string, message string, details i
 	})
 }
 
+// Redirect sends a redirect response with a Location header and a JSON payload
+// the frontend can follow when it does not handle the redirect itself
+func Redirect(c *gin.Context, statusCode int, location string, message string, data interface{}) {
+	c.Header("Location", location)
+	c.JSON(statusCode, SuccessResponse{
+		Success: true,
+		Message: message,
+		Data:    data,
+	})
+}
+
 // ValidationError sends a validation error response (422)
 func ValidationError(c *gin.Context, errors interface{}) {
 	c.JSON(422, ErrorResponse{
@@ -63,6 +74,17 @@ func SetRedisCache(redisClient *redis.Client, ctx context.Context, key string, d
 	return redisClient.Set(ctx, key, jsonData, 0).Err()
 }
 
+// DeleteRedisCacheByPrefix removes all cached keys that start with the given prefix
+func DeleteRedisCacheByPrefix(redisClient *redis.Client, ctx context.Context, prefix string) error {
+	iter := redisClient.Scan(ctx, 0, prefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		if err := redisClient.Del(ctx, iter.Val()).Err(); err != nil {
+			return err
+		}
+	}
+	return iter.Err()
+}
+
 // GetCurrentTimeString returns the current time in "YYYY-MM-DD HH:MM:SS" format for MySQL datetime
 func GetCurrentTimeString() string {
 	return time.Now().Format("2006-01-02 15:04:05")
@@ -0,0 +1,314 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported recurrence frequencies
+const (
+	RRuleDaily   = "DAILY"
+	RRuleWeekly  = "WEEKLY"
+	RRuleMonthly = "MONTHLY"
+)
+
+// MaxRRuleOccurrences caps how many occurrences a recurrence rule may produce
+const MaxRRuleOccurrences = 500
+
+// ErrInvalidRRule is returned for recurrence rules outside the supported subset
+var ErrInvalidRRule = errors.New("invalid recurrence rule")
+
+// rruleWeekdays maps RFC 5545 weekday codes to weekdays
+var rruleWeekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// RRuleDay is a BYDAY entry, Ordinal selects the nth weekday of a month (negative counts from the end, 0 = every)
+type RRuleDay struct {
+	Ordinal int
+	Weekday time.Weekday
+}
+
+// RRule is a recurrence rule in the supported RFC 5545 subset:
+// FREQ=DAILY|WEEKLY|MONTHLY with INTERVAL, BYDAY and exactly one of COUNT or UNTIL
+type RRule struct {
+	Freq      string
+	Interval  int
+	Count     int
+	Until     time.Time
+	UntilDate bool // UNTIL was a date, it includes that whole day in the series time zone
+	ByDay     []RRuleDay
+}
+
+// ParseRRule parses a recurrence rule such as "FREQ=MONTHLY;BYDAY=2TH;COUNT=12" (an "RRULE:" prefix is allowed)
+func ParseRRule(value string) (*RRule, error) {
+	value = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(value)), "RRULE:")
+	if value == "" {
+		return nil, fmt.Errorf("%w: rule is empty", ErrInvalidRRule)
+	}
+
+	rule := &RRule{Interval: 1}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(value, ";") {
+		key, val, ok := strings.Cut(part, "=")
+		if !ok || val == "" {
+			return nil, fmt.Errorf("%w: malformed part %q", ErrInvalidRRule, part)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("%w: %s is given twice", ErrInvalidRRule, key)
+		}
+		seen[key] = true
+
+		switch key {
+		case "FREQ":
+			if val != RRuleDaily && val != RRuleWeekly && val != RRuleMonthly {
+				return nil, fmt.Errorf("%w: FREQ must be DAILY, WEEKLY or MONTHLY", ErrInvalidRRule)
+			}
+			rule.Freq = val
+		case "INTERVAL":
+			interval, err := strconv.Atoi(val)
+			if err != nil || interval < 1 || interval > 366 {
+				return nil, fmt.Errorf("%w: INTERVAL must be between 1 and 366", ErrInvalidRRule)
+			}
+			rule.Interval = interval
+		case "COUNT":
+			count, err := strconv.Atoi(val)
+			if err != nil || count < 1 || count > MaxRRuleOccurrences {
+				return nil, fmt.Errorf("%w: COUNT must be between 1 and %d", ErrInvalidRRule, MaxRRuleOccurrences)
+			}
+			rule.Count = count
+		case "UNTIL":
+			if until, err := time.Parse(icalUTCLayout, val); err == nil {
+				rule.Until = until
+			} else if until, err := time.Parse("20060102", val); err == nil {
+				rule.Until = until
+				rule.UntilDate = true
+			} else {
+				return nil, fmt.Errorf("%w: UNTIL must be YYYYMMDD or YYYYMMDDTHHMMSSZ", ErrInvalidRRule)
+			}
+		case "BYDAY":
+			for _, code := range strings.Split(val, ",") {
+				day, err := parseRRuleDay(code)
+				if err != nil {
+					return nil, err
+				}
+				rule.ByDay = append(rule.ByDay, day)
+			}
+		default:
+			return nil, fmt.Errorf("%w: %s is not supported", ErrInvalidRRule, key)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, fmt.Errorf("%w: FREQ is required", ErrInvalidRRule)
+	}
+	if (rule.Count > 0) == seen["UNTIL"] {
+		return nil, fmt.Errorf("%w: exactly one of COUNT or UNTIL is required", ErrInvalidRRule)
+	}
+	if rule.Freq != RRuleMonthly {
+		for _, day := range rule.ByDay {
+			if day.Ordinal != 0 {
+				return nil, fmt.Errorf("%w: BYDAY ordinals are only allowed with FREQ=MONTHLY", ErrInvalidRRule)
+			}
+		}
+	}
+
+	return rule, nil
+}
+
+// parseRRuleDay parses a BYDAY entry such as TH, 2TH or -1FR
+func parseRRuleDay(code string) (RRuleDay, error) {
+	code = strings.TrimSpace(code)
+	if len(code) < 2 {
+		return RRuleDay{}, fmt.Errorf("%w: invalid BYDAY %q", ErrInvalidRRule, code)
+	}
+
+	weekday, ok := rruleWeekdays[code[len(code)-2:]]
+	if !ok {
+		return RRuleDay{}, fmt.Errorf("%w: invalid BYDAY %q", ErrInvalidRRule, code)
+	}
+
+	day := RRuleDay{Weekday: weekday}
+	if prefix := code[:len(code)-2]; prefix != "" {
+		ordinal, err := strconv.Atoi(prefix)
+		if err != nil || ordinal == 0 || ordinal < -5 || ordinal > 5 {
+			return RRuleDay{}, fmt.Errorf("%w: invalid BYDAY %q", ErrInvalidRRule, code)
+		}
+		day.Ordinal = ordinal
+	}
+	return day, nil
+}
+
+// String renders the rule in canonical form
+func (r RRule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			codes[i] = strings.ToUpper(day.Weekday.String()[:2])
+			if day.Ordinal != 0 {
+				codes[i] = strconv.Itoa(day.Ordinal) + codes[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	} else if r.UntilDate {
+		parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+	} else {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(icalUTCLayout))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Occurrences expands the rule from start, keeping the wall clock time of start in loc
+// Only dates matching the rule are returned, start itself is skipped when it does not match
+func (r RRule) Occurrences(start time.Time, loc *time.Location) ([]time.Time, error) {
+	if loc == nil {
+		loc = LoadTimezone(DefaultTimezone)
+	}
+	local := start.In(loc)
+
+	until := r.Until
+	if r.UntilDate {
+		until = time.Date(r.Until.Year(), r.Until.Month(), r.Until.Day(), 23, 59, 59, 0, loc)
+	}
+
+	occurrences := []time.Time{}
+	// add appends a candidate and reports whether expansion is finished
+	add := func(candidate time.Time) (bool, error) {
+		if candidate.Before(local) {
+			return false, nil
+		}
+		if r.Count == 0 && candidate.After(until) {
+			return true, nil
+		}
+		if len(occurrences) == MaxRRuleOccurrences {
+			return true, fmt.Errorf("%w: the rule produces more than %d occurrences", ErrInvalidRRule, MaxRRuleOccurrences)
+		}
+		occurrences = append(occurrences, candidate)
+		return r.Count > 0 && len(occurrences) == r.Count, nil
+	}
+
+	// at returns the start time of an occurrence on a local date
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, local.Hour(), local.Minute(), local.Second(), 0, loc)
+	}
+
+	// Candidates are generated in periods (days, weeks or months), stepping by INTERVAL
+	for period := 0; ; period += r.Interval {
+		var candidates []time.Time
+
+		switch r.Freq {
+		case RRuleDaily:
+			day := at(local.Year(), local.Month(), local.Day()+period)
+			if r.matchesWeekday(day.Weekday()) {
+				candidates = append(candidates, day)
+			}
+
+		case RRuleWeekly:
+			// Weeks start on Monday (WKST=MO)
+			monday := local.Day() - (int(local.Weekday())+6)%7 + period*7
+			for offset := 0; offset < 7; offset++ {
+				day := at(local.Year(), local.Month(), monday+offset)
+				if (len(r.ByDay) == 0 && day.Weekday() == local.Weekday()) || (len(r.ByDay) > 0 && r.matchesWeekday(day.Weekday())) {
+					candidates = append(candidates, day)
+				}
+			}
+
+		case RRuleMonthly:
+			first := time.Date(local.Year(), local.Month()+time.Month(period), 1, 0, 0, 0, 0, loc)
+			candidates = r.monthlyCandidates(first.Year(), first.Month(), local.Day(), at)
+		}
+
+		for _, candidate := range candidates {
+			done, err := add(candidate)
+			if err != nil {
+				return nil, err
+			}
+			if done {
+				return occurrences, nil
+			}
+		}
+
+		// Safety net, every supported rule matches at least once in a few periods
+		if period > 366*100 {
+			return occurrences, nil
+		}
+	}
+}
+
+// monthlyCandidates returns the occurrences within a month in chronological order
+func (r RRule) monthlyCandidates(year int, month time.Month, startDay int, at func(int, time.Month, int) time.Time) []time.Time {
+	daysInMonth := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+
+	// Without BYDAY the series repeats on the day of month of the first occurrence, skipping months without that day
+	if len(r.ByDay) == 0 {
+		if startDay > daysInMonth {
+			return nil
+		}
+		return []time.Time{at(year, month, startDay)}
+	}
+
+	days := map[int]bool{}
+	firstWeekday := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC).Weekday()
+	for _, byDay := range r.ByDay {
+		// First day of the month with this weekday
+		first := 1 + (int(byDay.Weekday)-int(firstWeekday)+7)%7
+
+		var matches []int
+		for day := first; day <= daysInMonth; day += 7 {
+			matches = append(matches, day)
+		}
+
+		switch {
+		case byDay.Ordinal == 0:
+			for _, day := range matches {
+				days[day] = true
+			}
+		case byDay.Ordinal > 0 && byDay.Ordinal <= len(matches):
+			days[matches[byDay.Ordinal-1]] = true
+		case byDay.Ordinal < 0 && -byDay.Ordinal <= len(matches):
+			days[matches[len(matches)+byDay.Ordinal]] = true
+		}
+	}
+
+	sorted := make([]int, 0, len(days))
+	for day := range days {
+		sorted = append(sorted, day)
+	}
+	sort.Ints(sorted)
+
+	candidates := make([]time.Time, len(sorted))
+	for i, day := range sorted {
+		candidates[i] = at(year, month, day)
+	}
+	return candidates
+}
+
+// matchesWeekday reports whether a weekday is allowed by BYDAY (every weekday when BYDAY is not set)
+func (r RRule) matchesWeekday(weekday time.Weekday) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, day := range r.ByDay {
+		if day.Weekday == weekday {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,176 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseRRule(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    string // Canonical form, empty when the rule is invalid
+		wantErr bool
+	}{
+		{name: "count", value: "FREQ=WEEKLY;COUNT=4", want: "FREQ=WEEKLY;COUNT=4"},
+		{name: "prefix and lower case", value: "rrule:freq=monthly;byday=2th;count=12", want: "FREQ=MONTHLY;BYDAY=2TH;COUNT=12"},
+		{name: "until date", value: "FREQ=DAILY;INTERVAL=2;UNTIL=20250131", want: "FREQ=DAILY;INTERVAL=2;UNTIL=20250131"},
+		{name: "until timestamp", value: "FREQ=WEEKLY;BYDAY=MO,WE;UNTIL=20250301T000000Z", want: "FREQ=WEEKLY;BYDAY=MO,WE;UNTIL=20250301T000000Z"},
+		{name: "last friday", value: "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3", want: "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3"},
+		{name: "empty", value: " ", wantErr: true},
+		{name: "missing freq", value: "COUNT=3", wantErr: true},
+		{name: "unsupported freq", value: "FREQ=YEARLY;COUNT=3", wantErr: true},
+		{name: "count and until", value: "FREQ=DAILY;COUNT=3;UNTIL=20250131", wantErr: true},
+		{name: "neither count nor until", value: "FREQ=DAILY", wantErr: true},
+		{name: "count too large", value: "FREQ=DAILY;COUNT=501", wantErr: true},
+		{name: "zero interval", value: "FREQ=DAILY;INTERVAL=0;COUNT=3", wantErr: true},
+		{name: "duplicate part", value: "FREQ=DAILY;COUNT=3;COUNT=4", wantErr: true},
+		{name: "malformed until", value: "FREQ=DAILY;UNTIL=2025-01-31", wantErr: true},
+		{name: "ordinal on weekly", value: "FREQ=WEEKLY;BYDAY=1MO;COUNT=3", wantErr: true},
+		{name: "invalid ordinal", value: "FREQ=MONTHLY;BYDAY=6MO;COUNT=3", wantErr: true},
+		// Cancelled occurrences are stored as exceptions of the series, EXDATE is not part of a rule
+		{name: "exdate", value: "FREQ=DAILY;COUNT=3;EXDATE=20250102", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRRule(tt.value)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidRRule) {
+					t.Fatalf("expected ErrInvalidRRule, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := rule.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRRuleOccurrences(t *testing.T) {
+	jakarta := LoadTimezone(DefaultTimezone)
+	newYork := LoadTimezone("America/New_York")
+
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time
+		loc   *time.Location
+		want  []string // Local times, formatted with time.RFC3339
+	}{
+		{
+			name:  "daily count",
+			rule:  "FREQ=DAILY;COUNT=3",
+			start: time.Date(2025, 1, 30, 9, 0, 0, 0, jakarta),
+			loc:   jakarta,
+			want:  []string{"2025-01-30T09:00:00+07:00", "2025-01-31T09:00:00+07:00", "2025-02-01T09:00:00+07:00"},
+		},
+		{
+			name:  "until date includes the whole day",
+			rule:  "FREQ=DAILY;INTERVAL=2;UNTIL=20250204",
+			start: time.Date(2025, 1, 31, 19, 30, 0, 0, jakarta),
+			loc:   jakarta,
+			want:  []string{"2025-01-31T19:30:00+07:00", "2025-02-02T19:30:00+07:00", "2025-02-04T19:30:00+07:00"},
+		},
+		{
+			name:  "until timestamp is exclusive of later times",
+			rule:  "FREQ=WEEKLY;UNTIL=20250217T015959Z",
+			start: time.Date(2025, 2, 3, 9, 0, 0, 0, jakarta),
+			loc:   jakarta,
+			want:  []string{"2025-02-03T09:00:00+07:00", "2025-02-10T09:00:00+07:00"},
+		},
+		{
+			name:  "weekly by day skips a start that does not match",
+			rule:  "FREQ=WEEKLY;BYDAY=MO,FR;COUNT=3",
+			start: time.Date(2025, 1, 29, 8, 0, 0, 0, jakarta), // Wednesday
+			loc:   jakarta,
+			want:  []string{"2025-01-31T08:00:00+07:00", "2025-02-03T08:00:00+07:00", "2025-02-07T08:00:00+07:00"},
+		},
+		{
+			name:  "monthly on the 31st skips short months",
+			rule:  "FREQ=MONTHLY;COUNT=3",
+			start: time.Date(2025, 1, 31, 10, 0, 0, 0, jakarta),
+			loc:   jakarta,
+			want:  []string{"2025-01-31T10:00:00+07:00", "2025-03-31T10:00:00+07:00", "2025-05-31T10:00:00+07:00"},
+		},
+		{
+			name:  "monthly last friday",
+			rule:  "FREQ=MONTHLY;BYDAY=-1FR;UNTIL=20250430",
+			start: time.Date(2025, 1, 1, 13, 0, 0, 0, jakarta),
+			loc:   jakarta,
+			want:  []string{"2025-01-31T13:00:00+07:00", "2025-02-28T13:00:00+07:00", "2025-03-28T13:00:00+07:00", "2025-04-25T13:00:00+07:00"},
+		},
+		{
+			name:  "fifth weekday only in months that have one",
+			rule:  "FREQ=MONTHLY;BYDAY=5SA;COUNT=2",
+			start: time.Date(2025, 1, 1, 9, 0, 0, 0, jakarta),
+			loc:   jakarta,
+			want:  []string{"2025-03-29T09:00:00+07:00", "2025-05-31T09:00:00+07:00"},
+		},
+		{
+			name:  "daily across the start of daylight saving time keeps the wall clock",
+			rule:  "FREQ=DAILY;COUNT=3",
+			start: time.Date(2025, 3, 8, 9, 0, 0, 0, newYork),
+			loc:   newYork,
+			want:  []string{"2025-03-08T09:00:00-05:00", "2025-03-09T09:00:00-04:00", "2025-03-10T09:00:00-04:00"},
+		},
+		{
+			name:  "weekly across the end of daylight saving time until a date",
+			rule:  "FREQ=WEEKLY;UNTIL=20251110",
+			start: time.Date(2025, 10, 27, 18, 0, 0, 0, newYork),
+			loc:   newYork,
+			want:  []string{"2025-10-27T18:00:00-04:00", "2025-11-03T18:00:00-05:00", "2025-11-10T18:00:00-05:00"},
+		},
+		{
+			name:  "start given in another zone is expanded in the series zone",
+			rule:  "FREQ=DAILY;COUNT=2",
+			start: time.Date(2025, 1, 31, 17, 0, 0, 0, time.UTC),
+			loc:   jakarta,
+			want:  []string{"2025-02-01T00:00:00+07:00", "2025-02-02T00:00:00+07:00"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := ParseRRule(tt.rule)
+			if err != nil {
+				t.Fatalf("ParseRRule(%q): %v", tt.rule, err)
+			}
+
+			occurrences, err := rule.Occurrences(tt.start, tt.loc)
+			if err != nil {
+				t.Fatalf("Occurrences: %v", err)
+			}
+
+			got := make([]string, len(occurrences))
+			for i, occurrence := range occurrences {
+				got[i] = occurrence.Format(time.RFC3339)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("occurrence %d = %s, want %s", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRRuleOccurrencesLimit(t *testing.T) {
+	rule, err := ParseRRule("FREQ=DAILY;UNTIL=20300101")
+	if err != nil {
+		t.Fatalf("ParseRRule: %v", err)
+	}
+
+	_, err = rule.Occurrences(time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC), time.UTC)
+	if !errors.Is(err, ErrInvalidRRule) {
+		t.Fatalf("expected ErrInvalidRRule for more than %d occurrences, got %v", MaxRRuleOccurrences, err)
+	}
+}
@@ -84,12 +84,21 @@ func GenerateUniqueSlug(baseSlug string, existingSlugs map[string]bool) string {
 	return baseSlug + "-" + fmt.Sprintf("%.0f", GetCurrentUnixTimestamp())
 }
 
+// numericSlugRegex matches slugs made of digits only
+var numericSlugRegex = regexp.MustCompile(`^[0-9]+$`)
+
+// IsNumericSlug reports whether a slug is made of digits only, such path segments are read as IDs
+func IsNumericSlug(slug string) bool {
+	return numericSlugRegex.MatchString(slug)
+}
+
 // ValidateSlug checks if a slug is valid
 // A valid slug should:
 // - Not be empty
 // - Be lowercase
 // - Only contain alphanumeric characters and hyphens
 // - Not start or end with hyphen
+// - Not consist of digits only
 func ValidateSlug(slug string) bool {
 	if slug == "" {
 		return false
@@ -100,6 +109,11 @@ func ValidateSlug(slug string) bool {
 		return false
 	}
 
+	// All-digit slugs are reserved for IDs
+	if IsNumericSlug(slug) {
+		return false
+	}
+
 	// Check if it contains only valid characters
 	validSlugRegex := regexp.MustCompile("^[a-z0-9-]+$")
 	return validSlugRegex.MatchString(slug)
@@ -0,0 +1,379 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// maxXLSXPartSize limits the uncompressed size of a single XLSX part to guard against zip bombs
+const maxXLSXPartSize = 64 << 20
+
+// ErrUnsupportedSpreadsheet is returned for files that are neither CSV nor XLSX
+var ErrUnsupportedSpreadsheet = errors.New("unsupported spreadsheet format, use .csv or .xlsx")
+
+// SpreadsheetFormat returns "csv" or "xlsx" from a file name, or an empty string when unsupported
+func SpreadsheetFormat(filename string) string {
+	switch strings.ToLower(path.Ext(filename)) {
+	case ".csv":
+		return "csv"
+	case ".xlsx":
+		return "xlsx"
+	}
+	return ""
+}
+
+// ReadSpreadsheet reads all rows of a CSV file or of the first worksheet of an XLSX file
+// Trailing empty rows are dropped and every cell is trimmed
+func ReadSpreadsheet(filename string, data []byte) ([][]string, error) {
+	var rows [][]string
+	var err error
+
+	switch SpreadsheetFormat(filename) {
+	case "csv":
+		rows, err = readCSV(data)
+	case "xlsx":
+		rows, err = readXLSX(data)
+	default:
+		return nil, ErrUnsupportedSpreadsheet
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rows {
+		for j := range rows[i] {
+			rows[i][j] = strings.TrimSpace(rows[i][j])
+		}
+	}
+	for len(rows) > 0 && isEmptyRow(rows[len(rows)-1]) {
+		rows = rows[:len(rows)-1]
+	}
+	return rows, nil
+}
+
+// isEmptyRow reports whether all cells of a row are empty
+func isEmptyRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// readCSV parses CSV data, detecting semicolon separated files (the default of spreadsheet apps in Indonesian locale)
+func readCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	firstLine := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		firstLine = data[:i]
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	if bytes.Count(firstLine, []byte(";")) > bytes.Count(firstLine, []byte(",")) {
+		reader.Comma = ';'
+	}
+	return reader.ReadAll()
+}
+
+// xlsxCell is a cell of a worksheet
+type xlsxCell struct {
+	Ref    string `xml:"r,attr"`
+	Type   string `xml:"t,attr"`
+	Value  string `xml:"v"`
+	Inline struct {
+		Text string `xml:"t"`
+		Runs []struct {
+			Text string `xml:"t"`
+		} `xml:"r"`
+	} `xml:"is"`
+}
+
+// xlsxRow is a row of a worksheet
+type xlsxRow struct {
+	Ref   int        `xml:"r,attr"`
+	Cells []xlsxCell `xml:"c"`
+}
+
+// readXLSX parses the first worksheet of an XLSX workbook
+func readXLSX(data []byte) ([][]string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid xlsx file: %w", err)
+	}
+
+	files := make(map[string]*zip.File, len(archive.File))
+	for _, file := range archive.File {
+		files[file.Name] = file
+	}
+
+	sharedStrings, err := readXLSXSharedStrings(files["xl/sharedStrings.xml"])
+	if err != nil {
+		return nil, err
+	}
+
+	sheet := files[firstXLSXSheetPath(files)]
+	if sheet == nil {
+		return nil, errors.New("invalid xlsx file: no worksheet found")
+	}
+	content, err := readZipPart(sheet)
+	if err != nil {
+		return nil, err
+	}
+
+	var worksheet struct {
+		Rows []xlsxRow `xml:"sheetData>row"`
+	}
+	if err := xml.Unmarshal(content, &worksheet); err != nil {
+		return nil, fmt.Errorf("invalid xlsx worksheet: %w", err)
+	}
+
+	rows := [][]string{}
+	for i, row := range worksheet.Rows {
+		// Rows may be sparse, r is 1-based
+		rowIndex := i
+		if row.Ref > 0 {
+			rowIndex = row.Ref - 1
+		}
+		for len(rows) < rowIndex {
+			rows = append(rows, []string{})
+		}
+
+		cells := []string{}
+		for j, cell := range row.Cells {
+			column := j
+			if cell.Ref != "" {
+				column = xlsxColumnIndex(cell.Ref)
+			}
+			for len(cells) < column {
+				cells = append(cells, "")
+			}
+			cells = append(cells, xlsxCellValue(cell, sharedStrings))
+		}
+		rows = append(rows, cells)
+	}
+	return rows, nil
+}
+
+// firstXLSXSheetPath resolves the part of the first worksheet through the workbook relationships
+func firstXLSXSheetPath(files map[string]*zip.File) string {
+	fallback := "xl/worksheets/sheet1.xml"
+
+	workbookXML, err := readZipPart(files["xl/workbook.xml"])
+	if err != nil {
+		return fallback
+	}
+	var workbook struct {
+		Sheets []struct {
+			RelID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if xml.Unmarshal(workbookXML, &workbook) != nil || len(workbook.Sheets) == 0 {
+		return fallback
+	}
+
+	relsXML, err := readZipPart(files["xl/_rels/workbook.xml.rels"])
+	if err != nil {
+		return fallback
+	}
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if xml.Unmarshal(relsXML, &rels) != nil {
+		return fallback
+	}
+	for _, rel := range rels.Relationships {
+		if rel.ID == workbook.Sheets[0].RelID {
+			if strings.HasPrefix(rel.Target, "/") {
+				return strings.TrimPrefix(rel.Target, "/")
+			}
+			return path.Join("xl", rel.Target)
+		}
+	}
+	return fallback
+}
+
+// readXLSXSharedStrings reads the shared string table, a workbook without one has no shared strings
+func readXLSXSharedStrings(file *zip.File) ([]string, error) {
+	if file == nil {
+		return nil, nil
+	}
+	content, err := readZipPart(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var table struct {
+		Items []struct {
+			Text string `xml:"t"`
+			Runs []struct {
+				Text string `xml:"t"`
+			} `xml:"r"`
+		} `xml:"si"`
+	}
+	if err := xml.Unmarshal(content, &table); err != nil {
+		return nil, fmt.Errorf("invalid xlsx shared strings: %w", err)
+	}
+
+	strs := make([]string, len(table.Items))
+	for i, item := range table.Items {
+		text := item.Text
+		for _, run := range item.Runs {
+			text += run.Text
+		}
+		strs[i] = text
+	}
+	return strs, nil
+}
+
+// xlsxCellValue returns the text of a cell
+func xlsxCellValue(cell xlsxCell, sharedStrings []string) string {
+	switch cell.Type {
+	case "s":
+		index, err := strconv.Atoi(cell.Value)
+		if err != nil || index < 0 || index >= len(sharedStrings) {
+			return ""
+		}
+		return sharedStrings[index]
+	case "inlineStr":
+		text := cell.Inline.Text
+		for _, run := range cell.Inline.Runs {
+			text += run.Text
+		}
+		return text
+	case "b":
+		if cell.Value == "1" {
+			return "TRUE"
+		}
+		return "FALSE"
+	case "", "n":
+		// Print numbers without binary floating point noise, e.g. 0.1 instead of 0.10000000000000001
+		if number, err := strconv.ParseFloat(cell.Value, 64); err == nil {
+			return strconv.FormatFloat(number, 'f', -1, 64)
+		}
+	}
+	return cell.Value
+}
+
+// xlsxColumnIndex returns the 0-based column of a cell reference such as "C12"
+func xlsxColumnIndex(ref string) int {
+	index := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		index = index*26 + int(r-'A'+1)
+	}
+	return index - 1
+}
+
+// xlsxColumnName returns the letters of a 0-based column, e.g. 27 => "AB"
+func xlsxColumnName(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
+
+// readZipPart reads a zip entry, refusing entries larger than maxXLSXPartSize
+func readZipPart(file *zip.File) ([]byte, error) {
+	if file == nil {
+		return nil, errors.New("missing xlsx part")
+	}
+	rc, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	content, err := io.ReadAll(io.LimitReader(rc, maxXLSXPartSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(content) > maxXLSXPartSize {
+		return nil, errors.New("xlsx file is too large")
+	}
+	return content, nil
+}
+
+// WriteXLSX writes rows as a single worksheet XLSX workbook with text cells
+func WriteXLSX(w io.Writer, sheetName string, rows [][]string) error {
+	archive := zip.NewWriter(w)
+
+	parts := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+			`</Types>`},
+		{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="` + xmlEscape(sheetName) + `" sheetId="1" r:id="rId1"/></sheets>` +
+			`</workbook>`},
+		{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`</Relationships>`},
+	}
+	for _, part := range parts {
+		fw, err := archive.Create(part.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, part.content); err != nil {
+			return err
+		}
+	}
+
+	fw, err := archive.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	var sheet strings.Builder
+	sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`)
+	sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for i, row := range rows {
+		fmt.Fprintf(&sheet, `<row r="%d">`, i+1)
+		for j, value := range row {
+			fmt.Fprintf(&sheet, `<c r="%s%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, xlsxColumnName(j), i+1, xmlEscape(value))
+		}
+		sheet.WriteString(`</row>`)
+	}
+	sheet.WriteString(`</sheetData></worksheet>`)
+	if _, err := io.WriteString(fw, sheet.String()); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
+
+// xmlEscape escapes text for XML content and attributes
+func xmlEscape(value string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(value))
+	return b.String()
+}
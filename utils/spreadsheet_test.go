@@ -0,0 +1,232 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// buildXLSX zips workbook parts into an XLSX file
+func buildXLSX(t *testing.T, parts map[string]string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range parts {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatalf("close xlsx: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// xlsxSheet wraps sheet data in a worksheet part
+func xlsxSheet(sheetData string) string {
+	return `<?xml version="1.0" encoding="UTF-8"?>` +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+		sheetData + `</sheetData></worksheet>`
+}
+
+const xlsxSharedStrings = `<?xml version="1.0" encoding="UTF-8"?>` +
+	`<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<si><t>name</t></si><si><t>city</t></si><si><r><t>RS </t></r><r><t>Sehat</t></r></si>` +
+	`</sst>`
+
+func TestReadSpreadsheetXLSX(t *testing.T) {
+	tests := []struct {
+		name  string
+		parts map[string]string
+		want  [][]string
+	}{
+		{
+			name: "shared and inline strings, numbers and booleans",
+			parts: map[string]string{
+				"xl/sharedStrings.xml": xlsxSharedStrings,
+				"xl/worksheets/sheet1.xml": xlsxSheet(
+					`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c></row>` +
+						`<row r="2"><c r="A2" t="s"><v>2</v></c><c r="B2" t="inlineStr"><is><t> Malang </t></is></c>` +
+						`<c r="C2"><v>0.10000000000000001</v></c><c r="D2" t="b"><v>1</v></c></row>`),
+			},
+			want: [][]string{{"name", "city"}, {"RS Sehat", "Malang", "0.1", "TRUE"}},
+		},
+		{
+			name: "blank trailing rows are dropped",
+			parts: map[string]string{
+				"xl/sharedStrings.xml": xlsxSharedStrings,
+				"xl/worksheets/sheet1.xml": xlsxSheet(
+					`<row r="1"><c r="A1" t="s"><v>0</v></c></row>` +
+						`<row r="2"><c r="A2" t="s"><v>2</v></c></row>` +
+						`<row r="3"><c r="A3" t="inlineStr"><is><t>  </t></is></c><c r="B3"/></row>` +
+						`<row r="4"/>` +
+						`<row r="9"><c r="C9" t="inlineStr"><is><t></t></is></c></row>`),
+			},
+			want: [][]string{{"name"}, {"RS Sehat"}},
+		},
+		{
+			name: "blank rows and cells inside the data are kept",
+			parts: map[string]string{
+				"xl/worksheets/sheet1.xml": xlsxSheet(
+					`<row r="1"><c r="A1" t="inlineStr"><is><t>a</t></is></c><c r="C1" t="inlineStr"><is><t>c</t></is></c></row>` +
+						`<row r="3"><c r="B3"><v>42</v></c></row>`),
+			},
+			want: [][]string{{"a", "", "c"}, {}, {"", "42"}},
+		},
+		{
+			name: "first sheet is resolved through the workbook relationships",
+			parts: map[string]string{
+				"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+					`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+					`<sheets><sheet name="Data" sheetId="1" r:id="rId7"/></sheets></workbook>`,
+				"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+					`<Relationship Id="rId7" Target="worksheets/data.xml"/></Relationships>`,
+				"xl/worksheets/sheet1.xml": xlsxSheet(`<row r="1"><c r="A1"><v>1</v></c></row>`),
+				"xl/worksheets/data.xml":   xlsxSheet(`<row r="1"><c r="A1"><v>2</v></c></row>`),
+			},
+			want: [][]string{{"2"}},
+		},
+		{
+			name: "only blank rows",
+			parts: map[string]string{
+				"xl/worksheets/sheet1.xml": xlsxSheet(`<row r="1"/><row r="2"><c r="A2" t="inlineStr"><is><t> </t></is></c></row>`),
+			},
+			want: [][]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := ReadSpreadsheet("direktori.xlsx", buildXLSX(t, tt.parts))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(rows, tt.want) {
+				t.Errorf("expected %q, got %q", tt.want, rows)
+			}
+		})
+	}
+}
+
+func TestReadSpreadsheetCSV(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want [][]string
+	}{
+		{
+			name: "comma separated",
+			data: "name,city\nRS Sehat,Malang\n",
+			want: [][]string{{"name", "city"}, {"RS Sehat", "Malang"}},
+		},
+		{
+			name: "semicolon separated with byte order mark",
+			data: "\xef\xbb\xbfname;city\n\"RS Sehat, Tbk\";Malang\n",
+			want: [][]string{{"name", "city"}, {"RS Sehat, Tbk", "Malang"}},
+		},
+		{
+			name: "blank trailing rows and ragged rows",
+			data: "name,city,phone\n RS Sehat ,Malang\n,,\n\n , \n",
+			want: [][]string{{"name", "city", "phone"}, {"RS Sehat", "Malang"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := ReadSpreadsheet("direktori.CSV", []byte(tt.data))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(rows, tt.want) {
+				t.Errorf("expected %q, got %q", tt.want, rows)
+			}
+		})
+	}
+}
+
+func TestReadSpreadsheetErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		data     []byte
+		wantErr  error // nil when any error is expected
+	}{
+		{name: "unsupported extension", filename: "direktori.xls", data: []byte("x"), wantErr: ErrUnsupportedSpreadsheet},
+		{name: "not a zip file", filename: "direktori.xlsx", data: []byte("name,city")},
+		{name: "no worksheet", filename: "direktori.xlsx", data: buildXLSX(t, map[string]string{"xl/styles.xml": "<styleSheet/>"})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadSpreadsheet(tt.filename, tt.data)
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestWriteXLSXRoundTrip(t *testing.T) {
+	rows := [][]string{
+		{"name", "notes"},
+		{"RS <Sehat> & Co", "line 1\nline 2"},
+		{"", "only second column"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteXLSX(&buf, "Direktori", rows); err != nil {
+		t.Fatalf("WriteXLSX: %v", err)
+	}
+
+	got, err := ReadSpreadsheet("export.xlsx", buf.Bytes())
+	if err != nil {
+		t.Fatalf("ReadSpreadsheet: %v", err)
+	}
+	if !reflect.DeepEqual(got, rows) {
+		t.Errorf("expected %q, got %q", rows, got)
+	}
+}
+
+func TestXLSXColumns(t *testing.T) {
+	tests := []struct {
+		ref   string
+		index int
+	}{
+		{"A1", 0},
+		{"Z10", 25},
+		{"AA3", 26},
+		{"AB12", 27},
+		{"ZZ1", 701},
+		{"AAA1", 702},
+	}
+
+	for _, tt := range tests {
+		if got := xlsxColumnIndex(tt.ref); got != tt.index {
+			t.Errorf("xlsxColumnIndex(%q) = %d, want %d", tt.ref, got, tt.index)
+		}
+		name := tt.ref[:len(tt.ref)-len(trimColumn(tt.ref))]
+		if got := xlsxColumnName(tt.index); got != name {
+			t.Errorf("xlsxColumnName(%d) = %q, want %q", tt.index, got, name)
+		}
+	}
+}
+
+// trimColumn returns the row number part of a cell reference
+func trimColumn(ref string) string {
+	for i, r := range ref {
+		if r >= '0' && r <= '9' {
+			return ref[i:]
+		}
+	}
+	return ""
+}
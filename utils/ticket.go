@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// ticketVersion prefixes tickets so the format can change later
+const ticketVersion = "T1"
+
+// ErrInvalidTicket is returned for malformed or forged tickets
+var ErrInvalidTicket = errors.New("invalid ticket")
+
+// TicketClaims identifies the registration a ticket was issued for
+type TicketClaims struct {
+	RegistrationID int64 `json:"r"`
+	AgendaID       int64 `json:"a"`
+	UserID         int64 `json:"u"`
+}
+
+// SignTicket returns a ticket payload signed with HMAC-SHA256 (T1.<payload>.<signature>)
+// The payload is deterministic so the same registration always gets the same ticket
+func SignTicket(secret string, claims TicketClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := ticketVersion + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(ticketSignature(secret, encoded)), nil
+}
+
+// VerifyTicket checks the signature of a ticket and returns its claims
+func VerifyTicket(secret string, ticket string) (*TicketClaims, error) {
+	parts := strings.Split(strings.TrimSpace(ticket), ".")
+	if len(parts) != 3 || parts[0] != ticketVersion {
+		return nil, ErrInvalidTicket
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidTicket
+	}
+	if !hmac.Equal(signature, ticketSignature(secret, parts[0]+"."+parts[1])) {
+		return nil, ErrInvalidTicket
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidTicket
+	}
+
+	claims := &TicketClaims{}
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return nil, ErrInvalidTicket
+	}
+	return claims, nil
+}
+
+// QRCodePNG renders content as a QR code PNG image
+func QRCodePNG(content string, size int) ([]byte, error) {
+	return qrcode.Encode(content, qrcode.Medium, size)
+}
+
+// ticketSignature computes the HMAC-SHA256 of a ticket payload
+func ticketSignature(secret string, payload string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
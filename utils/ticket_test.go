@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSignTicketRoundTrip(t *testing.T) {
+	claims := TicketClaims{RegistrationID: 42, AgendaID: 7, UserID: 3}
+
+	ticket, err := SignTicket("secret", claims)
+	if err != nil {
+		t.Fatalf("SignTicket: %v", err)
+	}
+	if !strings.HasPrefix(ticket, ticketVersion+".") {
+		t.Errorf("expected %s prefix, got %q", ticketVersion, ticket)
+	}
+	if again, _ := SignTicket("secret", claims); again != ticket {
+		t.Errorf("expected a deterministic ticket, got %q and %q", ticket, again)
+	}
+
+	got, err := VerifyTicket("secret", " "+ticket+"\n")
+	if err != nil {
+		t.Fatalf("VerifyTicket: %v", err)
+	}
+	if !reflect.DeepEqual(*got, claims) {
+		t.Errorf("expected %+v, got %+v", claims, *got)
+	}
+}
+
+func TestVerifyTicketRejects(t *testing.T) {
+	ticket, err := SignTicket("secret", TicketClaims{RegistrationID: 42, AgendaID: 7, UserID: 3})
+	if err != nil {
+		t.Fatalf("SignTicket: %v", err)
+	}
+	parts := strings.Split(ticket, ".")
+
+	// signed returns a correctly signed ticket for any version and payload segment
+	signed := func(version string, payload string) string {
+		encoded := version + "." + payload
+		return encoded + "." + base64.RawURLEncoding.EncodeToString(ticketSignature("secret", encoded))
+	}
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"r":43,"a":7,"u":3}`))
+
+	tests := []struct {
+		name   string
+		secret string
+		ticket string
+	}{
+		{"empty", "secret", ""},
+		{"missing segment", "secret", parts[0] + "." + parts[1]},
+		{"extra segment", "secret", ticket + ".x"},
+		{"tampered payload", "secret", parts[0] + "." + forged + "." + parts[2]},
+		{"tampered signature", "secret", parts[0] + "." + parts[1] + "." + base64.RawURLEncoding.EncodeToString([]byte("forged"))},
+		{"wrong secret", "other-secret", ticket},
+		{"wrong version prefix", "secret", "T2." + parts[1] + "." + parts[2]},
+		{"wrong version prefix re-signed", "secret", signed("T2", parts[1])},
+		{"malformed signature base64", "secret", parts[0] + "." + parts[1] + ".!!!"},
+		{"malformed payload base64", "secret", signed(ticketVersion, "!!!")},
+		{"payload is not json", "secret", signed(ticketVersion, base64.RawURLEncoding.EncodeToString([]byte("not json")))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := VerifyTicket(tt.secret, tt.ticket)
+			if !errors.Is(err, ErrInvalidTicket) {
+				t.Errorf("expected ErrInvalidTicket, got %v", err)
+			}
+			if claims != nil {
+				t.Errorf("expected no claims, got %+v", claims)
+			}
+		})
+	}
+}